@@ -31,6 +31,14 @@ func main() {
 		Commands: []*cliframework.Command{
 			serveCmd,
 			cli.DoctorCommand(fullVersion),
+			cli.ReplayCommand(),
+			cli.GenerateCommand(),
+			cli.ChromeTraceCommand(),
+			cli.AnalyzeCommand(),
+			cli.QueryCommand(),
+			cli.TailCommand(),
+			cli.CollectorConfigCommand(),
+			cli.ConfigCommand(),
 		},
 	}
 
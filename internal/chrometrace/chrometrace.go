@@ -0,0 +1,366 @@
+// Package chrometrace converts stored spans and logs to the Chrome Trace
+// Event format, which Perfetto (ui.perfetto.dev) and chrome://tracing open
+// directly.
+//
+// Each service becomes a process. Spans within a service are packed onto
+// tracks (threads) so that every track holds properly nested spans:
+// overlapping siblings and concurrent traces get tracks of their own, which
+// makes concurrency visible. Correlated logs and span events become instant
+// events on the track of their span, and parent/child calls that cross
+// services are drawn as flow arrows.
+package chrometrace
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// maxInstantName caps the display name of log instant events; the full
+// body is kept in args.
+const maxInstantName = 80
+
+// ErrNoTraces is returned by Export when nothing matches the filter.
+var ErrNoTraces = errors.New("no traces match the filter")
+
+// Trace is a Chrome Trace Event JSON document (the object form).
+type Trace struct {
+	TraceEvents     []Event        `json:"traceEvents"`
+	DisplayTimeUnit string         `json:"displayTimeUnit"`
+	OtherData       map[string]any `json:"otherData,omitempty"`
+
+	// Counts, not serialized
+	Traces int `json:"-"`
+	Spans  int `json:"-"`
+	Logs   int `json:"-"`
+}
+
+// Event is one trace event. Ts and Dur are microseconds relative to the
+// earliest timestamp in the export (see OtherData "base_time").
+type Event struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Ph    string         `json:"ph"`
+	Ts    float64        `json:"ts"`
+	Dur   *float64       `json:"dur,omitempty"`
+	Pid   int            `json:"pid"`
+	Tid   int            `json:"tid"`
+	Scope string         `json:"s,omitempty"`  // instant event scope
+	ID    string         `json:"id,omitempty"` // flow event ID
+	BP    string         `json:"bp,omitempty"` // flow binding point
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// Export gathers every trace with a span matching filter (see
+// storage.ObservabilityStorage.QueryTraces) plus the logs correlated with
+// those traces, and builds a Chrome trace from them.
+func Export(st *storage.ObservabilityStorage, filter storage.QueryFilter) (*Trace, error) {
+	spans, err := st.QueryTraces(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, ErrNoTraces
+	}
+	var traceIDs []string
+	seen := make(map[string]bool)
+	for _, s := range spans {
+		if !seen[s.TraceID] {
+			seen[s.TraceID] = true
+			traceIDs = append(traceIDs, s.TraceID)
+		}
+	}
+	return Build(spans, st.Logs().GetLogsByTraceIDs(traceIDs)), nil
+}
+
+// WriteTo writes the trace as JSON.
+func (t *Trace) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// Build converts spans and logs into a Chrome trace. Spans without a
+// payload are skipped; logs whose span is unknown land on the first track
+// of their service.
+func Build(spans []*storage.StoredSpan, logs []*storage.StoredLog) *Trace {
+	b := &builder{
+		pids:  make(map[string]int),
+		lanes: make(map[int][][]uint64),
+		where: make(map[string]track),
+	}
+
+	valid := make([]*storage.StoredSpan, 0, len(spans))
+	for _, s := range spans {
+		if s.Span != nil {
+			valid = append(valid, s)
+			b.observe(s.Span.StartTimeUnixNano)
+		}
+	}
+	for _, l := range logs {
+		b.observe(logTime(l))
+	}
+
+	// Longest first at equal starts, so parents take a track before children
+	sort.SliceStable(valid, func(i, j int) bool {
+		a, c := valid[i].Span, valid[j].Span
+		if a.StartTimeUnixNano != c.StartTimeUnixNano {
+			return a.StartTimeUnixNano < c.StartTimeUnixNano
+		}
+		return spanEnd(a) > spanEnd(c)
+	})
+
+	t := &Trace{DisplayTimeUnit: "ms"}
+	traces := make(map[string]bool)
+	for _, s := range valid {
+		traces[s.TraceID] = true
+		t.TraceEvents = append(t.TraceEvents, b.spanEvents(s)...)
+	}
+	t.TraceEvents = append(t.TraceEvents, b.flowEvents(valid)...)
+
+	for _, l := range logs {
+		if ts := logTime(l); ts != 0 {
+			t.TraceEvents = append(t.TraceEvents, b.logEvent(l, ts))
+			t.Logs++
+		}
+	}
+
+	t.TraceEvents = append(b.metadata(), t.TraceEvents...)
+	t.Traces, t.Spans = len(traces), len(valid)
+	t.OtherData = map[string]any{
+		"source":    "otlp-mcp",
+		"base_time": time.Unix(0, int64(b.base)).UTC().Format(time.RFC3339Nano),
+		"traces":    t.Traces,
+	}
+	return t
+}
+
+// track is a (process, thread) pair.
+type track struct{ pid, tid int }
+
+type builder struct {
+	base     uint64
+	services []string
+	pids     map[string]int
+	lanes    map[int][][]uint64 // pid -> per-track stack of open span end times
+	where    map[string]track   // span ID -> track it was placed on
+}
+
+func (b *builder) observe(ts uint64) {
+	if ts != 0 && (b.base == 0 || ts < b.base) {
+		b.base = ts
+	}
+}
+
+func (b *builder) micros(ts uint64) float64 {
+	if ts < b.base {
+		return 0
+	}
+	return float64(ts-b.base) / 1000
+}
+
+// pid returns the process ID for a service, assigning the next one.
+func (b *builder) pid(service string) int {
+	if pid, ok := b.pids[service]; ok {
+		return pid
+	}
+	b.services = append(b.services, service)
+	pid := len(b.services)
+	b.pids[service] = pid
+	return pid
+}
+
+// place assigns [start, end) to the first track of pid where it nests
+// inside (or follows) what is already there. Spans must arrive sorted by
+// start time.
+func (b *builder) place(pid int, start, end uint64) int {
+	lanes := b.lanes[pid]
+	for i, stack := range lanes {
+		for len(stack) > 0 && stack[len(stack)-1] <= start {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 || stack[len(stack)-1] >= end {
+			lanes[i] = append(stack, end)
+			return i + 1
+		}
+		lanes[i] = stack
+	}
+	b.lanes[pid] = append(lanes, []uint64{end})
+	return len(b.lanes[pid])
+}
+
+// spanEvents returns the complete event for a span plus instant events for
+// its span events.
+func (b *builder) spanEvents(s *storage.StoredSpan) []Event {
+	sp := s.Span
+	pid := b.pid(s.ServiceName)
+	tid := b.place(pid, sp.StartTimeUnixNano, spanEnd(sp))
+	b.where[s.SpanID] = track{pid, tid}
+
+	dur := float64(spanEnd(sp)-sp.StartTimeUnixNano) / 1000
+	args := map[string]any{
+		"trace_id": s.TraceID,
+		"span_id":  s.SpanID,
+		"kind":     strings.TrimPrefix(sp.Kind.String(), "SPAN_KIND_"),
+		"status":   statusName(sp.Status),
+	}
+	if len(sp.ParentSpanId) > 0 {
+		args["parent_span_id"] = hex.EncodeToString(sp.ParentSpanId)
+	}
+	if sp.Status != nil && sp.Status.Message != "" {
+		args["status_message"] = sp.Status.Message
+	}
+	if attrs := attributeArgs(sp.Attributes); attrs != nil {
+		args["attributes"] = attrs
+	}
+
+	events := []Event{{
+		Name: s.SpanName,
+		Cat:  "span",
+		Ph:   "X",
+		Ts:   b.micros(sp.StartTimeUnixNano),
+		Dur:  &dur,
+		Pid:  pid,
+		Tid:  tid,
+		Args: args,
+	}}
+	for _, ev := range sp.Events {
+		events = append(events, Event{
+			Name:  ev.Name,
+			Cat:   "span_event",
+			Ph:    "i",
+			Ts:    b.micros(max(ev.TimeUnixNano, sp.StartTimeUnixNano)),
+			Pid:   pid,
+			Tid:   tid,
+			Scope: "t",
+			Args:  attributeArgs(ev.Attributes),
+		})
+	}
+	return events
+}
+
+// flowEvents draws an arrow from parent to child for calls that cross a
+// process boundary, starting and ending at the child's start time.
+func (b *builder) flowEvents(spans []*storage.StoredSpan) []Event {
+	var events []Event
+	for i, s := range spans {
+		if len(s.Span.ParentSpanId) == 0 {
+			continue
+		}
+		from, ok := b.where[hex.EncodeToString(s.Span.ParentSpanId)]
+		to := b.where[s.SpanID]
+		if !ok || from.pid == to.pid {
+			continue
+		}
+		ts := b.micros(s.Span.StartTimeUnixNano)
+		id := fmt.Sprintf("%d", i+1)
+		events = append(events,
+			Event{Name: "call", Cat: "flow", Ph: "s", Ts: ts, Pid: from.pid, Tid: from.tid, ID: id},
+			Event{Name: "call", Cat: "flow", Ph: "f", BP: "e", Ts: ts, Pid: to.pid, Tid: to.tid, ID: id},
+		)
+	}
+	return events
+}
+
+// logEvent places a log record on its span's track, or on the first track
+// of its service when the span isn't in the export.
+func (b *builder) logEvent(l *storage.StoredLog, ts uint64) Event {
+	tr, ok := b.where[l.SpanID]
+	if !ok {
+		tr = track{pid: b.pid(l.ServiceName), tid: 1}
+	}
+	name := l.Body
+	if l.Severity != "" {
+		name = l.Severity + ": " + name
+	}
+	if r := []rune(name); len(r) > maxInstantName {
+		name = string(r[:maxInstantName-1]) + "…"
+	}
+
+	args := map[string]any{
+		"body":     l.Body,
+		"severity": l.Severity,
+		"trace_id": l.TraceID,
+	}
+	if l.SpanID != "" {
+		args["span_id"] = l.SpanID
+	}
+	if l.LogRecord != nil {
+		if attrs := attributeArgs(l.LogRecord.Attributes); attrs != nil {
+			args["attributes"] = attrs
+		}
+	}
+	return Event{
+		Name:  name,
+		Cat:   "log",
+		Ph:    "i",
+		Ts:    b.micros(ts),
+		Pid:   tr.pid,
+		Tid:   tr.tid,
+		Scope: "t",
+		Args:  args,
+	}
+}
+
+// metadata names the processes after services and numbers their tracks.
+func (b *builder) metadata() []Event {
+	var events []Event
+	for _, svc := range b.services {
+		pid := b.pids[svc]
+		events = append(events,
+			Event{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]any{"name": svc}},
+			Event{Name: "process_sort_index", Ph: "M", Pid: pid, Args: map[string]any{"sort_index": pid}},
+		)
+		for tid := 1; tid <= max(len(b.lanes[pid]), 1); tid++ {
+			events = append(events, Event{
+				Name: "thread_name", Ph: "M", Pid: pid, Tid: tid,
+				Args: map[string]any{"name": fmt.Sprintf("track %d", tid)},
+			})
+		}
+	}
+	return events
+}
+
+func spanEnd(sp *tracepb.Span) uint64 {
+	return max(sp.EndTimeUnixNano, sp.StartTimeUnixNano)
+}
+
+// logTime is the record's timestamp, falling back to when it was observed.
+func logTime(l *storage.StoredLog) uint64 {
+	if l.Timestamp != 0 || l.LogRecord == nil {
+		return l.Timestamp
+	}
+	return l.LogRecord.ObservedTimeUnixNano
+}
+
+func statusName(status *tracepb.Status) string {
+	if status == nil {
+		return "UNSET"
+	}
+	return strings.TrimPrefix(status.Code.String(), "STATUS_CODE_")
+}
+
+// attributeArgs flattens attributes to strings for the args pane, or nil if
+// there are none.
+func attributeArgs(attrs []*commonpb.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = storage.AttributeString(kv.Value)
+	}
+	return m
+}
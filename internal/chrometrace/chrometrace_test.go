@@ -0,0 +1,143 @@
+package chrometrace
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+const base = 1_700_000_000_000_000_000
+
+func span(service, name string, id, parent byte, startMs, endMs uint64) *storage.StoredSpan {
+	sp := &tracepb.Span{
+		SpanId:            []byte{0, 0, 0, 0, 0, 0, 0, id},
+		Name:              name,
+		StartTimeUnixNano: base + startMs*1_000_000,
+		EndTimeUnixNano:   base + endMs*1_000_000,
+	}
+	if parent != 0 {
+		sp.ParentSpanId = []byte{0, 0, 0, 0, 0, 0, 0, parent}
+	}
+	return &storage.StoredSpan{
+		Span:        sp,
+		TraceID:     "t1",
+		SpanID:      spanID(id),
+		ServiceName: service,
+		SpanName:    name,
+	}
+}
+
+func spanID(id byte) string {
+	return hex.EncodeToString([]byte{0, 0, 0, 0, 0, 0, 0, id})
+}
+
+// events returns the events of one phase keyed by name.
+func events(tr *Trace, ph string) map[string]Event {
+	m := make(map[string]Event)
+	for _, e := range tr.TraceEvents {
+		if e.Ph == ph {
+			m[e.Name] = e
+		}
+	}
+	return m
+}
+
+func TestBuild(t *testing.T) {
+	// api handles a request, calling db twice concurrently; db logs once
+	spans := []*storage.StoredSpan{
+		span("api", "GET /", 1, 0, 0, 100),
+		span("db", "q1", 2, 1, 10, 50),
+		span("db", "q2", 3, 1, 30, 60),
+		span("api", "render", 4, 1, 70, 80),
+	}
+	logs := []*storage.StoredLog{{
+		TraceID:     "t1",
+		SpanID:      spanID(3),
+		ServiceName: "db",
+		Severity:    "WARN",
+		Body:        "slow query",
+		Timestamp:   base + 40_000_000,
+		LogRecord:   &logspb.LogRecord{},
+	}}
+
+	tr := Build(spans, logs)
+	if tr.Traces != 1 || tr.Spans != 4 || tr.Logs != 1 {
+		t.Fatalf("unexpected counts: %d traces, %d spans, %d logs", tr.Traces, tr.Spans, tr.Logs)
+	}
+
+	x := events(tr, "X")
+	root, q1, q2, render := x["GET /"], x["q1"], x["q2"], x["render"]
+	if root.Ts != 0 || *root.Dur != 100_000 {
+		t.Errorf("expected root at 0 for 100ms, got ts=%v dur=%v", root.Ts, *root.Dur)
+	}
+	if q1.Pid == root.Pid {
+		t.Error("expected db spans in their own process")
+	}
+	if render.Pid != root.Pid || render.Tid != root.Tid {
+		t.Errorf("expected nested render on the root's track, got %+v", render)
+	}
+	if q1.Tid == q2.Tid {
+		t.Errorf("expected overlapping db queries on separate tracks, both on %d", q1.Tid)
+	}
+
+	log := events(tr, "i")["WARN: slow query"]
+	if log.Pid != q2.Pid || log.Tid != q2.Tid || log.Ts != 40_000 {
+		t.Errorf("expected log on q2's track at 40ms, got %+v", log)
+	}
+
+	if n := len(events(tr, "s")); n != 1 {
+		t.Errorf("expected one flow name for cross-service calls, got %d", n)
+	}
+	var flows int
+	for _, e := range tr.TraceEvents {
+		if e.Cat == "flow" {
+			flows++
+		}
+	}
+	if flows != 4 {
+		t.Errorf("expected start/finish flow events for both db calls, got %d", flows)
+	}
+
+	var names []string
+	for _, e := range tr.TraceEvents {
+		if e.Ph == "M" && e.Name == "process_name" {
+			names = append(names, e.Args["name"].(string))
+		}
+	}
+	if len(names) != 2 || names[0] != "api" || names[1] != "db" {
+		t.Errorf("expected processes api, db; got %v", names)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := Build([]*storage.StoredSpan{span("api", "GET /", 1, 0, 0, 5)}, nil).WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		TraceEvents     []map[string]any `json:"traceEvents"`
+		DisplayTimeUnit string           `json:"displayTimeUnit"`
+		OtherData       map[string]any   `json:"otherData"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.DisplayTimeUnit != "ms" || doc.OtherData["base_time"] != "2023-11-14T22:13:20Z" {
+		t.Errorf("unexpected header: %+v", doc)
+	}
+	if len(doc.TraceEvents) == 0 {
+		t.Error("expected events")
+	}
+}
+
+func TestExport(t *testing.T) {
+	st := storage.NewObservabilityStorage(10, 10, 10)
+	if _, err := Export(st, storage.QueryFilter{}); err == nil {
+		t.Error("expected an error with no traces")
+	}
+}
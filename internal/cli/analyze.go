@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/webui"
+	"github.com/urfave/cli/v3"
+)
+
+// AnalyzeCommand returns the CLI command definition for the 'analyze'
+// subcommand. It serves the MCP tools read-only over a telemetry archive.
+func AnalyzeCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "analyze",
+		Usage:     "Serve the MCP tools read-only over a captured telemetry archive",
+		ArgsUsage: "<path>...",
+		Description: `Load a telemetry capture - an otel-collector file exporter directory, a
+single signal directory or file (JSONL or protobuf, optionally compressed),
+or a snapshot range saved with the export_snapshot MCP tool, such as one
+attached to a bug report - into storage sized to hold all of it, and serve
+the MCP tools over it without any OTLP receiver or file watching.
+
+Tools that add listeners, load more data or clear it are not available,
+and export_chrome_trace and export_snapshot only write new files in the
+temp directory, rejecting output_path. Snapshots are in-memory bookmarks, so
+they can still be created, deleted and pinned from the tools or the web UI
+without touching the archive; query, exceptions, flame_graph and the rest
+work as usual. With --transport http the web UI is served too.
+
+Examples:
+  # Investigate a capture from an MCP client over stdio
+  otlp-mcp analyze ./bug-1234/otel
+
+  # A snapshot range exported from a live otlp-mcp
+  otlp-mcp analyze /tmp/otlp-mcp-before-fix.jsonl
+
+  # Browse it in the web UI at http://127.0.0.1:4380/ui/
+  otlp-mcp analyze --transport http traces.jsonl.zst logs.jsonl.zst`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "transport",
+				Usage: "MCP transport: stdio or http",
+				Value: "stdio",
+			},
+			&cli.StringFlag{
+				Name:  "http-host",
+				Usage: "HTTP transport bind address",
+				Value: "127.0.0.1",
+			},
+			&cli.IntFlag{
+				Name:  "http-port",
+				Usage: "HTTP transport port",
+				Value: 4380,
+			},
+			&cli.StringFlag{
+				Name:  "http-socket",
+				Usage: "Serve the HTTP transport on a Unix domain socket instead of TCP",
+			},
+			&cli.BoolFlag{
+				Name:  "verbose",
+				Usage: "Enable verbose logging",
+			},
+		},
+		Action: runAnalyze,
+	}
+}
+
+func runAnalyze(ctx context.Context, cmd *cli.Command) error {
+	paths := cmd.Args().Slice()
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+
+	cfg := DefaultConfig()
+	cfg.Transport = cmd.String("transport")
+	cfg.HTTPHost = cmd.String("http-host")
+	cfg.HTTPPort = cmd.Int("http-port")
+	cfg.HTTPSocket = cmd.String("http-socket")
+	cfg.Verbose = cmd.Bool("verbose")
+	if cfg.Transport != "stdio" && cfg.Transport != "http" {
+		return fmt.Errorf("unknown transport: %s (use 'stdio' or 'http')", cfg.Transport)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	st, err := loadArchive(ctx, paths)
+	if err != nil {
+		return err
+	}
+	defer st.ActivityCache().Close()
+
+	stats := st.Stats()
+	log.Printf("📦 Loaded %d spans in %d traces, %d logs and %d metrics from %d path(s)\n",
+		stats.Traces.SpanCount, stats.Traces.TraceCount, stats.Logs.LogCount, stats.Metrics.MetricCount, len(paths))
+
+	mcpServer, err := mcpserver.NewServer(st, nil, mcpserver.ServerOptions{
+		Verbose:  cfg.Verbose,
+		ReadOnly: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+
+	if cfg.Transport == "http" {
+		if cfg.HTTPSocket != "" {
+			log.Printf("🌐 Read-only MCP server on %s (path /mcp, web UI /ui/)\n", otlpreceiver.UnixScheme+otlpreceiver.SocketPath(cfg.HTTPSocket))
+		} else {
+			log.Printf("🌐 Read-only MCP server on http://%s:%d/mcp\n", cfg.HTTPHost, cfg.HTTPPort)
+			log.Printf("🖥  Web UI: http://%s:%d/ui/\n", cfg.HTTPHost, cfg.HTTPPort)
+		}
+		return runHTTPTransport(ctx, cfg, newOriginList(cfg.AllowedOrigins), mcpServer, webui.New(st, cfg.AllowedOrigins), nil)
+	}
+
+	log.Println("🎯 Read-only MCP server ready on stdio")
+	if err := mcpServer.Run(ctx); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("MCP server error: %w", err)
+	}
+	return nil
+}
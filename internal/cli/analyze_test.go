@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func TestLoadArchiveSizesStorage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "traces"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "logs"), 0o755))
+
+	span := `{"resourceSpans":[{"scopeSpans":[{"spans":[` +
+		`{"traceId":"AQEBAQEBAQEBAQEBAQEBAQ==","spanId":"AQEBAQEBAQE=","name":"a","startTimeUnixNano":"1","endTimeUnixNano":"2"},` +
+		`{"traceId":"AgICAgICAgICAgICAgICAg==","spanId":"AgICAgICAgI=","name":"b","startTimeUnixNano":"3","endTimeUnixNano":"4"}]}]}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "traces", "traces.jsonl"), []byte(span+"\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logs", "logs.jsonl"), nil, 0o644))
+
+	st, err := loadArchive(context.Background(), []string{dir})
+	require.NoError(t, err)
+	defer st.ActivityCache().Close()
+
+	stats := st.Stats()
+	assert.Equal(t, 2, stats.Traces.SpanCount)
+	assert.Equal(t, 2, stats.Traces.Capacity, "storage should be sized to the archive")
+	assert.Equal(t, 0, stats.Logs.LogCount)
+
+	_, err = loadArchive(context.Background(), []string{filepath.Join(dir, "logs")})
+	assert.ErrorContains(t, err, "no telemetry found")
+}
+
+func TestLoadArchiveExportedSnapshot(t *testing.T) {
+	src := storage.NewObservabilityStorage(10, 10, 10)
+	ctx := context.Background()
+	require.NoError(t, src.CreateSnapshot("start"))
+	require.NoError(t, src.ReceiveSpans(ctx, []*tracepb.ResourceSpans{{ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{
+		{TraceId: bytes.Repeat([]byte{1}, 16), SpanId: bytes.Repeat([]byte{1}, 8), Name: "a", StartTimeUnixNano: 1, EndTimeUnixNano: 2},
+	}}}}}))
+	require.NoError(t, src.ReceiveLogs(ctx, []*logspb.ResourceLogs{{ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{
+		{TimeUnixNano: 2, TraceId: bytes.Repeat([]byte{1}, 16)},
+	}}}}}))
+	require.NoError(t, src.ReceiveMetrics(ctx, []*metricspb.ResourceMetrics{{ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{
+		{Name: "requests", Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{TimeUnixNano: 3}}}}},
+	}}}}}))
+
+	data, err := src.GetSnapshotData("start", "")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	_, err = data.WriteJSONL(&buf)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "otlp-mcp-start.jsonl")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+
+	st, err := loadArchive(ctx, []string{path})
+	require.NoError(t, err)
+	defer st.ActivityCache().Close()
+
+	stats := st.Stats()
+	assert.Equal(t, 1, stats.Traces.SpanCount)
+	assert.Equal(t, 1, stats.Logs.LogCount)
+	assert.Equal(t, 1, stats.Metrics.MetricCount)
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/tobert/otlp-mcp/internal/chrometrace"
+	"github.com/tobert/otlp-mcp/internal/replay"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/urfave/cli/v3"
+)
+
+// ChromeTraceCommand returns the CLI command definition for the
+// 'chrome-trace' subcommand. It converts captured telemetry to Chrome
+// Trace Event JSON for Perfetto.
+func ChromeTraceCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "chrome-trace",
+		Usage:     "Convert captured traces to Chrome Trace Event JSON for Perfetto",
+		ArgsUsage: "<path>...",
+		Description: `Load telemetry captured by the otel-collector file exporter (the same
+paths 'replay' accepts) and write the matching traces, with their
+correlated logs, as Chrome Trace Event JSON. Open the result at
+https://ui.perfetto.dev or chrome://tracing.
+
+Services become processes and concurrent spans get their own tracks.
+
+Examples:
+  # One trace to a file
+  otlp-mcp chrome-trace --trace-id 4bf92f3577b34da6a3ce929d0e0e4736 -o trace.json /tank/otel
+
+  # The 20 most recent traces with errors, to stdout
+  otlp-mcp chrome-trace --query errors --limit 20 /tank/otel/traces > errors.json`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "trace-id",
+				Usage: "Export only this trace",
+			},
+			&cli.StringFlag{
+				Name:  "query",
+				Usage: `Export traces with a span matching this query (e.g. "service:cart duration>100ms")`,
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Maximum traces to export, most recent first (0 = all)",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output file (- for stdout)",
+				Value:   "-",
+			},
+		},
+		Action: runChromeTrace,
+	}
+}
+
+func runChromeTrace(ctx context.Context, cmd *cli.Command) error {
+	paths := cmd.Args().Slice()
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	filter, err := storage.ParseQuery(cmd.String("query"))
+	if err != nil {
+		return fmt.Errorf("invalid --query: %w", err)
+	}
+	if id := cmd.String("trace-id"); id != "" {
+		filter.TraceID = strings.ToLower(id)
+	}
+	if limit := cmd.Int("limit"); limit > 0 {
+		filter.Limit = limit
+	}
+
+	st, err := loadArchive(ctx, paths)
+	if err != nil {
+		return err
+	}
+	trace, err := chrometrace.Export(st, filter)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if path := cmd.String("output"); path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	if _, err := trace.WriteTo(out); err != nil {
+		return err
+	}
+	log.Printf("✅ Exported %d traces (%d spans, %d logs)\n", trace.Traces, trace.Spans, trace.Logs)
+	return nil
+}
+
+// loadArchive reads captured telemetry into a storage sized to hold all of it.
+func loadArchive(ctx context.Context, paths []string) (*storage.ObservabilityStorage, error) {
+	items, err := replay.Load(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load telemetry: %w", err)
+	}
+
+	var spans, logs, metrics int
+	for _, item := range items {
+		b := item.Batch
+		switch {
+		case b.Traces != nil:
+			for _, rs := range b.Traces.ResourceSpans {
+				for _, ss := range rs.ScopeSpans {
+					spans += len(ss.Spans)
+				}
+			}
+		case b.Logs != nil:
+			for _, rl := range b.Logs.ResourceLogs {
+				for _, sl := range rl.ScopeLogs {
+					logs += len(sl.LogRecords)
+				}
+			}
+		case b.Metrics != nil:
+			for _, rm := range b.Metrics.ResourceMetrics {
+				for _, sm := range rm.ScopeMetrics {
+					metrics += len(sm.Metrics)
+				}
+			}
+		}
+	}
+	if spans+logs+metrics == 0 {
+		return nil, fmt.Errorf("no telemetry found in %v", paths)
+	}
+
+	st := storage.NewObservabilityStorage(max(spans, 1), max(logs, 1), max(metrics, 1))
+	for _, item := range items {
+		b := item.Batch
+		switch {
+		case b.Traces != nil:
+			st.ReceiveSpans(ctx, b.Traces.ResourceSpans)
+		case b.Logs != nil:
+			st.ReceiveLogs(ctx, b.Logs.ResourceLogs)
+		case b.Metrics != nil:
+			st.ReceiveMetrics(ctx, b.Metrics.ResourceMetrics)
+		}
+	}
+	return st, nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/urfave/cli/v3"
+)
+
+// CollectorConfigCommand returns the CLI command definition for the
+// 'collector-config' subcommand. It prints a collector config fragment that
+// tees an existing collector's telemetry to otlp-mcp.
+func CollectorConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "collector-config",
+		Usage: "Print an otel-collector config fragment that also sends telemetry to otlp-mcp",
+		Description: `Generate the exporters and service.pipelines an OpenTelemetry Collector
+needs to send its telemetry to otlp-mcp as well as wherever it goes now.
+
+The otlp-mcp OTLP endpoint comes from the otlp-mcp config (otlp_port or
+otlp_socket, which must be fixed for a collector to find it), --otlp-port /
+--otlp-socket, or --endpoint. A running server reports its real endpoints
+through the collector_config MCP tool instead.
+
+With --otel-config, the collector's pipelines are reproduced with the new
+exporters appended, so the fragment can be merged or passed as an extra
+--config without dropping existing exporters. With --file-dir, file
+exporters writing the traces/logs/metrics layout 'serve --file-source'
+reads are added too.
+
+Examples:
+  # Tee everything to otlp-mcp on a fixed port
+  otlp-mcp collector-config --otlp-port 4317 --otel-config /etc/otelcol/config.yaml
+
+  # Traces only, plus rotated files for later analysis
+  otlp-mcp collector-config --otlp-port 4317 --signal traces --file-dir /var/otel`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "Path to otlp-mcp config file (default: search for .otlp-mcp.json)",
+			},
+			&cli.StringFlag{
+				Name:  "endpoint",
+				Usage: "OTLP gRPC endpoint the collector exports to (host:port or unix:///path)",
+			},
+			&cli.StringFlag{
+				Name:  "otlp-host",
+				Usage: "otlp-mcp OTLP bind address (overrides config file)",
+			},
+			&cli.IntFlag{
+				Name:  "otlp-port",
+				Usage: "otlp-mcp OTLP port (overrides config file)",
+				Value: -1,
+			},
+			&cli.StringFlag{
+				Name:  "otlp-socket",
+				Usage: "otlp-mcp OTLP Unix domain socket (overrides config file)",
+			},
+			&cli.StringFlag{
+				Name:  "otel-config",
+				Usage: "Collector config.yaml whose pipelines get the new exporters appended",
+			},
+			&cli.StringSliceFlag{
+				Name:  "signal",
+				Usage: "Signal to send: traces, logs or metrics (can be specified multiple times, default all)",
+			},
+			&cli.StringFlag{
+				Name:  "name",
+				Usage: "Name suffix of the generated exporters",
+				Value: otelconfig.DefaultExporterName,
+			},
+			&cli.StringFlag{
+				Name:  "file-dir",
+				Usage: "Also add file exporters writing <dir>/<signal>/<signal>.jsonl, for --file-source",
+			},
+			&cli.IntFlag{
+				Name:  "rotation-max-megabytes",
+				Usage: "File exporter rotation: maximum file size in MB (with --file-dir)",
+				Value: 100,
+			},
+			&cli.IntFlag{
+				Name:  "rotation-max-days",
+				Usage: "File exporter rotation: days to keep rotated files, 0 for no limit (with --file-dir)",
+			},
+			&cli.IntFlag{
+				Name:  "rotation-max-backups",
+				Usage: "File exporter rotation: rotated files to keep, 0 for no limit (with --file-dir)",
+				Value: 10,
+			},
+			&cli.BoolFlag{
+				Name:  "rotation-localtime",
+				Usage: "File exporter rotation: name rotated files in local time rather than UTC (with --file-dir)",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the fragment to this file instead of stdout",
+			},
+		},
+		Action: runCollectorConfig,
+	}
+}
+
+func runCollectorConfig(ctx context.Context, cmd *cli.Command) error {
+	cfg, err := LoadEffectiveConfig(cmd.String("config"))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if host := cmd.String("otlp-host"); host != "" {
+		cfg.OTLPHost = host
+	}
+	if port := cmd.Int("otlp-port"); port >= 0 {
+		cfg.OTLPPort = port
+	}
+	if socket := cmd.String("otlp-socket"); socket != "" {
+		cfg.OTLPSocket = socket
+	}
+
+	endpoint := cmd.String("endpoint")
+	if endpoint == "" {
+		if endpoint, err = configEndpoint(cfg); err != nil {
+			return err
+		}
+	}
+
+	opts := otelconfig.SnippetOptions{
+		Endpoint: endpoint,
+		Name:     cmd.String("name"),
+		Signals:  cmd.StringSlice("signal"),
+		FileDir:  cmd.String("file-dir"),
+	}
+	if opts.FileDir != "" {
+		opts.Rotation = otelconfig.Rotation{
+			MaxMegabytes: cmd.Int("rotation-max-megabytes"),
+			MaxDays:      cmd.Int("rotation-max-days"),
+			MaxBackups:   cmd.Int("rotation-max-backups"),
+			LocalTime:    cmd.Bool("rotation-localtime"),
+		}
+	}
+	if path := cmd.String("otel-config"); path != "" {
+		if opts.Base, err = otelconfig.Load(path); err != nil {
+			return err
+		}
+	}
+
+	snippet, err := otelconfig.NewSnippet(opts)
+	if err != nil {
+		return err
+	}
+
+	if path := cmd.String("output"); path != "" {
+		if err := os.WriteFile(path, []byte(snippet.YAML), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote collector config for %s to %s\n", endpoint, path)
+		return nil
+	}
+	_, err = fmt.Print(snippet.YAML)
+	return err
+}
+
+// configEndpoint returns the OTLP endpoint a serve with cfg listens on, as
+// UnifiedServer.Endpoints would report it.
+func configEndpoint(cfg *Config) (string, error) {
+	if cfg.OTLPSocket != "" {
+		return otlpreceiver.UnixScheme + otlpreceiver.SocketPath(cfg.OTLPSocket), nil
+	}
+	if cfg.OTLPPort == 0 {
+		return "", fmt.Errorf("otlp-mcp listens on an ephemeral OTLP port by default: set otlp_port in the config, or pass --otlp-port, --otlp-socket or --endpoint")
+	}
+	return net.JoinHostPort(cfg.OTLPHost, strconv.Itoa(cfg.OTLPPort)), nil
+}
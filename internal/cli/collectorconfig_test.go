@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
+)
+
+func TestCollectorConfigCommand(t *testing.T) {
+	dir := t.TempDir()
+	otlpConfig := filepath.Join(dir, "otlp-mcp.json")
+	require.NoError(t, os.WriteFile(otlpConfig, []byte(`{"otlp_host": "0.0.0.0", "otlp_port": 14317}`), 0o644))
+	collectorConfig := filepath.Join(dir, "collector.yaml")
+	require.NoError(t, os.WriteFile(collectorConfig, []byte(`
+exporters:
+  debug:
+service:
+  pipelines:
+    traces:
+      exporters: [debug]
+`), 0o644))
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = CollectorConfigCommand().Run(context.Background(), []string{
+			"collector-config", "--config", otlpConfig, "--otel-config", collectorConfig, "--file-dir", "/var/otel",
+		})
+	})
+	require.NoError(t, runErr)
+	assert.Contains(t, out, "endpoint: localhost:14317")
+	assert.Contains(t, out, "exporters: [debug, otlp/otlp-mcp, file/otlp-mcp-traces]")
+	assert.Contains(t, out, "max_megabytes: 100")
+
+	generated, err := otelconfig.Parse([]byte(out))
+	require.NoError(t, err)
+	assert.Equal(t, "/var/otel", generated.FileSources()[0].Path)
+
+	output := filepath.Join(dir, "tee.yaml")
+	err = CollectorConfigCommand().Run(context.Background(), []string{
+		"collector-config", "--config", otlpConfig, "--otlp-socket", "/run/otlp.sock", "-o", output,
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "endpoint: unix:///run/otlp.sock")
+}
+
+func TestCollectorConfigCommandEphemeralPort(t *testing.T) {
+	otlpConfig := filepath.Join(t.TempDir(), "otlp-mcp.json")
+	require.NoError(t, os.WriteFile(otlpConfig, []byte(`{}`), 0o644))
+
+	err := CollectorConfigCommand().Run(context.Background(), []string{"collector-config", "--config", otlpConfig})
+	assert.ErrorContains(t, err, "ephemeral")
+}
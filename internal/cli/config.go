@@ -3,39 +3,57 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Config holds the runtime configuration for the OTLP MCP server.
 // It can be populated from CLI flags, config files, or both.
+// The jsonschema tags describe each setting for 'otlp-mcp config schema'.
 type Config struct {
+	// JSON Schema reference for editors (ignored by the application)
+	Schema string `json:"$schema,omitempty" jsonschema:"JSON Schema for this file, for editor autocomplete (ignored)"`
+
 	// Comment field for user documentation (ignored by the application)
-	Comment string `json:"comment,omitempty"`
+	Comment string `json:"comment,omitempty" jsonschema:"Documentation string (ignored)"`
 
 	// Buffer sizes for different signal types (direct JSON mapping to CLI flags)
-	TraceBufferSize  int `json:"trace_buffer_size,omitempty"`
-	LogBufferSize    int `json:"log_buffer_size,omitempty"`
-	MetricBufferSize int `json:"metric_buffer_size,omitempty"`
+	TraceBufferSize  int `json:"trace_buffer_size,omitempty" jsonschema:"Number of spans to buffer"`
+	LogBufferSize    int `json:"log_buffer_size,omitempty" jsonschema:"Number of log records to buffer"`
+	MetricBufferSize int `json:"metric_buffer_size,omitempty" jsonschema:"Number of metric points to buffer"`
 
 	// OTLP server configuration
-	OTLPHost string `json:"otlp_host,omitempty"`
-	OTLPPort int    `json:"otlp_port,omitempty"`
+	OTLPHost string `json:"otlp_host,omitempty" jsonschema:"OTLP gRPC server bind address"`
+	OTLPPort int    `json:"otlp_port,omitempty" jsonschema:"OTLP gRPC server port, 0 for ephemeral"`
+	// OTLPSocket listens on a Unix domain socket instead of TCP when set
+	OTLPSocket string `json:"otlp_socket,omitempty" jsonschema:"Unix socket path for OTLP gRPC, replaces otlp_host/otlp_port"`
+	// OTLPExtraPorts are additional TCP ports, as if added with add_otlp_port
+	OTLPExtraPorts []int `json:"otlp_extra_ports,omitempty" jsonschema:"Additional OTLP gRPC ports to listen on, like the add_otlp_port tool"`
 
 	// MCP transport configuration
-	Transport      string   `json:"transport,omitempty"`       // "stdio" (default) or "http"
-	HTTPHost       string   `json:"http_host,omitempty"`       // HTTP server bind address
-	HTTPPort       int      `json:"http_port,omitempty"`       // HTTP server port
-	AllowedOrigins []string `json:"allowed_origins,omitempty"` // Allowed Origin headers for CORS
-	SessionTimeout string   `json:"session_timeout,omitempty"` // Session idle timeout (e.g., "30m")
-	Stateless      bool     `json:"stateless,omitempty"`       // Run HTTP transport in stateless mode
+	Transport      string   `json:"transport,omitempty" jsonschema:"MCP transport: stdio or http"`
+	HTTPHost       string   `json:"http_host,omitempty" jsonschema:"HTTP transport bind address"`
+	HTTPPort       int      `json:"http_port,omitempty" jsonschema:"HTTP transport port"`
+	HTTPSocket     string   `json:"http_socket,omitempty" jsonschema:"Unix socket path for the HTTP transport, replaces http_host/http_port"`
+	AllowedOrigins []string `json:"allowed_origins,omitempty" jsonschema:"Allowed Origin headers for the HTTP transport (CORS), * wildcards allowed"`
+	SessionTimeout string   `json:"session_timeout,omitempty" jsonschema:"HTTP session idle timeout, a Go duration like 30m"`
+	Stateless      bool     `json:"stateless,omitempty" jsonschema:"Run the HTTP transport without session persistence"`
 
 	// Web UI configuration
-	WebUIPort int    `json:"webui_port,omitempty"` // 0 = use same port as HTTP (default)
-	WebUIHost string `json:"webui_host,omitempty"` // default: 127.0.0.1
+	WebUIPort int    `json:"webui_port,omitempty" jsonschema:"Serve the web UI on a separate port, 0 for the HTTP transport port"`
+	WebUIHost string `json:"webui_host,omitempty" jsonschema:"Web UI bind address"`
+	JaegerAPI bool   `json:"jaeger_api,omitempty" jsonschema:"Serve the Jaeger query API under /jaeger/api on the web UI port"`
+
+	// FileSources are loaded at startup alongside --file-source
+	FileSources []string `json:"file_sources,omitempty" jsonschema:"Directories or exporter files of OTLP file exporter output to load, like --file-source"`
 
 	// Logging configuration
-	Verbose bool `json:"verbose,omitempty"`
+	Verbose bool `json:"verbose,omitempty" jsonschema:"Enable verbose logging"`
 }
 
 // DefaultConfig returns a Config with sensible default values.
@@ -65,19 +83,167 @@ func DefaultConfig() *Config {
 }
 
 // LoadConfigFromFile loads configuration from a JSON file at the given path.
-// It returns an error if the file cannot be read or parsed.
+// It returns an error if the file cannot be read or parsed, or if it has
+// unknown keys or invalid values, listing every problem found.
 func LoadConfigFromFile(path string) (*Config, error) {
+	config, _, err := loadConfigFile(path)
+	return config, err
+}
+
+// loadConfigFile is LoadConfigFromFile, also returning the keys the file
+// sets so merging can tell an explicit false or 0 from an absent key.
+func loadConfigFile(path string) (*Config, map[string]bool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		return nil, nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	problems, err := configProblems(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	var invalid []string
+	for _, p := range problems {
+		if p.Fatal {
+			invalid = append(invalid, p.Message)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, nil, fmt.Errorf("invalid config file %s:\n  - %s", path, strings.Join(invalid, "\n  - "))
+	}
+
+	var config Config
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	keys := make(map[string]bool, len(fields))
+	for key := range fields {
+		keys[key] = true
+	}
+	return &config, keys, nil
+}
+
+// configProblem is something wrong in a config file. Fatal problems make
+// loading it fail; the rest are worth a warning from doctor.
+type configProblem struct {
+	Message string
+	Fatal   bool
+}
+
+// configProblems checks config file JSON against the keys and values
+// Config accepts. It returns an error only when the data is not a JSON object.
+func configProblems(data []byte) ([]configProblem, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	var problems []configProblem
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if _, ok := configFieldIndex(name); ok {
+			continue
+		}
+		message := fmt.Sprintf("unknown key %q", name)
+		if suggestion := closestConfigKey(name); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		problems = append(problems, configProblem{Message: message, Fatal: true})
 	}
 
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		return append(problems, configProblem{Message: err.Error(), Fatal: true}), nil
+	}
+	invalid := func(format string, args ...any) {
+		problems = append(problems, configProblem{Message: fmt.Sprintf(format, args...), Fatal: true})
+	}
+	if config.Transport != "" && config.Transport != "stdio" && config.Transport != "http" {
+		invalid("transport %q must be \"stdio\" or \"http\"", config.Transport)
+	}
+	for _, port := range []struct {
+		name  string
+		value int
+	}{{"otlp_port", config.OTLPPort}, {"http_port", config.HTTPPort}, {"webui_port", config.WebUIPort}} {
+		if port.value < 0 || port.value > 65535 {
+			invalid("%s %d is out of range", port.name, port.value)
+		}
+	}
+	for _, port := range config.OTLPExtraPorts {
+		if port < 1 || port > 65535 {
+			invalid("otlp_extra_ports %d is out of range", port)
+		}
+	}
+	for _, size := range []struct {
+		name  string
+		value int
+	}{{"trace_buffer_size", config.TraceBufferSize}, {"log_buffer_size", config.LogBufferSize}, {"metric_buffer_size", config.MetricBufferSize}} {
+		if _, set := fields[size.name]; set && size.value < 1 {
+			invalid("%s %d must be at least 1", size.name, size.value)
+		}
+	}
+	if config.SessionTimeout != "" {
+		if _, err := time.ParseDuration(config.SessionTimeout); err != nil {
+			invalid("session_timeout %q is not a duration like \"30m\"", config.SessionTimeout)
+		}
+	}
+	if config.OTLPSocket != "" && config.OTLPPort != 0 {
+		problems = append(problems, configProblem{Message: "otlp_port is ignored because otlp_socket is set"})
+	}
+	return problems, nil
+}
+
+// ConfigKeys returns the JSON keys of Config in declaration order.
+func ConfigKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// configFieldIndex returns the index of the Config field with a JSON key.
+func configFieldIndex(key string) (int, bool) {
+	i := slices.Index(ConfigKeys(), key)
+	return i, i >= 0
+}
+
+// closestConfigKey suggests the known key a misspelled one was meant to
+// be, if any is within a few edits.
+func closestConfigKey(key string) string {
+	best, bestDistance := "", 4
+	for _, known := range ConfigKeys() {
+		if d := editDistance(key, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
 	}
+	return best
+}
 
-	return &config, nil
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
 }
 
 // FindProjectConfig searches for a .otlp-mcp.json config file.
@@ -127,70 +293,39 @@ func GlobalConfigPath() string {
 	return filepath.Join(home, ".config", "otlp-mcp", "config.json")
 }
 
-// MergeConfigs merges two configs with the overlay taking precedence.
-// Fields in overlay override corresponding fields in base.
-// Returns a new Config with the merged values.
-func MergeConfigs(base, overlay *Config) *Config {
-	if base == nil {
-		base = &Config{}
-	}
-	if overlay == nil {
-		return base
-	}
-
-	// Create a copy of base
-	merged := *base
-
-	// Override with overlay values if set
-	if overlay.OTLPHost != "" {
-		merged.OTLPHost = overlay.OTLPHost
-	}
-	if overlay.OTLPPort != 0 {
-		merged.OTLPPort = overlay.OTLPPort
-	}
-	if overlay.Verbose {
-		merged.Verbose = overlay.Verbose
-	}
+// Config value sources, as reported by 'otlp-mcp config show'.
+const (
+	SourceDefault = "default"
+	SourceGlobal  = "global"
+	SourceProject = "project"
+	SourceFile    = "file" // --config
+	SourceFlag    = "flag"
+)
 
-	// Merge buffer sizes
-	if overlay.TraceBufferSize > 0 {
-		merged.TraceBufferSize = overlay.TraceBufferSize
-	}
-	if overlay.LogBufferSize > 0 {
-		merged.LogBufferSize = overlay.LogBufferSize
-	}
-	if overlay.MetricBufferSize > 0 {
-		merged.MetricBufferSize = overlay.MetricBufferSize
-	}
+// ConfigSources maps each config key to where its effective value came
+// from, and each config file source to its path.
+type ConfigSources struct {
+	Values map[string]string
+	Files  map[string]string
+}
 
-	// Merge HTTP transport settings
-	if overlay.Transport != "" {
-		merged.Transport = overlay.Transport
-	}
-	if overlay.HTTPHost != "" {
-		merged.HTTPHost = overlay.HTTPHost
-	}
-	if overlay.HTTPPort > 0 {
-		merged.HTTPPort = overlay.HTTPPort
-	}
-	if len(overlay.AllowedOrigins) > 0 {
-		merged.AllowedOrigins = overlay.AllowedOrigins
-	}
-	if overlay.SessionTimeout != "" {
-		merged.SessionTimeout = overlay.SessionTimeout
-	}
-	if overlay.Stateless {
-		merged.Stateless = overlay.Stateless
-	}
+// set records that key was set by source.
+func (s *ConfigSources) set(key, source string) {
+	s.Values[key] = source
+}
 
-	// Merge Web UI settings
-	if overlay.WebUIPort > 0 {
-		merged.WebUIPort = overlay.WebUIPort
-	}
-	if overlay.WebUIHost != "" {
-		merged.WebUIHost = overlay.WebUIHost
+// MergeConfigs returns base with the keys set in overlay's config file
+// applied. Keys the file sets are applied even when the value is false,
+// 0 or empty, so a project config can turn off what a global one enables.
+func MergeConfigs(base, overlay *Config, keys map[string]bool) *Config {
+	merged := *base
+	dst := reflect.ValueOf(&merged).Elem()
+	src := reflect.ValueOf(overlay).Elem()
+	for key := range keys {
+		if i, ok := configFieldIndex(key); ok {
+			dst.Field(i).Set(src.Field(i))
+		}
 	}
-
 	return &merged
 }
 
@@ -201,36 +336,55 @@ func MergeConfigs(base, overlay *Config) *Config {
 // 4. Explicit config file (if specified via configPath)
 // Later sources override earlier ones.
 func LoadEffectiveConfig(configPath string) (*Config, error) {
-	// Start with defaults
+	config, _, err := LoadConfigWithSources(configPath)
+	return config, err
+}
+
+// LoadConfigWithSources is LoadEffectiveConfig, also reporting where each
+// value came from. An invalid config file is an error wherever it is found.
+func LoadConfigWithSources(configPath string) (*Config, *ConfigSources, error) {
 	config := DefaultConfig()
+	sources := &ConfigSources{Values: make(map[string]string), Files: make(map[string]string)}
+	for _, key := range ConfigKeys() {
+		sources.set(key, SourceDefault)
+	}
+
+	layer := func(path, source string) error {
+		overlay, keys, err := loadConfigFile(path)
+		if err != nil {
+			return err
+		}
+		config = MergeConfigs(config, overlay, keys)
+		for key := range keys {
+			sources.set(key, source)
+		}
+		sources.Files[source] = path
+		return nil
+	}
 
 	// Layer 2: Global config (if exists)
-	globalPath := GlobalConfigPath()
-	if globalPath != "" {
-		if globalCfg, err := LoadConfigFromFile(globalPath); err == nil {
-			config = MergeConfigs(config, globalCfg)
+	if globalPath := GlobalConfigPath(); globalPath != "" {
+		if _, err := os.Stat(globalPath); err == nil {
+			if err := layer(globalPath, SourceGlobal); err != nil {
+				return nil, nil, fmt.Errorf("failed to load global config: %w", err)
+			}
 		}
-		// Ignore errors for global config (it's optional)
 	}
 
 	// Layer 3: Project config (if exists and no explicit path)
 	if configPath == "" {
 		if projectPath, err := FindProjectConfig(); err == nil {
-			projectCfg, err := LoadConfigFromFile(projectPath)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load project config: %w", err)
+			if err := layer(projectPath, SourceProject); err != nil {
+				return nil, nil, fmt.Errorf("failed to load project config: %w", err)
 			}
-			config = MergeConfigs(config, projectCfg)
 		}
 		// Ignore not found error for project config (it's optional)
 	} else {
 		// Explicit config file specified
-		explicitCfg, err := LoadConfigFromFile(configPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load config file: %w", err)
+		if err := layer(configPath, SourceFile); err != nil {
+			return nil, nil, fmt.Errorf("failed to load config file: %w", err)
 		}
-		config = MergeConfigs(config, explicitCfg)
 	}
 
-	return config, nil
+	return config, sources, nil
 }
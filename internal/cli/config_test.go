@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configEnv gives a test its own HOME and project directory so the real
+// global and project configs don't leak in. It returns the global config
+// path and the project directory, which is the working directory.
+func configEnv(t *testing.T) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(project, ".git"), 0o755))
+	t.Chdir(project)
+	return filepath.Join(home, ".config", "otlp-mcp", "config.json"), project
+}
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
+
+func TestLoadConfigFromFileStrict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"trace_bufer_size": 5, "transport": "tcp", "http_port": 70000, "session_timeout": "soon"}`)
+
+	_, err := LoadConfigFromFile(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown key "trace_bufer_size" (did you mean "trace_buffer_size"?)`)
+	assert.Contains(t, err.Error(), `transport "tcp" must be "stdio" or "http"`)
+	assert.Contains(t, err.Error(), "http_port 70000 is out of range")
+	assert.Contains(t, err.Error(), `session_timeout "soon"`)
+
+	writeConfig(t, path, `{"$schema": "./schema.json", "comment": "ok", "trace_buffer_size": 5}`)
+	cfg, err := LoadConfigFromFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.TraceBufferSize)
+}
+
+func TestExampleConfigLoads(t *testing.T) {
+	_, err := LoadConfigFromFile(filepath.Join("..", "..", ".otlp-mcp.json.example"))
+	require.NoError(t, err)
+}
+
+func TestLoadConfigWithSources(t *testing.T) {
+	global, project := configEnv(t)
+	writeConfig(t, global, `{"verbose": true, "stateless": true, "trace_buffer_size": 20000}`)
+	writeConfig(t, filepath.Join(project, ".otlp-mcp.json"), `{"stateless": false, "otlp_port": 4317}`)
+
+	cfg, sources, err := LoadConfigWithSources("")
+	require.NoError(t, err)
+
+	assert.True(t, cfg.Verbose)
+	assert.False(t, cfg.Stateless, "explicit false in the project config overrides the global true")
+	assert.Equal(t, 20000, cfg.TraceBufferSize)
+	assert.Equal(t, 4317, cfg.OTLPPort)
+	assert.Equal(t, 50_000, cfg.LogBufferSize)
+
+	assert.Equal(t, SourceGlobal, sources.Values["verbose"])
+	assert.Equal(t, SourceProject, sources.Values["stateless"])
+	assert.Equal(t, SourceProject, sources.Values["otlp_port"])
+	assert.Equal(t, SourceDefault, sources.Values["log_buffer_size"])
+	assert.Equal(t, global, sources.Files[SourceGlobal])
+	assert.Contains(t, sources.Files, SourceProject)
+
+	explicit := filepath.Join(t.TempDir(), "explicit.json")
+	writeConfig(t, explicit, `{"otlp_port": 5317}`)
+	cfg, sources, err = LoadConfigWithSources(explicit)
+	require.NoError(t, err)
+	assert.Equal(t, 5317, cfg.OTLPPort)
+	assert.Equal(t, SourceFile, sources.Values["otlp_port"])
+	assert.NotContains(t, sources.Files, SourceProject, "--config replaces the project config")
+
+	writeConfig(t, global, `{"verbos": true}`)
+	_, _, err = LoadConfigWithSources("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load global config")
+}
+
+func TestConfigShow(t *testing.T) {
+	_, project := configEnv(t)
+	writeConfig(t, filepath.Join(project, ".otlp-mcp.json"), `{"otlp_port": 4317}`)
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = ConfigCommand().Run(context.Background(), []string{"config", "show", "--http-port", "9999"})
+	})
+	require.NoError(t, runErr)
+	assert.Contains(t, out, "# project config:")
+	assert.Regexp(t, `otlp_port\s+4317\s+project`, out)
+	assert.Regexp(t, `http_port\s+9999\s+flag`, out)
+	assert.Regexp(t, `transport\s+"stdio"\s+default`, out)
+	assert.NotContains(t, out, "comment")
+
+	out = captureStdout(t, func() {
+		runErr = ConfigCommand().Run(context.Background(), []string{"config", "show", "--json"})
+	})
+	require.NoError(t, runErr)
+	var shown struct {
+		Values  map[string]any    `json:"values"`
+		Sources map[string]string `json:"sources"`
+		Files   map[string]string `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &shown))
+	assert.Equal(t, 4317.0, shown.Values["otlp_port"])
+	assert.Equal(t, SourceProject, shown.Sources["otlp_port"])
+	assert.Contains(t, shown.Files, SourceProject)
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema, err := ConfigSchema()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	var doc struct {
+		Schema               string                    `json:"$schema"`
+		AdditionalProperties any                       `json:"additionalProperties"`
+		Properties           map[string]map[string]any `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+
+	assert.Equal(t, configSchemaURL, doc.Schema)
+	assert.Equal(t, false, doc.AdditionalProperties)
+	for _, key := range ConfigKeys() {
+		assert.Contains(t, doc.Properties, key)
+	}
+	assert.Equal(t, 10000.0, doc.Properties["trace_buffer_size"]["default"])
+	assert.Equal(t, []any{"stdio", "http"}, doc.Properties["transport"]["enum"])
+	assert.Equal(t, 65535.0, doc.Properties["http_port"]["maximum"])
+	assert.NotEmpty(t, doc.Properties["session_timeout"]["description"])
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"text/tabwriter"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/urfave/cli/v3"
+)
+
+// configSchemaURL is the JSON Schema draft 'config schema' declares.
+const configSchemaURL = "https://json-schema.org/draft/2020-12/schema"
+
+// ConfigCommand returns the CLI command definition for the 'config'
+// subcommand, which inspects otlp-mcp configuration.
+func ConfigCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "Inspect otlp-mcp configuration",
+		Description: `Configuration is layered: built-in defaults, then the global config
+(~/.config/otlp-mcp/config.json), then the project config (.otlp-mcp.json,
+or --config), then command line flags. Config files are validated strictly:
+unknown keys and invalid values are errors.
+
+Examples:
+  # Show the effective config and where each value came from
+  otlp-mcp config show
+
+  # Write a JSON Schema for editor autocomplete
+  otlp-mcp config schema > otlp-mcp.schema.json`,
+		Commands: []*cli.Command{
+			{
+				Name:  "show",
+				Usage: "Print the effective config with the source of each value",
+				Description: `Accepts the same config flags as 'serve', so it shows exactly what
+'serve' with those flags would use.`,
+				Flags: append(configFlags(), &cli.BoolFlag{
+					Name:  "json",
+					Usage: "Output as JSON",
+				}),
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					cfg, sources, err := LoadConfigWithSources(cmd.String("config"))
+					if err != nil {
+						return err
+					}
+					applyConfigFlags(cmd, cfg, sources)
+					if cmd.Bool("json") {
+						return writeConfigJSON(os.Stdout, cfg, sources)
+					}
+					return writeConfigTable(os.Stdout, cfg, sources)
+				},
+			},
+			{
+				Name:  "schema",
+				Usage: "Print a JSON Schema for otlp-mcp config files",
+				Description: `Reference it from a config file with "$schema" for editor
+autocomplete and validation:
+
+  {"$schema": "./otlp-mcp.schema.json", "trace_buffer_size": 50000}`,
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					schema, err := ConfigSchema()
+					if err != nil {
+						return err
+					}
+					data, err := json.MarshalIndent(schema, "", "  ")
+					if err != nil {
+						return fmt.Errorf("failed to encode schema: %w", err)
+					}
+					_, err = fmt.Println(string(data))
+					return err
+				},
+			},
+		},
+	}
+}
+
+// ConfigSchema returns the JSON Schema of otlp-mcp config files, with
+// the defaults and value ranges configProblems enforces.
+func ConfigSchema() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[Config](nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config schema: %w", err)
+	}
+	schema.Schema = configSchemaURL
+	schema.Title = "otlp-mcp configuration"
+	schema.Description = "Global (~/.config/otlp-mcp/config.json) or project (.otlp-mcp.json) otlp-mcp config file"
+
+	for key, value := range configValues(DefaultConfig()) {
+		prop := schema.Properties[key]
+		if prop == nil || reflect.ValueOf(value).IsZero() {
+			continue
+		}
+		if prop.Default, err = json.Marshal(value); err != nil {
+			return nil, fmt.Errorf("failed to encode default for %s: %w", key, err)
+		}
+	}
+	schema.Properties["transport"].Enum = []any{"stdio", "http"}
+	for _, key := range []string{"otlp_port", "http_port", "webui_port"} {
+		schema.Properties[key].Minimum = jsonschema.Ptr(0.0)
+		schema.Properties[key].Maximum = jsonschema.Ptr(65535.0)
+	}
+	extra := schema.Properties["otlp_extra_ports"].Items
+	extra.Minimum = jsonschema.Ptr(1.0)
+	extra.Maximum = jsonschema.Ptr(65535.0)
+	for _, key := range []string{"trace_buffer_size", "log_buffer_size", "metric_buffer_size"} {
+		schema.Properties[key].Minimum = jsonschema.Ptr(1.0)
+	}
+	return schema, nil
+}
+
+// configValues maps each config key to its value in cfg.
+func configValues(cfg *Config) map[string]any {
+	v := reflect.ValueOf(cfg).Elem()
+	values := make(map[string]any, v.NumField())
+	for i, key := range ConfigKeys() {
+		values[key] = v.Field(i).Interface()
+	}
+	return values
+}
+
+// shownConfigKeys are the keys 'config show' reports; $schema and comment
+// don't affect the server.
+func shownConfigKeys() []string {
+	var keys []string
+	for _, key := range ConfigKeys() {
+		if key != "$schema" && key != "comment" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// writeConfigTable writes the config files in use, then one row per key
+// with its JSON-encoded value and source.
+func writeConfigTable(w io.Writer, cfg *Config, sources *ConfigSources) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, source := range []string{SourceGlobal, SourceProject, SourceFile} {
+		if path, ok := sources.Files[source]; ok {
+			fmt.Fprintf(tw, "# %s config:\t%s\n", source, path)
+		}
+	}
+	if len(sources.Files) == 0 {
+		fmt.Fprintln(tw, "# no config files found, using defaults")
+	}
+	fmt.Fprintln(tw)
+
+	values := configValues(cfg)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, key := range shownConfigKeys() {
+		data, err := json.Marshal(values[key])
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", key, err)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, data, sources.Values[key])
+	}
+	return tw.Flush()
+}
+
+// writeConfigJSON writes the effective config values, their sources and
+// the config files in use as one JSON object.
+func writeConfigJSON(w io.Writer, cfg *Config, sources *ConfigSources) error {
+	all := configValues(cfg)
+	values := make(map[string]any)
+	shown := make(map[string]string)
+	for _, key := range shownConfigKeys() {
+		values[key] = all[key]
+		shown[key] = sources.Values[key]
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Values  map[string]any    `json:"values"`
+		Sources map[string]string `json:"sources"`
+		Files   map[string]string `json:"files"`
+	}{values, shown, sources.Files})
+}
@@ -4,12 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tobert/otlp-mcp/internal/filereader"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
 	"github.com/urfave/cli/v3"
 )
 
@@ -26,22 +33,67 @@ This command checks:
   - MCP configuration file (mcp_settings.json)
   - Path validation in configuration
   - Optional dependencies (otel-cli)
+  - .otlp-mcp.json files: parse errors, unknown fields and invalid values
+  - Port conflicts on the configured OTLP, HTTP and web UI addresses
+  - File source directories (--file-source): readability and layout
+  - With --otel-config: the collector's file exporters and OTLP receivers
+  - Live round trip: a throwaway receiver takes a test span over OTLP gRPC
+    (TCP and Unix socket) and it is queried back over MCP Streamable HTTP
 
 Exit codes:
   0 - All critical checks passed
   1 - One or more issues found`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "config",
+				Aliases: []string{"c"},
+				Usage:   "Path to otlp-mcp config file (default: search for .otlp-mcp.json)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "file-source",
+				Aliases: []string{"f"},
+				Usage:   "File source directory or file to check (can be specified multiple times)",
+			},
+			&cli.StringFlag{
+				Name:  "otel-config",
+				Usage: "Also check an otel-collector config.yaml: where it writes files and where apps should send",
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Skip the live round trip and port checks",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print results as JSON for scripting",
+			},
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			return runDoctor(version)
+			return runDoctor(ctx, version, doctorOptions{
+				ConfigPath:  cmd.String("config"),
+				FileSources: cmd.StringSlice("file-source"),
+				OtelConfig:  cmd.String("otel-config"),
+				Offline:     cmd.Bool("offline"),
+				JSON:        cmd.Bool("json"),
+			})
 		},
 	}
 }
 
+// doctorOptions selects the checks beyond the static setup checks.
+type doctorOptions struct {
+	ConfigPath  string   // otlp-mcp config file; empty searches as serve does
+	FileSources []string // File sources to check
+	OtelConfig  string   // otel-collector config to check
+	Offline     bool     // Skip checks that listen or connect
+	JSON        bool     // Print results as JSON
+}
+
 type checkResult struct {
-	Name       string
-	Status     string // "pass", "warn", "fail"
-	Message    string
-	Suggestion string
-	IsCritical bool
+	Name       string `json:"name"`
+	Status     string `json:"status"` // "pass", "warn", "fail"
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	IsCritical bool   `json:"critical"`
 }
 
 type fsUtils interface {
@@ -62,12 +114,46 @@ func (r *realFsUtils) UserHomeDir() (string, error)          { return os.UserHom
 func (r *realFsUtils) Getwd() (string, error)                { return os.Getwd() }
 func (r *realFsUtils) LookPath(file string) (string, error)  { return exec.LookPath(file) }
 
-func runDoctor(version string) error {
-	return runDoctorWithUtils(version, &realFsUtils{})
+func runDoctor(ctx context.Context, version string, opts doctorOptions) error {
+	extra := []func(utils fsUtils) checkResult{checkOtlpMCPConfig(opts.ConfigPath)}
+
+	sources := opts.FileSources
+	if opts.OtelConfig != "" {
+		extra = append(extra, checkOtelConfig(opts.OtelConfig))
+		if collector, err := otelconfig.Load(opts.OtelConfig); err == nil {
+			for _, src := range collector.FileSources() {
+				sources = append(sources, src.Path)
+			}
+		}
+	}
+	for _, src := range sources {
+		extra = append(extra, checkFileSource(src))
+	}
+
+	if !opts.Offline {
+		extra = append(extra, checkPortConflicts(opts.ConfigPath))
+		live := &liveCheck{ctx: ctx}
+		extra = append(extra, live.checkOTLPGRPC, live.checkMCPHTTP)
+	}
+
+	return runDoctorReport(version, &realFsUtils{}, opts.JSON, extra...)
 }
 
-func runDoctorWithUtils(version string, utils fsUtils) error {
-	fmt.Printf("🔍 otlp-mcp doctor v%s\n\n", version)
+func runDoctorWithUtils(version string, utils fsUtils, extra ...func(utils fsUtils) checkResult) error {
+	return runDoctorReport(version, utils, false, extra...)
+}
+
+// doctorReport is the --json output.
+type doctorReport struct {
+	Version string        `json:"version"`
+	Checks  []checkResult `json:"checks"`
+	Summary resultSummary `json:"summary"`
+}
+
+func runDoctorReport(version string, utils fsUtils, asJSON bool, extra ...func(utils fsUtils) checkResult) error {
+	if !asJSON {
+		fmt.Printf("🔍 otlp-mcp doctor v%s\n\n", version)
+	}
 
 	checks := []func(utils fsUtils) checkResult{
 		checkBinaryLocation,
@@ -75,17 +161,28 @@ func runDoctorWithUtils(version string, utils fsUtils) error {
 		checkMCPConfig,
 		checkOtelCLI,
 	}
+	checks = append(checks, extra...)
 
 	results := make([]checkResult, 0, len(checks))
 	for _, check := range checks {
 		result := check(utils)
 		results = append(results, result)
-		printCheckResult(result)
+		if !asJSON {
+			printCheckResult(result)
+		}
 	}
 
-	fmt.Println()
 	summary := summarizeResults(results)
-	printSummary(summary)
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(doctorReport{Version: version, Checks: results, Summary: summary}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println()
+		printSummary(summary)
+	}
 
 	if summary.FailCount > 0 {
 		return fmt.Errorf("found %d issues that need attention", summary.FailCount)
@@ -113,9 +210,9 @@ func printCheckResult(result checkResult) {
 }
 
 type resultSummary struct {
-	PassCount int
-	WarnCount int
-	FailCount int
+	PassCount int `json:"pass"`
+	WarnCount int `json:"warn"`
+	FailCount int `json:"fail"`
 }
 
 func summarizeResults(results []checkResult) resultSummary {
@@ -367,6 +464,285 @@ func checkOtelCLI(utils fsUtils) checkResult {
 	}
 }
 
+// Check 5: otel-collector config, when given. Reports which files the
+// collector's pipelines write (and so what serve --otel-config loads) and
+// the OTLP receivers applications must send to for anything to show up.
+func checkOtelConfig(path string) func(utils fsUtils) checkResult {
+	return func(utils fsUtils) checkResult {
+		data, err := utils.ReadFile(path)
+		if err != nil {
+			return checkResult{
+				Name:       "otel_config",
+				Status:     "fail",
+				Message:    fmt.Sprintf("Cannot read otel-collector config %s", path),
+				Suggestion: fmt.Sprintf("Error: %v", err),
+				IsCritical: true,
+			}
+		}
+		collector, err := otelconfig.Parse(data)
+		if err != nil {
+			return checkResult{
+				Name:       "otel_config",
+				Status:     "fail",
+				Message:    fmt.Sprintf("Invalid otel-collector config %s", path),
+				Suggestion: fmt.Sprintf("Error: %v", err),
+				IsCritical: true,
+			}
+		}
+
+		status := "pass"
+		var details []string
+		for _, fe := range collector.FileExporters {
+			state := "exists"
+			if _, err := utils.Stat(fe.Path); err != nil {
+				state = "not written yet"
+				if _, err := utils.Stat(filepath.Dir(fe.Path)); err != nil {
+					state = "directory missing"
+					status = "warn"
+				}
+			}
+			skipped := ""
+			if fe.GroupBy {
+				skipped = ", split by resource: not loaded"
+				status = "warn"
+			}
+			details = append(details, fmt.Sprintf("%s writes %s to %s (%s%s)",
+				fe.Name, strings.Join(fe.Signals, ", "), fe.Path, state, skipped))
+		}
+		for _, r := range collector.Receivers {
+			details = append(details, fmt.Sprintf("%s receives %s over %s: OTEL_EXPORTER_OTLP_ENDPOINT=%s",
+				r.Name, strings.Join(r.Signals, ", "), r.Protocol, r.EnvVars()["OTEL_EXPORTER_OTLP_ENDPOINT"]))
+		}
+		for _, warning := range collector.Warnings {
+			details = append(details, "warning: "+warning)
+			status = "warn"
+		}
+		if len(collector.FileExporters) == 0 {
+			details = append(details, "no file exporter is used by a pipeline: serve --otel-config would load nothing")
+			status = "warn"
+		}
+		if len(collector.Receivers) == 0 {
+			details = append(details, "no OTLP receiver is used by a pipeline: applications cannot send to this collector over OTLP")
+			status = "warn"
+		}
+
+		return checkResult{
+			Name:   "otel_config",
+			Status: status,
+			Message: fmt.Sprintf("otel-collector config %s: %d file exporter(s), %d OTLP receiver endpoint(s)",
+				path, len(collector.FileExporters), len(collector.Receivers)),
+			Suggestion: strings.Join(details, "\n  "),
+		}
+	}
+}
+
+// Check 6: otlp-mcp config files. serve refuses to start with unknown keys
+// or invalid values in any of them; doctor lists every problem at once.
+func checkOtlpMCPConfig(configPath string) func(utils fsUtils) checkResult {
+	return func(utils fsUtils) checkResult {
+		var paths []string
+		if configPath != "" {
+			paths = append(paths, configPath)
+		} else if projectPath, err := FindProjectConfig(); err == nil {
+			paths = append(paths, projectPath)
+		}
+		if globalPath := GlobalConfigPath(); globalPath != "" {
+			if _, err := utils.Stat(globalPath); err == nil {
+				paths = append(paths, globalPath)
+			}
+		}
+		if len(paths) == 0 {
+			return checkResult{
+				Name:    "otlp_mcp_config",
+				Status:  "pass",
+				Message: "No .otlp-mcp.json found, using defaults",
+			}
+		}
+
+		status := "pass"
+		var details []string
+		for _, path := range paths {
+			data, err := utils.ReadFile(path)
+			if err != nil {
+				details = append(details, fmt.Sprintf("%s: %v", path, err))
+				status = "fail"
+				continue
+			}
+			problems, err := configProblems(data)
+			if err != nil {
+				details = append(details, fmt.Sprintf("%s: invalid JSON: %v", path, err))
+				status = "fail"
+				continue
+			}
+			for _, p := range problems {
+				details = append(details, fmt.Sprintf("%s: %s", path, p.Message))
+				if p.Fatal {
+					status = "fail"
+				} else if status == "pass" {
+					status = "warn"
+				}
+			}
+		}
+
+		message := fmt.Sprintf("otlp-mcp config valid: %s", strings.Join(paths, ", "))
+		if status != "pass" {
+			message = fmt.Sprintf("otlp-mcp config has problems: %s", strings.Join(paths, ", "))
+		}
+		return checkResult{
+			Name:       "otlp_mcp_config",
+			Status:     status,
+			Message:    message,
+			Suggestion: strings.Join(details, "\n  "),
+			IsCritical: status == "fail",
+		}
+	}
+}
+
+// Check 7: file sources exist, are readable and follow the file exporter
+// layout: traces/, logs/ and metrics/ subdirectories, or a single file.
+func checkFileSource(path string) func(utils fsUtils) checkResult {
+	return func(utils fsUtils) checkResult {
+		fail := func(message, suggestion string) checkResult {
+			return checkResult{
+				Name:       "file_source",
+				Status:     "fail",
+				Message:    message,
+				Suggestion: suggestion,
+				IsCritical: true,
+			}
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fail(fmt.Sprintf("File source %s not found", path), fmt.Sprintf("Error: %v", err))
+		}
+		if !info.IsDir() {
+			f, err := os.Open(path)
+			if err != nil {
+				return fail(fmt.Sprintf("File source %s is not readable", path), fmt.Sprintf("Error: %v", err))
+			}
+			f.Close()
+			return checkResult{
+				Name:    "file_source",
+				Status:  "pass",
+				Message: fmt.Sprintf("File source %s: readable exporter file (%d bytes)", path, info.Size()),
+			}
+		}
+
+		var found, details []string
+		for _, signal := range otelconfig.Signals {
+			dir := filepath.Join(path, signal)
+			if _, err := os.Stat(dir); err != nil {
+				continue
+			}
+			files, err := filereader.DataFiles(dir)
+			if err != nil {
+				return fail(fmt.Sprintf("File source %s: %s/ is not readable", path, signal), fmt.Sprintf("Error: %v", err))
+			}
+			for _, file := range files {
+				f, err := os.Open(file)
+				if err != nil {
+					return fail(fmt.Sprintf("File source %s: %s is not readable", path, file), fmt.Sprintf("Error: %v", err))
+				}
+				f.Close()
+			}
+			found = append(found, signal)
+			details = append(details, fmt.Sprintf("%s/: %d file(s)", signal, len(files)))
+		}
+
+		if len(found) == 0 {
+			suggestion := "Expected traces/, logs/ or metrics/ subdirectories written by the collector's file exporter"
+			if slices.Contains(otelconfig.Signals, filepath.Base(path)) {
+				suggestion = fmt.Sprintf("This looks like a signal directory: use its parent, %s", filepath.Dir(path))
+			}
+			return checkResult{
+				Name:       "file_source",
+				Status:     "warn",
+				Message:    fmt.Sprintf("File source %s has no telemetry subdirectories", path),
+				Suggestion: suggestion,
+			}
+		}
+		return checkResult{
+			Name:       "file_source",
+			Status:     "pass",
+			Message:    fmt.Sprintf("File source %s: %s", path, strings.Join(found, ", ")),
+			Suggestion: strings.Join(details, "\n  "),
+		}
+	}
+}
+
+// Check 8: the configured listen addresses are free. A conflict is only a
+// warning: it is often otlp-mcp itself, already running.
+func checkPortConflicts(configPath string) func(utils fsUtils) checkResult {
+	return func(utils fsUtils) checkResult {
+		cfg, err := LoadEffectiveConfig(configPath)
+		if err != nil {
+			return checkResult{
+				Name:       "port_conflicts",
+				Status:     "warn",
+				Message:    "Port conflicts not checked: configuration could not be loaded",
+				Suggestion: fmt.Sprintf("Error: %v", err),
+			}
+		}
+
+		var addrs []string
+		if cfg.OTLPSocket == "" && cfg.OTLPPort > 0 {
+			addrs = append(addrs, net.JoinHostPort(cfg.OTLPHost, strconv.Itoa(cfg.OTLPPort)))
+		}
+		if cfg.Transport == "http" && cfg.HTTPSocket == "" {
+			addrs = append(addrs, net.JoinHostPort(cfg.HTTPHost, strconv.Itoa(cfg.HTTPPort)))
+		}
+		if cfg.WebUIPort > 0 {
+			addrs = append(addrs, net.JoinHostPort(cfg.WebUIHost, strconv.Itoa(cfg.WebUIPort)))
+		}
+		var sockets []string
+		for _, socket := range []string{cfg.OTLPSocket, cfg.HTTPSocket} {
+			if socket != "" {
+				sockets = append(sockets, otlpreceiver.SocketPath(socket))
+			}
+		}
+		if len(addrs) == 0 && len(sockets) == 0 {
+			return checkResult{
+				Name:    "port_conflicts",
+				Status:  "pass",
+				Message: "No fixed ports configured (OTLP uses an ephemeral port)",
+			}
+		}
+
+		var conflicts []string
+		for _, addr := range addrs {
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %v", addr, err))
+				continue
+			}
+			l.Close()
+		}
+		for _, socket := range sockets {
+			// A stale socket file is replaced on startup; a live one is not.
+			if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+				conn.Close()
+				conflicts = append(conflicts, fmt.Sprintf("%s: something is already listening", socket))
+			}
+		}
+
+		checked := strings.Join(append(addrs, sockets...), ", ")
+		if len(conflicts) > 0 {
+			return checkResult{
+				Name:       "port_conflicts",
+				Status:     "warn",
+				Message:    fmt.Sprintf("Configured addresses in use: %d of %s", len(conflicts), checked),
+				Suggestion: strings.Join(conflicts, "\n  ") + "\n  If otlp-mcp is not already running there, pick another port or stop the other process",
+			}
+		}
+		return checkResult{
+			Name:    "port_conflicts",
+			Status:  "pass",
+			Message: fmt.Sprintf("Configured addresses are free: %s", checked),
+		}
+	}
+}
+
 // getMCPConfigPaths returns possible MCP config file paths for various agents
 func getMCPConfigPaths(utils fsUtils) []string {
 	homeDir, err := utils.UserHomeDir()
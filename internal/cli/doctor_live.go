@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otlpclient"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// liveCheckTimeout bounds the whole live round trip.
+const liveCheckTimeout = 10 * time.Second
+
+// liveCheck runs the live round trip through a throwaway otlp-mcp: test
+// spans go in over OTLP gRPC, on TCP and a Unix socket, and are queried
+// back over MCP Streamable HTTP, as an agent would. It reports two
+// results; checkOTLPGRPC must run first and checkMCPHTTP cleans up.
+type liveCheck struct {
+	ctx      context.Context
+	storage  *storage.ObservabilityStorage
+	receiver *otlpreceiver.UnifiedServer
+	socket   string
+	traceIDs []string
+	err      error
+}
+
+func (l *liveCheck) checkOTLPGRPC(utils fsUtils) checkResult {
+	ctx, cancel := context.WithTimeout(l.ctx, liveCheckTimeout)
+	defer cancel()
+
+	fail := func(message string, err error) checkResult {
+		l.err = err
+		return checkResult{
+			Name:       "live_otlp_grpc",
+			Status:     "fail",
+			Message:    message,
+			Suggestion: fmt.Sprintf("Error: %v", err),
+			IsCritical: true,
+		}
+	}
+
+	l.storage = storage.NewObservabilityStorage(100, 100, 100)
+	receiver, err := otlpreceiver.NewUnifiedServer(otlpreceiver.Config{Host: "127.0.0.1", Port: 0}, l.storage)
+	if err != nil {
+		return fail("Live check: could not start an OTLP receiver on 127.0.0.1", err)
+	}
+	l.receiver = receiver
+	go receiver.Start(context.Background())
+
+	endpoints := []string{receiver.Endpoint()}
+	// Keep the path short; Unix socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "otlp-mcp-doctor")
+	if err != nil {
+		return fail("Live check: could not create a temporary directory", err)
+	}
+	l.socket = filepath.Join(dir, "otlp.sock")
+	if err := receiver.AddSocket(ctx, l.socket); err != nil {
+		return fail("Live check: could not listen on a Unix socket", err)
+	}
+	endpoints = append(endpoints, otlpreceiver.UnixScheme+l.socket)
+
+	for _, endpoint := range endpoints {
+		traceID, err := sendTestSpan(ctx, endpoint)
+		if err != nil {
+			return fail(fmt.Sprintf("Live check: sending a span over OTLP gRPC to %s failed", endpoint), err)
+		}
+		l.traceIDs = append(l.traceIDs, traceID)
+	}
+
+	if got := l.storage.Stats().Traces.SpanCount; got != len(endpoints) {
+		return fail("Live check: spans sent over OTLP gRPC were not stored", fmt.Errorf("stored %d of %d spans", got, len(endpoints)))
+	}
+	return checkResult{
+		Name:    "live_otlp_grpc",
+		Status:  "pass",
+		Message: "Live check: OTLP gRPC receiver accepts spans over TCP and Unix socket",
+	}
+}
+
+func (l *liveCheck) checkMCPHTTP(utils fsUtils) checkResult {
+	defer l.close()
+	if l.err != nil {
+		return checkResult{
+			Name:    "live_mcp_http",
+			Status:  "warn",
+			Message: "Live check: MCP HTTP query skipped because the OTLP check failed",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, liveCheckTimeout)
+	defer cancel()
+
+	fail := func(message string, err error) checkResult {
+		return checkResult{
+			Name:       "live_mcp_http",
+			Status:     "fail",
+			Message:    message,
+			Suggestion: fmt.Sprintf("Error: %v", err),
+			IsCritical: true,
+		}
+	}
+
+	server, err := mcpserver.NewServer(l.storage, l.receiver)
+	if err != nil {
+		return fail("Live check: could not create an MCP server", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fail("Live check: could not listen for MCP HTTP on 127.0.0.1", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server.MCPServer() }, nil))
+	httpServer := &http.Server{Handler: mux}
+	go httpServer.Serve(listener)
+	defer httpServer.Close()
+
+	r := &remote{base: &url.URL{Scheme: "http", Host: listener.Addr().String()}, client: &http.Client{}}
+	for _, traceID := range l.traceIDs {
+		var out mcpserver.QueryOutput
+		if _, err := r.callTool(ctx, "query", mcpserver.QueryInput{TraceID: traceID}, &out); err != nil {
+			return fail("Live check: query over MCP Streamable HTTP failed", err)
+		}
+		if len(out.Traces) != 1 {
+			return fail("Live check: the test span was not returned by query", fmt.Errorf("trace %s: got %d spans", traceID, len(out.Traces)))
+		}
+	}
+	return checkResult{
+		Name:    "live_mcp_http",
+		Status:  "pass",
+		Message: "Live check: test spans queried back over MCP Streamable HTTP",
+	}
+}
+
+func (l *liveCheck) close() {
+	if l.receiver != nil {
+		l.receiver.Stop()
+	}
+	if l.storage != nil {
+		l.storage.ActivityCache().Close()
+	}
+	if l.socket != "" {
+		os.RemoveAll(filepath.Dir(l.socket))
+	}
+}
+
+// sendTestSpan exports one span with a random trace ID over OTLP gRPC and
+// returns the trace ID in hex.
+func sendTestSpan(ctx context.Context, endpoint string) (string, error) {
+	exporter, err := otlpclient.New(otlpclient.Config{Endpoint: endpoint, Protocol: otlpclient.ProtocolGRPC, Timeout: liveCheckTimeout})
+	if err != nil {
+		return "", err
+	}
+	defer exporter.Close()
+
+	traceID := make([]byte, 16)
+	spanID := make([]byte, 8)
+	rand.Read(traceID)
+	rand.Read(spanID)
+	now := uint64(time.Now().UnixNano())
+	err = exporter.ExportSpans(ctx, []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "otlp-mcp-doctor"}},
+		}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           traceID,
+			SpanId:            spanID,
+			Name:              "doctor",
+			StartTimeUnixNano: now,
+			EndTimeUnixNano:   now + uint64(time.Millisecond),
+		}}}},
+	}})
+	return hex.EncodeToString(traceID), err
+}
@@ -2,7 +2,11 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -135,6 +139,53 @@ func TestDoctorCommand(t *testing.T) {
 	assert.Contains(t, out, "✅ All checks passed!")
 }
 
+func TestCheckOtelConfig(t *testing.T) {
+	config := []byte(`
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+exporters:
+  file/traces:
+    path: /var/otel/traces/traces.jsonl
+  file/logs:
+    path: /missing/logs.jsonl
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [file/traces]
+    logs:
+      receivers: [otlp]
+      exporters: [file/logs]
+`)
+	utils := &mockFsUtils{
+		readFileMap: map[string][]byte{"/etc/otel/config.yaml": config},
+		statMap: map[string]os.FileInfo{
+			"/var/otel/traces/traces.jsonl": &mockFileInfo{},
+		},
+		statErr:     os.ErrNotExist,
+		readFileErr: os.ErrNotExist,
+	}
+
+	result := checkOtelConfig("/etc/otel/config.yaml")(utils)
+	assert.Equal(t, "warn", result.Status, "the logs exporter directory is missing")
+	assert.Contains(t, result.Message, "2 file exporter(s), 1 OTLP receiver endpoint(s)")
+	assert.Contains(t, result.Suggestion, "file/traces writes traces to /var/otel/traces/traces.jsonl (exists)")
+	assert.Contains(t, result.Suggestion, "file/logs writes logs to /missing/logs.jsonl (directory missing)")
+	assert.Contains(t, result.Suggestion, "OTEL_EXPORTER_OTLP_ENDPOINT=http://localhost:4317")
+
+	utils.statMap["/missing"] = &mockFileInfo{isDir: true}
+	result = checkOtelConfig("/etc/otel/config.yaml")(utils)
+	assert.Equal(t, "pass", result.Status)
+	assert.Contains(t, result.Suggestion, "(not written yet)")
+
+	result = checkOtelConfig("/etc/otel/other.yaml")(utils)
+	assert.Equal(t, "fail", result.Status)
+	assert.Contains(t, result.Message, "Cannot read otel-collector config")
+}
+
 // mockFileInfo implements os.FileInfo for testing purposes
 type mockFileInfo struct {
 	name    string
@@ -151,3 +202,124 @@ func (m *mockFileInfo) Mode() os.FileMode  { return m.mode }
 func (m *mockFileInfo) ModTime() time.Time { return m.modTime }
 func (m *mockFileInfo) IsDir() bool        { return m.isDir }
 func (m *mockFileInfo) Sys() interface{}   { return m.sys }
+
+func TestConfigProblems(t *testing.T) {
+	problems, err := configProblems([]byte(`{"otlp_prot": 4317, "transport": "grpc", "http_port": 70000, "session_timeout": "soon", "otlp_socket": "/tmp/s", "otlp_port": 4317}`))
+	assert.NoError(t, err)
+
+	var fatal, warnings []string
+	for _, p := range problems {
+		if p.Fatal {
+			fatal = append(fatal, p.Message)
+		} else {
+			warnings = append(warnings, p.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		`unknown key "otlp_prot" (did you mean "otlp_port"?)`,
+		`transport "grpc" must be "stdio" or "http"`,
+		"http_port 70000 is out of range",
+		`session_timeout "soon" is not a duration like "30m"`,
+	}, fatal)
+	assert.Equal(t, []string{"otlp_port is ignored because otlp_socket is set"}, warnings)
+
+	problems, err = configProblems([]byte(`{"otlp_port": "4317"}`))
+	assert.NoError(t, err)
+	assert.Len(t, problems, 1)
+	assert.True(t, problems[0].Fatal, "wrong types make serve fail")
+
+	_, err = configProblems([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestCheckOtlpMCPConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "otlp-mcp.json")
+	utils := &mockFsUtils{statErr: os.ErrNotExist, readFileMap: map[string][]byte{path: []byte(`{"otlp_port": 4317, "verbos": true}`)}}
+
+	result := checkOtlpMCPConfig(path)(utils)
+	assert.Equal(t, "fail", result.Status)
+	assert.Contains(t, result.Suggestion, `unknown key "verbos" (did you mean "verbose"?)`)
+
+	utils.readFileMap[path] = []byte(`{"otlp_port": 4317, "otlp_socket": "/tmp/otlp.sock"}`)
+	result = checkOtlpMCPConfig(path)(utils)
+	assert.Equal(t, "warn", result.Status)
+
+	utils.readFileMap[path] = []byte(`{"transport": "sse"}`)
+	result = checkOtlpMCPConfig(path)(utils)
+	assert.Equal(t, "fail", result.Status)
+	assert.True(t, result.IsCritical)
+
+	utils.readFileMap[path] = []byte(`{"otlp_port": 4317}`)
+	result = checkOtlpMCPConfig(path)(utils)
+	assert.Equal(t, "pass", result.Status)
+}
+
+func TestCheckFileSource(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "traces"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "traces", "traces.jsonl"), []byte("{}\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "traces", "traces-2025-12-09T13-10-56.000.jsonl.gz"), nil, 0o644))
+
+	result := checkFileSource(dir)(nil)
+	assert.Equal(t, "pass", result.Status)
+	assert.Contains(t, result.Suggestion, "traces/: 2 file(s)")
+
+	result = checkFileSource(filepath.Join(dir, "traces"))(nil)
+	assert.Equal(t, "warn", result.Status)
+	assert.Contains(t, result.Suggestion, "use its parent")
+
+	result = checkFileSource(filepath.Join(dir, "traces", "traces.jsonl"))(nil)
+	assert.Equal(t, "pass", result.Status)
+
+	result = checkFileSource(filepath.Join(dir, "missing"))(nil)
+	assert.Equal(t, "fail", result.Status)
+}
+
+func TestCheckPortConflicts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	path := filepath.Join(t.TempDir(), "otlp-mcp.json")
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`{"otlp_host": "127.0.0.1", "otlp_port": %d}`, port)), 0o644))
+	result := checkPortConflicts(path)(nil)
+	assert.Equal(t, "warn", result.Status)
+	assert.Contains(t, result.Message, "in use")
+
+	listener.Close()
+	result = checkPortConflicts(path)(nil)
+	assert.Equal(t, "pass", result.Status)
+}
+
+func TestLiveCheck(t *testing.T) {
+	live := &liveCheck{ctx: context.Background()}
+	grpcResult := live.checkOTLPGRPC(nil)
+	assert.Equal(t, "pass", grpcResult.Status, grpcResult.Suggestion)
+	httpResult := live.checkMCPHTTP(nil)
+	assert.Equal(t, "pass", httpResult.Status, httpResult.Suggestion)
+	assert.Len(t, live.traceIDs, 2)
+}
+
+func TestDoctorJSON(t *testing.T) {
+	utils := &mockFsUtils{
+		executable:  "/usr/local/bin/otlp-mcp",
+		statMap:     map[string]os.FileInfo{"/usr/local/bin/otlp-mcp": &mockFileInfo{mode: 0755}},
+		statErr:     os.ErrNotExist,
+		lookPathErr: os.ErrNotExist,
+	}
+
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runDoctorReport("test-version", utils, true)
+	})
+	assert.Error(t, runErr, "the MCP config is missing")
+
+	var report doctorReport
+	assert.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, "test-version", report.Version)
+	assert.Len(t, report.Checks, 4)
+	assert.Equal(t, "mcp_config", report.Checks[2].Name)
+	assert.Equal(t, resultSummary{PassCount: 2, WarnCount: 1, FailCount: 1}, report.Summary)
+}
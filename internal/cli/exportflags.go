@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/tobert/otlp-mcp/internal/otlpclient"
+	"github.com/urfave/cli/v3"
+)
+
+// exporterFlags returns the flags shared by commands that send OTLP
+// (replay, generate). They honor the standard OTEL_EXPORTER_OTLP_* variables.
+func exporterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "endpoint",
+			Aliases: []string{"e"},
+			Usage:   "OTLP endpoint: host:port or unix:///path for grpc, base URL for http/protobuf",
+			Value:   "127.0.0.1:4317",
+			Sources: cli.EnvVars("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		},
+		&cli.StringFlag{
+			Name:    "protocol",
+			Usage:   "OTLP protocol: 'grpc' or 'http/protobuf'",
+			Value:   otlpclient.ProtocolGRPC,
+			Sources: cli.EnvVars("OTEL_EXPORTER_OTLP_PROTOCOL"),
+		},
+		&cli.StringMapFlag{
+			Name:  "header",
+			Usage: "Extra header sent with each export, as key=value (can be specified multiple times)",
+		},
+	}
+}
+
+// newExporter creates an OTLP exporter from the exporterFlags values.
+func newExporter(cmd *cli.Command) (otlpclient.Exporter, error) {
+	return otlpclient.New(otlpclient.Config{
+		Endpoint: cmd.String("endpoint"),
+		Protocol: cmd.String("protocol"),
+		Headers:  cmd.StringMap("header"),
+	})
+}
@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/urfave/cli/v3"
+)
+
+// QueryCommand returns the CLI command definition for the 'query'
+// subcommand. It runs the query tool against a running HTTP instance.
+func QueryCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "query",
+		Usage:     "Query a running otlp-mcp from the terminal",
+		ArgsUsage: "[query...]",
+		Description: `Run the query MCP tool against a running 'otlp-mcp serve --transport http'
+and print matching spans, logs and metrics as tables, a trace waterfall or
+JSON. Useful when debugging over SSH without a browser or agent.
+
+Arguments use the web UI query language (service:cart errors duration>100ms
+event:exception linked:ID ...); flags set the same filters and take
+precedence over it.
+
+Examples:
+  # Slow requests in one service
+  otlp-mcp query service:checkout duration>500ms
+
+  # One trace as a waterfall, following producer/consumer links
+  otlp-mcp query --trace-id 4bf92f3577b34da6a3ce929d0e0e4736 --follow-links -o waterfall
+
+  # Errors since a snapshot, as JSON for jq
+  otlp-mcp query --errors --from before-deploy -o json | jq '.traces[].span_name'`,
+		Flags: append(remoteFlags(),
+			&cli.StringFlag{Name: "service", Usage: "Filter by service name"},
+			&cli.StringFlag{Name: "trace-id", Usage: "Filter by trace ID (hex)"},
+			&cli.StringFlag{Name: "span", Usage: "Filter by span name"},
+			&cli.StringFlag{Name: "severity", Usage: "Filter logs by severity (INFO, WARN, ERROR, ...)"},
+			&cli.StringSliceFlag{Name: "metric", Usage: "Filter metrics by name (repeatable)"},
+			&cli.StringFlag{Name: "from", Usage: "Start of time range (snapshot name)"},
+			&cli.StringFlag{Name: "to", Usage: "End of time range (snapshot name, empty = current)"},
+			&cli.IntFlag{Name: "limit", Usage: "Maximum results per signal (0 = no limit)"},
+			&cli.BoolFlag{Name: "errors", Usage: "Only spans with error status"},
+			&cli.StringFlag{Name: "status", Usage: "Filter spans by status: OK, ERROR or UNSET"},
+			&cli.DurationFlag{Name: "min-duration", Usage: "Minimum span duration (e.g. 500ms)"},
+			&cli.DurationFlag{Name: "max-duration", Usage: "Maximum span duration"},
+			&cli.StringFlag{Name: "has", Usage: "Only spans and logs with this attribute key"},
+			&cli.StringSliceFlag{Name: "attr", Usage: "Attribute equality filter KEY=VALUE (repeatable)"},
+			&cli.StringFlag{Name: "event", Usage: "Only spans with an event of this name"},
+			&cli.StringFlag{Name: "exception", Usage: "Only spans that recorded this exception type"},
+			&cli.StringFlag{Name: "linked", Usage: "Only spans linked to or from this trace ID"},
+			&cli.BoolFlag{Name: "follow-links", Usage: "With --trace-id: include traces reachable through span links"},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Output format: table, waterfall or json",
+				Value:   "table",
+			},
+		),
+		Action: runQuery,
+	}
+}
+
+func runQuery(ctx context.Context, cmd *cli.Command) error {
+	output := cmd.String("output")
+	if output != "table" && output != "waterfall" && output != "json" {
+		return fmt.Errorf("invalid --output %q: want table, waterfall or json", output)
+	}
+	input, err := queryInputFromFlags(cmd)
+	if err != nil {
+		return err
+	}
+	if output == "waterfall" {
+		input.VizFormat = "waterfall"
+	}
+
+	r, err := newRemote(cmd)
+	if err != nil {
+		return err
+	}
+	var result mcpserver.QueryOutput
+	text, err := r.callTool(ctx, "query", input, &result)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	case "waterfall":
+		if len(result.Traces) == 0 {
+			fmt.Println("No matching spans.")
+			return nil
+		}
+		fmt.Println(text)
+		return nil
+	default:
+		writeQueryTables(os.Stdout, result)
+		return nil
+	}
+}
+
+// queryInputFromFlags builds the query tool input from the positional query
+// and the filter flags, which override matching query terms.
+func queryInputFromFlags(cmd *cli.Command) (mcpserver.QueryInput, error) {
+	filter, err := storage.ParseQuery(strings.Join(cmd.Args().Slice(), " "))
+	if err != nil {
+		return mcpserver.QueryInput{}, fmt.Errorf("invalid query: %w", err)
+	}
+
+	setString := func(flag string, dst *string) {
+		if v := cmd.String(flag); v != "" {
+			*dst = v
+		}
+	}
+	setString("service", &filter.ServiceName)
+	setString("trace-id", &filter.TraceID)
+	setString("span", &filter.SpanName)
+	setString("severity", &filter.LogSeverity)
+	setString("from", &filter.StartSnapshot)
+	setString("to", &filter.EndSnapshot)
+	setString("status", &filter.SpanStatus)
+	setString("has", &filter.HasAttribute)
+	setString("event", &filter.EventName)
+	setString("exception", &filter.ExceptionType)
+	setString("linked", &filter.LinkedTraceID)
+
+	if names := cmd.StringSlice("metric"); len(names) > 0 {
+		filter.MetricNames = names
+	}
+	if limit := cmd.Int("limit"); limit > 0 {
+		filter.Limit = limit
+	}
+	if cmd.Bool("errors") {
+		filter.ErrorsOnly = true
+	}
+	if d := cmd.Duration("min-duration"); d > 0 {
+		ns := uint64(d)
+		filter.MinDurationNs = &ns
+	}
+	if d := cmd.Duration("max-duration"); d > 0 {
+		ns := uint64(d)
+		filter.MaxDurationNs = &ns
+	}
+	for _, kv := range cmd.StringSlice("attr") {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return mcpserver.QueryInput{}, fmt.Errorf("invalid --attr %q: want KEY=VALUE", kv)
+		}
+		if filter.AttributeEquals == nil {
+			filter.AttributeEquals = make(map[string]string)
+		}
+		filter.AttributeEquals[key] = value
+	}
+
+	if cmd.Bool("follow-links") && filter.TraceID == "" {
+		return mcpserver.QueryInput{}, fmt.Errorf("--follow-links requires a trace ID")
+	}
+
+	return mcpserver.QueryInput{
+		ServiceName:     filter.ServiceName,
+		TraceID:         strings.ToLower(filter.TraceID),
+		SpanName:        filter.SpanName,
+		LogSeverity:     filter.LogSeverity,
+		MetricNames:     filter.MetricNames,
+		StartSnapshot:   filter.StartSnapshot,
+		EndSnapshot:     filter.EndSnapshot,
+		Limit:           filter.Limit,
+		ErrorsOnly:      filter.ErrorsOnly,
+		SpanStatus:      filter.SpanStatus,
+		MinDurationNs:   filter.MinDurationNs,
+		MaxDurationNs:   filter.MaxDurationNs,
+		HasAttribute:    filter.HasAttribute,
+		AttributeEquals: filter.AttributeEquals,
+		EventName:       filter.EventName,
+		ExceptionType:   filter.ExceptionType,
+		LinkedTraceID:   filter.LinkedTraceID,
+		FollowLinks:     cmd.Bool("follow-links"),
+	}, nil
+}
+
+// writeQueryTables prints each non-empty signal of a query result as an
+// aligned table, followed by a one-line summary.
+func writeQueryTables(w io.Writer, result mcpserver.QueryOutput) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if len(result.Traces) > 0 {
+		fmt.Fprintln(tw, "TIME\tTRACE\tSERVICE\tSPAN\tDURATION\tSTATUS")
+		for _, t := range result.Traces {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				formatNanos(t.StartTime), t.TraceID, t.ServiceName, t.SpanName,
+				formatSpanDuration(t.StartTime, t.EndTime), displayStatus(t.Status))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if len(result.Logs) > 0 {
+		fmt.Fprintln(tw, "TIME\tSERVICE\tSEVERITY\tTRACE\tBODY")
+		for _, l := range result.Logs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+				formatNanos(l.Timestamp), l.ServiceName, l.Severity, orDash(l.TraceID), oneLine(l.Body, 120))
+		}
+		fmt.Fprintln(tw)
+	}
+
+	if len(result.Metrics) > 0 {
+		fmt.Fprintln(tw, "TIME\tSERVICE\tMETRIC\tTYPE\tVALUE\tPOINTS")
+		for _, m := range result.Metrics {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n",
+				formatNanos(m.Timestamp), m.ServiceName, m.MetricName, m.MetricType,
+				metricValue(m), m.DataPoints)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+
+	s := result.Summary
+	fmt.Fprintf(w, "%d spans in %d traces, %d logs, %d metrics", s.TraceCount, len(s.TraceIDs), s.LogCount, s.MetricCount)
+	if len(s.Services) > 0 {
+		fmt.Fprintf(w, " from %s", strings.Join(s.Services, ", "))
+	}
+	fmt.Fprintln(w)
+	for _, l := range result.LinkedTraces {
+		fmt.Fprintf(w, "  ⇢ %s/%s links to %s/%s (depth %d)\n", l.FromTraceID, l.FromSpanID, l.ToTraceID, l.ToSpanID, l.Depth)
+	}
+}
+
+// formatNanos formats a Unix nano timestamp in local time.
+func formatNanos(ns uint64) string {
+	if ns == 0 {
+		return "-"
+	}
+	return time.Unix(0, int64(ns)).Format("15:04:05.000")
+}
+
+func formatSpanDuration(start, end uint64) string {
+	if end < start {
+		return "-"
+	}
+	return formatMillis(float64(end-start) / 1e6)
+}
+
+// formatMillis renders a duration in milliseconds with a precision that
+// suits its size.
+func formatMillis(ms float64) string {
+	switch {
+	case ms >= 1000:
+		return fmt.Sprintf("%.2fs", ms/1000)
+	case ms >= 1:
+		return fmt.Sprintf("%.1fms", ms)
+	default:
+		return fmt.Sprintf("%.0fµs", ms*1000)
+	}
+}
+
+func displayStatus(status string) string {
+	status = strings.TrimPrefix(status, "STATUS_CODE_")
+	if status == "" {
+		return "UNSET"
+	}
+	return status
+}
+
+func metricValue(m mcpserver.MetricSummary) string {
+	switch {
+	case m.Value != nil:
+		return fmt.Sprintf("%g", *m.Value)
+	case m.Count != nil && m.Sum != nil:
+		return fmt.Sprintf("count=%d sum=%g", *m.Count, *m.Sum)
+	case m.Count != nil:
+		return fmt.Sprintf("count=%d", *m.Count)
+	}
+	return "-"
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// oneLine collapses whitespace and truncates s to max runes.
+func oneLine(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/tobert/otlp-mcp/internal/webui"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// newRemoteTestServer serves MCP and the web UI on one mux, as
+// 'serve --transport http' does, with two spans stored: a fast OK one from
+// "web" and a slow failing one from "api".
+func newRemoteTestServer(t *testing.T) (*httptest.Server, *storage.ObservabilityStorage) {
+	t.Helper()
+	st := storage.NewObservabilityStorage(100, 100, 100)
+	recv, err := otlpreceiver.NewUnifiedServer(otlpreceiver.Config{Host: "127.0.0.1", Port: 0}, st)
+	require.NoError(t, err)
+	t.Cleanup(recv.Stop)
+	go recv.Start(context.Background())
+
+	srv, err := mcpserver.NewServer(st, recv)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv.MCPServer() }, nil))
+	webui.New(st, nil).RegisterRoutes(mux)
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+
+	now := uint64(time.Now().UnixNano())
+	resource := func(service string) *resourcepb.Resource {
+		return &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: service}},
+		}}}
+	}
+	err = st.ReceiveSpans(context.Background(), []*tracepb.ResourceSpans{
+		{
+			Resource: resource("web"),
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+				Name: "GET /", TraceId: bytes.Repeat([]byte{1}, 16), SpanId: []byte{1, 1, 1, 1, 1, 1, 1, 1},
+				StartTimeUnixNano: now, EndTimeUnixNano: now + uint64(5*time.Millisecond),
+				Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK},
+			}}}},
+		},
+		{
+			Resource: resource("api"),
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+				Name: "charge", TraceId: bytes.Repeat([]byte{2}, 16), SpanId: []byte{2, 2, 2, 2, 2, 2, 2, 2},
+				StartTimeUnixNano: now, EndTimeUnixNano: now + uint64(800*time.Millisecond),
+				Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR},
+			}}}},
+		},
+	})
+	require.NoError(t, err)
+	return ts, st
+}
+
+// captureStdout runs fn with os.Stdout redirected and returns what it wrote.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+	defer func() { os.Stdout = oldStdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestQueryCommand(t *testing.T) {
+	ts, _ := newRemoteTestServer(t)
+
+	testCases := []struct {
+		name  string
+		args  []string
+		spans []string
+	}{
+		{"all", nil, []string{"GET /", "charge"}},
+		{"query_language", []string{"duration>100ms"}, []string{"charge"}},
+		{"flag", []string{"--service", "web"}, []string{"GET /"}},
+		{"flag_overrides_query", []string{"--service", "api", "service:web"}, []string{"charge"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]string{"query", "--server", ts.URL, "-o", "json"}, tc.args...)
+			var runErr error
+			out := captureStdout(t, func() {
+				runErr = QueryCommand().Run(context.Background(), args)
+			})
+			require.NoError(t, runErr)
+
+			var result mcpserver.QueryOutput
+			require.NoError(t, json.Unmarshal([]byte(out), &result))
+			var names []string
+			for _, span := range result.Traces {
+				names = append(names, span.SpanName)
+			}
+			assert.ElementsMatch(t, tc.spans, names)
+		})
+	}
+}
+
+func TestQueryCommandErrors(t *testing.T) {
+	ts, _ := newRemoteTestServer(t)
+
+	err := QueryCommand().Run(context.Background(), []string{"query", "--server", ts.URL, "--attr", "novalue"})
+	assert.ErrorContains(t, err, "KEY=VALUE")
+
+	err = QueryCommand().Run(context.Background(), []string{"query", "--server", ts.URL, "--follow-links"})
+	assert.ErrorContains(t, err, "requires a trace ID")
+
+	err = QueryCommand().Run(context.Background(), []string{"query", "--server", ts.URL, "--from", "missing"})
+	assert.ErrorContains(t, err, "query failed")
+}
+
+func TestWriteQueryTables(t *testing.T) {
+	value := 42.0
+	var buf bytes.Buffer
+	writeQueryTables(&buf, mcpserver.QueryOutput{
+		Traces: []mcpserver.TraceSummary{{
+			TraceID: "abc", ServiceName: "api", SpanName: "charge",
+			StartTime: 1, EndTime: 1 + uint64(1500*time.Millisecond), Status: "STATUS_CODE_ERROR",
+		}},
+		Logs:    []mcpserver.LogSummary{{ServiceName: "api", Severity: "ERROR", Body: "card\n  declined", Timestamp: 1}},
+		Metrics: []mcpserver.MetricSummary{{MetricName: "queue.depth", ServiceName: "api", MetricType: "Gauge", Value: &value, DataPoints: 1}},
+		Summary: mcpserver.QuerySummary{TraceCount: 1, LogCount: 1, MetricCount: 1, Services: []string{"api"}, TraceIDs: []string{"abc"}},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "1.50s")
+	assert.Contains(t, out, "ERROR")
+	assert.Contains(t, out, "card declined")
+	assert.Contains(t, out, "queue.depth")
+	assert.Contains(t, out, "1 spans in 1 traces, 1 logs, 1 metrics from api")
+}
+
+func TestTailCommand(t *testing.T) {
+	ts, _ := newRemoteTestServer(t)
+
+	testCases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"history", nil, []string{"GET /", "charge"}},
+		{"filtered", []string{"service:api"}, []string{"charge"}},
+		{"no_history", []string{"--no-history"}, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+			defer cancel()
+
+			args := append([]string{"tail", "--server", ts.URL, "--json"}, tc.args...)
+			var runErr error
+			out := captureStdout(t, func() {
+				runErr = TailCommand().Run(ctx, args)
+			})
+			require.NoError(t, runErr)
+
+			var names []string
+			dec := json.NewDecoder(bytes.NewBufferString(out))
+			for dec.More() {
+				var span tailSpan
+				require.NoError(t, dec.Decode(&span))
+				names = append(names, span.SpanName)
+			}
+			assert.ElementsMatch(t, tc.want, names)
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/tobert/otlp-mcp/internal/webui"
+	"github.com/urfave/cli/v3"
+)
+
+// reloadDebounce is how long config file events must settle before a
+// reload, since editors often write a file in several steps.
+const reloadDebounce = 250 * time.Millisecond
+
+// liveConfigKeys are the settings a reload applies to a running server.
+// Changes to any other setting are logged as needing a restart.
+var liveConfigKeys = []string{
+	"$schema", "comment",
+	"trace_buffer_size", "log_buffer_size", "metric_buffer_size",
+	"allowed_origins", "otlp_extra_ports", "file_sources",
+}
+
+// configReloader applies config file changes to a running server: buffer
+// sizes, allowed origins, extra OTLP ports and file sources. Buffered
+// telemetry and snapshots are kept.
+type configReloader struct {
+	cmd        *cli.Command // serve command, whose flags still win over files
+	configPath string       // --config, if given
+	current    *Config      // the config in effect
+
+	storage        *storage.ObservabilityStorage
+	otlpServer     *otlpreceiver.UnifiedServer
+	mcpServer      *mcpserver.Server
+	webuiServer    *webui.Server // nil without a web UI
+	origins        *originList
+	fileSourceOpts mcpserver.FileSourceOptions
+
+	// fixedSources are file sources from --file-source or the collector
+	// config, which reloads leave alone.
+	fixedSources map[string]bool
+}
+
+// Run reloads the config when one of its files changes or on SIGHUP,
+// until ctx is done. sources names the config files loaded at startup.
+func (r *configReloader) Run(ctx context.Context, sources *ConfigSources) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("⚠️  Config reload: cannot watch config files, use SIGHUP: %v\n", err)
+	} else {
+		defer watcher.Close()
+	}
+	files := r.watchedFiles(sources)
+	if watcher != nil {
+		dirs := make(map[string]bool)
+		for _, file := range files {
+			dirs[filepath.Dir(file)] = true
+		}
+		// Watch directories, not files, so editors that replace the file
+		// and configs created after startup are seen.
+		for dir := range dirs {
+			if err := watcher.Add(dir); err != nil && r.current.Verbose {
+				log.Printf("⚠️  Config reload: not watching %s: %v\n", dir, err)
+			}
+		}
+	}
+
+	var events <-chan fsnotify.Event
+	var watchErrors <-chan error
+	if watcher != nil {
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	settle := time.NewTimer(reloadDebounce)
+	settle.Stop()
+	defer settle.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hup:
+			log.Println("🔄 Received SIGHUP, reloading config")
+			r.Reload(ctx)
+
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if slices.Contains(files, filepath.Clean(event.Name)) {
+				settle.Reset(reloadDebounce)
+			}
+
+		case err, ok := <-watchErrors:
+			if !ok {
+				watchErrors = nil
+				continue
+			}
+			log.Printf("⚠️  Config reload: watcher error: %v\n", err)
+
+		case <-settle.C:
+			log.Println("🔄 Config file changed, reloading")
+			r.Reload(ctx)
+		}
+	}
+}
+
+// watchedFiles returns the config files whose changes trigger a reload:
+// --config if given, otherwise the global config and the project config,
+// or where a new project config would be found.
+func (r *configReloader) watchedFiles(sources *ConfigSources) []string {
+	if r.configPath != "" {
+		return []string{absPath(r.configPath)}
+	}
+
+	var files []string
+	if global := GlobalConfigPath(); global != "" {
+		files = append(files, absPath(global))
+	}
+	if project, ok := sources.Files[SourceProject]; ok {
+		files = append(files, absPath(project))
+	} else if cwd, err := os.Getwd(); err == nil {
+		files = append(files, filepath.Join(cwd, ".otlp-mcp.json"))
+	}
+	return files
+}
+
+// absPath returns path made absolute and clean, or path itself if the
+// working directory is unknown.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Reload loads the config files again, applies command line flags on
+// top, and applies the result. An invalid config is logged and the
+// running config kept.
+func (r *configReloader) Reload(ctx context.Context) {
+	next, sources, err := LoadConfigWithSources(r.configPath)
+	if err != nil {
+		log.Printf("⚠️  Config reload failed, keeping current config: %v\n", err)
+		return
+	}
+	applyConfigFlags(r.cmd, next, sources)
+	r.apply(ctx, next)
+}
+
+// apply changes the running server to match next, logging each change and
+// every changed setting that needs a restart.
+func (r *configReloader) apply(ctx context.Context, next *Config) {
+	prev := r.current
+	changed := 0
+
+	for _, buffer := range []struct {
+		name     string
+		from, to int
+		resize   func(int) int
+	}{
+		{"Trace buffer", prev.TraceBufferSize, next.TraceBufferSize, r.storage.Traces().Resize},
+		{"Log buffer", prev.LogBufferSize, next.LogBufferSize, r.storage.Logs().Resize},
+		{"Metric buffer", prev.MetricBufferSize, next.MetricBufferSize, r.storage.Metrics().Resize},
+	} {
+		if buffer.from == buffer.to {
+			continue
+		}
+		dropped := buffer.resize(buffer.to)
+		log.Printf("🔄 %s: %d -> %d (%d oldest dropped)\n", buffer.name, buffer.from, buffer.to, dropped)
+		changed++
+	}
+
+	if !slices.Equal(prev.AllowedOrigins, next.AllowedOrigins) {
+		r.origins.Set(next.AllowedOrigins)
+		if r.webuiServer != nil {
+			r.webuiServer.SetAllowedOrigins(next.AllowedOrigins)
+		}
+		log.Printf("🔄 Allowed origins: %v\n", next.AllowedOrigins)
+		changed++
+	}
+
+	// Ports that fail to bind stay out of the config in effect, and ports
+	// that fail to close stay in it, so a later reload tries them again.
+	ports := slices.Clone(next.OTLPExtraPorts)
+	for _, port := range prev.OTLPExtraPorts {
+		if slices.Contains(next.OTLPExtraPorts, port) {
+			continue
+		}
+		if err := r.otlpServer.RemovePort(port); err != nil {
+			log.Printf("⚠️  Config reload: failed to remove OTLP port %d: %v\n", port, err)
+			ports = append(ports, port)
+			continue
+		}
+		log.Printf("🔄 OTLP port %d removed\n", port)
+		changed++
+	}
+	for _, port := range next.OTLPExtraPorts {
+		if slices.Contains(prev.OTLPExtraPorts, port) {
+			continue
+		}
+		if err := r.otlpServer.AddPort(ctx, port); err != nil {
+			log.Printf("⚠️  Config reload: failed to add OTLP port %d: %v\n", port, err)
+			ports = slices.DeleteFunc(ports, func(p int) bool { return p == port })
+			continue
+		}
+		log.Printf("🔄 OTLP port %d added\n", port)
+		changed++
+	}
+
+	fileSources := slices.Clone(next.FileSources)
+	for _, dir := range prev.FileSources {
+		if slices.Contains(next.FileSources, dir) || r.fixedSources[dir] {
+			continue
+		}
+		if err := r.mcpServer.RemoveFileSource(dir); err != nil {
+			log.Printf("⚠️  Config reload: failed to remove file source %s: %v\n", dir, err)
+			continue
+		}
+		log.Printf("🔄 File source %s removed\n", dir)
+		changed++
+	}
+	for _, dir := range next.FileSources {
+		if slices.Contains(prev.FileSources, dir) || r.fixedSources[dir] {
+			continue
+		}
+		if err := r.mcpServer.AddFileSource(ctx, dir, r.fileSourceOpts); err != nil {
+			log.Printf("⚠️  Config reload: failed to add file source %s: %v\n", dir, err)
+			fileSources = slices.DeleteFunc(fileSources, func(d string) bool { return d == dir })
+			continue
+		}
+		log.Printf("🔄 File source %s added\n", dir)
+		changed++
+	}
+
+	// Everything else is in effect only from startup
+	applied := *next
+	restart := restartConfigChanges(prev, next)
+	for _, key := range restart {
+		log.Printf("⚠️  Config reload: %s changed, restart otlp-mcp to apply\n", key)
+		i, _ := configFieldIndex(key)
+		reflect.ValueOf(&applied).Elem().Field(i).Set(reflect.ValueOf(prev).Elem().Field(i))
+	}
+	applied.OTLPExtraPorts = ports
+	applied.FileSources = fileSources
+	r.current = &applied
+
+	if changed == 0 && len(restart) == 0 {
+		log.Println("🔄 Config reloaded, nothing to apply")
+	}
+}
+
+// restartConfigChanges returns the keys outside liveConfigKeys whose
+// values differ between prev and next.
+func restartConfigChanges(prev, next *Config) []string {
+	before, after := configValues(prev), configValues(next)
+	var keys []string
+	for _, key := range ConfigKeys() {
+		if slices.Contains(liveConfigKeys, key) {
+			continue
+		}
+		if !reflect.DeepEqual(before[key], after[key]) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/tobert/otlp-mcp/internal/webui"
+)
+
+// freePort returns a TCP port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestConfigReloaderApply(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := DefaultConfig()
+	cfg.TraceBufferSize, cfg.LogBufferSize, cfg.MetricBufferSize = 4, 4, 4
+	obsStorage := storage.NewObservabilityStorage(cfg.TraceBufferSize, cfg.LogBufferSize, cfg.MetricBufferSize)
+	otlpServer, err := otlpreceiver.NewUnifiedServer(otlpreceiver.Config{Host: "127.0.0.1"}, obsStorage)
+	require.NoError(t, err)
+	go otlpServer.Start(ctx)
+	defer otlpServer.Stop()
+	mcpServer, err := mcpserver.NewServer(obsStorage, otlpServer)
+	require.NoError(t, err)
+	defer mcpServer.Shutdown()
+
+	for i := 0; i < 4; i++ {
+		_, err := sendTestSpan(ctx, otlpServer.Endpoint())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, obsStorage.Traces().Stats().SpanCount)
+
+	fixed := t.TempDir()
+	require.NoError(t, mcpServer.AddFileSource(ctx, fixed, mcpserver.FileSourceOptions{}))
+
+	r := &configReloader{
+		current:      cfg,
+		storage:      obsStorage,
+		otlpServer:   otlpServer,
+		mcpServer:    mcpServer,
+		webuiServer:  webui.New(obsStorage, cfg.AllowedOrigins),
+		origins:      newOriginList(cfg.AllowedOrigins),
+		fixedSources: map[string]bool{fixed: true},
+	}
+
+	source := t.TempDir()
+	port := freePort(t)
+	next := *cfg
+	next.TraceBufferSize = 2
+	next.LogBufferSize = 8
+	next.AllowedOrigins = []string{"https://example.com"}
+	next.OTLPExtraPorts = []int{port}
+	next.FileSources = []string{source, fixed}
+	next.HTTPPort = 9999
+	r.apply(ctx, &next)
+
+	stats := obsStorage.Stats()
+	assert.Equal(t, 2, stats.Traces.Capacity)
+	assert.Equal(t, 2, stats.Traces.SpanCount, "shrinking keeps the newest spans")
+	assert.Equal(t, 8, stats.Logs.Capacity)
+	assert.Equal(t, []string{"https://example.com"}, r.origins.Patterns())
+	assert.Contains(t, otlpServer.Endpoints(), net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	assert.ElementsMatch(t, []string{fixed, source}, mcpServer.ListFileSources())
+	assert.Equal(t, DefaultConfig().HTTPPort, r.current.HTTPPort, "restart-only settings stay as started")
+
+	// Dropping them from the config undoes it, except the fixed source
+	empty := *r.current
+	empty.OTLPExtraPorts = nil
+	empty.FileSources = nil
+	r.apply(ctx, &empty)
+
+	assert.Len(t, otlpServer.Endpoints(), 1)
+	assert.Equal(t, []string{fixed}, mcpServer.ListFileSources())
+
+	// A port that fails to close is still listening, so it stays in effect
+	_, primary, err := net.SplitHostPort(otlpServer.Endpoint())
+	require.NoError(t, err)
+	primaryPort, err := strconv.Atoi(primary)
+	require.NoError(t, err)
+	r.current.OTLPExtraPorts = []int{primaryPort}
+	empty = *r.current
+	empty.OTLPExtraPorts = nil
+	r.apply(ctx, &empty)
+	assert.Equal(t, []int{primaryPort}, r.current.OTLPExtraPorts)
+}
+
+func TestConfigReloaderReload(t *testing.T) {
+	ctx := context.Background()
+	configEnv(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, path, `{"trace_buffer_size": 10}`)
+
+	cfg, _, err := LoadConfigWithSources(path)
+	require.NoError(t, err)
+	obsStorage := storage.NewObservabilityStorage(cfg.TraceBufferSize, cfg.LogBufferSize, cfg.MetricBufferSize)
+
+	r := &configReloader{cmd: ServeCommand(), configPath: path, current: cfg, storage: obsStorage, origins: newOriginList(nil)}
+
+	// An invalid config leaves everything as it was
+	writeConfig(t, path, `{"trace_bufer_size": 20}`)
+	r.Reload(ctx)
+	assert.Equal(t, 10, obsStorage.Traces().Stats().Capacity)
+
+	writeConfig(t, path, `{"trace_buffer_size": 20}`)
+	r.Reload(ctx)
+	assert.Equal(t, 20, obsStorage.Traces().Stats().Capacity)
+	assert.Equal(t, 20, r.current.TraceBufferSize)
+}
+
+func TestRestartConfigChanges(t *testing.T) {
+	prev := DefaultConfig()
+	next := DefaultConfig()
+	next.TraceBufferSize = 1
+	next.FileSources = []string{"/tmp"}
+	next.Transport = "http"
+	next.Verbose = true
+	assert.Equal(t, []string{"transport", "verbose"}, restartConfigChanges(prev, next))
+}
+
+func TestWatchedFiles(t *testing.T) {
+	_, project := configEnv(t)
+	r := &configReloader{}
+
+	files := r.watchedFiles(&ConfigSources{Files: map[string]string{}})
+	assert.True(t, slices.ContainsFunc(files, func(f string) bool { return strings.HasSuffix(f, filepath.Join("otlp-mcp", "config.json")) }))
+	assert.Contains(t, files, filepath.Join(project, ".otlp-mcp.json"), "a project config created later is seen")
+
+	r.configPath = "explicit.json"
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(cwd, "explicit.json")}, r.watchedFiles(nil))
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/urfave/cli/v3"
+)
+
+// remoteFlags returns the flags shared by commands that talk to a running
+// 'serve --transport http' instance (query, tail).
+func remoteFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "server",
+			Aliases: []string{"s"},
+			Usage:   "Base URL of a running 'otlp-mcp serve --transport http'",
+			Value:   "http://127.0.0.1:4380",
+			Sources: cli.EnvVars("OTLP_MCP_SERVER"),
+		},
+		&cli.StringFlag{
+			Name:  "socket",
+			Usage: "Connect over this Unix domain socket (serve --http-socket) instead of TCP",
+		},
+	}
+}
+
+// remote is a connection target resolved from remoteFlags.
+type remote struct {
+	base   *url.URL
+	client *http.Client
+}
+
+// newRemote resolves the server URL and, for --socket, an HTTP client that
+// dials the socket whatever the URL's host.
+func newRemote(cmd *cli.Command) (*remote, error) {
+	raw := cmd.String("server")
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	base, err := url.Parse(raw)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid --server %q: want a URL like http://127.0.0.1:4380", cmd.String("server"))
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid --server %q: scheme must be http or https", cmd.String("server"))
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/")
+
+	r := &remote{base: base, client: &http.Client{}}
+	if socket := cmd.String("socket"); socket != "" {
+		r.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+	}
+	return r, nil
+}
+
+// endpoint returns the URL of path on the server.
+func (r *remote) endpoint(path string) string {
+	u := *r.base
+	u.Path += path
+	return u.String()
+}
+
+// callTool calls an MCP tool on the server and decodes its structured
+// output into out. It returns the tool's text content (the visualization).
+func (r *remote) callTool(ctx context.Context, name string, args, out any) (string, error) {
+	client := mcp.NewClient(&mcp.Implementation{Name: "otlp-mcp-cli", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:             r.endpoint("/mcp"),
+		HTTPClient:           r.client,
+		MaxRetries:           -1,
+		DisableStandaloneSSE: true,
+	}, nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot reach otlp-mcp at %s (is 'otlp-mcp serve --transport http' running?): %w", r.base, err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", name, err)
+	}
+
+	var text []string
+	for _, c := range result.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			text = append(text, tc.Text)
+		}
+	}
+	if result.IsError {
+		return "", fmt.Errorf("%s failed: %s", name, strings.Join(text, "; "))
+	}
+
+	if out != nil && result.StructuredContent != nil {
+		data, err := json.Marshal(result.StructuredContent)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return "", fmt.Errorf("unexpected %s output: %w", name, err)
+		}
+	}
+	return strings.Join(text, "\n"), nil
+}
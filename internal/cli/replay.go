@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/replay"
+	"github.com/urfave/cli/v3"
+)
+
+// ReplayCommand returns the CLI command definition for the 'replay' subcommand.
+// It re-sends captured telemetry to a live OTLP endpoint.
+func ReplayCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "replay",
+		Usage:     "Re-send captured OTLP telemetry to a live endpoint",
+		ArgsUsage: "<path>...",
+		Description: `Replay telemetry captured by the otel-collector file exporter (or any
+directory in the same layout) to an OTLP endpoint, such as otlp-mcp itself.
+
+Each path may be a file (.jsonl, .pb, optionally .gz/.zst), a signal
+directory (traces/, logs/, metrics/), or a base directory containing them.
+A snapshot range written by the export_snapshot MCP tool is a single JSONL
+file mixing all three signals. Records are sent in original timestamp order.
+
+Examples:
+  # Replay yesterday's capture in real time to a local otlp-mcp
+  otlp-mcp replay --endpoint 127.0.0.1:4317 /tank/otel
+
+  # 10x speed, forever, with fresh timestamps and trace IDs
+  otlp-mcp replay --speed 10 --loop --rewrite-timestamps --rewrite-trace-ids /tank/otel
+
+  # Re-send a snapshot range exported from another otlp-mcp
+  otlp-mcp replay --rewrite-timestamps /tmp/otlp-mcp-before-fix.jsonl
+
+  # As fast as possible over HTTP/protobuf
+  otlp-mcp replay --speed 0 --protocol http/protobuf --endpoint http://127.0.0.1:4318 traces.jsonl`,
+		Flags: append(exporterFlags(),
+			&cli.FloatFlag{
+				Name:  "speed",
+				Usage: "Timing multiplier: 1 = original inter-arrival timing, 2 = twice as fast, 0 = no delays",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "loop",
+				Usage: "Repeat the replay until interrupted",
+			},
+			&cli.BoolFlag{
+				Name:  "rewrite-timestamps",
+				Usage: "Shift timestamps so the first record lands at replay start (spacing preserved)",
+			},
+			&cli.BoolFlag{
+				Name:  "rewrite-trace-ids",
+				Usage: "Replace trace IDs with fresh random IDs on every pass",
+			},
+			&cli.BoolFlag{
+				Name:  "verbose",
+				Usage: "Log progress after each pass",
+			},
+		),
+		Action: runReplay,
+	}
+}
+
+func runReplay(ctx context.Context, cmd *cli.Command) error {
+	paths := cmd.Args().Slice()
+	if len(paths) == 0 {
+		return fmt.Errorf("at least one path is required")
+	}
+	speed := cmd.Float("speed")
+	if speed < 0 {
+		return fmt.Errorf("--speed must be >= 0")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	items, err := replay.Load(ctx, paths)
+	if err != nil {
+		return fmt.Errorf("failed to load telemetry: %w", err)
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no telemetry found in %v", paths)
+	}
+
+	exporter, err := newExporter(cmd)
+	if err != nil {
+		return err
+	}
+	defer exporter.Close()
+
+	span := items[len(items)-1].Time.Sub(items[0].Time)
+	log.Printf("🔁 Replaying %d batches (%s of original time) to %s (%s)\n",
+		len(items), span.Round(time.Millisecond), cmd.String("endpoint"), cmd.String("protocol"))
+
+	verbose := cmd.Bool("verbose")
+	stats, err := replay.Run(ctx, exporter, items, replay.Options{
+		Speed:             speed,
+		Loop:              cmd.Bool("loop"),
+		RewriteTimestamps: cmd.Bool("rewrite-timestamps"),
+		RewriteTraceIDs:   cmd.Bool("rewrite-trace-ids"),
+		OnIteration: func(s replay.Stats) {
+			if verbose {
+				log.Printf("   pass %d: %d spans, %d logs, %d metric points sent so far\n",
+					s.Iterations, s.Spans, s.Logs, s.Metrics)
+			}
+		},
+	})
+
+	log.Printf("✅ Sent %d spans, %d logs, %d metric points in %d batches (%d passes)\n",
+		stats.Spans, stats.Logs, stats.Metrics, stats.Batches, stats.Iterations)
+
+	// Ctrl-C ends a looped replay; that's the normal way to stop
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
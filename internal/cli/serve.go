@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,7 +15,9 @@ import (
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tobert/otlp-mcp/internal/filereader"
 	"github.com/tobert/otlp-mcp/internal/mcpserver"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
 	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
 	"github.com/tobert/otlp-mcp/internal/storage"
 	"github.com/tobert/otlp-mcp/internal/webui"
@@ -38,154 +41,215 @@ Examples:
   otlp-mcp serve                        # stdio mode (default)
   otlp-mcp serve --transport http       # HTTP mode on port 4380
   otlp-mcp serve --transport http --http-port 8080`,
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:    "config",
-				Aliases: []string{"c"},
-				Usage:   "Path to config file (default: search for .otlp-mcp.json)",
-			},
-			&cli.IntFlag{
-				Name:  "trace-buffer-size",
-				Usage: "Number of spans to buffer (overrides config file)",
-				Value: 0, // 0 means use config/default
-			},
-			&cli.IntFlag{
-				Name:  "log-buffer-size",
-				Usage: "Number of log records to buffer (overrides config file)",
-				Value: 0, // 0 means use config/default
-			},
-			&cli.IntFlag{
-				Name:  "metric-buffer-size",
-				Usage: "Number of metric points to buffer (overrides config file)",
-				Value: 0, // 0 means use config/default
-			},
-			&cli.StringFlag{
-				Name:  "otlp-host",
-				Usage: "OTLP server bind address (overrides config file)",
-				Value: "",
-			},
-			&cli.IntFlag{
-				Name:  "otlp-port",
-				Usage: "OTLP server port, 0 for ephemeral (overrides config file)",
-				Value: -1, // -1 means not set
-			},
-			&cli.BoolFlag{
-				Name:  "verbose",
-				Usage: "Enable verbose logging (overrides config file)",
-			},
+		Flags: append(configFlags(),
 			&cli.StringSliceFlag{
 				Name:    "file-source",
 				Aliases: []string{"f"},
-				Usage:   "Directory to load OTLP JSONL files from (can be specified multiple times)",
+				Usage:   "Directory to load OTLP files from (can be specified multiple times)",
 			},
-			// HTTP transport flags
 			&cli.StringFlag{
-				Name:  "transport",
-				Usage: "MCP transport: 'stdio' (default) or 'http'",
-				Value: "",
+				Name:  "file-source-since",
+				Usage: "Only load file source records at or after this time (RFC3339, '2006-01-02 15:04', or a duration ago like 2h); includes rotated archives",
 			},
 			&cli.StringFlag{
-				Name:  "http-host",
-				Usage: "HTTP server bind address (when transport=http)",
-				Value: "",
-			},
-			&cli.IntFlag{
-				Name:  "http-port",
-				Usage: "HTTP server port (when transport=http, default 4380)",
-				Value: -1,
-			},
-			&cli.StringSliceFlag{
-				Name:  "allowed-origin",
-				Usage: "Allowed Origin headers for HTTP transport (can specify multiple)",
-			},
-			&cli.DurationFlag{
-				Name:  "session-timeout",
-				Usage: "Session idle timeout for HTTP transport (e.g., 30m)",
-				Value: 0,
-			},
-			&cli.BoolFlag{
-				Name:  "stateless",
-				Usage: "Run HTTP transport in stateless mode (no session persistence)",
-			},
-			// Web UI flags
-			&cli.IntFlag{
-				Name:  "webui-port",
-				Usage: "Serve web UI on a separate port (0 = same port as HTTP transport, required for stdio)",
-				Value: -1,
-			},
-			&cli.StringFlag{
-				Name:  "webui-host",
-				Usage: "Web UI bind address (default: 127.0.0.1)",
-				Value: "",
+				Name:  "file-source-until",
+				Usage: "Only load file source records at or before this time (same formats as --file-source-since)",
 			},
 			// Otel collector integration
 			&cli.StringFlag{
 				Name:  "otel-config",
 				Usage: "Path to otel-collector config.yaml to auto-discover file sources (skips OTLP listener)",
 			},
-		},
+		),
 		Action: runServe,
 	}
 }
 
-// runServe is the action handler for the serve command.
-// It wires together all components: storage, OTLP receiver, and MCP server.
-func runServe(cliCtx context.Context, cmd *cli.Command) error {
-	// Load effective config from files
-	configPath := cmd.String("config")
-	cfg, err := LoadEffectiveConfig(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+// configFlags returns the flags that override Config values, shared by
+// serve and 'config show'. Apply them with applyConfigFlags.
+func configFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "Path to config file (default: search for .otlp-mcp.json)",
+		},
+		&cli.IntFlag{
+			Name:  "trace-buffer-size",
+			Usage: "Number of spans to buffer (overrides config file)",
+			Value: 0, // 0 means use config/default
+		},
+		&cli.IntFlag{
+			Name:  "log-buffer-size",
+			Usage: "Number of log records to buffer (overrides config file)",
+			Value: 0, // 0 means use config/default
+		},
+		&cli.IntFlag{
+			Name:  "metric-buffer-size",
+			Usage: "Number of metric points to buffer (overrides config file)",
+			Value: 0, // 0 means use config/default
+		},
+		&cli.StringFlag{
+			Name:  "otlp-host",
+			Usage: "OTLP server bind address (overrides config file)",
+			Value: "",
+		},
+		&cli.IntFlag{
+			Name:  "otlp-port",
+			Usage: "OTLP server port, 0 for ephemeral (overrides config file)",
+			Value: -1, // -1 means not set
+		},
+		&cli.StringFlag{
+			Name:  "otlp-socket",
+			Usage: "Listen for OTLP gRPC on a Unix domain socket instead of TCP (overrides config file)",
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "Enable verbose logging (overrides config file)",
+		},
+		// HTTP transport flags
+		&cli.StringFlag{
+			Name:  "transport",
+			Usage: "MCP transport: 'stdio' (default) or 'http'",
+			Value: "",
+		},
+		&cli.StringFlag{
+			Name:  "http-host",
+			Usage: "HTTP server bind address (when transport=http)",
+			Value: "",
+		},
+		&cli.IntFlag{
+			Name:  "http-port",
+			Usage: "HTTP server port (when transport=http, default 4380)",
+			Value: -1,
+		},
+		&cli.StringFlag{
+			Name:  "http-socket",
+			Usage: "Serve HTTP transport on a Unix domain socket instead of host:port (when transport=http)",
+			Value: "",
+		},
+		&cli.StringSliceFlag{
+			Name:  "allowed-origin",
+			Usage: "Allowed Origin headers for HTTP transport (can specify multiple)",
+		},
+		&cli.DurationFlag{
+			Name:  "session-timeout",
+			Usage: "Session idle timeout for HTTP transport (e.g., 30m)",
+			Value: 0,
+		},
+		&cli.BoolFlag{
+			Name:  "stateless",
+			Usage: "Run HTTP transport in stateless mode (no session persistence)",
+		},
+		// Web UI flags
+		&cli.IntFlag{
+			Name:  "webui-port",
+			Usage: "Serve web UI on a separate port (0 = same port as HTTP transport, required for stdio)",
+			Value: -1,
+		},
+		&cli.StringFlag{
+			Name:  "webui-host",
+			Usage: "Web UI bind address (default: 127.0.0.1)",
+			Value: "",
+		},
+		&cli.BoolFlag{
+			Name:  "jaeger-api",
+			Usage: "Also serve the Jaeger HTTP query API under /jaeger/api on the web UI port, for Jaeger UI",
+		},
 	}
+}
 
-	// Apply CLI flag overrides (highest precedence)
+// applyConfigFlags applies the configFlags set on cmd over cfg, recording
+// them in sources.
+func applyConfigFlags(cmd *cli.Command, cfg *Config, sources *ConfigSources) {
 	if traceSize := cmd.Int("trace-buffer-size"); traceSize > 0 {
 		cfg.TraceBufferSize = traceSize
+		sources.set("trace_buffer_size", SourceFlag)
 	}
 	if logSize := cmd.Int("log-buffer-size"); logSize > 0 {
 		cfg.LogBufferSize = logSize
+		sources.set("log_buffer_size", SourceFlag)
 	}
 	if metricSize := cmd.Int("metric-buffer-size"); metricSize > 0 {
 		cfg.MetricBufferSize = metricSize
+		sources.set("metric_buffer_size", SourceFlag)
 	}
 	if host := cmd.String("otlp-host"); host != "" {
 		cfg.OTLPHost = host
+		sources.set("otlp_host", SourceFlag)
 	}
 	if port := cmd.Int("otlp-port"); port >= 0 { // 0 is valid (ephemeral), -1 means not set
 		cfg.OTLPPort = port
+		sources.set("otlp_port", SourceFlag)
+	}
+	if socket := cmd.String("otlp-socket"); socket != "" {
+		cfg.OTLPSocket = socket
+		sources.set("otlp_socket", SourceFlag)
 	}
 	if cmd.IsSet("verbose") { // Only override if explicitly set
 		cfg.Verbose = cmd.Bool("verbose")
+		sources.set("verbose", SourceFlag)
 	}
 
 	// Apply HTTP transport flag overrides
 	if transport := cmd.String("transport"); transport != "" {
 		cfg.Transport = transport
+		sources.set("transport", SourceFlag)
 	}
 	if httpHost := cmd.String("http-host"); httpHost != "" {
 		cfg.HTTPHost = httpHost
+		sources.set("http_host", SourceFlag)
 	}
 	if httpPort := cmd.Int("http-port"); httpPort > 0 {
 		cfg.HTTPPort = httpPort
+		sources.set("http_port", SourceFlag)
+	}
+	if httpSocket := cmd.String("http-socket"); httpSocket != "" {
+		cfg.HTTPSocket = httpSocket
+		sources.set("http_socket", SourceFlag)
 	}
 	if origins := cmd.StringSlice("allowed-origin"); len(origins) > 0 {
 		cfg.AllowedOrigins = origins
+		sources.set("allowed_origins", SourceFlag)
 	}
 	if timeout := cmd.Duration("session-timeout"); timeout > 0 {
 		cfg.SessionTimeout = timeout.String()
+		sources.set("session_timeout", SourceFlag)
 	}
 	if cmd.IsSet("stateless") {
 		cfg.Stateless = cmd.Bool("stateless")
+		sources.set("stateless", SourceFlag)
 	}
 
 	// Apply Web UI flag overrides
 	if webuiPort := cmd.Int("webui-port"); webuiPort >= 0 {
 		cfg.WebUIPort = webuiPort
+		sources.set("webui_port", SourceFlag)
 	}
 	if webuiHost := cmd.String("webui-host"); webuiHost != "" {
 		cfg.WebUIHost = webuiHost
+		sources.set("webui_host", SourceFlag)
+	}
+	if cmd.IsSet("jaeger-api") {
+		cfg.JaegerAPI = cmd.Bool("jaeger-api")
+		sources.set("jaeger_api", SourceFlag)
+	}
+}
+
+// runServe is the action handler for the serve command.
+// It wires together all components: storage, OTLP receiver, and MCP server.
+func runServe(cliCtx context.Context, cmd *cli.Command) error {
+	// Load effective config from files
+	configPath := cmd.String("config")
+	cfg, sources, err := LoadConfigWithSources(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	// Apply CLI flag overrides (highest precedence)
+	applyConfigFlags(cmd, cfg, sources)
+
 	if cfg.Verbose {
 		log.Println("🔧 Configuration:")
 		if configPath != "" {
@@ -200,7 +264,11 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 		log.Printf("  Trace buffer: %d spans\n", cfg.TraceBufferSize)
 		log.Printf("  Log buffer: %d records\n", cfg.LogBufferSize)
 		log.Printf("  Metric buffer: %d points\n", cfg.MetricBufferSize)
-		log.Printf("  OTLP bind: %s:%d\n", cfg.OTLPHost, cfg.OTLPPort)
+		if cfg.OTLPSocket != "" {
+			log.Printf("  OTLP bind: %s\n", otlpreceiver.UnixScheme+otlpreceiver.SocketPath(cfg.OTLPSocket))
+		} else {
+			log.Printf("  OTLP bind: %s:%d\n", cfg.OTLPHost, cfg.OTLPPort)
+		}
 		log.Println()
 	}
 
@@ -221,6 +289,22 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 	// Check if we're using otel-config mode (file sources only, no OTLP listener by default)
 	otelConfigPath := cmd.String("otel-config")
 	useOtelConfig := otelConfigPath != ""
+	var collector *otelconfig.Config
+	if useOtelConfig {
+		var err error
+		if collector, err = otelconfig.Load(otelConfigPath); err != nil {
+			return err
+		}
+		for _, warning := range collector.Warnings {
+			log.Printf("⚠️  %s: %s\n", otelConfigPath, warning)
+		}
+		for _, fe := range collector.SkippedExporters() {
+			log.Printf("⚠️  %s: exporter %q splits output by resource (group_by), not loaded\n", otelConfigPath, fe.Name)
+		}
+		for _, r := range collector.Receivers {
+			log.Printf("📡 Collector OTLP receiver %s (%s): %s [%s]\n", r.Name, r.Protocol, r.ClientEndpoint(), strings.Join(r.Signals, ", "))
+		}
+	}
 
 	// 2. Create context for lifecycle management
 	ctx, cancel := context.WithCancel(context.Background())
@@ -236,8 +320,9 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 		var err error
 		otlpServer, err = otlpreceiver.NewUnifiedServer(
 			otlpreceiver.Config{
-				Host: cfg.OTLPHost,
-				Port: cfg.OTLPPort,
+				Host:   cfg.OTLPHost,
+				Port:   cfg.OTLPPort,
+				Socket: cfg.OTLPSocket,
 			},
 			obsStorage,
 		)
@@ -251,8 +336,9 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 		var err error
 		otlpServer, err = otlpreceiver.NewUnifiedServer(
 			otlpreceiver.Config{
-				Host: cfg.OTLPHost,
-				Port: cfg.OTLPPort,
+				Host:   cfg.OTLPHost,
+				Port:   cfg.OTLPPort,
+				Socket: cfg.OTLPSocket,
 			},
 			obsStorage,
 		)
@@ -277,63 +363,127 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 			log.Printf("   OTEL_EXPORTER_OTLP_TRACES_ENDPOINT=%s\n", endpoint)
 			log.Printf("   OTEL_EXPORTER_OTLP_LOGS_ENDPOINT=%s\n", endpoint)
 			log.Printf("   OTEL_EXPORTER_OTLP_METRICS_ENDPOINT=%s\n", endpoint)
+			if otlpreceiver.IsSocketEndpoint(endpoint) {
+				log.Printf("   OTEL_EXPORTER_OTLP_INSECURE=true\n")
+			}
+		}
+	}
+
+	// Extra ports from the config file, as if added with add_otlp_port
+	for _, port := range cfg.OTLPExtraPorts {
+		if err := otlpServer.AddPort(ctx, port); err != nil {
+			return fmt.Errorf("failed to listen on otlp_extra_ports %d: %w", port, err)
 		}
+		log.Printf("🌐 OTLP gRPC receiver also listening on port %d\n", port)
 	}
 
 	// 4. Create MCP server with unified storage and receiver
 	mcpServer, err := mcpserver.NewServer(obsStorage, otlpServer, mcpserver.ServerOptions{
-		Verbose: cfg.Verbose,
+		Verbose:   cfg.Verbose,
+		Collector: collector,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
 	if cfg.Verbose {
-		log.Println("✅ MCP server created with 12 snapshot-first tools:")
+		log.Println("✅ MCP server created with 22 snapshot-first tools:")
 		log.Println("   - get_otlp_endpoint (get primary endpoint)")
-		log.Println("   - add_otlp_port (add listening ports on-demand)")
-		log.Println("   - remove_otlp_port (remove ports when done)")
+		log.Println("   - add_otlp_port / remove_otlp_port (TCP listeners on-demand)")
+		log.Println("   - add_otlp_socket / remove_otlp_socket (Unix socket listeners)")
+		log.Println("   - collector_config (otel-collector receivers and exporters)")
 		log.Println("   - create_snapshot (bookmark buffer positions)")
 		log.Println("   - query (multi-signal query with filters)")
+		log.Println("   - flame_graph (span time by service.span path)")
+		log.Println("   - exceptions (grouped by type and top frame)")
+		log.Println("   - export_chrome_trace (Perfetto / chrome://tracing)")
 		log.Println("   - get_snapshot_data (time-based query)")
-		log.Println("   - manage_snapshots (list/delete/clear)")
+		log.Println("   - export_snapshot (OTLP JSONL for analyze/replay)")
+		log.Println("   - manage_snapshots (list/delete/clear/pin/unpin)")
 		log.Println("   - get_stats (buffer health dashboard)")
+		log.Println("   - status / recent_activity (fast polling)")
+		log.Println("   - set_buffer_size (resize buffers)")
 		log.Println("   - clear_data (nuclear reset)")
 		log.Println("   - set_file_source (load from filesystem)")
 		log.Println("   - remove_file_source (stop watching)")
 		log.Println("   - list_file_sources (show active sources)")
 	}
 
-	// 4. Collect file source directories (loaded in background after server starts)
-	var fileSourceDirs []string
-	fileSources := cmd.StringSlice("file-source")
-	fileSourceDirs = append(fileSourceDirs, fileSources...)
-
-	if useOtelConfig {
-		dirs, err := ParseOtelConfig(otelConfigPath)
-		if err != nil {
-			return fmt.Errorf("failed to parse otel config %s: %w", otelConfigPath, err)
+	// 4. Collect file sources (loaded in background after server starts)
+	var fileSources []otelconfig.FileSource
+	for _, dir := range cmd.StringSlice("file-source") {
+		fileSources = append(fileSources, otelconfig.FileSource{Path: dir})
+	}
+	if collector != nil {
+		fileSources = append(fileSources, collector.FileSources()...)
+	}
+	// Sources from flags and the collector config stay put when the config
+	// file is reloaded; file_sources from the config file can come and go.
+	fixedSources := make(map[string]bool, len(fileSources))
+	for _, src := range fileSources {
+		fixedSources[src.Path] = true
+	}
+	for _, dir := range cfg.FileSources {
+		if !fixedSources[dir] {
+			fileSources = append(fileSources, otelconfig.FileSource{Path: dir})
 		}
-		fileSourceDirs = append(fileSourceDirs, dirs...)
+	}
+
+	// Optional time window applies to every file source
+	fileSourceOpts := mcpserver.FileSourceOptions{ActiveOnly: true}
+	now := time.Now()
+	if fileSourceOpts.Since, err = filereader.ParseTimeBound(cmd.String("file-source-since"), now); err != nil {
+		return fmt.Errorf("invalid --file-source-since: %w", err)
+	}
+	if fileSourceOpts.Until, err = filereader.ParseTimeBound(cmd.String("file-source-until"), now); err != nil {
+		return fmt.Errorf("invalid --file-source-until: %w", err)
 	}
 
 	// 5. Load file sources in background so MCP server accepts connections immediately
 	var fileLoadWg sync.WaitGroup
-	if len(fileSourceDirs) > 0 {
+	if len(fileSources) > 0 {
 		fileLoadWg.Add(1)
 		go func() {
 			defer fileLoadWg.Done()
-			for _, dir := range fileSourceDirs {
-				if err := mcpServer.AddFileSource(ctx, dir, true); err != nil {
-					log.Printf("⚠️  Failed to load file source %s: %v\n", dir, err)
+			for _, src := range fileSources {
+				opts := fileSourceOpts
+				opts.Signals = src.Signals
+				if err := mcpServer.AddFileSource(ctx, src.Path, opts); err != nil {
+					log.Printf("⚠️  Failed to load file source %s: %v\n", src.Path, err)
 				} else {
-					log.Printf("📁 Loaded file source: %s\n", dir)
+					log.Printf("📁 Loaded file source: %s\n", src.Path)
 				}
 			}
 			log.Println("✅ Background file loading complete")
 		}()
 	}
 
+	// Web UI: always with the HTTP transport; with stdio only on its own port
+	var webuiServer *webui.Server
+	if cfg.Transport == "http" || cfg.WebUIPort != 0 {
+		webuiServer = webui.New(obsStorage, cfg.AllowedOrigins)
+		if cfg.JaegerAPI {
+			webuiServer.EnableJaegerAPI()
+			log.Printf("🔎 Jaeger query API on the web UI port under %s/api\n", webui.JaegerPrefix)
+		}
+	}
+	origins := newOriginList(cfg.AllowedOrigins)
+
+	// Apply config file changes live, on edit or SIGHUP
+	reloader := &configReloader{
+		cmd:            cmd,
+		configPath:     configPath,
+		current:        cfg,
+		storage:        obsStorage,
+		otlpServer:     otlpServer,
+		mcpServer:      mcpServer,
+		webuiServer:    webuiServer,
+		origins:        origins,
+		fileSourceOpts: fileSourceOpts,
+		fixedSources:   fixedSources,
+	}
+	go reloader.Run(ctx, sources)
+
 	// 6. Setup graceful shutdown on SIGINT/SIGTERM
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -355,11 +505,15 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 	switch cfg.Transport {
 	case "http":
 		// Warn if binding to non-localhost address (security risk)
-		if cfg.HTTPHost != "127.0.0.1" && cfg.HTTPHost != "::1" && cfg.HTTPHost != "localhost" {
-			log.Printf("⚠️  WARNING: Binding to %s - this server has NO AUTHENTICATION!\n", cfg.HTTPHost)
-			log.Println("⚠️  Only bind to localhost (127.0.0.1) unless you understand the security implications.")
+		if cfg.HTTPSocket != "" {
+			log.Printf("🌐 MCP server starting on %s (path /mcp)\n", otlpreceiver.UnixScheme+otlpreceiver.SocketPath(cfg.HTTPSocket))
+		} else {
+			if cfg.HTTPHost != "127.0.0.1" && cfg.HTTPHost != "::1" && cfg.HTTPHost != "localhost" {
+				log.Printf("⚠️  WARNING: Binding to %s - this server has NO AUTHENTICATION!\n", cfg.HTTPHost)
+				log.Println("⚠️  Only bind to localhost (127.0.0.1) unless you understand the security implications.")
+			}
+			log.Printf("🌐 MCP server starting on http://%s:%d/mcp\n", cfg.HTTPHost, cfg.HTTPPort)
 		}
-		log.Printf("🌐 MCP server starting on http://%s:%d/mcp\n", cfg.HTTPHost, cfg.HTTPPort)
 		log.Println("💡 Use MCP tools to query traces and get the OTLP endpoint")
 		log.Println("💡 If programs need a specific port, use add_otlp_port to listen on it")
 
@@ -370,7 +524,6 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 		}
 
 		// Start web UI
-		webuiServer := webui.New(obsStorage, cfg.AllowedOrigins)
 		if cfg.WebUIPort != 0 {
 			// Separate port for web UI
 			webuiAddr := fmt.Sprintf("%s:%d", cfg.WebUIHost, cfg.WebUIPort)
@@ -380,12 +533,14 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 					log.Printf("⚠️  Web UI server error: %v\n", err)
 				}
 			}()
+		} else if cfg.HTTPSocket != "" {
+			log.Printf("🖥  Web UI: %s (path /ui/)\n", otlpreceiver.UnixScheme+otlpreceiver.SocketPath(cfg.HTTPSocket))
 		} else {
 			log.Printf("🖥  Web UI: http://%s:%d/ui/\n", cfg.HTTPHost, cfg.HTTPPort)
 		}
 		log.Println()
 
-		if err := runHTTPTransport(ctx, cfg, mcpServer, webuiServer, otlpErrChan); err != nil {
+		if err := runHTTPTransport(ctx, cfg, origins, mcpServer, webuiServer, otlpErrChan); err != nil {
 			return err
 		}
 
@@ -400,7 +555,6 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 				log.Printf("⚠️  WARNING: WebUI binding to %s - this server has NO AUTHENTICATION!\n", cfg.WebUIHost)
 				log.Println("⚠️  Only bind to localhost (127.0.0.1) unless you understand the security implications.")
 			}
-			webuiServer := webui.New(obsStorage, cfg.AllowedOrigins)
 			webuiAddr := fmt.Sprintf("%s:%d", cfg.WebUIHost, cfg.WebUIPort)
 			log.Printf("🖥  Web UI: http://%s/ui/\n", webuiAddr)
 			go func() {
@@ -433,7 +587,7 @@ func runServe(cliCtx context.Context, cmd *cli.Command) error {
 // runHTTPTransport starts the MCP server using Streamable HTTP transport.
 // It creates an HTTP server with origin validation and graceful shutdown.
 // If webuiServer is non-nil and WebUIPort == 0, web UI routes are registered on the same mux.
-func runHTTPTransport(ctx context.Context, cfg *Config, mcpServer *mcpserver.Server, webuiServer *webui.Server, otlpErrChan chan error) error {
+func runHTTPTransport(ctx context.Context, cfg *Config, origins *originList, mcpServer *mcpserver.Server, webuiServer *webui.Server, otlpErrChan chan error) error {
 	// Parse session timeout
 	sessionTimeout, err := time.ParseDuration(cfg.SessionTimeout)
 	if err != nil {
@@ -454,8 +608,8 @@ func runHTTPTransport(ctx context.Context, cfg *Config, mcpServer *mcpserver.Ser
 
 	// Wrap with origin validation middleware
 	mux := http.NewServeMux()
-	mux.Handle("/mcp", originValidationMiddleware(cfg.AllowedOrigins, handler))
-	mux.Handle("/mcp/", originValidationMiddleware(cfg.AllowedOrigins, handler))
+	mux.Handle("/mcp", originValidationMiddleware(origins, handler))
+	mux.Handle("/mcp/", originValidationMiddleware(origins, handler))
 
 	// Register web UI routes on the same mux when no separate port is configured
 	if webuiServer != nil && cfg.WebUIPort == 0 {
@@ -470,10 +624,25 @@ func runHTTPTransport(ctx context.Context, cfg *Config, mcpServer *mcpserver.Ser
 		IdleTimeout:       120 * time.Second,
 	}
 
+	// Bind a Unix socket up front so errors surface before serving
+	var socketListener net.Listener
+	if cfg.HTTPSocket != "" {
+		socketListener, err = otlpreceiver.ListenUnix(cfg.HTTPSocket)
+		if err != nil {
+			return fmt.Errorf("failed to listen on HTTP socket: %w", err)
+		}
+	}
+
 	// Start server in background
 	serverErr := make(chan error, 1)
 	go func() {
-		if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		var err error
+		if socketListener != nil {
+			err = server.Serve(socketListener)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
 			serverErr <- err
 		}
 		close(serverErr)
@@ -499,9 +668,35 @@ func runHTTPTransport(ctx context.Context, cfg *Config, mcpServer *mcpserver.Ser
 	}
 }
 
+// originList holds the allowed origin patterns, which a config reload
+// may replace while requests are being served.
+type originList struct {
+	mu       sync.RWMutex
+	patterns []string
+}
+
+// newOriginList creates an originList holding patterns.
+func newOriginList(patterns []string) *originList {
+	return &originList{patterns: patterns}
+}
+
+// Set replaces the allowed origin patterns.
+func (o *originList) Set(patterns []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.patterns = patterns
+}
+
+// Patterns returns the current allowed origin patterns.
+func (o *originList) Patterns() []string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.patterns
+}
+
 // originValidationMiddleware validates Origin headers and sets CORS headers.
 // It supports wildcard patterns like "http://localhost:*".
-func originValidationMiddleware(allowedOrigins []string, next http.Handler) http.Handler {
+func originValidationMiddleware(origins *originList, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
@@ -512,7 +707,7 @@ func originValidationMiddleware(allowedOrigins []string, next http.Handler) http
 		}
 
 		// Check against allowed origins
-		if !isOriginAllowed(origin, allowedOrigins) {
+		if !isOriginAllowed(origin, origins.Patterns()) {
 			http.Error(w, "Origin not allowed", http.StatusForbidden)
 			return
 		}
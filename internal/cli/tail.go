@@ -0,0 +1,225 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/urfave/cli/v3"
+)
+
+// TailCommand returns the CLI command definition for the 'tail' subcommand.
+// It streams new spans and logs from a running HTTP instance.
+func TailCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tail",
+		Usage:     "Stream new spans and logs from a running otlp-mcp",
+		ArgsUsage: "[query...]",
+		Description: `Follow telemetry arriving at a running 'otlp-mcp serve --transport http',
+like 'kubectl logs -f', over the same WebSocket the web UI uses. Recent
+history is printed first unless --no-history is set. Press Ctrl-C to stop.
+
+Arguments use the web UI query language and are evaluated server-side.
+
+Examples:
+  # Everything
+  otlp-mcp tail
+
+  # Errors from one service, logs only
+  otlp-mcp tail --signal logs service:checkout severity:ERROR
+
+  # New slow spans as JSON lines
+  otlp-mcp tail --no-history --signal spans --json 'duration>1s'`,
+		Flags: append(remoteFlags(),
+			&cli.StringFlag{Name: "service", Usage: "Only this service"},
+			&cli.StringFlag{Name: "severity", Usage: "Only logs of this severity"},
+			&cli.StringFlag{
+				Name:  "signal",
+				Usage: "What to print: spans, logs or all",
+				Value: "all",
+			},
+			&cli.BoolFlag{Name: "no-history", Usage: "Skip recent history and print only new telemetry"},
+			&cli.BoolFlag{Name: "json", Usage: "Print one JSON object per line"},
+		),
+		Action: runTail,
+	}
+}
+
+// tailFilter is the filter message the web UI WebSocket accepts.
+type tailFilter struct {
+	Service  string `json:"service,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+// tailUpdate is the subset of a web UI WebSocket update that tail prints.
+type tailUpdate struct {
+	Reset   bool          `json:"reset"`
+	Error   string        `json:"error"`
+	Dropped *tailCounters `json:"dropped"`
+	Traces  []tailSpan    `json:"traces"`
+	Logs    []tailLog     `json:"logs"`
+}
+
+type tailCounters struct {
+	Spans uint64 `json:"spans"`
+	Logs  uint64 `json:"logs"`
+}
+
+type tailSpan struct {
+	Time       string  `json:"time"`
+	TraceID    string  `json:"trace_id"`
+	SpanID     string  `json:"span_id"`
+	Service    string  `json:"service"`
+	SpanName   string  `json:"span_name"`
+	Kind       string  `json:"kind"`
+	DurationMs float64 `json:"duration_ms"`
+	Status     string  `json:"status"`
+}
+
+type tailLog struct {
+	Time     string `json:"time"`
+	TraceID  string `json:"trace_id,omitempty"`
+	Service  string `json:"service"`
+	Severity string `json:"severity"`
+	Body     string `json:"body"`
+}
+
+func runTail(ctx context.Context, cmd *cli.Command) error {
+	signalName := cmd.String("signal")
+	if signalName != "spans" && signalName != "logs" && signalName != "all" {
+		return fmt.Errorf("invalid --signal %q: want spans, logs or all", signalName)
+	}
+	query := strings.TrimSpace(strings.Join(cmd.Args().Slice(), " "))
+	parsed, err := storage.ParseQuery(query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+	parsed.Limit = 0 // meaningless for a stream, and ignored by the server
+	if reflect.DeepEqual(parsed, storage.QueryFilter{}) {
+		query = ""
+	}
+	filter := tailFilter{Service: cmd.String("service"), Severity: cmd.String("severity"), Query: query}
+
+	r, err := newRemote(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, _, err := websocket.Dial(ctx, r.endpoint("/ws"), &websocket.DialOptions{HTTPClient: r.client})
+	if err != nil {
+		return fmt.Errorf("cannot reach otlp-mcp at %s (is 'otlp-mcp serve --transport http' running?): %w", r.base, err)
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(16 << 20)
+
+	// The server streams unfiltered history as soon as we connect; with a
+	// filter, wait for the reset that restarts the stream with it applied.
+	filtered := filter != tailFilter{}
+	if filtered {
+		if err := wsjson.Write(ctx, conn, filter); err != nil {
+			return fmt.Errorf("failed to send filter: %w", err)
+		}
+	}
+
+	p := &tailPrinter{
+		out:   os.Stdout,
+		json:  cmd.Bool("json"),
+		spans: signalName != "logs",
+		logs:  signalName != "spans",
+	}
+	started := false
+	for {
+		var update tailUpdate
+		if err := wsjson.Read(ctx, conn, &update); err != nil {
+			if ctx.Err() != nil {
+				conn.Close(websocket.StatusNormalClosure, "")
+				return nil
+			}
+			if websocket.CloseStatus(err) == websocket.StatusNormalClosure || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("stream ended: %w", err)
+		}
+		if update.Error != "" {
+			return fmt.Errorf("server rejected filter: %s", update.Error)
+		}
+		if !started {
+			if filtered && !update.Reset {
+				continue
+			}
+			started = true
+			if cmd.Bool("no-history") {
+				continue
+			}
+		}
+		if err := p.print(update); err != nil {
+			return err
+		}
+	}
+}
+
+// tailPrinter writes the spans and logs of updates as text or JSON lines.
+type tailPrinter struct {
+	out   io.Writer
+	json  bool
+	spans bool
+	logs  bool
+}
+
+func (p *tailPrinter) print(update tailUpdate) error {
+	if p.spans {
+		for _, s := range update.Traces {
+			if err := p.line(s, func() string {
+				return fmt.Sprintf("%s SPAN %-5s %s %s %s trace=%s",
+					s.Time, displayStatus(s.Status), s.Service, s.SpanName, formatMillis(s.DurationMs), s.TraceID)
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	if p.logs {
+		for _, l := range update.Logs {
+			if err := p.line(l, func() string {
+				line := fmt.Sprintf("%s LOG  %-5s %s %s", l.Time, orDash(l.Severity), l.Service, oneLine(l.Body, 500))
+				if l.TraceID != "" {
+					line += " trace=" + l.TraceID
+				}
+				return line
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d := update.Dropped; d != nil && (d.Spans > 0 || d.Logs > 0) {
+		fmt.Fprintf(os.Stderr, "⚠️  server dropped %d spans and %d logs (sampled or over the per-update cap)\n", d.Spans, d.Logs)
+	}
+	return nil
+}
+
+func (p *tailPrinter) line(item any, text func() string) error {
+	if p.json {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.out, string(data))
+		return err
+	}
+	_, err := fmt.Fprintln(p.out, text())
+	return err
+}
@@ -1,23 +1,24 @@
-// Package filereader reads OTLP telemetry from JSONL files written by the
-// OpenTelemetry Collector's file exporter. It feeds data into the same ring
+// Package filereader reads OTLP telemetry from files written by the
+// OpenTelemetry Collector's file exporter (JSONL or length-delimited protobuf,
+// optionally gzip/zstd compressed). It feeds data into the same ring
 // buffers used by the TCP receiver, so all query/snapshot logic works unchanged.
 package filereader
 
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"google.golang.org/protobuf/encoding/protojson"
 
 	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
 	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
@@ -39,13 +40,17 @@ type StorageReceiver interface {
 	ReceiveMetrics(ctx context.Context, resourceMetrics []*metricspb.ResourceMetrics) error
 }
 
-// FileSource reads OTLP telemetry from a directory of JSONL files.
-// It watches for new data and feeds it into the storage ring buffers.
+// FileSource reads OTLP telemetry from a directory of file exporter output,
+// or from a single exporter file at an arbitrary path. It watches for new
+// data and feeds it into the storage ring buffers.
 type FileSource struct {
 	directory  string
+	file       string   // Single exporter file being followed, instead of the directory layout
+	signals    []string // Signals written to file; empty means detect each record's signal
 	storage    StorageReceiver
 	verbose    bool
-	activeOnly bool // Only load active files, skip rotated archives
+	activeOnly bool       // Only load active files, skip rotated archives
+	window     timeWindow // Optional since/until bounds on loaded records
 
 	// Storage capacities for tail-seek optimization
 	spanCapacity   int
@@ -66,17 +71,26 @@ type FileSource struct {
 
 // Config holds configuration for a FileSource.
 type Config struct {
-	Directory string // Base directory (e.g., /tank/otel)
+	Directory string // Base directory (e.g., /tank/otel), or a single exporter file
 	Verbose   bool   // Enable verbose logging
 
+	// Signals lists what a single exporter file (Directory naming a file)
+	// holds, as routed to it by the collector's pipelines. Empty means
+	// guess from the file name (traces.json) or detect each record's signal,
+	// which works for JSON but not protobuf records.
+	Signals []string
+
 	// ActiveOnly when true (default) only loads active files like traces.jsonl,
 	// skipping rotated archives like traces-2025-12-09T13-10-56.jsonl.
 	// This prevents loading gigabytes of historical data on startup.
 	ActiveOnly bool
 
-	// Optional: time cutoff - only load data newer than this
-	// Zero value means load everything (future feature)
+	// SinceTime and UntilTime bound the records that are loaded, including
+	// from the watch path. Zero values leave that side of the window open.
+	// When either is set, rotated archives overlapping the window are loaded
+	// in timestamp order regardless of ActiveOnly, and tail-seek is disabled.
 	SinceTime time.Time
+	UntilTime time.Time
 
 	// Storage capacities — used by tail-seek to avoid reading entire files
 	// when only the last N entries fit in ring buffers. Zero means read all.
@@ -87,19 +101,50 @@ type Config struct {
 
 // New creates a new FileSource that reads from the given directory.
 // The directory should contain subdirectories: traces/, logs/, metrics/
-// with .jsonl files inside them.
+// with .jsonl (or .pb, optionally .gz/.zst compressed) files inside them.
+//
+// Directory may instead name one exporter file with any name, such as
+// /var/log/otel/spans.json; it and its rotated backups are followed. The
+// file itself need not exist yet when Signals are given.
 func New(cfg Config, storage StorageReceiver) (*FileSource, error) {
 	if cfg.Directory == "" {
 		return nil, fmt.Errorf("directory is required")
 	}
+	for _, signal := range cfg.Signals {
+		if !isSignal(signal) {
+			return nil, fmt.Errorf("unknown signal %q (expected traces, logs or metrics)", signal)
+		}
+	}
 
-	// Verify directory exists
+	// Verify the directory, or the exporter file's directory, exists
+	var file string
 	info, err := os.Stat(cfg.Directory)
-	if err != nil {
+	switch {
+	case err == nil && info.IsDir():
+		if len(cfg.Signals) > 0 {
+			return nil, fmt.Errorf("signals can only be set for a single exporter file, and %s is a directory", cfg.Directory)
+		}
+	case err == nil:
+		file = cfg.Directory
+	case os.IsNotExist(err) && len(cfg.Signals) > 0:
+		if dirInfo, dirErr := os.Stat(filepath.Dir(cfg.Directory)); dirErr != nil || !dirInfo.IsDir() {
+			return nil, fmt.Errorf("cannot access directory of %s: %w", cfg.Directory, err)
+		}
+		file = cfg.Directory
+	default:
 		return nil, fmt.Errorf("cannot access directory %s: %w", cfg.Directory, err)
 	}
-	if !info.IsDir() {
-		return nil, fmt.Errorf("%s is not a directory", cfg.Directory)
+
+	signals := cfg.Signals
+	if file != "" && len(signals) == 0 {
+		if guess := fileStem(filepath.Base(file)); isSignal(guess) {
+			signals = []string{guess}
+		}
+	}
+
+	if !cfg.SinceTime.IsZero() && !cfg.UntilTime.IsZero() && cfg.UntilTime.Before(cfg.SinceTime) {
+		return nil, fmt.Errorf("until (%s) is before since (%s)",
+			cfg.UntilTime.Format(time.RFC3339), cfg.SinceTime.Format(time.RFC3339))
 	}
 
 	watcher, err := fsnotify.NewWatcher()
@@ -111,9 +156,12 @@ func New(cfg Config, storage StorageReceiver) (*FileSource, error) {
 
 	return &FileSource{
 		directory:      cfg.Directory,
+		file:           file,
+		signals:        signals,
 		storage:        storage,
 		verbose:        cfg.Verbose,
 		activeOnly:     cfg.ActiveOnly,
+		window:         timeWindow{since: cfg.SinceTime, until: cfg.UntilTime},
 		spanCapacity:   cfg.SpanCapacity,
 		logCapacity:    cfg.LogCapacity,
 		metricCapacity: cfg.MetricCapacity,
@@ -131,9 +179,18 @@ func (fs *FileSource) Start(ctx context.Context) error {
 		log.Printf("📁 FileSource: starting with directory %s\n", fs.directory)
 	}
 
-	// Set up watches on signal subdirectories
+	// Set up watches on signal subdirectories, or on the exporter file's
+	// directory so rotation and re-creation are seen too
+	if fs.file != "" {
+		if err := fs.watcher.Add(filepath.Dir(fs.file)); err != nil {
+			log.Printf("⚠️  FileSource: could not watch %s: %v\n", filepath.Dir(fs.file), err)
+		}
+	}
 	signals := []string{"traces", "logs", "metrics"}
 	for _, signal := range signals {
+		if fs.file != "" {
+			break
+		}
 		dir := filepath.Join(fs.directory, signal)
 		if _, err := os.Stat(dir); err == nil {
 			if err := fs.watcher.Add(dir); err != nil {
@@ -168,8 +225,12 @@ func (fs *FileSource) Directory() string {
 	return fs.directory
 }
 
-// loadInitialData reads all existing JSONL files into storage.
+// loadInitialData reads all existing telemetry files into storage.
 func (fs *FileSource) loadInitialData(ctx context.Context) error {
+	if fs.file != "" {
+		return fs.loadInitialExporterFiles(ctx)
+	}
+
 	signals := []struct {
 		name     string
 		capacity int
@@ -182,7 +243,7 @@ func (fs *FileSource) loadInitialData(ctx context.Context) error {
 
 	for _, sig := range signals {
 		dir := filepath.Join(fs.directory, sig.name)
-		files, err := fs.findJSONLFiles(dir)
+		files, err := fs.findDataFiles(dir)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue // Signal directory doesn't exist, skip
@@ -190,8 +251,14 @@ func (fs *FileSource) loadInitialData(ctx context.Context) error {
 			return err
 		}
 
+		// Tail-seek would skip older records inside the window
+		capacity := sig.capacity
+		if fs.window.active() {
+			capacity = 0
+		}
+
 		for _, file := range files {
-			count, err := sig.loader(ctx, file, sig.capacity)
+			count, err := sig.loader(ctx, file, capacity)
 			if err != nil {
 				log.Printf("⚠️  FileSource: error loading %s: %v\n", file, err)
 				continue
@@ -205,23 +272,119 @@ func (fs *FileSource) loadInitialData(ctx context.Context) error {
 	return nil
 }
 
-// findJSONLFiles returns .jsonl files in a directory, sorted by modification time.
-// When activeOnly is true, only returns active files (e.g., traces.jsonl) and
-// skips rotated archives (e.g., traces-2025-12-09T13-10-56.jsonl).
-func (fs *FileSource) findJSONLFiles(dir string) ([]string, error) {
-	entries, err := os.ReadDir(dir)
+// loadInitialExporterFiles reads a single exporter file and its rotated
+// backups into storage.
+func (fs *FileSource) loadInitialExporterFiles(ctx context.Context) error {
+	files, err := fs.findExporterFiles()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	// Tail-seek would skip older records inside the window
+	capacity := fs.exporterCapacity()
+	if fs.window.active() {
+		capacity = 0
 	}
 
+	for _, file := range files {
+		count, err := fs.loadExporterFile(ctx, file, capacity)
+		if err != nil {
+			log.Printf("⚠️  FileSource: error loading %s: %v\n", file, err)
+			continue
+		}
+		if fs.verbose && count > 0 {
+			log.Printf("📁 FileSource: loaded %d records from %s\n", count, filepath.Base(file))
+		}
+	}
+	return nil
+}
+
+// findDataFiles returns telemetry files (.jsonl, .pb, optionally .gz/.zst compressed)
+// in a directory, oldest first: rotated archives by the timestamp in their name,
+// other files by modification time.
+// When activeOnly is true, only returns active files (e.g., traces.jsonl) and
+// skips rotated archives (e.g., traces-2025-12-09T13-10-56.jsonl.gz).
+// When a time window is set, archives are selected by the window instead.
+func (fs *FileSource) findDataFiles(dir string) ([]string, error) {
 	// Determine the expected active filename from the directory name
-	// e.g., /tank/otel/traces -> traces.jsonl
+	// e.g., /tank/otel/traces -> traces.jsonl (or traces.pb)
 	signal := filepath.Base(dir)
-	activeFileName := signal + ".jsonl"
+
+	return fs.findFiles(dir, func(name string) (bool, time.Time, bool) {
+		if !isDataFile(name) {
+			return false, time.Time{}, false
+		}
+
+		// When activeOnly, skip archived/rotated files
+		// Active files: traces.jsonl, logs.jsonl, metrics.jsonl
+		// Archived files: traces-2025-12-09T13-10-56.jsonl (contain hyphen after signal name)
+		if fs.activeOnly && !fs.window.active() && !isActiveFile(name, signal) {
+			if fs.verbose {
+				log.Printf("📁 FileSource: skipping archived file %s (activeOnly mode)\n", name)
+			}
+			return false, time.Time{}, false
+		}
+
+		ts, archive := parseArchiveTime(name, signal)
+		return true, ts, archive
+	})
+}
+
+// findExporterFiles returns the followed exporter file and its rotated
+// backups (spans-2025-12-09T13-10-56.000.json.gz for spans.json), with the
+// same ordering and archive selection as findDataFiles.
+func (fs *FileSource) findExporterFiles() ([]string, error) {
+	active := filepath.Base(fs.file)
+	files, err := fs.findFiles(filepath.Dir(fs.file), func(name string) (bool, time.Time, bool) {
+		if name == active {
+			return true, time.Time{}, false
+		}
+		ts, ok := parseRotatedTime(name, active)
+		if !ok {
+			return false, time.Time{}, false
+		}
+		if fs.activeOnly && !fs.window.active() {
+			if fs.verbose {
+				log.Printf("📁 FileSource: skipping archived file %s (activeOnly mode)\n", name)
+			}
+			return false, time.Time{}, false
+		}
+		return true, ts, true
+	})
+	if os.IsNotExist(err) {
+		return nil, nil // Nothing written yet
+	}
+	return files, err
+}
+
+// isExporterFile reports whether path is the followed exporter file or one
+// of its rotated backups.
+func (fs *FileSource) isExporterFile(path string) bool {
+	if filepath.Dir(path) != filepath.Dir(fs.file) {
+		return false
+	}
+	name, active := filepath.Base(path), filepath.Base(fs.file)
+	if name == active {
+		return true
+	}
+	_, ok := parseRotatedTime(name, active)
+	return ok
+}
+
+// findFiles returns the files in dir that classify accepts, oldest first:
+// rotated archives by their rotation time, other files by modification
+// time. classify reports whether to load a file and, for an archive, when
+// it was rotated. Archives entirely outside the time window are skipped.
+func (fs *FileSource) findFiles(dir string, classify func(name string) (load bool, rotated time.Time, archive bool)) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
 
 	type fileInfo struct {
 		path    string
-		modTime time.Time
+		modTime time.Time // archive timestamp when present, else modification time
+		archive bool
 	}
 	var files []fileInfo
 
@@ -230,17 +393,8 @@ func (fs *FileSource) findJSONLFiles(dir string) ([]string, error) {
 			continue
 		}
 		name := entry.Name()
-		if !strings.HasSuffix(name, ".jsonl") && !strings.Contains(name, ".jsonl.") {
-			continue
-		}
-
-		// When activeOnly, skip archived/rotated files
-		// Active files: traces.jsonl, logs.jsonl, metrics.jsonl
-		// Archived files: traces-2025-12-09T13-10-56.jsonl (contain hyphen after signal name)
-		if fs.activeOnly && name != activeFileName {
-			if fs.verbose {
-				log.Printf("📁 FileSource: skipping archived file %s (activeOnly mode)\n", name)
-			}
+		load, rotated, archive := classify(name)
+		if !load {
 			continue
 		}
 
@@ -249,67 +403,168 @@ func (fs *FileSource) findJSONLFiles(dir string) ([]string, error) {
 		if err != nil {
 			continue
 		}
-		files = append(files, fileInfo{path: path, modTime: info.ModTime()})
+		f := fileInfo{path: path, modTime: info.ModTime()}
+		if archive {
+			f.modTime, f.archive = rotated, true
+		}
+		files = append(files, f)
 	}
 
-	// Sort by modification time (oldest first) so we load data in chronological order
-	sort.Slice(files, func(i, j int) bool {
+	// Sort oldest first so we load data in chronological order
+	sort.SliceStable(files, func(i, j int) bool {
 		return files[i].modTime.Before(files[j].modTime)
 	})
 
-	result := make([]string, len(files))
-	for i, f := range files {
-		result[i] = f.path
+	result := make([]string, 0, len(files))
+	var prevRotation time.Time
+	for _, f := range files {
+		// An archive holds records written between the previous rotation and
+		// its own, so archives entirely outside the window can be skipped.
+		if f.archive && fs.window.active() {
+			skip := (!fs.window.since.IsZero() && f.modTime.Before(fs.window.since)) ||
+				(!fs.window.until.IsZero() && !prevRotation.IsZero() && prevRotation.After(fs.window.until))
+			prevRotation = f.modTime
+			if skip {
+				if fs.verbose {
+					log.Printf("📁 FileSource: skipping archive %s (outside time window)\n", filepath.Base(f.path))
+				}
+				continue
+			}
+		}
+		result = append(result, f.path)
 	}
 	return result, nil
 }
 
-// loadTraceFile reads a JSONL file containing traces and feeds them to storage.
+// loadTraceFile reads a file containing traces and feeds them to storage.
 func (fs *FileSource) loadTraceFile(ctx context.Context, path string, capacity int) (int, error) {
-	return fs.processFile(ctx, path, capacity, func(line []byte) error {
+	return fs.processFile(ctx, path, capacity, fs.recordHandler(ctx, "traces"))
+}
+
+// loadLogFile reads a file containing logs and feeds them to storage.
+func (fs *FileSource) loadLogFile(ctx context.Context, path string, capacity int) (int, error) {
+	return fs.processFile(ctx, path, capacity, fs.recordHandler(ctx, "logs"))
+}
+
+// loadMetricFile reads a file containing metrics and feeds them to storage.
+func (fs *FileSource) loadMetricFile(ctx context.Context, path string, capacity int) (int, error) {
+	return fs.processFile(ctx, path, capacity, fs.recordHandler(ctx, "metrics"))
+}
+
+// recordHandler returns a function that decodes one record of a signal,
+// applies the time window and feeds it to storage.
+func (fs *FileSource) recordHandler(ctx context.Context, signal string) func([]byte) error {
+	switch signal {
+	case "logs":
+		return func(record []byte) error {
+			var data logspb.LogsData
+			if err := unmarshalPayload(record, &data); err != nil {
+				return fmt.Errorf("parse log data: %w", err)
+			}
+			if fs.window.active() {
+				data.ResourceLogs = fs.window.filterLogs(data.ResourceLogs)
+			}
+			if len(data.ResourceLogs) > 0 {
+				return fs.storage.ReceiveLogs(ctx, data.ResourceLogs)
+			}
+			return nil
+		}
+	case "metrics":
+		return func(record []byte) error {
+			var data metricspb.MetricsData
+			if err := unmarshalPayload(record, &data); err != nil {
+				return fmt.Errorf("parse metric data: %w", err)
+			}
+			if fs.window.active() {
+				data.ResourceMetrics = fs.window.filterMetrics(data.ResourceMetrics)
+			}
+			if len(data.ResourceMetrics) > 0 {
+				return fs.storage.ReceiveMetrics(ctx, data.ResourceMetrics)
+			}
+			return nil
+		}
+	}
+	return func(record []byte) error {
 		var data tracepb.TracesData
-		if err := protojson.Unmarshal(line, &data); err != nil {
-			return fmt.Errorf("parse trace JSON: %w", err)
+		if err := unmarshalPayload(record, &data); err != nil {
+			return fmt.Errorf("parse trace data: %w", err)
+		}
+		if fs.window.active() {
+			data.ResourceSpans = fs.window.filterSpans(data.ResourceSpans)
 		}
 		if len(data.ResourceSpans) > 0 {
 			return fs.storage.ReceiveSpans(ctx, data.ResourceSpans)
 		}
 		return nil
-	})
+	}
 }
 
-// loadLogFile reads a JSONL file containing logs and feeds them to storage.
-func (fs *FileSource) loadLogFile(ctx context.Context, path string, capacity int) (int, error) {
-	return fs.processFile(ctx, path, capacity, func(line []byte) error {
-		var data logspb.LogsData
-		if err := protojson.Unmarshal(line, &data); err != nil {
-			return fmt.Errorf("parse log JSON: %w", err)
+// loadExporterFile reads a followed exporter file. A file holding one
+// signal uses that signal's loader; otherwise each record's signal is
+// detected from its JSON.
+func (fs *FileSource) loadExporterFile(ctx context.Context, path string, capacity int) (int, error) {
+	if len(fs.signals) == 1 {
+		return fs.loaderFor(fs.signals[0])(ctx, path, capacity)
+	}
+
+	handlers := map[string]func([]byte) error{}
+	for _, signal := range []string{"traces", "logs", "metrics"} {
+		if len(fs.signals) > 0 && !slices.Contains(fs.signals, signal) {
+			continue
 		}
-		if len(data.ResourceLogs) > 0 {
-			return fs.storage.ReceiveLogs(ctx, data.ResourceLogs)
+		handlers[signal] = fs.recordHandler(ctx, signal)
+	}
+	return fs.processFile(ctx, path, capacity, func(record []byte) error {
+		signal := recordSignal(record)
+		handler, ok := handlers[signal]
+		if !ok {
+			if signal == "" {
+				return fmt.Errorf("cannot tell the signal of a record; protobuf files need one signal each")
+			}
+			return fmt.Errorf("unexpected %s record", signal)
 		}
-		return nil
+		return handler(record)
 	})
 }
 
-// loadMetricFile reads a JSONL file containing metrics and feeds them to storage.
-func (fs *FileSource) loadMetricFile(ctx context.Context, path string, capacity int) (int, error) {
-	return fs.processFile(ctx, path, capacity, func(line []byte) error {
-		var data metricspb.MetricsData
-		if err := protojson.Unmarshal(line, &data); err != nil {
-			return fmt.Errorf("parse metric JSON: %w", err)
-		}
-		if len(data.ResourceMetrics) > 0 {
-			return fs.storage.ReceiveMetrics(ctx, data.ResourceMetrics)
+// exporterCapacity is the tail-seek capacity for a followed exporter file:
+// the combined capacity of the signals it may hold.
+func (fs *FileSource) exporterCapacity() int {
+	signals := fs.signals
+	if len(signals) == 0 {
+		signals = []string{"traces", "logs", "metrics"}
+	}
+	total := 0
+	for _, signal := range signals {
+		switch signal {
+		case "traces":
+			total += fs.spanCapacity
+		case "logs":
+			total += fs.logCapacity
+		case "metrics":
+			total += fs.metricCapacity
 		}
-		return nil
-	})
+	}
+	return total
 }
 
-// processFile reads a JSONL file from the last known offset, calling handler for each line.
+// loaderFor returns the file loader for a signal.
+func (fs *FileSource) loaderFor(signal string) func(context.Context, string, int) (int, error) {
+	switch signal {
+	case "logs":
+		return fs.loadLogFile
+	case "metrics":
+		return fs.loadMetricFile
+	}
+	return fs.loadTraceFile
+}
+
+// processFile reads a telemetry file from the last known offset, calling handler for each
+// record. Plain JSONL, length-delimited protobuf and gzip/zstd-compressed variants
+// of both are supported; offsets are positions in the decompressed stream.
 // When capacity > 0 and this is the first read (offset == 0), it tail-seeks to only read
-// approximately the last `capacity` lines, avoiding parsing data that would be evicted
-// from ring buffers anyway. Returns the number of lines processed.
+// approximately the last `capacity` records, avoiding parsing data that would be evicted
+// from ring buffers anyway. Returns the number of records processed.
 func (fs *FileSource) processFile(ctx context.Context, path string, capacity int, handler func([]byte) error) (int, error) {
 	fs.mu.Lock()
 	offset := fs.fileOffsets[path]
@@ -321,61 +576,152 @@ func (fs *FileSource) processFile(ctx context.Context, path string, capacity int
 	}
 	defer file.Close()
 
-	// Tail-seek optimization: on first read with known capacity, skip to the
-	// tail of the file. We only need ~capacity lines since that's all the
-	// ring buffer can hold.
-	if offset == 0 && capacity > 0 {
-		offset = fs.estimateTailOffset(file, capacity)
-	}
+	var reader io.Reader = file
+	var framed bool
+	var skipRecords int
 
-	// Seek to read position
-	if offset > 0 {
-		if _, err := file.Seek(offset, io.SeekStart); err != nil {
-			// File might have been rotated, start from beginning
-			if _, err := file.Seek(0, io.SeekStart); err != nil {
-				return 0, err
+	if comp := fileCompression(path); comp != compressionNone {
+		// Compressed streams can't seek: skip already-read bytes by decompressing,
+		// and on first read skip whole records instead of parsing them.
+		if offset == 0 && capacity > 0 {
+			skipRecords = fs.countCompressedRecords(path, comp) - capacity
+		}
+
+		decompressed, closeFn, err := openDecompressor(comp, file)
+		if err != nil {
+			return 0, err
+		}
+		defer closeFn()
+
+		br := bufio.NewReader(decompressed)
+		first, err := br.Peek(1)
+		if err != nil {
+			return 0, nil // Empty or still being written
+		}
+		framed = isFramed(first[0])
+		if offset > 0 {
+			if _, err := io.CopyN(io.Discard, br, offset); err != nil {
+				return 0, nil // Nothing new since the last read
 			}
 		}
-	}
+		reader = br
+	} else {
+		framed, err = sniffFramed(file)
+		if err != nil {
+			return 0, nil // Empty file, nothing to read yet
+		}
 
-	scanner := bufio.NewScanner(file)
-	buf := make([]byte, 0, jsonlBufferInitial)
-	scanner.Buffer(buf, jsonlBufferMax)
+		// Tail-seek optimization: on first read with known capacity, skip to the
+		// tail of the file. We only need ~capacity records since that's all the
+		// ring buffer can hold.
+		if offset == 0 && capacity > 0 {
+			if framed {
+				offset = frameTailOffset(file, capacity)
+			} else {
+				offset = fs.estimateTailOffset(file, capacity)
+			}
+		}
+
+		// A file shorter than our offset was truncated or replaced; start over
+		if info, err := file.Stat(); err == nil && offset > info.Size() {
+			offset = 0
+		}
+
+		// Seek to read position
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
 
+	records := newRecordReader(reader, framed)
 	count := 0
-	for scanner.Scan() {
+	var readErr error
+	for {
 		select {
 		case <-ctx.Done():
+			fs.setOffset(path, offset+records.Consumed())
 			return count, ctx.Err()
 		default:
 		}
 
-		line := scanner.Bytes()
-		if len(line) == 0 {
+		record, err := records.Next()
+		if errors.Is(err, errIncomplete) {
+			// A trailing JSON line without newline is accepted once it parses
+			if len(record) > 0 && handler(record) == nil {
+				records.Accept()
+				count++
+			}
+			break
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			readErr = fmt.Errorf("reading %s: %w", path, err)
+			break
+		}
+		if len(record) == 0 {
+			continue
+		}
+		if skipRecords > 0 {
+			skipRecords--
 			continue
 		}
 
-		if err := handler(line); err != nil {
-			// Log but continue - don't let one bad line stop everything
+		if err := handler(record); err != nil {
+			// Log but continue - don't let one bad record stop everything
 			if fs.verbose {
-				log.Printf("⚠️  FileSource: error processing line in %s: %v\n", filepath.Base(path), err)
+				log.Printf("⚠️  FileSource: error processing record in %s: %v\n", filepath.Base(path), err)
 			}
 			continue
 		}
 		count++
 	}
 
-	if err := scanner.Err(); err != nil {
-		return count, fmt.Errorf("reading %s: %w", path, err)
-	}
+	fs.setOffset(path, offset+records.Consumed())
+	return count, readErr
+}
 
-	// Update offset
-	newOffset, _ := file.Seek(0, io.SeekCurrent)
+// setOffset records how far into a file's (decompressed) stream we have read.
+func (fs *FileSource) setOffset(path string, offset int64) {
 	fs.mu.Lock()
-	fs.fileOffsets[path] = newOffset
+	fs.fileOffsets[path] = offset
 	fs.mu.Unlock()
+}
 
-	return count, nil
+// countCompressedRecords decompresses a file once to count its records,
+// which is far cheaper than parsing records that would be evicted anyway.
+func (fs *FileSource) countCompressedRecords(path string, comp compression) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	decompressed, closeFn, err := openDecompressor(comp, file)
+	if err != nil {
+		return 0
+	}
+	defer closeFn()
+
+	br := bufio.NewReader(decompressed)
+	first, err := br.Peek(1)
+	if err != nil {
+		return 0
+	}
+
+	records := newRecordReader(br, isFramed(first[0]))
+	count := 0
+	for {
+		record, err := records.Next()
+		if err != nil {
+			break
+		}
+		if len(record) > 0 {
+			count++
+		}
+	}
+	return count
 }
 
 // estimateTailOffset calculates a byte offset to seek to for reading approximately
@@ -470,22 +816,23 @@ func (fs *FileSource) watchLoop() {
 
 			// Determine signal type from path
 			path := event.Name
-			if !strings.HasSuffix(path, ".jsonl") && !strings.Contains(path, ".jsonl.") {
-				continue
-			}
-
-			dir := filepath.Dir(path)
-			signal := filepath.Base(dir)
-
 			var count int
 			var err error
-			switch signal {
-			case "traces":
-				count, err = fs.loadTraceFile(fs.ctx, path, 0)
-			case "logs":
-				count, err = fs.loadLogFile(fs.ctx, path, 0)
-			case "metrics":
-				count, err = fs.loadMetricFile(fs.ctx, path, 0)
+			signal := "records"
+			if fs.file != "" {
+				if !fs.isExporterFile(path) {
+					continue
+				}
+				count, err = fs.loadExporterFile(fs.ctx, path, 0)
+			} else {
+				if !isDataFile(path) {
+					continue
+				}
+				signal = filepath.Base(filepath.Dir(path))
+				if !isSignal(signal) {
+					continue
+				}
+				count, err = fs.loaderFor(signal)(fs.ctx, path, 0)
 			}
 
 			if err != nil {
@@ -508,6 +855,8 @@ type Stats struct {
 	Directory    string   `json:"directory"`
 	WatchedDirs  []string `json:"watched_dirs"`
 	FilesTracked int      `json:"files_tracked"`
+	Since        string   `json:"since,omitempty"`
+	Until        string   `json:"until,omitempty"`
 }
 
 // Stats returns current statistics.
@@ -516,9 +865,16 @@ func (fs *FileSource) Stats() Stats {
 	filesTracked := len(fs.fileOffsets)
 	fs.mu.Unlock()
 
-	return Stats{
+	stats := Stats{
 		Directory:    fs.directory,
 		WatchedDirs:  fs.watcher.WatchList(),
 		FilesTracked: filesTracked,
 	}
+	if !fs.window.since.IsZero() {
+		stats.Since = fs.window.since.Format(time.RFC3339)
+	}
+	if !fs.window.until.IsZero() {
+		stats.Until = fs.window.until.Format(time.RFC3339)
+	}
+	return stats
 }
@@ -0,0 +1,341 @@
+package filereader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The collector's file exporter writes one of two layouts:
+//
+//   - format: json without compression - one protojson document per line (JSONL)
+//   - format: proto, or any format with compression: zstd - each message is
+//     prefixed with a 4-byte big-endian length; with compression the message
+//     itself is a zstd frame wrapping the JSON or protobuf payload
+//
+// Rotated archives may additionally be gzip- or zstd-compressed as a whole
+// (e.g. traces-2025-12-09T13-10-56.jsonl.gz). Both layers are handled here.
+
+const (
+	// protoFrameMax bounds a single length-prefixed message. Larger prefixes
+	// mean the file is not length-delimited or is corrupt.
+	protoFrameMax = 64 * 1024 * 1024
+)
+
+// dataExtensions are the recognized telemetry file extensions, before any
+// whole-file compression suffix.
+var dataExtensions = []string{".jsonl", ".binpb", ".pb", ".proto"}
+
+// zstdMagic starts every zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// zstdDecoder decodes per-message zstd frames. DecodeAll is safe for concurrent use.
+var zstdDecoder, _ = zstd.NewReader(nil)
+
+// errIncomplete reports a record that is still being written. The caller
+// stops reading and retries from the last complete record on the next event.
+var errIncomplete = errors.New("incomplete record")
+
+type compression int
+
+const (
+	compressionNone compression = iota
+	compressionGzip
+	compressionZstd
+)
+
+// fileCompression returns the whole-file compression implied by a file name.
+func fileCompression(name string) compression {
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		return compressionGzip
+	case strings.HasSuffix(name, ".zst"), strings.HasSuffix(name, ".zstd"):
+		return compressionZstd
+	}
+	return compressionNone
+}
+
+// trimCompressionExt strips a whole-file compression suffix from a file name.
+func trimCompressionExt(name string) string {
+	for _, ext := range []string{".gz", ".zst", ".zstd"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
+
+// isDataFile reports whether a file name looks like collector file exporter
+// output: traces.jsonl, traces.pb, traces.jsonl.1, traces-<ts>.jsonl.gz, etc.
+func isDataFile(name string) bool {
+	base := trimCompressionExt(filepath.Base(name))
+	for _, ext := range dataExtensions {
+		if strings.HasSuffix(base, ext) || strings.Contains(base, ext+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isActiveFile reports whether name is the active (non-rotated) file for a
+// signal, e.g. traces.jsonl or traces.pb for the traces directory.
+func isActiveFile(name, signal string) bool {
+	base := trimCompressionExt(name)
+	for _, ext := range dataExtensions {
+		if base == signal+ext {
+			return true
+		}
+	}
+	return false
+}
+
+// isSignal reports whether name is a signal: traces, logs or metrics.
+func isSignal(name string) bool {
+	return name == "traces" || name == "logs" || name == "metrics"
+}
+
+// fileStem strips compression and data extensions from a file name, so
+// traces.jsonl.gz and traces.json are both "traces".
+func fileStem(name string) string {
+	name = trimCompressionExt(name)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// recordSignal detects the signal of a JSON record from its top-level key,
+// for exporter files that receive several signals. Binary protobuf records
+// carry no such marker and return "".
+func recordSignal(payload []byte) string {
+	if bytes.HasPrefix(payload, zstdMagic) {
+		decoded, err := zstdDecoder.DecodeAll(payload, nil)
+		if err != nil {
+			return ""
+		}
+		payload = decoded
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return ""
+	}
+	key, err := dec.Token()
+	if err != nil {
+		return ""
+	}
+	switch key {
+	case "resourceSpans", "resource_spans":
+		return "traces"
+	case "resourceLogs", "resource_logs":
+		return "logs"
+	case "resourceMetrics", "resource_metrics":
+		return "metrics"
+	}
+	return ""
+}
+
+// openDecompressor wraps r with the decompressor for c.
+// The returned close function releases decoder resources.
+func openDecompressor(c compression, r io.Reader) (io.Reader, func(), error) {
+	switch c {
+	case compressionGzip:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open gzip stream: %w", err)
+		}
+		return gz, func() { gz.Close() }, nil
+	case compressionZstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, nil, fmt.Errorf("open zstd stream: %w", err)
+		}
+		return zr, zr.Close, nil
+	}
+	return r, func() {}, nil
+}
+
+// isFramed reports whether a stream starting with first is length-delimited
+// rather than JSONL. JSON lines start with '{'; a length prefix starting
+// with that byte would describe a message of more than 2GB.
+func isFramed(first byte) bool {
+	switch first {
+	case '{', ' ', '\t', '\r', '\n':
+		return false
+	}
+	return true
+}
+
+// sniffFramed reads the first byte of a plain file to pick its layout.
+// Returns io.EOF for an empty file.
+func sniffFramed(file *os.File) (bool, error) {
+	first := make([]byte, 1)
+	if _, err := file.ReadAt(first, 0); err != nil {
+		return false, err
+	}
+	return isFramed(first[0]), nil
+}
+
+// recordReader yields raw records (JSON lines or length-prefixed messages)
+// and tracks how many bytes of complete records have been consumed.
+type recordReader interface {
+	// Next returns the next record, io.EOF at a clean end, or errIncomplete
+	// when the stream ends mid-record. A record returned with errIncomplete
+	// may still be usable; Accept marks it consumed.
+	Next() ([]byte, error)
+	Accept()
+	Consumed() int64
+}
+
+func newRecordReader(r io.Reader, framed bool) recordReader {
+	br := bufio.NewReaderSize(r, 64*1024)
+	if framed {
+		return &frameReader{r: br}
+	}
+	return &lineReader{r: br}
+}
+
+// lineReader reads newline-delimited records.
+type lineReader struct {
+	r        *bufio.Reader
+	consumed int64
+	buf      []byte
+}
+
+func (lr *lineReader) Next() ([]byte, error) {
+	lr.buf = lr.buf[:0]
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		lr.buf = append(lr.buf, chunk...)
+		if len(lr.buf) > jsonlBufferMax {
+			return nil, fmt.Errorf("line exceeds %d bytes", jsonlBufferMax)
+		}
+		switch {
+		case err == nil:
+			lr.consumed += int64(len(lr.buf))
+			return bytes.TrimRight(lr.buf, "\r\n"), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF):
+			if len(lr.buf) == 0 {
+				return nil, io.EOF
+			}
+			// A final line without a newline may still be mid-write; hand it
+			// out but only count it consumed once the caller accepts it.
+			return lr.buf, errIncomplete
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, errIncomplete
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Accept marks a trailing line returned with errIncomplete as consumed.
+func (lr *lineReader) Accept() { lr.consumed += int64(len(lr.buf)) }
+
+func (lr *lineReader) Consumed() int64 { return lr.consumed }
+
+// frameReader reads 4-byte big-endian length-prefixed records.
+type frameReader struct {
+	r        *bufio.Reader
+	consumed int64
+	buf      []byte
+}
+
+func (fr *frameReader) Next() ([]byte, error) {
+	var prefix [4]byte
+	n, err := io.ReadFull(fr.r, prefix[:])
+	if err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errIncomplete
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(prefix[:])
+	if size > protoFrameMax {
+		return nil, fmt.Errorf("message length %d exceeds %d bytes", size, protoFrameMax)
+	}
+	if cap(fr.buf) < int(size) {
+		fr.buf = make([]byte, size)
+	}
+	fr.buf = fr.buf[:size]
+	if _, err := io.ReadFull(fr.r, fr.buf); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errIncomplete
+		}
+		return nil, err
+	}
+
+	fr.consumed += int64(len(prefix)) + int64(size)
+	return fr.buf, nil
+}
+
+func (fr *frameReader) Accept() {}
+
+func (fr *frameReader) Consumed() int64 { return fr.consumed }
+
+// frameTailOffset walks the length prefixes of a plain framed file without
+// reading payloads and returns the offset of the last `capacity` messages.
+// Returns 0 if the file holds fewer messages.
+func frameTailOffset(file *os.File, capacity int) int64 {
+	info, err := file.Stat()
+	if err != nil {
+		return 0
+	}
+	size := info.Size()
+
+	// Ring of the most recent message offsets
+	offsets := make([]int64, capacity)
+	var count int
+	var pos int64
+	var prefix [4]byte
+	for pos+4 <= size {
+		if _, err := file.ReadAt(prefix[:], pos); err != nil {
+			break
+		}
+		next := pos + 4 + int64(binary.BigEndian.Uint32(prefix[:]))
+		if next > size {
+			break // trailing message still being written
+		}
+		offsets[count%capacity] = pos
+		count++
+		pos = next
+	}
+
+	if count <= capacity {
+		return 0
+	}
+	return offsets[count%capacity]
+}
+
+// unmarshalPayload decodes one record into msg. Records may be zstd frames
+// (collector compression: zstd) wrapping either protojson or binary protobuf.
+func unmarshalPayload(payload []byte, msg proto.Message) error {
+	if bytes.HasPrefix(payload, zstdMagic) {
+		decoded, err := zstdDecoder.DecodeAll(payload, nil)
+		if err != nil {
+			return fmt.Errorf("decompress zstd message: %w", err)
+		}
+		payload = decoded
+	}
+
+	trimmed := bytes.TrimLeft(payload, " \t\r\n")
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return protojson.Unmarshal(trimmed, msg)
+	}
+	return proto.Unmarshal(payload, msg)
+}
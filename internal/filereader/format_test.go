@@ -0,0 +1,352 @@
+package filereader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// recorder is a StorageReceiver that remembers what it was given.
+type recorder struct {
+	mu      sync.Mutex
+	spans   []string // span names
+	logs    []string // log bodies
+	metrics []string // metric names
+}
+
+func (r *recorder) ReceiveSpans(ctx context.Context, resourceSpans []*tracepb.ResourceSpans) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rs := range resourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, span := range ss.Spans {
+				r.spans = append(r.spans, span.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *recorder) ReceiveLogs(ctx context.Context, resourceLogs []*logspb.ResourceLogs) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rl := range resourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			for _, rec := range sl.LogRecords {
+				r.logs = append(r.logs, rec.Body.GetStringValue())
+			}
+		}
+	}
+	return nil
+}
+
+func (r *recorder) ReceiveMetrics(ctx context.Context, resourceMetrics []*metricspb.ResourceMetrics) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rm := range resourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				r.metrics = append(r.metrics, m.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// traceRecord is one exporter record holding a single span.
+func traceRecord(name string, startNano uint64) *tracepb.TracesData {
+	return &tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           bytes.Repeat([]byte{1}, 16),
+			SpanId:            bytes.Repeat([]byte{2}, 8),
+			Name:              name,
+			StartTimeUnixNano: startNano,
+			EndTimeUnixNano:   startNano + 1,
+		}}}},
+	}}}
+}
+
+// spanNames returns n span names: span-0, span-1, ...
+func spanNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("span-%d", i)
+	}
+	return names
+}
+
+// jsonl encodes one span per name as protojson lines.
+func jsonl(t *testing.T, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, name := range names {
+		line, err := protojson.Marshal(traceRecord(name, 1))
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// framed encodes one span per name as length-delimited payloads, each
+// optionally zstd-compressed as with the collector's compression: zstd.
+func framed(t *testing.T, compress bool, names ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, name := range names {
+		payload, err := proto.Marshal(traceRecord(name, 1))
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		if compress {
+			payload = zstdBytes(t, payload)
+		}
+		var prefix [4]byte
+		binary.BigEndian.PutUint32(prefix[:], uint32(len(payload)))
+		buf.Write(prefix[:])
+		buf.Write(payload)
+	}
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zstdBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("zstd: %v", err)
+	}
+	defer enc.Close()
+	return enc.EncodeAll(data, nil)
+}
+
+// newTestSource returns a FileSource over a temp directory with a traces/
+// subdirectory, and that subdirectory.
+func newTestSource(t *testing.T, cfg Config) (*FileSource, *recorder, string) {
+	t.Helper()
+	cfg.Directory = t.TempDir()
+	dir := filepath.Join(cfg.Directory, "traces")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	rec := &recorder{}
+	fs, err := New(cfg, rec)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(fs.Stop)
+	return fs, rec, dir
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func appendFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		t.Fatalf("append %s: %v", path, err)
+	}
+}
+
+func TestProcessFileFormats(t *testing.T) {
+	names := spanNames(3)
+	tests := []struct {
+		file string
+		data func(t *testing.T) []byte
+	}{
+		{"traces.jsonl", func(t *testing.T) []byte { return jsonl(t, names...) }},
+		{"traces-2025-12-09T13-10-56.jsonl.gz", func(t *testing.T) []byte { return gzipBytes(t, jsonl(t, names...)) }},
+		{"traces.jsonl.zst", func(t *testing.T) []byte { return zstdBytes(t, jsonl(t, names...)) }},
+		{"traces.pb", func(t *testing.T) []byte { return framed(t, false, names...) }},
+		{"traces.pb.gz", func(t *testing.T) []byte { return gzipBytes(t, framed(t, false, names...)) }},
+		// Per-message zstd frames, detected by their magic number
+		{"traces.binpb", func(t *testing.T) []byte { return framed(t, true, names...) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			fs, rec, dir := newTestSource(t, Config{})
+			path := filepath.Join(dir, tt.file)
+			writeFile(t, path, tt.data(t))
+
+			count, err := fs.loadTraceFile(context.Background(), path, 0)
+			if err != nil {
+				t.Fatalf("loadTraceFile failed: %v", err)
+			}
+			if count != 3 || !slices.Equal(rec.spans, names) {
+				t.Errorf("expected %v, got %d records: %v", names, count, rec.spans)
+			}
+		})
+	}
+}
+
+func TestProcessFileTruncatedTail(t *testing.T) {
+	tests := []struct {
+		file    string
+		records func(t *testing.T, names ...string) []byte
+	}{
+		{"traces.jsonl", jsonl},
+		{"traces.pb", func(t *testing.T, names ...string) []byte { return framed(t, false, names...) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			fs, rec, dir := newTestSource(t, Config{})
+			path := filepath.Join(dir, tt.file)
+			ctx := context.Background()
+
+			// The last record is still being written
+			complete := tt.records(t, "span-0", "span-1")
+			last := tt.records(t, "span-2")
+			cut := len(last) / 2
+			writeFile(t, path, append(slices.Clone(complete), last[:cut]...))
+
+			count, err := fs.loadTraceFile(ctx, path, 0)
+			if err != nil {
+				t.Fatalf("loadTraceFile failed: %v", err)
+			}
+			if count != 2 {
+				t.Fatalf("expected 2 complete records, got %d: %v", count, rec.spans)
+			}
+			if offset := fs.fileOffsets[path]; offset != int64(len(complete)) {
+				t.Errorf("expected offset %d at the last complete record, got %d", len(complete), offset)
+			}
+
+			// Once finished, the next read picks it up from there
+			appendFile(t, path, last[cut:])
+			count, err = fs.loadTraceFile(ctx, path, 0)
+			if err != nil {
+				t.Fatalf("loadTraceFile failed: %v", err)
+			}
+			if count != 1 || !slices.Equal(rec.spans, spanNames(3)) {
+				t.Errorf("expected the finished record only, got %d: %v", count, rec.spans)
+			}
+		})
+	}
+}
+
+func TestProcessFileTailSeek(t *testing.T) {
+	names := spanNames(10)
+	tests := []struct {
+		file string
+		data func(t *testing.T) []byte
+	}{
+		{"traces.pb", func(t *testing.T) []byte { return framed(t, false, names...) }},
+		{"traces.pb.zst", func(t *testing.T) []byte { return zstdBytes(t, framed(t, false, names...)) }},
+		{"traces.jsonl.gz", func(t *testing.T) []byte { return gzipBytes(t, jsonl(t, names...)) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			fs, rec, dir := newTestSource(t, Config{})
+			path := filepath.Join(dir, tt.file)
+			writeFile(t, path, tt.data(t))
+
+			count, err := fs.loadTraceFile(context.Background(), path, 4)
+			if err != nil {
+				t.Fatalf("loadTraceFile failed: %v", err)
+			}
+			if count != 4 || !slices.Equal(rec.spans, names[6:]) {
+				t.Errorf("expected the last 4 records, got %d: %v", count, rec.spans)
+			}
+		})
+	}
+}
+
+func TestFrameTailOffset(t *testing.T) {
+	data := framed(t, false, spanNames(5)...)
+	path := filepath.Join(t.TempDir(), "traces.pb")
+	// A trailing partial frame is not counted
+	writeFile(t, path, append(slices.Clone(data), 0, 0, 1, 0, 'x'))
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer file.Close()
+
+	if offset := frameTailOffset(file, 5); offset != 0 {
+		t.Errorf("expected offset 0 when every frame fits, got %d", offset)
+	}
+
+	frameSize := len(data) / 5 // all frames are the same size
+	if offset := frameTailOffset(file, 2); offset != int64(3*frameSize) {
+		t.Errorf("expected offset of frame 3 (%d), got %d", 3*frameSize, offset)
+	}
+}
+
+func TestIsFramed(t *testing.T) {
+	for _, first := range []byte{'{', ' ', '\n'} {
+		if isFramed(first) {
+			t.Errorf("expected %q to start JSONL", first)
+		}
+	}
+	if !isFramed(framed(t, false, "span")[0]) {
+		t.Error("expected a length prefix to be framed")
+	}
+}
+
+func TestRecordSignal(t *testing.T) {
+	line := bytes.TrimSpace(jsonl(t, "span"))
+	if got := recordSignal(line); got != "traces" {
+		t.Errorf("expected traces, got %q", got)
+	}
+	if got := recordSignal(zstdBytes(t, line)); got != "traces" {
+		t.Errorf("expected traces from a zstd frame, got %q", got)
+	}
+
+	logLine, err := protojson.Marshal(&logspb.LogsData{ResourceLogs: []*logspb.ResourceLogs{{
+		ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{{
+			Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "hi"}},
+		}}}},
+	}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if got := recordSignal(logLine); got != "logs" {
+		t.Errorf("expected logs, got %q", got)
+	}
+
+	payload, _ := proto.Marshal(traceRecord("span", 1))
+	if got := recordSignal(payload); got != "" {
+		t.Errorf("expected no signal for binary protobuf, got %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+package filereader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Batch is one decoded record from a telemetry file. Exactly one field is set.
+type Batch struct {
+	Traces  *tracepb.TracesData
+	Logs    *logspb.LogsData
+	Metrics *metricspb.MetricsData
+}
+
+// DataFiles returns every telemetry file in one signal directory (e.g.
+// /tank/otel/traces), rotated archives included, oldest first.
+func DataFiles(dir string) ([]string, error) {
+	return (&FileSource{}).findDataFiles(dir)
+}
+
+// ReadFile decodes every record in a telemetry file in order, calling fn for
+// each. signal is "traces", "logs" or "metrics"; when empty it is taken from
+// the parent directory name, as in the file exporter layout, or the file
+// name (traces.json), and failing both each JSON record's signal is
+// detected. Records that fail to decode are skipped; an error from fn stops
+// reading and is returned. Unlike FileSource, this is a one-shot read with
+// no offsets or watching.
+func ReadFile(ctx context.Context, path, signal string, fn func(Batch) error) error {
+	if signal == "" {
+		signal = filepath.Base(filepath.Dir(path))
+		if !isSignal(signal) {
+			signal = fileStem(filepath.Base(path))
+		}
+		if !isSignal(signal) {
+			signal = ""
+		}
+	}
+	decode := detectBatch
+	if signal != "" {
+		var err error
+		if decode, err = batchDecoder(signal); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, closeFn, err := openDecompressor(fileCompression(path), file)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	br := bufio.NewReader(reader)
+	first, err := br.Peek(1)
+	if err != nil {
+		return nil // Empty file
+	}
+
+	records := newRecordReader(br, isFramed(first[0]))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := records.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && !errors.Is(err, errIncomplete) {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		if len(record) > 0 {
+			if batch, decodeErr := decode(record); decodeErr == nil {
+				if fnErr := fn(batch); fnErr != nil {
+					return fnErr
+				}
+			}
+		}
+		if err != nil {
+			return nil // Truncated tail
+		}
+	}
+}
+
+// detectBatch decodes a record of a file holding several signals.
+func detectBatch(record []byte) (Batch, error) {
+	decode, err := batchDecoder(recordSignal(record))
+	if err != nil {
+		return Batch{}, err
+	}
+	return decode(record)
+}
+
+// batchDecoder returns a record decoder for a signal name.
+func batchDecoder(signal string) (func([]byte) (Batch, error), error) {
+	switch signal {
+	case "traces":
+		return func(record []byte) (Batch, error) {
+			var data tracepb.TracesData
+			err := unmarshalPayload(record, &data)
+			return Batch{Traces: &data}, err
+		}, nil
+	case "logs":
+		return func(record []byte) (Batch, error) {
+			var data logspb.LogsData
+			err := unmarshalPayload(record, &data)
+			return Batch{Logs: &data}, err
+		}, nil
+	case "metrics":
+		return func(record []byte) (Batch, error) {
+			var data metricspb.MetricsData
+			err := unmarshalPayload(record, &data)
+			return Batch{Metrics: &data}, err
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown signal %q (expected traces, logs or metrics)", signal)
+}
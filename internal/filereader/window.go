@@ -0,0 +1,232 @@
+package filereader
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// archiveTimeLayouts are the timestamp formats used in rotated file names.
+// The collector's file exporter (via lumberjack) writes
+// traces-2025-12-09T13-10-56.123.jsonl; older setups omit the milliseconds.
+var archiveTimeLayouts = []string{
+	"2006-01-02T15-04-05.000",
+	"2006-01-02T15-04-05",
+}
+
+// timeBoundLayouts are accepted absolute formats for ParseTimeBound.
+var timeBoundLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseTimeBound parses a since/until bound. It accepts RFC3339 timestamps,
+// "2006-01-02 15:04"-style local times, bare dates, and durations like "2h"
+// or "30m" meaning that long before now. An empty string is the zero time.
+func ParseTimeBound(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return now.Add(-d), nil
+	}
+	for _, layout := range timeBoundLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use RFC3339, \"2006-01-02 15:04\", or a duration like 2h)", s)
+}
+
+// parseArchiveTime extracts the rotation timestamp from an archived file
+// name like traces-2025-12-09T13-10-56.jsonl.gz. Lumberjack uses UTC.
+func parseArchiveTime(name, signal string) (time.Time, bool) {
+	base := trimCompressionExt(name)
+	for _, ext := range dataExtensions {
+		base = strings.TrimSuffix(base, ext)
+	}
+	stamp, ok := strings.CutPrefix(base, signal+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range archiveTimeLayouts {
+		if t, err := time.Parse(layout, stamp); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseRotatedTime extracts the rotation timestamp from a backup of an
+// exporter file with any name: lumberjack rotates spans.json to
+// spans-2025-12-09T13-10-56.000.json, optionally gzip-compressed.
+func parseRotatedTime(name, active string) (time.Time, bool) {
+	ext := filepath.Ext(active)
+	base, ok := strings.CutSuffix(trimCompressionExt(name), ext)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp, ok := strings.CutPrefix(base, strings.TrimSuffix(active, ext)+"-")
+	if !ok {
+		return time.Time{}, false
+	}
+	for _, layout := range archiveTimeLayouts {
+		if t, err := time.Parse(layout, stamp); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// timeWindow limits loaded records to [since, until]. Zero bounds are open.
+type timeWindow struct {
+	since time.Time
+	until time.Time
+}
+
+// active reports whether either bound is set.
+func (w timeWindow) active() bool {
+	return !w.since.IsZero() || !w.until.IsZero()
+}
+
+// contains reports whether a record timestamp falls inside the window.
+// Records without a timestamp are kept.
+func (w timeWindow) contains(unixNano uint64) bool {
+	if unixNano == 0 {
+		return true
+	}
+	ts := int64(unixNano)
+	if !w.since.IsZero() && ts < w.since.UnixNano() {
+		return false
+	}
+	if !w.until.IsZero() && ts > w.until.UnixNano() {
+		return false
+	}
+	return true
+}
+
+// filterSpans drops spans that start outside the window, pruning empty scopes and resources.
+func (w timeWindow) filterSpans(resourceSpans []*tracepb.ResourceSpans) []*tracepb.ResourceSpans {
+	kept := resourceSpans[:0]
+	for _, rs := range resourceSpans {
+		scopes := rs.ScopeSpans[:0]
+		for _, ss := range rs.ScopeSpans {
+			spans := ss.Spans[:0]
+			for _, span := range ss.Spans {
+				if w.contains(span.StartTimeUnixNano) {
+					spans = append(spans, span)
+				}
+			}
+			if len(spans) > 0 {
+				ss.Spans = spans
+				scopes = append(scopes, ss)
+			}
+		}
+		if len(scopes) > 0 {
+			rs.ScopeSpans = scopes
+			kept = append(kept, rs)
+		}
+	}
+	return kept
+}
+
+// filterLogs drops log records outside the window, using the observed time
+// when the event time is unset.
+func (w timeWindow) filterLogs(resourceLogs []*logspb.ResourceLogs) []*logspb.ResourceLogs {
+	kept := resourceLogs[:0]
+	for _, rl := range resourceLogs {
+		scopes := rl.ScopeLogs[:0]
+		for _, sl := range rl.ScopeLogs {
+			records := sl.LogRecords[:0]
+			for _, rec := range sl.LogRecords {
+				ts := rec.TimeUnixNano
+				if ts == 0 {
+					ts = rec.ObservedTimeUnixNano
+				}
+				if w.contains(ts) {
+					records = append(records, rec)
+				}
+			}
+			if len(records) > 0 {
+				sl.LogRecords = records
+				scopes = append(scopes, sl)
+			}
+		}
+		if len(scopes) > 0 {
+			rl.ScopeLogs = scopes
+			kept = append(kept, rl)
+		}
+	}
+	return kept
+}
+
+// filterMetrics drops data points outside the window, pruning metrics left
+// without points along with empty scopes and resources.
+func (w timeWindow) filterMetrics(resourceMetrics []*metricspb.ResourceMetrics) []*metricspb.ResourceMetrics {
+	kept := resourceMetrics[:0]
+	for _, rm := range resourceMetrics {
+		scopes := rm.ScopeMetrics[:0]
+		for _, sm := range rm.ScopeMetrics {
+			metrics := sm.Metrics[:0]
+			for _, m := range sm.Metrics {
+				if w.filterDataPoints(m) > 0 {
+					metrics = append(metrics, m)
+				}
+			}
+			if len(metrics) > 0 {
+				sm.Metrics = metrics
+				scopes = append(scopes, sm)
+			}
+		}
+		if len(scopes) > 0 {
+			rm.ScopeMetrics = scopes
+			kept = append(kept, rm)
+		}
+	}
+	return kept
+}
+
+// filterDataPoints filters a metric's points in place and returns how many remain.
+func (w timeWindow) filterDataPoints(m *metricspb.Metric) int {
+	switch data := m.Data.(type) {
+	case *metricspb.Metric_Gauge:
+		data.Gauge.DataPoints = filterPoints(w, data.Gauge.DataPoints, (*metricspb.NumberDataPoint).GetTimeUnixNano)
+		return len(data.Gauge.DataPoints)
+	case *metricspb.Metric_Sum:
+		data.Sum.DataPoints = filterPoints(w, data.Sum.DataPoints, (*metricspb.NumberDataPoint).GetTimeUnixNano)
+		return len(data.Sum.DataPoints)
+	case *metricspb.Metric_Histogram:
+		data.Histogram.DataPoints = filterPoints(w, data.Histogram.DataPoints, (*metricspb.HistogramDataPoint).GetTimeUnixNano)
+		return len(data.Histogram.DataPoints)
+	case *metricspb.Metric_ExponentialHistogram:
+		data.ExponentialHistogram.DataPoints = filterPoints(w, data.ExponentialHistogram.DataPoints, (*metricspb.ExponentialHistogramDataPoint).GetTimeUnixNano)
+		return len(data.ExponentialHistogram.DataPoints)
+	case *metricspb.Metric_Summary:
+		data.Summary.DataPoints = filterPoints(w, data.Summary.DataPoints, (*metricspb.SummaryDataPoint).GetTimeUnixNano)
+		return len(data.Summary.DataPoints)
+	}
+	return 0
+}
+
+func filterPoints[P any](w timeWindow, points []P, timeOf func(P) uint64) []P {
+	kept := points[:0]
+	for _, p := range points {
+		if w.contains(timeOf(p)) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
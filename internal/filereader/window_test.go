@@ -0,0 +1,229 @@
+package filereader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2025, 12, 9, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"2h", now.Add(-2 * time.Hour)},
+		{"-30m", now.Add(-30 * time.Minute)},
+		{"2025-12-09T10:00:00Z", time.Date(2025, 12, 9, 10, 0, 0, 0, time.UTC)},
+		{"2025-12-09 10:30", time.Date(2025, 12, 9, 10, 30, 0, 0, time.Local)},
+		{"2025-12-08", time.Date(2025, 12, 8, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeBound(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseTimeBound(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTimeBound(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseTimeBound("yesterday", now); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestParseArchiveTime(t *testing.T) {
+	want := time.Date(2025, 12, 9, 13, 10, 56, 0, time.UTC)
+	for _, name := range []string{
+		"traces-2025-12-09T13-10-56.jsonl",
+		"traces-2025-12-09T13-10-56.000.jsonl.gz",
+		"traces-2025-12-09T13-10-56.pb.zst",
+	} {
+		got, ok := parseArchiveTime(name, "traces")
+		if !ok || !got.Equal(want) {
+			t.Errorf("parseArchiveTime(%q) = %v, %v; want %v", name, got, ok, want)
+		}
+	}
+	for _, name := range []string{"traces.jsonl", "logs-2025-12-09T13-10-56.jsonl", "traces-latest.jsonl"} {
+		if _, ok := parseArchiveTime(name, "traces"); ok {
+			t.Errorf("parseArchiveTime(%q) should not be an archive", name)
+		}
+	}
+}
+
+// writeArchives writes one empty archive per rotation time into dir, plus
+// the active file, and returns the archive names.
+func writeArchives(t *testing.T, dir string, rotations ...string) []string {
+	t.Helper()
+	var names []string
+	for _, rotation := range rotations {
+		name := "traces-" + rotation + ".jsonl"
+		writeFile(t, filepath.Join(dir, name), nil)
+		names = append(names, name)
+	}
+	writeFile(t, filepath.Join(dir, "traces.jsonl"), nil)
+	return names
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
+
+func TestFindDataFilesWindow(t *testing.T) {
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	fs, _, dir := newTestSource(t, Config{ActiveOnly: true, SinceTime: since, UntilTime: until})
+	// Written out of order, to check they are read by rotation time
+	archives := writeArchives(t, dir,
+		"2025-01-03T00-00-00", // holds Jan 2 00:00 - Jan 3: starts inside the window
+		"2025-01-01T00-00-00", // holds records before Jan 1 00:00: all before since
+		"2025-01-04T00-00-00", // holds Jan 3 - Jan 4: all after until
+		"2025-01-02T00-00-00", // holds Jan 1 - Jan 2: overlaps since
+	)
+
+	files, err := fs.findDataFiles(dir)
+	if err != nil {
+		t.Fatalf("findDataFiles failed: %v", err)
+	}
+	want := []string{archives[3], archives[0], "traces.jsonl"}
+	if got := baseNames(files); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	// Without a window, activeOnly skips every archive
+	fs.window = timeWindow{}
+	files, err = fs.findDataFiles(dir)
+	if err != nil {
+		t.Fatalf("findDataFiles failed: %v", err)
+	}
+	if got := baseNames(files); !slices.Equal(got, []string{"traces.jsonl"}) {
+		t.Errorf("expected only the active file, got %v", got)
+	}
+}
+
+func TestFindDataFilesSinceOnly(t *testing.T) {
+	since := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	fs, _, dir := newTestSource(t, Config{SinceTime: since})
+	archives := writeArchives(t, dir, "2025-01-01T00-00-00", "2025-01-02T00-00-00", "2025-01-03T00-00-00")
+
+	// The active file is newest by modification time
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "traces.jsonl"), future, future); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	files, err := fs.findDataFiles(dir)
+	if err != nil {
+		t.Fatalf("findDataFiles failed: %v", err)
+	}
+	want := []string{archives[2], "traces.jsonl"}
+	if got := baseNames(files); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLoadWindowDropsRecords(t *testing.T) {
+	since := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	until := since.Add(time.Hour)
+	before, inside, after := since.Add(-time.Minute), since.Add(time.Minute), until.Add(time.Minute)
+	ns := func(ts time.Time) uint64 { return uint64(ts.UnixNano()) }
+
+	fs, rec, dir := newTestSource(t, Config{SinceTime: since, UntilTime: until})
+	ctx := context.Background()
+
+	// Spans: one record mixing in- and out-of-window spans, one all outside
+	mixed := traceRecord("inside", ns(inside))
+	mixed.ResourceSpans[0].ScopeSpans[0].Spans = append(mixed.ResourceSpans[0].ScopeSpans[0].Spans,
+		traceRecord("before", ns(before)).ResourceSpans[0].ScopeSpans[0].Spans[0],
+		traceRecord("untimed", 0).ResourceSpans[0].ScopeSpans[0].Spans[0])
+	var traces []byte
+	for _, data := range []proto.Message{mixed, traceRecord("after", ns(after))} {
+		line, err := protojson.Marshal(data)
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		traces = append(append(traces, line...), '\n')
+	}
+	tracePath := filepath.Join(dir, "traces.jsonl")
+	writeFile(t, tracePath, traces)
+	if _, err := fs.loadTraceFile(ctx, tracePath, 0); err != nil {
+		t.Fatalf("loadTraceFile failed: %v", err)
+	}
+	if want := []string{"inside", "untimed"}; !slices.Equal(rec.spans, want) {
+		t.Errorf("expected spans %v, got %v", want, rec.spans)
+	}
+
+	// Logs fall back to the observed time
+	logRecord := func(body string, ts, observed time.Time) *logspb.LogRecord {
+		r := &logspb.LogRecord{Body: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}}}
+		if !ts.IsZero() {
+			r.TimeUnixNano = ns(ts)
+		}
+		r.ObservedTimeUnixNano = ns(observed)
+		return r
+	}
+	logs, err := protojson.Marshal(&logspb.LogsData{ResourceLogs: []*logspb.ResourceLogs{{
+		ScopeLogs: []*logspb.ScopeLogs{{LogRecords: []*logspb.LogRecord{
+			logRecord("inside", inside, after),
+			logRecord("observed inside", time.Time{}, inside),
+			logRecord("after", after, inside),
+		}}},
+	}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	logPath := filepath.Join(t.TempDir(), "logs.jsonl")
+	writeFile(t, logPath, append(logs, '\n'))
+	if _, err := fs.loadLogFile(ctx, logPath, 0); err != nil {
+		t.Fatalf("loadLogFile failed: %v", err)
+	}
+	if want := []string{"inside", "observed inside"}; !slices.Equal(rec.logs, want) {
+		t.Errorf("expected logs %v, got %v", want, rec.logs)
+	}
+
+	// Metrics keep only in-window points, and are dropped without any
+	gauge := func(name string, times ...time.Time) *metricspb.Metric {
+		var points []*metricspb.NumberDataPoint
+		for _, ts := range times {
+			points = append(points, &metricspb.NumberDataPoint{TimeUnixNano: ns(ts)})
+		}
+		return &metricspb.Metric{Name: name, Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: points}}}
+	}
+	kept := gauge("kept", before, inside, after)
+	metrics, err := protojson.Marshal(&metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{kept, gauge("dropped", before, after)}}},
+	}}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	metricPath := filepath.Join(t.TempDir(), "metrics.jsonl")
+	writeFile(t, metricPath, append(metrics, '\n'))
+	if _, err := fs.loadMetricFile(ctx, metricPath, 0); err != nil {
+		t.Fatalf("loadMetricFile failed: %v", err)
+	}
+	if want := []string{"kept"}; !slices.Equal(rec.metrics, want) {
+		t.Errorf("expected metrics %v, got %v", want, rec.metrics)
+	}
+
+	points := timeWindow{since: since, until: until}.filterDataPoints(kept)
+	if points != 1 {
+		t.Errorf("expected 1 in-window point, got %d", points)
+	}
+}
@@ -0,0 +1,847 @@
+// Package generate produces synthetic OTLP telemetry for demos, benchmarks
+// and load testing: a topology of services calling each other, traces with
+// configurable fan-out, errors and latency, correlated logs, and metrics of
+// every OTLP metric type.
+package generate
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/otlpclient"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// Latency distributions accepted by Config.LatencyDist.
+const (
+	DistExponential = "exponential"
+	DistNormal      = "normal"
+	DistLogNormal   = "lognormal"
+	DistUniform     = "uniform"
+)
+
+// Config describes the synthetic topology and load.
+type Config struct {
+	Services    int           // Number of services (default 5)
+	FanOut      int           // Maximum downstream calls per span (default 2)
+	Depth       int           // Maximum call depth including the root (default 3)
+	ErrorRate   float64       // Probability that a span fails, 0..1
+	Latency     time.Duration // Mean self-time per span (default 20ms)
+	LatencyDist string        // exponential (default), normal, lognormal or uniform
+	LogsPerSpan float64       // Mean correlated log records per span (default 1)
+
+	// MetricInterval is how often each service reports metrics, in
+	// generated time. Zero disables metrics.
+	MetricInterval time.Duration
+
+	Rate     float64       // Target traces per second; zero sends as fast as possible, and needs Count
+	Duration time.Duration // Stop after this long; zero runs until cancelled
+	Count    int           // Stop after this many traces; zero means unlimited
+	Seed     int64         // Random seed; zero picks one from the clock
+}
+
+// Stats counts generated telemetry.
+type Stats struct {
+	Traces  int
+	Spans   int
+	Logs    int
+	Metrics int // data points
+	Errors  int // spans with error status
+}
+
+// Batch is one unit of generated telemetry, ready to export.
+type Batch struct {
+	Spans   []*tracepb.ResourceSpans
+	Logs    []*logspb.ResourceLogs
+	Metrics []*metricspb.ResourceMetrics
+}
+
+// serviceNames gives the first services recognizable names; more are numbered.
+var serviceNames = []string{
+	"frontend", "checkout", "cart", "payment", "inventory",
+	"shipping", "auth", "search", "recommendation", "email",
+}
+
+var operations = []string{"GET /api/items", "POST /api/orders", "GET /api/users", "PUT /api/cart", "rpc Process", "rpc Lookup"}
+
+var errorTypes = []struct{ typ, msg string }{
+	{"TimeoutError", "upstream request timed out"},
+	{"ConnectionRefusedError", "connection refused"},
+	{"ValueError", "invalid order quantity"},
+	{"NotFoundError", "item not found"},
+}
+
+// maxExportItems bounds the spans, log records or metrics sent in one export
+// request, keeping it well under the 4MB default gRPC message size limit at
+// any rate or count.
+const maxExportItems = 1000
+
+// histogramBounds are explicit bucket bounds in milliseconds.
+var histogramBounds = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// service is one node of the topology plus its running metric state.
+type service struct {
+	name       string
+	resource   *resourcepb.Resource
+	downstream []int // indexes of services this one may call
+
+	// Cumulative metric state since generator start
+	requests    int64
+	errors      int64
+	durations   []float64 // ms, since the last metric report
+	histCounts  []uint64  // cumulative explicit bucket counts
+	histSum     float64
+	histCount   uint64
+	lastReport  time.Time
+	startTime   time.Time
+	memoryBytes float64
+}
+
+// Generator builds synthetic telemetry. It is not safe for concurrent use.
+type Generator struct {
+	cfg      Config
+	rng      *rand.Rand
+	services []*service
+	stats    Stats
+}
+
+// New validates cfg, fills in defaults and builds the service topology.
+func New(cfg Config) (*Generator, error) {
+	if cfg.Services <= 0 {
+		cfg.Services = 5
+	}
+	if cfg.FanOut < 0 {
+		return nil, fmt.Errorf("fan-out must be >= 0")
+	}
+	if cfg.FanOut == 0 && cfg.Depth == 0 {
+		cfg.FanOut = 2
+	}
+	if cfg.Depth <= 0 {
+		cfg.Depth = 3
+	}
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 1 {
+		return nil, fmt.Errorf("error rate must be between 0 and 1")
+	}
+	if cfg.Latency <= 0 {
+		cfg.Latency = 20 * time.Millisecond
+	}
+	switch cfg.LatencyDist {
+	case "":
+		cfg.LatencyDist = DistExponential
+	case DistExponential, DistNormal, DistLogNormal, DistUniform:
+	default:
+		return nil, fmt.Errorf("unknown latency distribution %q (use exponential, normal, lognormal or uniform)", cfg.LatencyDist)
+	}
+	if cfg.LogsPerSpan < 0 {
+		return nil, fmt.Errorf("logs per span must be >= 0")
+	}
+	if cfg.Rate < 0 {
+		return nil, fmt.Errorf("rate must be >= 0")
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+
+	g := &Generator{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed))}
+	g.buildTopology()
+	return g, nil
+}
+
+// Config returns the effective configuration with defaults applied.
+func (g *Generator) Config() Config { return g.cfg }
+
+// Stats returns totals generated so far.
+func (g *Generator) Stats() Stats { return g.stats }
+
+// Services returns the generated service names.
+func (g *Generator) Services() []string {
+	names := make([]string, len(g.services))
+	for i, svc := range g.services {
+		names[i] = svc.name
+	}
+	return names
+}
+
+// buildTopology creates services where each may call services with a higher
+// index, so call graphs are acyclic and the first service is the entry point.
+func (g *Generator) buildTopology() {
+	for i := 0; i < g.cfg.Services; i++ {
+		name := fmt.Sprintf("service-%d", i)
+		if i < len(serviceNames) {
+			name = serviceNames[i]
+		}
+		g.services = append(g.services, &service{
+			name: name,
+			resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				stringAttr("service.name", name),
+				stringAttr("service.version", "1.0.0"),
+				stringAttr("deployment.environment", "synthetic"),
+				stringAttr("telemetry.sdk.name", "otlp-mcp-generate"),
+			}},
+			histCounts:  make([]uint64, len(histogramBounds)+1),
+			memoryBytes: float64(64+g.rng.Intn(192)) * 1024 * 1024,
+		})
+	}
+
+	for i, svc := range g.services {
+		for j := i + 1; j < len(g.services); j++ {
+			// Always link to the next service so every service is reachable
+			if j == i+1 || g.rng.Float64() < 0.4 {
+				svc.downstream = append(svc.downstream, j)
+			}
+		}
+	}
+}
+
+// NextTrace generates one complete trace ending at end, with its correlated logs.
+func (g *Generator) NextTrace(end time.Time) Batch {
+	traceID := g.randomBytes(16)
+	var spans []spanRecord
+	var logs []logRecord
+
+	root := g.buildSpan(0, 1, 0, traceID, nil, &spans, &logs)
+	// Shift the whole trace so it has just finished at end
+	shift := end.UnixNano() - int64(root.end)
+	for i := range spans {
+		spans[i].span.StartTimeUnixNano = uint64(int64(spans[i].span.StartTimeUnixNano) + shift)
+		spans[i].span.EndTimeUnixNano = uint64(int64(spans[i].span.EndTimeUnixNano) + shift)
+		for _, ev := range spans[i].span.Events {
+			ev.TimeUnixNano = uint64(int64(ev.TimeUnixNano) + shift)
+		}
+	}
+	for i := range logs {
+		logs[i].rec.TimeUnixNano = uint64(int64(logs[i].rec.TimeUnixNano) + shift)
+		logs[i].rec.ObservedTimeUnixNano = logs[i].rec.TimeUnixNano
+	}
+
+	g.stats.Traces++
+	g.stats.Spans += len(spans)
+	g.stats.Logs += len(logs)
+
+	return Batch{
+		Spans: g.groupSpans(spans),
+		Logs:  g.groupLogs(logs),
+	}
+}
+
+type spanRecord struct {
+	service int
+	span    *tracepb.Span
+	end     uint64
+}
+
+type logRecord struct {
+	service int
+	rec     *logspb.LogRecord
+}
+
+// buildSpan creates a server span for svcIdx starting at start (relative
+// nanoseconds), recursing into downstream calls. Child calls run
+// sequentially after a short self-time, and the parent ends after them.
+func (g *Generator) buildSpan(svcIdx, depth int, start int64, traceID, parentID []byte, spans *[]spanRecord, logs *[]logRecord) spanRecord {
+	svc := g.services[svcIdx]
+	spanID := g.randomBytes(8)
+	op := operations[g.rng.Intn(len(operations))]
+
+	span := &tracepb.Span{
+		TraceId:           traceID,
+		SpanId:            spanID,
+		ParentSpanId:      parentID,
+		Name:              op,
+		Kind:              tracepb.Span_SPAN_KIND_SERVER,
+		StartTimeUnixNano: uint64(start),
+		Attributes: []*commonpb.KeyValue{
+			stringAttr("http.route", op),
+			intAttr("synthetic.depth", int64(depth)),
+		},
+	}
+	if depth == 1 {
+		span.Name = "GET /"
+	}
+
+	// Self time before, between and after downstream calls
+	cursor := start + int64(g.sampleLatency()/4)
+	failed := g.rng.Float64() < g.cfg.ErrorRate
+
+	if depth < g.cfg.Depth && len(svc.downstream) > 0 && g.cfg.FanOut > 0 {
+		calls := 1 + g.rng.Intn(g.cfg.FanOut)
+		for c := 0; c < calls; c++ {
+			target := svc.downstream[g.rng.Intn(len(svc.downstream))]
+			child := g.buildSpan(target, depth+1, cursor, traceID, spanID, spans, logs)
+			cursor = int64(child.end) + int64(g.rng.Intn(500))*int64(time.Microsecond)
+			// Downstream failures sometimes propagate
+			if child.span.Status.GetCode() == tracepb.Status_STATUS_CODE_ERROR && g.rng.Float64() < 0.5 {
+				failed = true
+			}
+		}
+	} else if g.rng.Float64() < 0.5 {
+		// Leaf services talk to a database
+		dbStart := cursor
+		cursor += int64(g.sampleLatency() / 2)
+		*spans = append(*spans, spanRecord{service: svcIdx, end: uint64(cursor), span: &tracepb.Span{
+			TraceId:           traceID,
+			SpanId:            g.randomBytes(8),
+			ParentSpanId:      spanID,
+			Name:              "SELECT " + svc.name,
+			Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+			StartTimeUnixNano: uint64(dbStart),
+			EndTimeUnixNano:   uint64(cursor),
+			Attributes: []*commonpb.KeyValue{
+				stringAttr("db.system", "postgresql"),
+				stringAttr("db.statement", "SELECT * FROM "+svc.name+" WHERE id = $1"),
+			},
+			Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK},
+		}})
+	}
+
+	end := cursor + int64(g.sampleLatency()*3/4)
+	span.EndTimeUnixNano = uint64(end)
+
+	status := int64(200)
+	if failed {
+		e := errorTypes[g.rng.Intn(len(errorTypes))]
+		status = 500
+		span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: e.msg}
+		span.Events = append(span.Events, &tracepb.Span_Event{
+			Name:         "exception",
+			TimeUnixNano: uint64(end - int64(time.Millisecond)),
+			Attributes: []*commonpb.KeyValue{
+				stringAttr("exception.type", e.typ),
+				stringAttr("exception.message", e.msg),
+				stringAttr("exception.stacktrace", fmt.Sprintf("%s: %s\n\tat %s.handle(%s.go:%d)\n\tat main.serve(server.go:42)",
+					e.typ, e.msg, svc.name, svc.name, 10+g.rng.Intn(300))),
+			},
+		})
+		g.stats.Errors++
+	} else {
+		span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+	}
+	span.Attributes = append(span.Attributes, intAttr("http.response.status_code", status))
+
+	g.recordRequest(svc, time.Duration(end-start), failed)
+	g.addLogs(svcIdx, span, failed, logs)
+
+	rec := spanRecord{service: svcIdx, span: span, end: uint64(end)}
+	*spans = append(*spans, rec)
+	return rec
+}
+
+// addLogs emits a Poisson-distributed number of log records for a span.
+func (g *Generator) addLogs(svcIdx int, span *tracepb.Span, failed bool, logs *[]logRecord) {
+	n := g.poisson(g.cfg.LogsPerSpan)
+	if failed && n == 0 && g.cfg.LogsPerSpan > 0 {
+		n = 1 // Errors always leave a trace in the logs
+	}
+	duration := int64(span.EndTimeUnixNano - span.StartTimeUnixNano)
+	for i := 0; i < n; i++ {
+		severity, text := logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"
+		body := fmt.Sprintf("handled %s", span.Name)
+		switch {
+		case failed && i == n-1:
+			severity, text = logspb.SeverityNumber_SEVERITY_NUMBER_ERROR, "ERROR"
+			body = "request failed: " + span.Status.GetMessage()
+		case g.rng.Float64() < 0.1:
+			severity, text = logspb.SeverityNumber_SEVERITY_NUMBER_WARN, "WARN"
+			body = fmt.Sprintf("slow dependency while handling %s", span.Name)
+		case g.rng.Float64() < 0.3:
+			severity, text = logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG, "DEBUG"
+			body = fmt.Sprintf("cache lookup for %s", span.Name)
+		}
+
+		var offset int64
+		if duration > 0 {
+			offset = g.rng.Int63n(duration)
+		}
+		*logs = append(*logs, logRecord{service: svcIdx, rec: &logspb.LogRecord{
+			TimeUnixNano:   span.StartTimeUnixNano + uint64(offset),
+			SeverityNumber: severity,
+			SeverityText:   text,
+			Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+			TraceId:        span.TraceId,
+			SpanId:         span.SpanId,
+			Attributes:     []*commonpb.KeyValue{stringAttr("code.function", "handle")},
+		}})
+	}
+}
+
+func (g *Generator) recordRequest(svc *service, d time.Duration, failed bool) {
+	ms := float64(d) / float64(time.Millisecond)
+	svc.requests++
+	if failed {
+		svc.errors++
+	}
+	svc.durations = append(svc.durations, ms)
+	svc.histSum += ms
+	svc.histCount++
+	svc.histCounts[sort.SearchFloat64s(histogramBounds, ms)]++
+}
+
+// sampleLatency draws a span self-time from the configured distribution.
+func (g *Generator) sampleLatency() time.Duration {
+	mean := float64(g.cfg.Latency)
+	var v float64
+	switch g.cfg.LatencyDist {
+	case DistNormal:
+		v = mean + g.rng.NormFloat64()*mean/4
+	case DistLogNormal:
+		// sigma 0.8 gives a realistic long tail; mu chosen so the mean matches
+		const sigma = 0.8
+		v = math.Exp(math.Log(mean) - sigma*sigma/2 + sigma*g.rng.NormFloat64())
+	case DistUniform:
+		v = g.rng.Float64() * 2 * mean
+	default:
+		v = g.rng.ExpFloat64() * mean
+	}
+	if v < float64(10*time.Microsecond) {
+		v = float64(10 * time.Microsecond)
+	}
+	return time.Duration(v)
+}
+
+// poisson draws from a Poisson distribution with the given mean (Knuth).
+func (g *Generator) poisson(mean float64) int {
+	if mean <= 0 {
+		return 0
+	}
+	l := math.Exp(-mean)
+	k, p := 0, 1.0
+	for {
+		p *= g.rng.Float64()
+		if p <= l {
+			return k
+		}
+		k++
+	}
+}
+
+// MetricsDue reports metrics for every service whose MetricInterval has
+// elapsed by now, returning nil when none are due or metrics are disabled.
+func (g *Generator) MetricsDue(now time.Time) []*metricspb.ResourceMetrics {
+	return g.reportMetrics(now, false)
+}
+
+// FlushMetrics reports metrics for every service regardless of interval, so
+// short runs still end with one report of each type.
+func (g *Generator) FlushMetrics(now time.Time) []*metricspb.ResourceMetrics {
+	return g.reportMetrics(now, true)
+}
+
+func (g *Generator) reportMetrics(now time.Time, force bool) []*metricspb.ResourceMetrics {
+	if g.cfg.MetricInterval <= 0 {
+		return nil
+	}
+	var out []*metricspb.ResourceMetrics
+	for _, svc := range g.services {
+		if svc.startTime.IsZero() {
+			svc.startTime, svc.lastReport = now, now
+		}
+		if !force && now.Sub(svc.lastReport) < g.cfg.MetricInterval {
+			continue
+		}
+		out = append(out, g.serviceMetrics(svc, now))
+		svc.lastReport = now
+	}
+	return out
+}
+
+// serviceMetrics builds one metric of each OTLP type for a service.
+func (g *Generator) serviceMetrics(svc *service, now time.Time) *metricspb.ResourceMetrics {
+	ts := uint64(now.UnixNano())
+	start := uint64(svc.startTime.UnixNano())
+	interval := now.Sub(svc.lastReport).Seconds()
+
+	// Gauge: memory drifts with a random walk
+	svc.memoryBytes = math.Max(16*1024*1024, svc.memoryBytes+g.rng.NormFloat64()*2*1024*1024)
+	gauge := &metricspb.Metric{
+		Name: "process.memory.usage", Unit: "By",
+		Description: "Resident memory of the service process",
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+			TimeUnixNano: ts,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: svc.memoryBytes},
+		}}}},
+	}
+
+	// Sum: cumulative request and error counters
+	counter := func(outcome string, v int64) *metricspb.NumberDataPoint {
+		return &metricspb.NumberDataPoint{
+			StartTimeUnixNano: start,
+			TimeUnixNano:      ts,
+			Value:             &metricspb.NumberDataPoint_AsInt{AsInt: v},
+			Attributes:        []*commonpb.KeyValue{stringAttr("outcome", outcome)},
+		}
+	}
+	sum := &metricspb.Metric{
+		Name: "http.server.request.count", Unit: "{request}",
+		Description: "Requests handled",
+		Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+			DataPoints:             []*metricspb.NumberDataPoint{counter("success", svc.requests-svc.errors), counter("error", svc.errors)},
+		}},
+	}
+
+	// Histogram: cumulative explicit-bucket request durations
+	histSum := svc.histSum
+	hist := &metricspb.Metric{
+		Name: "http.server.request.duration", Unit: "ms",
+		Description: "Request duration",
+		Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			DataPoints: []*metricspb.HistogramDataPoint{{
+				StartTimeUnixNano: start,
+				TimeUnixNano:      ts,
+				Count:             svc.histCount,
+				Sum:               &histSum,
+				BucketCounts:      append([]uint64(nil), svc.histCounts...),
+				ExplicitBounds:    histogramBounds,
+			}},
+		}},
+	}
+
+	// Exponential histogram and summary: durations since the last report
+	expHist := &metricspb.Metric{
+		Name: "rpc.server.duration", Unit: "ms",
+		Description: "RPC duration since the last report",
+		Data: &metricspb.Metric_ExponentialHistogram{ExponentialHistogram: &metricspb.ExponentialHistogram{
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+			DataPoints:             []*metricspb.ExponentialHistogramDataPoint{exponentialPoint(svc.durations, uint64(svc.lastReport.UnixNano()), ts)},
+		}},
+	}
+	summary := &metricspb.Metric{
+		Name: "http.server.latency.summary", Unit: "ms",
+		Description: "Request latency quantiles since the last report",
+		Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{
+			DataPoints: []*metricspb.SummaryDataPoint{summaryPoint(svc.durations, uint64(svc.lastReport.UnixNano()), ts)},
+		}},
+	}
+	svc.durations = svc.durations[:0]
+
+	// Integer gauge of requests in flight
+	rate := &metricspb.Metric{
+		Name: "http.server.active_requests", Unit: "{request}",
+		Description: "Concurrent requests in flight",
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{{
+			TimeUnixNano: ts,
+			Value:        &metricspb.NumberDataPoint_AsInt{AsInt: int64(g.rng.Intn(1 + int(g.cfg.Rate*interval/10+1)))},
+		}}}},
+	}
+
+	metrics := []*metricspb.Metric{gauge, rate, sum, hist, expHist, summary}
+	g.stats.Metrics += 1 + 1 + 2 + 1 + 1 + 1
+	return &metricspb.ResourceMetrics{
+		Resource: svc.resource,
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope:   &commonpb.InstrumentationScope{Name: "otlp-mcp-generate"},
+			Metrics: metrics,
+		}},
+	}
+}
+
+// exponentialPoint builds a base-2 exponential histogram (scale 0) of values.
+func exponentialPoint(values []float64, start, ts uint64) *metricspb.ExponentialHistogramDataPoint {
+	dp := &metricspb.ExponentialHistogramDataPoint{
+		StartTimeUnixNano: start,
+		TimeUnixNano:      ts,
+		Scale:             0,
+		Positive:          &metricspb.ExponentialHistogramDataPoint_Buckets{},
+	}
+	if len(values) == 0 {
+		return dp
+	}
+
+	var sum float64
+	minIdx, maxIdx := math.MaxInt32, math.MinInt32
+	indexes := make([]int, len(values))
+	for i, v := range values {
+		sum += v
+		if v <= 0 {
+			dp.ZeroCount++
+			indexes[i] = math.MinInt32
+			continue
+		}
+		// Bucket i covers (2^i, 2^(i+1)]
+		idx := int(math.Ceil(math.Log2(v))) - 1
+		indexes[i] = idx
+		minIdx, maxIdx = min(minIdx, idx), max(maxIdx, idx)
+	}
+	dp.Count = uint64(len(values))
+	dp.Sum = &sum
+	if maxIdx >= minIdx {
+		counts := make([]uint64, maxIdx-minIdx+1)
+		for _, idx := range indexes {
+			if idx != math.MinInt32 {
+				counts[idx-minIdx]++
+			}
+		}
+		dp.Positive.Offset = int32(minIdx)
+		dp.Positive.BucketCounts = counts
+	}
+	return dp
+}
+
+// summaryPoint builds a summary with p50/p90/p99 quantiles of values.
+func summaryPoint(values []float64, start, ts uint64) *metricspb.SummaryDataPoint {
+	dp := &metricspb.SummaryDataPoint{StartTimeUnixNano: start, TimeUnixNano: ts}
+	if len(values) == 0 {
+		return dp
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for _, v := range sorted {
+		dp.Sum += v
+	}
+	dp.Count = uint64(len(sorted))
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+		dp.QuantileValues = append(dp.QuantileValues, &metricspb.SummaryDataPoint_ValueAtQuantile{
+			Quantile: q,
+			Value:    sorted[max(idx, 0)],
+		})
+	}
+	return dp
+}
+
+// groupSpans wraps spans in one ResourceSpans per service.
+func (g *Generator) groupSpans(spans []spanRecord) []*tracepb.ResourceSpans {
+	byService := make(map[int][]*tracepb.Span)
+	var order []int
+	for _, s := range spans {
+		if _, ok := byService[s.service]; !ok {
+			order = append(order, s.service)
+		}
+		byService[s.service] = append(byService[s.service], s.span)
+	}
+	out := make([]*tracepb.ResourceSpans, 0, len(order))
+	for _, idx := range order {
+		out = append(out, &tracepb.ResourceSpans{
+			Resource: g.services[idx].resource,
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Scope: &commonpb.InstrumentationScope{Name: "otlp-mcp-generate"},
+				Spans: byService[idx],
+			}},
+		})
+	}
+	return out
+}
+
+// groupLogs wraps log records in one ResourceLogs per service.
+func (g *Generator) groupLogs(logs []logRecord) []*logspb.ResourceLogs {
+	byService := make(map[int][]*logspb.LogRecord)
+	var order []int
+	for _, l := range logs {
+		if _, ok := byService[l.service]; !ok {
+			order = append(order, l.service)
+		}
+		byService[l.service] = append(byService[l.service], l.rec)
+	}
+	out := make([]*logspb.ResourceLogs, 0, len(order))
+	for _, idx := range order {
+		out = append(out, &logspb.ResourceLogs{
+			Resource: g.services[idx].resource,
+			ScopeLogs: []*logspb.ScopeLogs{{
+				Scope:      &commonpb.InstrumentationScope{Name: "otlp-mcp-generate"},
+				LogRecords: byService[idx],
+			}},
+		})
+	}
+	return out
+}
+
+// Run generates traces at the configured rate and exports them until the
+// Duration or Count limit is reached or ctx is cancelled. Traces are sent in
+// batches every tick (100ms) to keep per-export overhead low at high rates,
+// split into exports of at most maxExportItems spans or log records. A zero
+// rate generates all Count traces in the first tick.
+// onTick, if set, is called after each export round with running totals.
+func (g *Generator) Run(ctx context.Context, exp otlpclient.Exporter, onTick func(Stats)) (Stats, error) {
+	const tick = 100 * time.Millisecond
+	if g.cfg.Rate == 0 && g.cfg.Count == 0 {
+		return g.stats, fmt.Errorf("a rate of 0 (as fast as possible) needs a count")
+	}
+
+	startedAt := time.Now()
+	var deadline time.Time
+	if g.cfg.Duration > 0 {
+		deadline = startedAt.Add(g.cfg.Duration)
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var owed float64 // fractional traces carried between ticks
+	for {
+		now := time.Now()
+		owed += g.cfg.Rate * tick.Seconds()
+		n := int(owed)
+		owed -= float64(n)
+		if g.cfg.Rate == 0 {
+			n = g.cfg.Count
+		}
+		if g.cfg.Count > 0 {
+			n = min(n, g.cfg.Count-g.stats.Traces)
+		}
+
+		var batch Batch
+		var pending int // spans and log records in batch
+		for i := 0; i < n; i++ {
+			before := g.stats.Spans + g.stats.Logs
+			tb := g.NextTrace(now)
+			batch.Spans = append(batch.Spans, tb.Spans...)
+			batch.Logs = append(batch.Logs, tb.Logs...)
+			// Send full batches as they fill so --rate 0 doesn't hold the whole run
+			if pending += g.stats.Spans + g.stats.Logs - before; pending >= maxExportItems {
+				if err := export(ctx, exp, batch); err != nil {
+					return g.stats, err
+				}
+				batch, pending = Batch{}, 0
+			}
+		}
+		done := (g.cfg.Count > 0 && g.stats.Traces >= g.cfg.Count) ||
+			(!deadline.IsZero() && !now.Before(deadline))
+		if done {
+			batch.Metrics = g.FlushMetrics(now)
+		} else {
+			batch.Metrics = g.MetricsDue(now)
+		}
+
+		if err := export(ctx, exp, batch); err != nil {
+			return g.stats, err
+		}
+		if onTick != nil {
+			onTick(g.stats)
+		}
+		if done {
+			return g.stats, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return g.stats, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// export sends a batch in requests of at most maxExportItems spans, log
+// records or metrics each.
+func export(ctx context.Context, exp otlpclient.Exporter, b Batch) error {
+	for _, spans := range splitSpans(b.Spans, maxExportItems) {
+		if err := exp.ExportSpans(ctx, spans); err != nil {
+			return fmt.Errorf("export spans: %w", err)
+		}
+	}
+	for _, logs := range splitLogs(b.Logs, maxExportItems) {
+		if err := exp.ExportLogs(ctx, logs); err != nil {
+			return fmt.Errorf("export logs: %w", err)
+		}
+	}
+	for _, metrics := range splitMetrics(b.Metrics, maxExportItems) {
+		if err := exp.ExportMetrics(ctx, metrics); err != nil {
+			return fmt.Errorf("export metrics: %w", err)
+		}
+	}
+	return nil
+}
+
+// splitSpans divides resource spans into groups of at most limit spans,
+// splitting a scope's spans across groups where needed.
+func splitSpans(in []*tracepb.ResourceSpans, limit int) [][]*tracepb.ResourceSpans {
+	var out [][]*tracepb.ResourceSpans
+	var cur []*tracepb.ResourceSpans
+	room := limit
+	for _, rs := range in {
+		for _, ss := range rs.ScopeSpans {
+			for spans := ss.Spans; len(spans) > 0; {
+				if room == 0 {
+					out, cur, room = append(out, cur), nil, limit
+				}
+				part := spans[:min(room, len(spans))]
+				spans = spans[len(part):]
+				room -= len(part)
+				cur = append(cur, &tracepb.ResourceSpans{
+					Resource:   rs.Resource,
+					SchemaUrl:  rs.SchemaUrl,
+					ScopeSpans: []*tracepb.ScopeSpans{{Scope: ss.Scope, SchemaUrl: ss.SchemaUrl, Spans: part}},
+				})
+			}
+		}
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// splitLogs divides resource logs into groups of at most limit log records,
+// splitting a scope's records across groups where needed.
+func splitLogs(in []*logspb.ResourceLogs, limit int) [][]*logspb.ResourceLogs {
+	var out [][]*logspb.ResourceLogs
+	var cur []*logspb.ResourceLogs
+	room := limit
+	for _, rl := range in {
+		for _, sl := range rl.ScopeLogs {
+			for records := sl.LogRecords; len(records) > 0; {
+				if room == 0 {
+					out, cur, room = append(out, cur), nil, limit
+				}
+				part := records[:min(room, len(records))]
+				records = records[len(part):]
+				room -= len(part)
+				cur = append(cur, &logspb.ResourceLogs{
+					Resource:  rl.Resource,
+					SchemaUrl: rl.SchemaUrl,
+					ScopeLogs: []*logspb.ScopeLogs{{Scope: sl.Scope, SchemaUrl: sl.SchemaUrl, LogRecords: part}},
+				})
+			}
+		}
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// splitMetrics divides resource metrics into groups of at most limit
+// metrics. A service's report is small and never split.
+func splitMetrics(in []*metricspb.ResourceMetrics, limit int) [][]*metricspb.ResourceMetrics {
+	var out [][]*metricspb.ResourceMetrics
+	var cur []*metricspb.ResourceMetrics
+	count := 0
+	for _, rm := range in {
+		n := 0
+		for _, sm := range rm.ScopeMetrics {
+			n += len(sm.Metrics)
+		}
+		if len(cur) > 0 && count+n > limit {
+			out, cur, count = append(out, cur), nil, 0
+		}
+		cur = append(cur, rm)
+		count += n
+	}
+	if len(cur) > 0 {
+		out = append(out, cur)
+	}
+	return out
+}
+
+func (g *Generator) randomBytes(n int) []byte {
+	b := make([]byte, n)
+	g.rng.Read(b)
+	return b
+}
+
+func stringAttr(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttr(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
@@ -0,0 +1,302 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	collectorlogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectortracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// storageExporter feeds generated telemetry straight into storage.
+type storageExporter struct {
+	store *storage.ObservabilityStorage
+}
+
+func (e storageExporter) ExportSpans(ctx context.Context, rs []*tracepb.ResourceSpans) error {
+	return e.store.ReceiveSpans(ctx, rs)
+}
+
+func (e storageExporter) ExportLogs(ctx context.Context, rl []*logspb.ResourceLogs) error {
+	return e.store.ReceiveLogs(ctx, rl)
+}
+
+func (e storageExporter) ExportMetrics(ctx context.Context, rm []*metricspb.ResourceMetrics) error {
+	return e.store.ReceiveMetrics(ctx, rm)
+}
+
+func (e storageExporter) Close() error { return nil }
+
+func TestNewValidates(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"error rate":   {ErrorRate: 1.5},
+		"distribution": {LatencyDist: "pareto"},
+		"fan-out":      {FanOut: -1},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNextTraceStructure(t *testing.T) {
+	gen, err := New(Config{Services: 4, FanOut: 3, Depth: 4, ErrorRate: 0.5, LogsPerSpan: 2, Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	end := time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+	batch := gen.NextTrace(end)
+
+	var spans []*tracepb.Span
+	for _, rs := range batch.Spans {
+		for _, ss := range rs.ScopeSpans {
+			spans = append(spans, ss.Spans...)
+		}
+	}
+	if len(spans) < 2 {
+		t.Fatalf("expected a multi-span trace, got %d spans", len(spans))
+	}
+
+	ids := make(map[string]bool)
+	var roots int
+	var latest uint64
+	for _, s := range spans {
+		ids[string(s.SpanId)] = true
+		if len(s.ParentSpanId) == 0 {
+			roots++
+		}
+		if !bytes.Equal(s.TraceId, spans[0].TraceId) {
+			t.Error("all spans should share one trace ID")
+		}
+		if s.EndTimeUnixNano < s.StartTimeUnixNano {
+			t.Errorf("span %s ends before it starts", s.Name)
+		}
+		latest = max(latest, s.EndTimeUnixNano)
+	}
+	if roots != 1 {
+		t.Errorf("expected exactly one root span, got %d", roots)
+	}
+	for _, s := range spans {
+		if len(s.ParentSpanId) > 0 && !ids[string(s.ParentSpanId)] {
+			t.Errorf("span %s has a parent outside the trace", s.Name)
+		}
+	}
+	if latest != uint64(end.UnixNano()) {
+		t.Errorf("trace should end at %v, ended at %v", end, time.Unix(0, int64(latest)))
+	}
+
+	// Logs are correlated with spans of this trace
+	for _, rl := range batch.Logs {
+		for _, sl := range rl.ScopeLogs {
+			for _, rec := range sl.LogRecords {
+				if !bytes.Equal(rec.TraceId, spans[0].TraceId) || !ids[string(rec.SpanId)] {
+					t.Error("log record should reference a span in the trace")
+				}
+			}
+		}
+	}
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	names := func() []string {
+		gen, _ := New(Config{Services: 6, Seed: 42})
+		var out []string
+		for _, rs := range gen.NextTrace(time.Now()).Spans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					out = append(out, s.Name)
+				}
+			}
+		}
+		return out
+	}
+	a, b := names(), names()
+	if len(a) != len(b) {
+		t.Fatalf("same seed produced %d and %d spans", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("span %d differs: %q vs %q", i, a[i], b[i])
+		}
+	}
+}
+
+func TestRunProducesEveryMetricType(t *testing.T) {
+	gen, err := New(Config{Services: 3, ErrorRate: 0.2, MetricInterval: time.Second, Count: 20, Rate: 1000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := storage.NewObservabilityStorage(1000, 1000, 1000)
+	stats, err := gen.Run(context.Background(), storageExporter{store}, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if stats.Traces != 20 {
+		t.Errorf("expected 20 traces, got %d", stats.Traces)
+	}
+
+	all := store.Stats()
+	if all.Traces.SpanCount != stats.Spans {
+		t.Errorf("stored %d spans, generated %d", all.Traces.SpanCount, stats.Spans)
+	}
+
+	ms := store.Metrics()
+	for _, typ := range []storage.MetricType{
+		storage.MetricTypeGauge,
+		storage.MetricTypeSum,
+		storage.MetricTypeHistogram,
+		storage.MetricTypeExponentialHistogram,
+		storage.MetricTypeSummary,
+	} {
+		if len(ms.GetMetricsByType(typ)) == 0 {
+			t.Errorf("no %v metrics generated", typ)
+		}
+	}
+	if got := len(ms.GetMetricsByService("frontend")); got == 0 {
+		t.Error("expected metrics from the frontend service")
+	}
+}
+
+// sizeExporter records the size of every export request.
+type sizeExporter struct {
+	spanExports, logExports []int // items per request
+	spans, logs             int
+	largest                 int // bytes
+}
+
+func (e *sizeExporter) ExportSpans(ctx context.Context, rs []*tracepb.ResourceSpans) error {
+	n := 0
+	for _, r := range rs {
+		for _, ss := range r.ScopeSpans {
+			n += len(ss.Spans)
+		}
+	}
+	e.spanExports = append(e.spanExports, n)
+	e.spans += n
+	e.largest = max(e.largest, proto.Size(&collectortracepb.ExportTraceServiceRequest{ResourceSpans: rs}))
+	return nil
+}
+
+func (e *sizeExporter) ExportLogs(ctx context.Context, rl []*logspb.ResourceLogs) error {
+	n := 0
+	for _, r := range rl {
+		for _, sl := range r.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	e.logExports = append(e.logExports, n)
+	e.logs += n
+	e.largest = max(e.largest, proto.Size(&collectorlogspb.ExportLogsServiceRequest{ResourceLogs: rl}))
+	return nil
+}
+
+func (e *sizeExporter) ExportMetrics(ctx context.Context, rm []*metricspb.ResourceMetrics) error {
+	return nil
+}
+
+func (e *sizeExporter) Close() error { return nil }
+
+func TestRunSplitsLargeBatches(t *testing.T) {
+	// A zero rate needs a count to stop at
+	gen, err := New(Config{Services: 5, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gen.Run(context.Background(), &sizeExporter{}, nil); err == nil {
+		t.Error("expected an error for a zero rate without a count")
+	}
+
+	// With one, everything falls due in the first tick
+	gen, err = New(Config{Services: 5, FanOut: 3, Depth: 4, LogsPerSpan: 1, Count: 2000, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &sizeExporter{}
+	stats, err := gen.Run(context.Background(), exp, nil)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if stats.Traces != 2000 {
+		t.Errorf("expected all 2000 traces, got %d", stats.Traces)
+	}
+	if len(exp.spanExports) < 2 || len(exp.logExports) < 2 {
+		t.Fatalf("expected several span and log exports, got %d and %d", len(exp.spanExports), len(exp.logExports))
+	}
+	for _, n := range append(exp.spanExports, exp.logExports...) {
+		if n > maxExportItems {
+			t.Errorf("export of %d items exceeds %d", n, maxExportItems)
+		}
+	}
+	if exp.largest >= 4*1024*1024 {
+		t.Errorf("largest export is %d bytes, over the gRPC limit", exp.largest)
+	}
+	if exp.spans != stats.Spans || exp.logs != stats.Logs {
+		t.Errorf("exported %d spans and %d logs, generated %d and %d", exp.spans, exp.logs, stats.Spans, stats.Logs)
+	}
+}
+
+func TestSplitSpans(t *testing.T) {
+	spans := func(n int) []*tracepb.Span { return make([]*tracepb.Span, n) }
+	in := []*tracepb.ResourceSpans{
+		{ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans(3)}}},
+		{ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans(6)}}},
+	}
+	var sizes []int
+	for _, group := range splitSpans(in, 4) {
+		n := 0
+		for _, rs := range group {
+			n += len(rs.ScopeSpans[0].Spans)
+		}
+		sizes = append(sizes, n)
+	}
+	if want := []int{4, 4, 1}; !slices.Equal(sizes, want) {
+		t.Errorf("expected groups of %v, got %v", want, sizes)
+	}
+}
+
+func TestHistogramSumPerReport(t *testing.T) {
+	gen, err := New(Config{Services: 1, MetricInterval: time.Second, Seed: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	histogram := func(rms []*metricspb.ResourceMetrics) *metricspb.HistogramDataPoint {
+		for _, m := range rms[0].ScopeMetrics[0].Metrics {
+			if h := m.GetHistogram(); h != nil {
+				return h.DataPoints[0]
+			}
+		}
+		t.Fatal("no histogram reported")
+		return nil
+	}
+
+	now := time.Now()
+	gen.NextTrace(now)
+	first := histogram(gen.FlushMetrics(now))
+	firstSum := first.GetSum()
+	if first.Sum == &gen.services[0].histSum {
+		t.Fatal("reported Sum aliases the running sum")
+	}
+
+	gen.NextTrace(now.Add(time.Second))
+	second := histogram(gen.FlushMetrics(now.Add(time.Second)))
+	if second.GetSum() <= firstSum {
+		t.Errorf("expected the cumulative Sum to grow, got %v then %v", firstSum, second.GetSum())
+	}
+	if first.GetSum() != firstSum {
+		t.Errorf("first report's Sum changed from %v to %v", firstSum, first.GetSum())
+	}
+	if first.Sum == second.Sum {
+		t.Error("consecutive reports share a Sum pointer")
+	}
+}
@@ -12,13 +12,23 @@ import (
 )
 
 // registerResources registers all MCP resources and resource templates.
+// A read-only server has no receiver or file sources to describe.
 func (s *Server) registerResources() {
-	s.mcpServer.AddResource(&mcp.Resource{
-		URI:         "otlp://endpoint",
-		Name:        "endpoint",
-		Description: "OTLP gRPC endpoint address, active ports, and environment variable suggestions.",
-		MIMEType:    "application/json",
-	}, s.handleEndpointResource)
+	if !s.readOnly {
+		s.mcpServer.AddResource(&mcp.Resource{
+			URI:         "otlp://endpoint",
+			Name:        "endpoint",
+			Description: "OTLP gRPC endpoint address, active ports and sockets, and environment variable suggestions.",
+			MIMEType:    "application/json",
+		}, s.handleEndpointResource)
+
+		s.mcpServer.AddResource(&mcp.Resource{
+			URI:         "otlp://file-sources",
+			Name:        "file-sources",
+			Description: "Active filesystem directories being watched for OTLP files, with any time window.",
+			MIMEType:    "application/json",
+		}, s.handleFileSourcesResource)
+	}
 
 	s.mcpServer.AddResource(&mcp.Resource{
 		URI:         "otlp://stats",
@@ -37,17 +47,10 @@ func (s *Server) registerResources() {
 	s.mcpServer.AddResource(&mcp.Resource{
 		URI:         "otlp://snapshots",
 		Name:        "snapshots",
-		Description: "All snapshots with timestamps and buffer positions.",
+		Description: "All snapshots with timestamps, buffer positions, annotations, pins and whether their data has been evicted.",
 		MIMEType:    "application/json",
 	}, s.handleSnapshotsResource)
 
-	s.mcpServer.AddResource(&mcp.Resource{
-		URI:         "otlp://file-sources",
-		Name:        "file-sources",
-		Description: "Active filesystem directories being watched for OTLP JSONL.",
-		MIMEType:    "application/json",
-	}, s.handleFileSourcesResource)
-
 	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
 		URITemplate: "otlp://services/{service}",
 		Name:        "service-detail",
@@ -58,7 +61,7 @@ func (s *Server) registerResources() {
 	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
 		URITemplate: "otlp://snapshots/{name}",
 		Name:        "snapshot-detail",
-		Description: "Metadata for a specific snapshot: creation time and buffer positions.",
+		Description: "Metadata for a specific snapshot: creation time, buffer positions, pin and whether its data has been evicted.",
 		MIMEType:    "application/json",
 	}, s.handleSnapshotDetailResource)
 }
@@ -69,14 +72,18 @@ func (s *Server) handleEndpointResource(
 	ctx context.Context,
 	req *mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, error) {
+	endpoint := s.otlpReceiver.Endpoint()
 	data := map[string]any{
-		"endpoint":  s.otlpReceiver.Endpoint(),
+		"endpoint":  endpoint,
 		"protocol":  "grpc",
 		"endpoints": s.otlpReceiver.Endpoints(),
-		"env": map[string]string{
-			"OTEL_EXPORTER_OTLP_ENDPOINT": s.otlpReceiver.Endpoint(),
-			"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
-		},
+		"env":       otlpEnvVars(endpoint),
+	}
+	if sockets := s.socketEndpoints(); len(sockets) > 0 {
+		data["sockets"] = sockets
+	}
+	if receivers := s.collectorReceivers(); len(receivers) > 0 {
+		data["collector_receivers"] = receivers
 	}
 	return jsonResult(req.Params.URI, data)
 }
@@ -91,17 +98,20 @@ func (s *Server) handleStatsResource(
 			"count":    stats.Traces.SpanCount,
 			"capacity": stats.Traces.Capacity,
 			"distinct": stats.Traces.TraceCount,
+			"evicted":  stats.Traces.Evicted,
 		},
 		"logs": map[string]any{
 			"count":      stats.Logs.LogCount,
 			"capacity":   stats.Logs.Capacity,
 			"severities": stats.Logs.Severities,
+			"evicted":    stats.Logs.Evicted,
 		},
 		"metrics": map[string]any{
 			"count":        stats.Metrics.MetricCount,
 			"capacity":     stats.Metrics.Capacity,
 			"unique_names": stats.Metrics.UniqueNames,
 			"types":        stats.Metrics.TypeCounts,
+			"evicted":      stats.Metrics.Evicted,
 		},
 		"snapshots": stats.Snapshots,
 	}
@@ -124,18 +134,10 @@ func (s *Server) handleSnapshotsResource(
 	ctx context.Context,
 	req *mcp.ReadResourceRequest,
 ) (*mcp.ReadResourceResult, error) {
-	names := s.storage.Snapshots().List()
-	snapshots := make([]map[string]any, 0, len(names))
-	for _, name := range names {
-		snap, err := s.storage.Snapshots().Get(name)
-		if err != nil {
-			continue
-		}
-		snapshots = append(snapshots, map[string]any{
-			"name":       snap.Name,
-			"created_at": snap.CreatedAt.Format("2006-01-02T15:04:05Z"),
-			"positions":  map[string]int{"traces": snap.TracePos, "logs": snap.LogPos, "metrics": snap.MetricPos},
-		})
+	statuses := s.storage.SnapshotStatuses()
+	snapshots := make([]map[string]any, 0, len(statuses))
+	for _, status := range statuses {
+		snapshots = append(snapshots, snapshotResource(status))
 	}
 	data := map[string]any{
 		"snapshots": snapshots,
@@ -209,17 +211,33 @@ func (s *Server) handleSnapshotDetailResource(
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
 
-	snap, err := s.storage.Snapshots().Get(name)
+	status, err := s.storage.SnapshotStatus(name)
 	if err != nil {
 		return nil, mcp.ResourceNotFoundError(req.Params.URI)
 	}
+	return jsonResult(req.Params.URI, snapshotResource(status))
+}
 
+// snapshotResource describes a snapshot with its annotations, pin and
+// range validity.
+func snapshotResource(status storage.SnapshotStatus) map[string]any {
 	data := map[string]any{
-		"name":       snap.Name,
-		"created_at": snap.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		"positions":  map[string]int{"traces": snap.TracePos, "logs": snap.LogPos, "metrics": snap.MetricPos},
+		"name":       status.Name,
+		"created_at": status.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		"positions":  map[string]int{"traces": status.TracePos, "logs": status.LogPos, "metrics": status.MetricPos},
+		"pinned":     status.Pinned,
+		"validity":   toRangeValidity(&status.Validity),
 	}
-	return jsonResult(req.Params.URI, data)
+	if status.Description != "" {
+		data["description"] = status.Description
+	}
+	if len(status.Tags) > 0 {
+		data["tags"] = status.Tags
+	}
+	if len(status.Metadata) > 0 {
+		data["metadata"] = status.Metadata
+	}
+	return data
 }
 
 // ─── Helpers ────────────────────────────────────────────────────────────
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/tobert/otlp-mcp/internal/filereader"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
 	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
 	"github.com/tobert/otlp-mcp/internal/storage"
 )
@@ -18,38 +20,71 @@ type Server struct {
 	storage      *storage.ObservabilityStorage
 	otlpReceiver *otlpreceiver.UnifiedServer // OTLP receiver for dynamic port rebinding
 
-	// File sources - directories being watched for OTLP JSONL files
+	// File sources - directories being watched for OTLP file exporter output
 	fileSourcesMu sync.RWMutex
 	fileSources   map[string]*filereader.FileSource
 	verbose       bool
+	readOnly      bool
+
+	collector *otelconfig.Config // otel-collector config, when serving its output
 }
 
 // ServerOptions configures the MCP server.
 type ServerOptions struct {
 	Verbose bool // Enable verbose logging
+
+	// ReadOnly serves already-loaded telemetry, such as an archive opened by
+	// 'otlp-mcp analyze': no OTLP receiver is needed, tools that change
+	// listeners, file sources or stored data are not registered, and exports
+	// only write new files in the temp directory. Snapshots are bookmarks
+	// held in memory, so they can still be created, deleted and pinned.
+	ReadOnly bool
+
+	// Collector is the otel-collector config whose file output is being
+	// served. Its OTLP receivers are reported by get_otlp_endpoint, since
+	// applications send there rather than to otlp-mcp.
+	Collector *otelconfig.Config
 }
 
 // NewServer creates a new MCP server that exposes snapshot-first observability tools.
-// The otlpReceiver provides the OTLP endpoint and enables dynamic port rebinding.
+// The otlpReceiver provides the OTLP endpoint and enables dynamic port rebinding;
+// it may be nil for a read-only server.
 func NewServer(obsStorage *storage.ObservabilityStorage, otlpReceiver *otlpreceiver.UnifiedServer, opts ...ServerOptions) (*Server, error) {
 	if obsStorage == nil {
 		return nil, fmt.Errorf("observability storage cannot be nil")
 	}
 
-	if otlpReceiver == nil {
-		return nil, fmt.Errorf("OTLP receiver cannot be nil")
+	var opt ServerOptions
+	if len(opts) > 0 {
+		opt = opts[0]
 	}
 
-	var verbose bool
-	if len(opts) > 0 {
-		verbose = opts[0].Verbose
+	if otlpReceiver == nil && !opt.ReadOnly {
+		return nil, fmt.Errorf("OTLP receiver cannot be nil")
 	}
 
 	s := &Server{
 		storage:      obsStorage,
 		otlpReceiver: otlpReceiver,
 		fileSources:  make(map[string]*filereader.FileSource),
-		verbose:      verbose,
+		verbose:      opt.Verbose,
+		readOnly:     opt.ReadOnly,
+		collector:    opt.Collector,
+	}
+
+	instructions := `OpenTelemetry observability server. Captures OTLP traces, logs, and metrics in memory.
+
+Workflow: get_otlp_endpoint -> set OTEL_EXPORTER_OTLP_ENDPOINT -> run program -> query/snapshot.
+
+Tools: query (filtered search), create_snapshot/get_snapshot_data (before/after), status/recent_activity (polling).
+Resources: otlp://endpoint, otlp://stats, otlp://services, otlp://snapshots, otlp://file-sources.`
+	if opt.ReadOnly {
+		instructions = `OpenTelemetry observability server in read-only analysis mode: serving a captured telemetry archive. Nothing new arrives and data cannot be changed.
+
+Workflow: get_stats/otlp://services for an overview -> query, exceptions, flame_graph to investigate.
+
+Tools: query (filtered search), exceptions, flame_graph, export_chrome_trace, recent_activity.
+Resources: otlp://stats, otlp://services, otlp://snapshots.`
 	}
 
 	// Create MCP server with implementation metadata
@@ -58,12 +93,7 @@ func NewServer(obsStorage *storage.ObservabilityStorage, otlpReceiver *otlprecei
 		Title:   "OpenTelemetry Observability for Agents",
 		Version: "0.4.0",
 	}, &mcp.ServerOptions{
-		Instructions: `OpenTelemetry observability server. Captures OTLP traces, logs, and metrics in memory.
-
-Workflow: get_otlp_endpoint -> set OTEL_EXPORTER_OTLP_ENDPOINT -> run program -> query/snapshot.
-
-Tools: query (filtered search), create_snapshot/get_snapshot_data (before/after), status/recent_activity (polling).
-Resources: otlp://endpoint, otlp://stats, otlp://services, otlp://snapshots, otlp://file-sources.`,
+		Instructions:       instructions,
 		SubscribeHandler:   func(_ context.Context, _ *mcp.SubscribeRequest) error { return nil },
 		UnsubscribeHandler: func(_ context.Context, _ *mcp.UnsubscribeRequest) error { return nil },
 	})
@@ -101,11 +131,26 @@ func (s *Server) Shutdown() {
 	s.stopAllFileSources()
 }
 
-// AddFileSource adds a new file source that reads OTLP JSONL from a directory.
-// When activeOnly is true, only active files (e.g., traces.jsonl) are loaded,
-// skipping rotated archives (e.g., traces-2025-12-09T13-10-56.jsonl).
+// FileSourceOptions configures a file source added with AddFileSource.
+type FileSourceOptions struct {
+	// ActiveOnly loads only active files (e.g., traces.jsonl), skipping
+	// rotated archives (e.g., traces-2025-12-09T13-10-56.jsonl).
+	ActiveOnly bool
+
+	// Since and Until bound loaded records by timestamp. When either is set,
+	// rotated archives overlapping the window are loaded instead.
+	Since time.Time
+	Until time.Time
+
+	// Signals names what a single exporter file holds, for files outside
+	// the base/signal/signal.jsonl layout. Empty for directories.
+	Signals []string
+}
+
+// AddFileSource adds a new file source that reads OTLP file exporter output
+// from a directory, or from a single exporter file.
 // Returns an error if the directory is already being watched.
-func (s *Server) AddFileSource(ctx context.Context, directory string, activeOnly bool) error {
+func (s *Server) AddFileSource(ctx context.Context, directory string, opts FileSourceOptions) error {
 	s.fileSourcesMu.Lock()
 	defer s.fileSourcesMu.Unlock()
 
@@ -116,7 +161,10 @@ func (s *Server) AddFileSource(ctx context.Context, directory string, activeOnly
 	fs, err := filereader.New(filereader.Config{
 		Directory:      directory,
 		Verbose:        s.verbose,
-		ActiveOnly:     activeOnly,
+		ActiveOnly:     opts.ActiveOnly,
+		SinceTime:      opts.Since,
+		UntilTime:      opts.Until,
+		Signals:        opts.Signals,
 		SpanCapacity:   s.storage.Traces().Stats().Capacity,
 		LogCapacity:    s.storage.Logs().Stats().Capacity,
 		MetricCapacity: s.storage.Metrics().Stats().Capacity,
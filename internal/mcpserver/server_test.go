@@ -3,10 +3,18 @@ package mcpserver
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
 	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
 	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
 )
 
 // TestServerCreation verifies basic server initialization.
@@ -73,6 +81,63 @@ func TestServerCreationNilReceiver(t *testing.T) {
 	}
 }
 
+// TestServerReadOnly verifies that a read-only server needs no receiver and
+// leaves out the tools that change listeners or data or overwrite files.
+func TestServerReadOnly(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(100, 500, 1000)
+	server, err := NewServer(obsStorage, nil, ServerOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("failed to create read-only server: %v", err)
+	}
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.MCPServer().Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		names[tool.Name] = true
+	}
+	for _, name := range []string{"query", "exceptions", "get_stats", "create_snapshot"} {
+		if !names[name] {
+			t.Errorf("expected read-only tool %s", name)
+		}
+	}
+	for _, name := range []string{"get_otlp_endpoint", "add_otlp_port", "clear_data", "set_file_source"} {
+		if names[name] {
+			t.Errorf("unexpected tool %s on a read-only server", name)
+		}
+	}
+
+	// Exports never overwrite a chosen file, only create new temp files
+	obsStorage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{makeResourceSpan("frontend", "GET /")})
+	existing := filepath.Join(t.TempDir(), "keep.json")
+	if err := os.WriteFile(existing, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{OutputPath: existing}); err == nil {
+		t.Error("expected output_path to be refused on a read-only server")
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Errorf("expected the existing file untouched, got %q", data)
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	if _, output, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{}); err != nil || output.Path == "" {
+		t.Errorf("expected a default export to work read-only, got %+v, %v", output, err)
+	}
+}
+
 // TestServerToolRegistration verifies that all 8 snapshot-first tools are registered.
 func TestServerToolRegistration(t *testing.T) {
 	obsStorage := storage.NewObservabilityStorage(100, 500, 1000)
@@ -285,6 +350,17 @@ func TestRemoveOTLPPortHandler(t *testing.T) {
 		t.Errorf("expected 2 endpoints after adding, got %d", len(addOutput.Endpoints))
 	}
 
+	// The primary port can't be removed, even with another port open
+	var primaryPort int
+	fmt.Sscanf(originalEndpoint, "127.0.0.1:%d", &primaryPort)
+	_, primaryOutput, err := server.handleRemoveOTLPPort(ctx, nil, RemoveOTLPPortInput{Port: primaryPort})
+	if err != nil {
+		t.Fatalf("handleRemoveOTLPPort with primary port failed: %v", err)
+	}
+	if primaryOutput.Success {
+		t.Error("expected failure when removing the primary port, got success")
+	}
+
 	// Now remove the added port
 	_, removeOutput, err := server.handleRemoveOTLPPort(ctx, nil, RemoveOTLPPortInput{Port: newPort})
 	if err != nil {
@@ -323,3 +399,731 @@ func TestRemoveOTLPPortHandler(t *testing.T) {
 		t.Error("expected failure for non-existent port, got success")
 	}
 }
+
+// TestOTLPSocketHandlers verifies the add_otlp_socket and remove_otlp_socket tool handlers.
+func TestOTLPSocketHandlers(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	// Keep the path short; Unix socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "otlp-mcp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "otlp.sock")
+
+	_, addOutput, err := server.handleAddOTLPSocket(ctx, nil, AddOTLPSocketInput{Path: socketPath})
+	if err != nil {
+		t.Fatalf("handleAddOTLPSocket failed: %v", err)
+	}
+	if !addOutput.Success {
+		t.Fatalf("add socket failed: %s", addOutput.Message)
+	}
+	if len(addOutput.Endpoints) != 2 {
+		t.Errorf("expected 2 endpoints after adding socket, got %d", len(addOutput.Endpoints))
+	}
+
+	// Adding the same socket twice should fail
+	_, dupOutput, _ := server.handleAddOTLPSocket(ctx, nil, AddOTLPSocketInput{Path: "unix://" + socketPath})
+	if dupOutput.Success {
+		t.Error("expected failure for duplicate socket, got success")
+	}
+
+	// get_otlp_endpoint should report the socket with insecure env vars
+	_, endpointOutput, err := server.handleGetOTLPEndpoint(ctx, nil, GetOTLPEndpointInput{})
+	if err != nil {
+		t.Fatalf("handleGetOTLPEndpoint failed: %v", err)
+	}
+	if len(endpointOutput.Sockets) != 1 {
+		t.Fatalf("expected 1 socket endpoint, got %d", len(endpointOutput.Sockets))
+	}
+	sock := endpointOutput.Sockets[0]
+	if sock.Path != socketPath {
+		t.Errorf("expected socket path %s, got %s", socketPath, sock.Path)
+	}
+	if sock.EnvironmentVars["OTEL_EXPORTER_OTLP_ENDPOINT"] != "unix://"+socketPath {
+		t.Errorf("unexpected socket endpoint env: %v", sock.EnvironmentVars)
+	}
+	if sock.EnvironmentVars["OTEL_EXPORTER_OTLP_INSECURE"] != "true" {
+		t.Error("expected OTEL_EXPORTER_OTLP_INSECURE=true for socket endpoint")
+	}
+	if _, ok := endpointOutput.EnvironmentVars["OTEL_EXPORTER_OTLP_INSECURE"]; ok {
+		t.Error("TCP endpoint should not set OTEL_EXPORTER_OTLP_INSECURE")
+	}
+
+	_, removeOutput, err := server.handleRemoveOTLPSocket(ctx, nil, RemoveOTLPSocketInput{Path: socketPath})
+	if err != nil {
+		t.Fatalf("handleRemoveOTLPSocket failed: %v", err)
+	}
+	if !removeOutput.Success {
+		t.Errorf("remove socket failed: %s", removeOutput.Message)
+	}
+	if len(removeOutput.Endpoints) != 1 {
+		t.Errorf("expected 1 endpoint after removing socket, got %d", len(removeOutput.Endpoints))
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed, stat err: %v", err)
+	}
+
+	// Removing an unknown socket should fail
+	_, missingOutput, _ := server.handleRemoveOTLPSocket(ctx, nil, RemoveOTLPSocketInput{Path: socketPath})
+	if missingOutput.Success {
+		t.Error("expected failure for unknown socket, got success")
+	}
+}
+
+// TestRemovePrimarySocket verifies a primary Unix socket listener can't be
+// removed while Start is serving it.
+func TestRemovePrimarySocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "otlp-mcp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "otlp.sock")
+
+	otlpReceiver, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Host: "127.0.0.1", Socket: socketPath},
+		storage.NewObservabilityStorage(100, 500, 1000),
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP receiver: %v", err)
+	}
+	defer otlpReceiver.Stop()
+	ctx := context.Background()
+	go otlpReceiver.Start(ctx)
+
+	if err := otlpReceiver.AddPort(ctx, 0); err != nil {
+		t.Fatalf("AddPort failed: %v", err)
+	}
+	if err := otlpReceiver.RemoveSocket(socketPath); err == nil {
+		t.Error("expected an error removing the primary socket")
+	}
+	if len(otlpReceiver.Endpoints()) != 2 {
+		t.Errorf("expected both listeners to remain, got %v", otlpReceiver.Endpoints())
+	}
+}
+
+// TestSetFileSourceTimeWindow verifies since/until handling in set_file_source.
+func TestSetFileSourceTimeWindow(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "traces"), 0o755); err != nil {
+		t.Fatalf("failed to create traces dir: %v", err)
+	}
+
+	_, badOutput, err := server.handleSetFileSource(ctx, nil, SetFileSourceInput{Directory: dir, Since: "yesterday-ish"})
+	if err != nil {
+		t.Fatalf("handleSetFileSource failed: %v", err)
+	}
+	if badOutput.Success {
+		t.Error("expected failure for invalid since, got success")
+	}
+
+	_, reversedOutput, _ := server.handleSetFileSource(ctx, nil, SetFileSourceInput{Directory: dir, Since: "1h", Until: "2h"})
+	if reversedOutput.Success {
+		t.Error("expected failure when until is before since, got success")
+	}
+
+	_, output, err := server.handleSetFileSource(ctx, nil, SetFileSourceInput{
+		Directory: dir,
+		Since:     "2025-12-09T14:00:00Z",
+		Until:     "2025-12-09T15:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("handleSetFileSource failed: %v", err)
+	}
+	if !output.Success {
+		t.Fatalf("set file source failed: %s", output.Message)
+	}
+	defer server.RemoveFileSource(dir)
+
+	_, list, _ := server.handleListFileSources(ctx, nil, ListFileSourcesInput{})
+	if list.Count != 1 {
+		t.Fatalf("expected 1 file source, got %d", list.Count)
+	}
+	if list.Sources[0].Since != "2025-12-09T14:00:00Z" || list.Sources[0].Until != "2025-12-09T15:00:00Z" {
+		t.Errorf("unexpected window: since=%q until=%q", list.Sources[0].Since, list.Sources[0].Until)
+	}
+}
+
+// TestAddFileSourceExporterFile verifies loading one exporter file that
+// several collector pipelines write to.
+func TestAddFileSourceExporterFile(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "all.json")
+	data := `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"AQEBAQEBAQEBAQEBAQEBAQ==","spanId":"AQEBAQEBAQE=","name":"a","startTimeUnixNano":"1","endTimeUnixNano":"2"}]}]}]}` + "\n" +
+		`{"resourceLogs":[{"scopeLogs":[{"logRecords":[{"timeUnixNano":"1","body":{"stringValue":"hello"}}]}]}]}` + "\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("failed to write exporter file: %v", err)
+	}
+
+	if err := server.AddFileSource(ctx, path, FileSourceOptions{ActiveOnly: true, Signals: []string{"traces", "logs"}}); err != nil {
+		t.Fatalf("AddFileSource failed: %v", err)
+	}
+	defer server.RemoveFileSource(path)
+
+	stats := server.storage.Stats()
+	if stats.Traces.SpanCount != 1 || stats.Logs.LogCount != 1 {
+		t.Errorf("expected 1 span and 1 log, got %d spans and %d logs", stats.Traces.SpanCount, stats.Logs.LogCount)
+	}
+}
+
+// TestGetOTLPEndpointCollectorReceivers verifies the collector's receivers
+// are reported when serving an otel-collector config.
+func TestGetOTLPEndpointCollectorReceivers(t *testing.T) {
+	collector, err := otelconfig.Parse([]byte(`
+receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: 0.0.0.0:4318
+exporters:
+  file:
+    path: /tmp/otel/all.jsonl
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [file]
+`))
+	if err != nil {
+		t.Fatalf("parse collector config: %v", err)
+	}
+	obsStorage := storage.NewObservabilityStorage(100, 500, 1000)
+	recv, err := otlpreceiver.NewUnifiedServer(otlpreceiver.Config{Host: "127.0.0.1", Port: 0}, obsStorage)
+	if err != nil {
+		t.Fatalf("create receiver: %v", err)
+	}
+	server, err := NewServer(obsStorage, recv, ServerOptions{Collector: collector})
+	if err != nil {
+		t.Fatalf("create server: %v", err)
+	}
+
+	_, output, err := server.handleGetOTLPEndpoint(context.Background(), nil, GetOTLPEndpointInput{})
+	if err != nil {
+		t.Fatalf("handleGetOTLPEndpoint failed: %v", err)
+	}
+	if len(output.CollectorReceivers) != 1 {
+		t.Fatalf("expected 1 collector receiver, got %d", len(output.CollectorReceivers))
+	}
+	r := output.CollectorReceivers[0]
+	if r.Endpoint != "localhost:4318" || r.Protocol != "http" {
+		t.Errorf("unexpected receiver: %+v", r)
+	}
+	if r.EnvironmentVars["OTEL_EXPORTER_OTLP_ENDPOINT"] != "http://localhost:4318" || r.EnvironmentVars["OTEL_EXPORTER_OTLP_PROTOCOL"] != "http/protobuf" {
+		t.Errorf("unexpected receiver env: %v", r.EnvironmentVars)
+	}
+	if output.Note == "" {
+		t.Error("expected a note pointing applications at the collector")
+	}
+}
+
+// TestCollectorConfigHandler verifies collector_config exports to the
+// receiver's own endpoint.
+func TestCollectorConfigHandler(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, output, err := server.handleCollectorConfig(ctx, nil, CollectorConfigInput{Signals: []string{"traces"}, FileDir: "/var/otel"})
+	if err != nil {
+		t.Fatalf("handleCollectorConfig failed: %v", err)
+	}
+	endpoint := server.otlpReceiver.Endpoints()[0]
+	if output.Endpoint != endpoint {
+		t.Errorf("expected endpoint %s, got %s", endpoint, output.Endpoint)
+	}
+	if !strings.Contains(output.YAML, "endpoint: "+endpoint) || !strings.Contains(output.YAML, "/var/otel/traces/traces.jsonl") {
+		t.Errorf("unexpected YAML:\n%s", output.YAML)
+	}
+	if len(output.Pipelines) != 1 || output.Pipelines[0] != "traces" {
+		t.Errorf("expected the traces pipeline, got %v", output.Pipelines)
+	}
+	if len(result.Content) != 1 {
+		t.Errorf("expected the YAML as text content, got %d items", len(result.Content))
+	}
+
+	if _, _, err := server.handleCollectorConfig(ctx, nil, CollectorConfigInput{CollectorConfig: "/nonexistent/config.yaml"}); err == nil {
+		t.Error("expected error for a missing collector config")
+	}
+}
+
+// TestFlameGraphHandler verifies flame_graph aggregation, weights and formats.
+func TestFlameGraphHandler(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	parent := makeResourceSpan("frontend", "GET /")
+	child := makeResourceSpan("db", "query")
+	childSpan := child.ScopeSpans[0].Spans[0]
+	childSpan.SpanId = []byte{9, 9, 9, 9, 9, 9, 9, 9}
+	childSpan.ParentSpanId = parent.ScopeSpans[0].Spans[0].SpanId
+	childSpan.StartTimeUnixNano, childSpan.EndTimeUnixNano = 1_200_000_000, 1_800_000_000
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{parent, child})
+
+	result, output, err := server.handleFlameGraph(ctx, nil, FlameGraphInput{ServiceName: "db", Weight: "self"})
+	if err != nil {
+		t.Fatalf("handleFlameGraph failed: %v", err)
+	}
+	if output.TraceCount != 1 || output.SpanCount != 2 {
+		t.Errorf("expected the whole trace (1 trace, 2 spans), got %d traces, %d spans", output.TraceCount, output.SpanCount)
+	}
+	if len(output.TopFrames) != 2 || output.TopFrames[0].Path != "frontend.GET /;db.query" {
+		t.Errorf("expected db.query to have the most self time, got %+v", output.TopFrames)
+	}
+	if output.TopFrames[0].SelfNs != 600_000_000 {
+		t.Errorf("expected 600ms self time for db.query, got %d", output.TopFrames[0].SelfNs)
+	}
+	if len(result.Content) != 1 || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "Top self time") {
+		t.Errorf("expected self-weighted text rendering, got %+v", result.Content)
+	}
+
+	_, folded, err := server.handleFlameGraph(ctx, nil, FlameGraphInput{Format: "folded"})
+	if err != nil {
+		t.Fatalf("handleFlameGraph failed: %v", err)
+	}
+	if folded.Folded != "frontend.GET / 400000\nfrontend.GET /;db.query 600000\n" {
+		t.Errorf("unexpected folded output: %q", folded.Folded)
+	}
+
+	if _, _, err := server.handleFlameGraph(ctx, nil, FlameGraphInput{Weight: "heaviest"}); err == nil {
+		t.Error("expected error for unknown weight")
+	}
+}
+
+func TestQueryVizFormat(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	parent := makeResourceSpan("frontend", "GET /")
+	child := makeResourceSpan("db", "query")
+	childSpan := child.ScopeSpans[0].Spans[0]
+	childSpan.SpanId = []byte{9, 9, 9, 9, 9, 9, 9, 9}
+	childSpan.ParentSpanId = parent.ScopeSpans[0].Spans[0].SpanId
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{parent, child})
+
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{"", "frontend"},
+		{"mermaid_sequence", "```mermaid\nsequenceDiagram\n"},
+		{"mermaid_gantt", "```mermaid\ngantt\n"},
+		{"mermaid_flowchart", `s1 -->|"1 call"| s0`},
+		{"dot", "```dot\ndigraph services {\n"},
+	}
+	for _, tc := range testCases {
+		result, _, err := server.handleQuery(ctx, nil, QueryInput{VizFormat: tc.format})
+		if err != nil {
+			t.Fatalf("handleQuery(%q) failed: %v", tc.format, err)
+		}
+		if len(result.Content) != 1 {
+			t.Fatalf("expected one content item for %q, got %d", tc.format, len(result.Content))
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, tc.want) {
+			t.Errorf("viz_format %q: expected %q in:\n%s", tc.format, tc.want, text)
+		}
+	}
+
+	if _, _, err := server.handleQuery(ctx, nil, QueryInput{VizFormat: "svg"}); err == nil {
+		t.Error("expected error for unknown viz_format")
+	}
+}
+
+func TestExportChromeTraceHandler(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	if _, _, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{}); err == nil {
+		t.Error("expected error with no traces")
+	}
+
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{makeResourceSpan("frontend", "GET /")})
+	path := filepath.Join(t.TempDir(), "trace.json")
+	_, output, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{ServiceName: "frontend", OutputPath: path})
+	if err != nil {
+		t.Fatalf("handleExportChromeTrace failed: %v", err)
+	}
+	if output.Path != path || output.TraceCount != 1 || output.SpanCount != 1 {
+		t.Errorf("unexpected output: %+v", output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(data)) != output.Bytes || !strings.Contains(string(data), `"traceEvents"`) {
+		t.Errorf("unexpected file contents: %s", data)
+	}
+
+	// Without a path each export gets a new file in the temp directory
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	_, first, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{ServiceName: "frontend"})
+	if err != nil {
+		t.Fatalf("handleExportChromeTrace failed: %v", err)
+	}
+	_, second, err := server.handleExportChromeTrace(ctx, nil, ExportChromeTraceInput{ServiceName: "frontend"})
+	if err != nil {
+		t.Fatalf("handleExportChromeTrace failed: %v", err)
+	}
+	if filepath.Dir(first.Path) != tmp || first.Path == second.Path {
+		t.Errorf("expected two new files in %s, got %s and %s", tmp, first.Path, second.Path)
+	}
+}
+
+func TestExceptionsHandler(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	str := func(k, v string) *commonpb.KeyValue {
+		return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+	}
+	failed := makeResourceSpan("api", "checkout")
+	failed.ScopeSpans[0].Spans[0].Events = []*tracepb.Span_Event{{
+		Name:         "exception",
+		TimeUnixNano: 1_500_000_000,
+		Attributes: []*commonpb.KeyValue{
+			str("exception.type", "java.io.IOException"),
+			str("exception.message", "disk full"),
+			str("exception.stacktrace", "java.io.IOException: disk full\n\tat com.shop.Store.save(Store.java:42)"),
+		},
+	}}
+	plain := makeResourceSpan("api", "list")
+	plain.ScopeSpans[0].Spans[0].SpanId = []byte{9, 9, 9, 9, 9, 9, 9, 9}
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{failed, plain})
+
+	result, output, err := server.handleExceptions(ctx, nil, ExceptionsInput{ExceptionType: "IOException"})
+	if err != nil {
+		t.Fatalf("handleExceptions failed: %v", err)
+	}
+	if output.GroupCount != 1 || output.ExceptionCount != 1 {
+		t.Fatalf("expected one exception in one group, got %+v", output)
+	}
+	g := output.Groups[0]
+	if g.Type != "java.io.IOException" || g.TopFrame != "com.shop.Store.save(Store.java:42)" || g.Message != "disk full" {
+		t.Errorf("unexpected group: %+v", g)
+	}
+	if len(result.Content) != 1 || !strings.Contains(result.Content[0].(*mcp.TextContent).Text, "java.io.IOException") {
+		t.Errorf("expected text rendering, got %+v", result.Content)
+	}
+
+	_, query, err := server.handleQuery(ctx, nil, QueryInput{EventName: "exception"})
+	if err != nil {
+		t.Fatalf("handleQuery failed: %v", err)
+	}
+	if len(query.Traces) != 1 || len(query.Traces[0].Events) != 1 || query.Traces[0].Events[0].Attributes["exception.type"] != "java.io.IOException" {
+		t.Errorf("expected the failing span with its exception event, got %+v", query.Traces)
+	}
+}
+
+func TestQueryFollowLinks(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	producer := makeResourceSpan("api", "publish")
+	pub := producer.ScopeSpans[0].Spans[0]
+	consumer := makeResourceSpan("worker", "process")
+	con := consumer.ScopeSpans[0].Spans[0]
+	con.TraceId = []byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+	con.SpanId = []byte{9, 9, 9, 9, 9, 9, 9, 9}
+	con.Links = []*tracepb.Span_Link{{TraceId: pub.TraceId, SpanId: pub.SpanId}}
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{producer, consumer})
+
+	producerID := fmt.Sprintf("%x", pub.TraceId)
+	consumerID := fmt.Sprintf("%x", con.TraceId)
+
+	_, plain, err := server.handleQuery(ctx, nil, QueryInput{TraceID: producerID})
+	if err != nil {
+		t.Fatalf("handleQuery failed: %v", err)
+	}
+	if len(plain.Traces) != 1 || len(plain.LinkedTraces) != 1 || plain.LinkedTraces[0].FromTraceID != consumerID {
+		t.Errorf("expected the producer span and one incoming link, got %+v", plain)
+	}
+
+	result, followed, err := server.handleQuery(ctx, nil, QueryInput{TraceID: producerID, FollowLinks: true})
+	if err != nil {
+		t.Fatalf("handleQuery failed: %v", err)
+	}
+	if len(followed.Traces) != 2 || followed.Traces[1].SpanName != "process" || followed.Summary.TraceCount != 2 {
+		t.Errorf("expected the consumer trace to be included, got %+v", followed.Traces)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "worker.process links to trace "+producerID[:6]) {
+		t.Errorf("expected the link in the waterfall:\n%s", text)
+	}
+
+	_, linked, err := server.handleQuery(ctx, nil, QueryInput{LinkedTraceID: producerID})
+	if err != nil {
+		t.Fatalf("handleQuery failed: %v", err)
+	}
+	if len(linked.Traces) != 1 || linked.Traces[0].TraceID != consumerID || linked.Traces[0].Links[0].TraceID != producerID {
+		t.Errorf("expected the consumer span with its link, got %+v", linked.Traces)
+	}
+}
+
+// TestSetBufferSizeHandler verifies set_buffer_size resizes buffers,
+// keeps the newest spans and leaves snapshots usable.
+func TestSetBufferSizeHandler(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(4, 500, 1000)
+
+	otlpReceiver, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Host: "127.0.0.1", Port: 0},
+		obsStorage,
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP receiver: %v", err)
+	}
+	defer otlpReceiver.Stop()
+
+	server, err := NewServer(obsStorage, otlpReceiver)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	addSpans := func(names ...string) {
+		for _, name := range names {
+			obsStorage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{{
+				ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+					TraceId: []byte{1}, SpanId: []byte{1}, Name: name,
+				}}}},
+			}})
+		}
+	}
+	addSpans("a", "b")
+	if err := obsStorage.CreateSnapshot("before"); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	addSpans("c", "d")
+
+	if _, _, err := server.handleSetBufferSize(ctx, nil, SetBufferSizeInput{}); err == nil {
+		t.Error("expected an error with no sizes")
+	}
+	if _, _, err := server.handleSetBufferSize(ctx, nil, SetBufferSizeInput{Traces: MaxBufferSize + 1}); err == nil {
+		t.Error("expected an error for an oversized buffer")
+	}
+
+	_, output, err := server.handleSetBufferSize(ctx, nil, SetBufferSizeInput{Traces: 3, Logs: 1000})
+	if err != nil {
+		t.Fatalf("handleSetBufferSize failed: %v", err)
+	}
+	if output.Traces == nil || output.Traces.PreviousCapacity != 4 || output.Traces.Capacity != 3 || output.Traces.Dropped != 1 {
+		t.Errorf("unexpected trace resize: %+v", output.Traces)
+	}
+	if output.Logs == nil || output.Logs.Capacity != 1000 {
+		t.Errorf("unexpected log resize: %+v", output.Logs)
+	}
+	if output.Metrics != nil {
+		t.Errorf("metrics were not asked to change: %+v", output.Metrics)
+	}
+
+	_, stats, _ := server.handleGetStats(ctx, nil, GetStatsInput{})
+	if stats.Traces.Capacity != 3 || stats.Traces.SpanCount != 3 || stats.Traces.Evicted != 1 {
+		t.Errorf("unexpected trace stats after resize: %+v", stats.Traces)
+	}
+
+	// The snapshot taken before the resize still finds the spans after it
+	addSpans("e")
+	data, err := obsStorage.GetSnapshotData("before", "")
+	if err != nil {
+		t.Fatalf("get snapshot data: %v", err)
+	}
+	var names []string
+	for _, span := range data.Traces {
+		names = append(names, span.SpanName)
+	}
+	if strings.Join(names, ",") != "c,d,e" {
+		t.Errorf("expected spans c,d,e after the snapshot, got %v", names)
+	}
+}
+
+func TestManageSnapshotsValidityAndPin(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(4, 500, 1000)
+
+	otlpReceiver, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Host: "127.0.0.1", Port: 0},
+		obsStorage,
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP receiver: %v", err)
+	}
+	defer otlpReceiver.Stop()
+
+	server, err := NewServer(obsStorage, otlpReceiver)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	addSpans := func(n int) {
+		for i := 0; i < n; i++ {
+			obsStorage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{{
+				ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+					TraceId: []byte{1}, SpanId: []byte{1}, Name: "span",
+				}}}},
+			}})
+		}
+	}
+
+	if _, _, err := server.handleCreateSnapshot(ctx, nil, CreateSnapshotInput{Name: "lost"}); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	addSpans(5)
+	_, created, err := server.handleCreateSnapshot(ctx, nil, CreateSnapshotInput{Name: "kept", Pin: true, PinBudget: 4})
+	if err != nil {
+		t.Fatalf("create pinned snapshot: %v", err)
+	}
+	if !created.Pinned {
+		t.Error("expected the snapshot to be pinned")
+	}
+	addSpans(8)
+
+	_, list, err := server.handleManageSnapshots(ctx, nil, ManageSnapshotsInput{Action: "list"})
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(list.Details) != 2 {
+		t.Fatalf("expected 2 snapshots, got %+v", list.Details)
+	}
+	if d := list.Details[0]; d.Name != "lost" || d.Validity.Status != storage.RangeEvicted || d.Validity.LostSpans != 5 {
+		t.Errorf("unexpected validity for 'lost': %+v %+v", d, d.Validity)
+	}
+	if d := list.Details[1]; d.Name != "kept" || !d.Pinned || d.Validity.Status != storage.RangeIntact {
+		t.Errorf("unexpected validity for 'kept': %+v %+v", d, d.Validity)
+	}
+
+	_, data, err := server.handleGetSnapshotData(ctx, nil, GetSnapshotDataInput{StartSnapshot: "lost"})
+	if err != nil {
+		t.Fatalf("get snapshot data: %v", err)
+	}
+	if data.Summary.Validity == nil || data.Summary.Validity.Status != storage.RangePartial {
+		t.Errorf("expected partial validity in snapshot data, got %+v", data.Summary.Validity)
+	}
+
+	if _, _, err := server.handleManageSnapshots(ctx, nil, ManageSnapshotsInput{Action: "unpin", Name: "kept"}); err != nil {
+		t.Fatalf("unpin: %v", err)
+	}
+	if capacity := obsStorage.Traces().Stats().Capacity; capacity != 4 {
+		t.Errorf("expected capacity 4 after unpin, got %d", capacity)
+	}
+	if _, _, err := server.handleManageSnapshots(ctx, nil, ManageSnapshotsInput{Action: "pin"}); err == nil {
+		t.Error("expected an error pinning without a name")
+	}
+}
+
+func TestSnapshotAnnotations(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(100, 100, 100)
+
+	otlpReceiver, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Host: "127.0.0.1", Port: 0},
+		obsStorage,
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP receiver: %v", err)
+	}
+	defer otlpReceiver.Stop()
+
+	server, err := NewServer(obsStorage, otlpReceiver)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+
+	ctx := context.Background()
+	_, created, err := server.handleCreateSnapshot(ctx, nil, CreateSnapshotInput{
+		Name:        "baseline",
+		Description: "before the cache change",
+		Tags:        []string{"baseline", " perf "},
+		Metadata:    map[string]string{"git_sha": "abc123", "test_name": "TestLoad"},
+	})
+	if err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	if len(created.Tags) != 2 || created.Tags[1] != "perf" {
+		t.Errorf("expected trimmed tags, got %v", created.Tags)
+	}
+	time.Sleep(time.Millisecond)
+	if _, _, err := server.handleCreateSnapshot(ctx, nil, CreateSnapshotInput{Name: "untagged"}); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+
+	_, list, err := server.handleManageSnapshots(ctx, nil, ManageSnapshotsInput{Action: "list", Tag: "perf"})
+	if err != nil {
+		t.Fatalf("list snapshots: %v", err)
+	}
+	if len(list.Snapshots) != 1 || list.Snapshots[0] != "baseline" {
+		t.Fatalf("expected only 'baseline' tagged perf, got %v", list.Snapshots)
+	}
+	if d := list.Details[0]; d.Description != "before the cache change" || d.Metadata["git_sha"] != "abc123" {
+		t.Errorf("unexpected details: %+v", d)
+	}
+
+	_, data, err := server.handleGetSnapshotData(ctx, nil, GetSnapshotDataInput{StartSnapshot: "baseline"})
+	if err != nil {
+		t.Fatalf("get snapshot data: %v", err)
+	}
+	if len(data.Annotations) != 2 || data.Annotations[0].Name != "baseline" || data.Annotations[1].Name != "untagged" {
+		t.Fatalf("expected annotations baseline and untagged, got %+v", data.Annotations)
+	}
+	if data.Annotations[0].Metadata["test_name"] != "TestLoad" {
+		t.Errorf("expected metadata in annotation, got %+v", data.Annotations[0])
+	}
+}
+
+func TestExportSnapshotHandler(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	if _, _, err := server.handleExportSnapshot(ctx, nil, ExportSnapshotInput{StartSnapshot: "missing"}); err == nil {
+		t.Error("expected error for an unknown snapshot")
+	}
+
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{makeResourceSpan("frontend", "before")})
+	if err := server.storage.CreateSnapshot("start"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{makeResourceSpan("frontend", "GET /")})
+
+	path := filepath.Join(t.TempDir(), "snapshot.jsonl")
+	_, output, err := server.handleExportSnapshot(ctx, nil, ExportSnapshotInput{StartSnapshot: "start", OutputPath: path})
+	if err != nil {
+		t.Fatalf("handleExportSnapshot failed: %v", err)
+	}
+	if output.Path != path || output.SpanCount != 1 || output.LogCount != 0 {
+		t.Errorf("unexpected output: %+v", output)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(data)) != output.Bytes || !strings.Contains(string(data), `"GET /"`) || strings.Contains(string(data), `"before"`) {
+		t.Errorf("unexpected file contents: %s", data)
+	}
+}
+
+func TestExportSnapshotHandlerDefaultPath(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	// The snapshot name must not pick where the default file goes
+	if err := server.storage.CreateSnapshot("../escape/x"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	server.storage.ReceiveSpans(ctx, []*tracepb.ResourceSpans{makeResourceSpan("frontend", "GET /")})
+
+	_, output, err := server.handleExportSnapshot(ctx, nil, ExportSnapshotInput{StartSnapshot: "../escape/x"})
+	if err != nil {
+		t.Fatalf("handleExportSnapshot failed: %v", err)
+	}
+	if filepath.Dir(output.Path) != tmp || !strings.HasSuffix(output.Path, ".jsonl") {
+		t.Errorf("expected a new file in %s, got %s", tmp, output.Path)
+	}
+	if _, err := os.Stat(output.Path); err != nil {
+		t.Error(err)
+	}
+}
@@ -3,12 +3,18 @@ package mcpserver
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/tobert/otlp-mcp/internal/chrometrace"
+	"github.com/tobert/otlp-mcp/internal/filereader"
+	"github.com/tobert/otlp-mcp/internal/otelconfig"
+	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
 	"github.com/tobert/otlp-mcp/internal/storage"
 	"github.com/tobert/otlp-mcp/internal/viz"
-	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -18,6 +24,7 @@ import (
 // 1. get_otlp_endpoint - Get the unified OTLP endpoint (one for all signals)
 // 2. add_otlp_port - Add listening ports dynamically (multi-port support)
 // 3. remove_otlp_port - Remove ports when no longer needed
+//    (add_otlp_socket/remove_otlp_socket do the same for Unix sockets)
 // 4. create_snapshot - Bookmark current state across all buffers
 // 5. query - Multi-signal query with optional snapshot time range
 // 6. get_snapshot_data - Get all signals between two snapshots
@@ -37,6 +44,24 @@ type GetOTLPEndpointOutput struct {
 	Endpoint        string            `json:"endpoint" jsonschema:"OTLP gRPC endpoint address (accepts traces, logs, and metrics)"`
 	Protocol        string            `json:"protocol" jsonschema:"Protocol type (grpc)"`
 	EnvironmentVars map[string]string `json:"environment_vars" jsonschema:"Suggested environment variables for configuring applications"`
+	Sockets         []SocketEndpoint  `json:"sockets,omitempty" jsonschema:"Unix domain socket endpoints, with their own environment variables"`
+
+	CollectorReceivers []CollectorReceiver `json:"collector_receivers,omitempty" jsonschema:"OTLP receivers of the otel-collector whose file output is loaded; applications send here"`
+	Note               string              `json:"note,omitempty" jsonschema:"Which endpoint applications should use"`
+}
+
+type CollectorReceiver struct {
+	Name            string            `json:"name" jsonschema:"Receiver ID in the collector config (e.g. otlp)"`
+	Protocol        string            `json:"protocol" jsonschema:"grpc or http"`
+	Endpoint        string            `json:"endpoint" jsonschema:"Address applications on this host connect to"`
+	Signals         []string          `json:"signals" jsonschema:"Signals the collector pipelines accept from this receiver"`
+	EnvironmentVars map[string]string `json:"environment_vars" jsonschema:"Environment variables for exporting to this receiver"`
+}
+
+type SocketEndpoint struct {
+	Endpoint        string            `json:"endpoint" jsonschema:"Socket endpoint (unix:///path)"`
+	Path            string            `json:"path" jsonschema:"Socket file path"`
+	EnvironmentVars map[string]string `json:"environment_vars" jsonschema:"Environment variables for exporting over this socket"`
 }
 
 func (s *Server) handleGetOTLPEndpoint(
@@ -45,14 +70,65 @@ func (s *Server) handleGetOTLPEndpoint(
 	input GetOTLPEndpointInput,
 ) (*mcp.CallToolResult, GetOTLPEndpointOutput, error) {
 	endpoint := s.otlpReceiver.Endpoint()
-	return &mcp.CallToolResult{}, GetOTLPEndpointOutput{
-		Endpoint: endpoint,
-		Protocol: "grpc",
-		EnvironmentVars: map[string]string{
-			"OTEL_EXPORTER_OTLP_ENDPOINT": endpoint,
-			"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
-		},
-	}, nil
+	output := GetOTLPEndpointOutput{
+		Endpoint:           endpoint,
+		Protocol:           "grpc",
+		EnvironmentVars:    otlpEnvVars(endpoint),
+		Sockets:            s.socketEndpoints(),
+		CollectorReceivers: s.collectorReceivers(),
+	}
+	if len(output.CollectorReceivers) > 0 {
+		output.Note = "Telemetry is read from the otel-collector's file exporters: point applications at a collector receiver, not at otlp-mcp."
+	}
+	return &mcp.CallToolResult{}, output, nil
+}
+
+// collectorReceivers lists the OTLP receivers of the collector config being
+// served, if any.
+func (s *Server) collectorReceivers() []CollectorReceiver {
+	if s.collector == nil {
+		return nil
+	}
+	receivers := make([]CollectorReceiver, 0, len(s.collector.Receivers))
+	for _, r := range s.collector.Receivers {
+		receivers = append(receivers, CollectorReceiver{
+			Name:            r.Name,
+			Protocol:        r.Protocol,
+			Endpoint:        r.ClientEndpoint(),
+			Signals:         r.Signals,
+			EnvironmentVars: r.EnvVars(),
+		})
+	}
+	return receivers
+}
+
+// socketEndpoints lists the receiver's Unix socket listeners.
+func (s *Server) socketEndpoints() []SocketEndpoint {
+	var sockets []SocketEndpoint
+	for _, ep := range s.otlpReceiver.Endpoints() {
+		if !otlpreceiver.IsSocketEndpoint(ep) {
+			continue
+		}
+		sockets = append(sockets, SocketEndpoint{
+			Endpoint:        ep,
+			Path:            otlpreceiver.SocketPath(ep),
+			EnvironmentVars: otlpEnvVars(ep),
+		})
+	}
+	return sockets
+}
+
+// otlpEnvVars returns the exporter environment variables for an endpoint.
+// Socket endpoints have no TLS, so exporters must also be told to connect insecurely.
+func otlpEnvVars(endpoint string) map[string]string {
+	env := map[string]string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT": endpoint,
+		"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+	}
+	if otlpreceiver.IsSocketEndpoint(endpoint) {
+		env["OTEL_EXPORTER_OTLP_INSECURE"] = "true"
+	}
+	return env
 }
 
 // add_otlp_port
@@ -141,18 +217,173 @@ func (s *Server) handleRemoveOTLPPort(
 	}, nil
 }
 
+// add_otlp_socket
+
+type AddOTLPSocketInput struct {
+	Path string `json:"path" jsonschema:"Unix socket path (e.g. /run/otlp-mcp/otlp.sock or unix:///run/otlp-mcp/otlp.sock)"`
+}
+
+type AddOTLPSocketOutput struct {
+	Endpoints []string `json:"endpoints" jsonschema:"All active OTLP endpoint addresses"`
+	Success   bool     `json:"success" jsonschema:"Whether socket addition succeeded"`
+	Message   string   `json:"message,omitempty" jsonschema:"Additional information or error message"`
+}
+
+func (s *Server) handleAddOTLPSocket(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input AddOTLPSocketInput,
+) (*mcp.CallToolResult, AddOTLPSocketOutput, error) {
+	if input.Path == "" {
+		return &mcp.CallToolResult{}, AddOTLPSocketOutput{
+			Endpoints: s.otlpReceiver.Endpoints(),
+			Success:   false,
+			Message:   "path is required",
+		}, nil
+	}
+
+	if err := s.otlpReceiver.AddSocket(ctx, input.Path); err != nil {
+		return &mcp.CallToolResult{}, AddOTLPSocketOutput{
+			Endpoints: s.otlpReceiver.Endpoints(),
+			Success:   false,
+			Message:   fmt.Sprintf("failed to add socket: %v", err),
+		}, nil
+	}
+
+	endpoints := s.otlpReceiver.Endpoints()
+	return &mcp.CallToolResult{}, AddOTLPSocketOutput{
+		Endpoints: endpoints,
+		Success:   true,
+		Message:   fmt.Sprintf("successfully added socket %s - now listening on %d endpoints", otlpreceiver.SocketPath(input.Path), len(endpoints)),
+	}, nil
+}
+
+// remove_otlp_socket
+
+type RemoveOTLPSocketInput struct {
+	Path string `json:"path" jsonschema:"Unix socket path to remove"`
+}
+
+type RemoveOTLPSocketOutput struct {
+	Endpoints []string `json:"endpoints" jsonschema:"Remaining active OTLP endpoint addresses"`
+	Success   bool     `json:"success" jsonschema:"Whether socket removal succeeded"`
+	Message   string   `json:"message,omitempty" jsonschema:"Additional information or error message"`
+}
+
+func (s *Server) handleRemoveOTLPSocket(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input RemoveOTLPSocketInput,
+) (*mcp.CallToolResult, RemoveOTLPSocketOutput, error) {
+	if input.Path == "" {
+		return &mcp.CallToolResult{}, RemoveOTLPSocketOutput{
+			Endpoints: s.otlpReceiver.Endpoints(),
+			Success:   false,
+			Message:   "path is required",
+		}, nil
+	}
+
+	if err := s.otlpReceiver.RemoveSocket(input.Path); err != nil {
+		return &mcp.CallToolResult{}, RemoveOTLPSocketOutput{
+			Endpoints: s.otlpReceiver.Endpoints(),
+			Success:   false,
+			Message:   fmt.Sprintf("failed to remove socket: %v", err),
+		}, nil
+	}
+
+	endpoints := s.otlpReceiver.Endpoints()
+	return &mcp.CallToolResult{}, RemoveOTLPSocketOutput{
+		Endpoints: endpoints,
+		Success:   true,
+		Message:   fmt.Sprintf("successfully removed socket %s - now listening on %d endpoints", otlpreceiver.SocketPath(input.Path), len(endpoints)),
+	}, nil
+}
+
+// collector_config
+
+type CollectorConfigInput struct {
+	Endpoint             string   `json:"endpoint,omitempty" jsonschema:"OTLP endpoint the collector should export to (default: this server's first OTLP endpoint)"`
+	Signals              []string `json:"signals,omitempty" jsonschema:"Signals to send: traces, logs, metrics (default: all)"`
+	CollectorConfig      string   `json:"collector_config,omitempty" jsonschema:"Path to the collector's config.yaml; its pipelines get the new exporters appended (default: the --otel-config being served, if any)"`
+	FileDir              string   `json:"file_dir,omitempty" jsonschema:"Also add file exporters writing the traces/logs/metrics layout under this directory, for set_file_source"`
+	RotationMaxMegabytes int      `json:"rotation_max_megabytes,omitempty" jsonschema:"File exporter rotation: maximum file size in MB"`
+	RotationMaxDays      int      `json:"rotation_max_days,omitempty" jsonschema:"File exporter rotation: days to keep rotated files"`
+	RotationMaxBackups   int      `json:"rotation_max_backups,omitempty" jsonschema:"File exporter rotation: number of rotated files to keep"`
+}
+
+type CollectorConfigOutput struct {
+	YAML      string   `json:"yaml" jsonschema:"Collector config fragment with exporters and service.pipelines"`
+	Endpoint  string   `json:"endpoint" jsonschema:"OTLP endpoint the fragment exports to"`
+	Exporters []string `json:"exporters" jsonschema:"Exporter IDs added"`
+	Pipelines []string `json:"pipelines" jsonschema:"Pipelines the exporters are added to"`
+}
+
+func (s *Server) handleCollectorConfig(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input CollectorConfigInput,
+) (*mcp.CallToolResult, CollectorConfigOutput, error) {
+	endpoint := input.Endpoint
+	if endpoint == "" {
+		endpoints := s.otlpReceiver.Endpoints()
+		if len(endpoints) == 0 {
+			return nil, CollectorConfigOutput{}, fmt.Errorf("the OTLP receiver is not listening: use add_otlp_port first, or pass endpoint")
+		}
+		endpoint = endpoints[0]
+	}
+
+	base := s.collector
+	if input.CollectorConfig != "" {
+		var err error
+		if base, err = otelconfig.Load(input.CollectorConfig); err != nil {
+			return nil, CollectorConfigOutput{}, err
+		}
+	}
+
+	snippet, err := otelconfig.NewSnippet(otelconfig.SnippetOptions{
+		Endpoint: endpoint,
+		Signals:  input.Signals,
+		Base:     base,
+		FileDir:  input.FileDir,
+		Rotation: otelconfig.Rotation{
+			MaxMegabytes: input.RotationMaxMegabytes,
+			MaxDays:      input.RotationMaxDays,
+			MaxBackups:   input.RotationMaxBackups,
+		},
+	})
+	if err != nil {
+		return nil, CollectorConfigOutput{}, fmt.Errorf("failed to generate collector config: %w", err)
+	}
+
+	// The YAML itself is the most useful text for a client to show.
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: snippet.YAML}}}
+	return result, CollectorConfigOutput{
+		YAML:      snippet.YAML,
+		Endpoint:  endpoint,
+		Exporters: snippet.Exporters,
+		Pipelines: snippet.Pipelines,
+	}, nil
+}
+
 // create_snapshot
 
 type CreateSnapshotInput struct {
-	Name string `json:"name" jsonschema:"Snapshot name (e.g. 'before-deploy', 'test-start')"`
+	Name        string            `json:"name" jsonschema:"Snapshot name (e.g. 'before-deploy', 'test-start')"`
+	Description string            `json:"description,omitempty" jsonschema:"What this snapshot marks (e.g. 'restarted api with cache disabled')"`
+	Tags        []string          `json:"tags,omitempty" jsonschema:"Tags to find it by with manage_snapshots list (e.g. 'baseline', 'deploy')"`
+	Metadata    map[string]string `json:"metadata,omitempty" jsonschema:"Arbitrary key/values such as git_sha or test_name"`
+	Pin         bool              `json:"pin,omitempty" jsonschema:"Keep the data after this snapshot from being evicted: full buffers grow instead, up to pin_budget"`
+	PinBudget   int               `json:"pin_budget,omitempty" jsonschema:"With pin: most items each buffer may grow by (default: its capacity)"`
 }
 
 type CreateSnapshotOutput struct {
-	Name      string `json:"name" jsonschema:"Snapshot name"`
-	TracePos  int    `json:"trace_position" jsonschema:"Current trace buffer position"`
-	LogPos    int    `json:"log_position" jsonschema:"Current log buffer position"`
-	MetricPos int    `json:"metric_position" jsonschema:"Current metric buffer position"`
-	Message   string `json:"message" jsonschema:"Success message"`
+	Name      string   `json:"name" jsonschema:"Snapshot name"`
+	TracePos  int      `json:"trace_position" jsonschema:"Current trace buffer position"`
+	LogPos    int      `json:"log_position" jsonschema:"Current log buffer position"`
+	MetricPos int      `json:"metric_position" jsonschema:"Current metric buffer position"`
+	Pinned    bool     `json:"pinned,omitempty" jsonschema:"Whether the snapshot is pinned"`
+	Tags      []string `json:"tags,omitempty" jsonschema:"Tags as stored (trimmed, without duplicates)"`
+	Message   string   `json:"message" jsonschema:"Success message"`
 }
 
 func (s *Server) handleCreateSnapshot(
@@ -163,11 +394,28 @@ func (s *Server) handleCreateSnapshot(
 	if input.Name == "" {
 		return nil, CreateSnapshotOutput{}, fmt.Errorf("snapshot name cannot be empty")
 	}
+	if input.PinBudget < 0 || input.PinBudget > MaxBufferSize {
+		return nil, CreateSnapshotOutput{}, fmt.Errorf("pin_budget %d must be between 0 and %d", input.PinBudget, MaxBufferSize)
+	}
 
 	err := s.storage.CreateSnapshot(input.Name)
 	if err != nil {
 		return nil, CreateSnapshotOutput{}, fmt.Errorf("failed to create snapshot: %w", err)
 	}
+	// Don't leave a half-made snapshot behind, such as one missing its pin
+	if input.Description != "" || len(input.Tags) > 0 || len(input.Metadata) > 0 {
+		err := s.storage.Snapshots().Annotate(input.Name, input.Description, input.Tags, input.Metadata)
+		if err != nil {
+			_ = s.storage.DeleteSnapshot(input.Name)
+			return nil, CreateSnapshotOutput{}, fmt.Errorf("failed to annotate snapshot: %w", err)
+		}
+	}
+	if input.Pin {
+		if err := s.storage.PinSnapshot(input.Name, input.PinBudget); err != nil {
+			_ = s.storage.DeleteSnapshot(input.Name)
+			return nil, CreateSnapshotOutput{}, fmt.Errorf("failed to pin snapshot: %w", err)
+		}
+	}
 
 	// Get the snapshot we just created to return its positions
 	snap, err := s.storage.Snapshots().Get(input.Name)
@@ -177,12 +425,18 @@ func (s *Server) handleCreateSnapshot(
 
 	_ = s.mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "otlp://snapshots"})
 
+	message := fmt.Sprintf("Created snapshot '%s' at current buffer positions", input.Name)
+	if snap.Pinned {
+		message += " (pinned)"
+	}
 	return &mcp.CallToolResult{}, CreateSnapshotOutput{
 		Name:      snap.Name,
 		TracePos:  snap.TracePos,
 		LogPos:    snap.LogPos,
 		MetricPos: snap.MetricPos,
-		Message:   fmt.Sprintf("Created snapshot '%s' at current buffer positions", input.Name),
+		Pinned:    snap.Pinned,
+		Tags:      snap.Tags,
+		Message:   message,
 	}, nil
 }
 
@@ -210,13 +464,32 @@ type QueryInput struct {
 	// Attribute filters (NEW)
 	HasAttribute    string            `json:"has_attribute,omitempty" jsonschema:"Filter spans/logs that have this attribute key (e.g., 'http.status_code')"`
 	AttributeEquals map[string]string `json:"attribute_equals,omitempty" jsonschema:"Filter by attribute key-value pairs (e.g., {'http.status_code': '500'})"`
+
+	// Span event filters
+	EventName     string `json:"event_name,omitempty" jsonschema:"Only spans with an event of this name ('exception' for any recorded exception)"`
+	ExceptionType string `json:"exception_type,omitempty" jsonschema:"Only spans that recorded this exception type, qualified or not (e.g. 'IOException' matches 'java.io.IOException')"`
+
+	// Span link traversal
+	LinkedTraceID string `json:"linked_trace_id,omitempty" jsonschema:"Only spans connected to this trace by a span link, in either direction (e.g. the consumers of a message published in it)"`
+	FollowLinks   bool   `json:"follow_links,omitempty" jsonschema:"With trace_id: also return every span of the traces reachable through span links in both directions (producer/consumer chains, up to 3 hops)"`
+
+	VizFormat string `json:"viz_format,omitempty" jsonschema:"Visualization format for the text output: waterfall (default, ASCII), mermaid_sequence or mermaid_gantt (one diagram per trace), mermaid_flowchart or dot (service dependency graph)"`
 }
 
 type QueryOutput struct {
-	Traces  []TraceSummary  `json:"traces" jsonschema:"Matching trace spans"`
-	Logs    []LogSummary    `json:"logs" jsonschema:"Matching log records"`
-	Metrics []MetricSummary `json:"metrics" jsonschema:"Matching metrics"`
-	Summary QuerySummary    `json:"summary" jsonschema:"Query result summary"`
+	Traces       []TraceSummary     `json:"traces" jsonschema:"Matching trace spans"`
+	Logs         []LogSummary       `json:"logs" jsonschema:"Matching log records"`
+	Metrics      []MetricSummary    `json:"metrics" jsonschema:"Matching metrics"`
+	LinkedTraces []TraceLinkSummary `json:"linked_traces,omitempty" jsonschema:"With trace_id: span links connecting it to other traces, followed in both directions"`
+	Summary      QuerySummary       `json:"summary" jsonschema:"Query result summary"`
+}
+
+type TraceLinkSummary struct {
+	FromTraceID string `json:"from_trace_id" jsonschema:"Trace of the span carrying the link (typically the consumer)"`
+	FromSpanID  string `json:"from_span_id" jsonschema:"Span carrying the link"`
+	ToTraceID   string `json:"to_trace_id" jsonschema:"Trace of the linked span (typically the producer)"`
+	ToSpanID    string `json:"to_span_id" jsonschema:"Linked span"`
+	Depth       int    `json:"depth" jsonschema:"Hops from the queried trace (1 = direct link)"`
 }
 
 type QuerySummary struct {
@@ -225,6 +498,30 @@ type QuerySummary struct {
 	MetricCount int      `json:"metric_count" jsonschema:"Number of metrics returned"`
 	Services    []string `json:"services" jsonschema:"Distinct services in results"`
 	TraceIDs    []string `json:"trace_ids" jsonschema:"Distinct trace IDs in results"`
+
+	Validity *RangeValidity `json:"validity,omitempty" jsonschema:"With start_snapshot: whether the buffers still hold the whole snapshot range"`
+}
+
+// RangeValidity reports whether data in a snapshot range has been evicted
+// from the ring buffers, which makes results silently incomplete.
+type RangeValidity struct {
+	Status      string `json:"status" jsonschema:"intact (all data buffered), partial (oldest data evicted) or evicted (all data gone)"`
+	LostSpans   int    `json:"lost_spans" jsonschema:"Spans in the range no longer buffered"`
+	LostLogs    int    `json:"lost_logs" jsonschema:"Logs in the range no longer buffered"`
+	LostMetrics int    `json:"lost_metrics" jsonschema:"Metrics in the range no longer buffered"`
+}
+
+// toRangeValidity converts storage range validity for output.
+func toRangeValidity(v *storage.RangeValidity) *RangeValidity {
+	if v == nil {
+		return nil
+	}
+	return &RangeValidity{
+		Status:      v.Status,
+		LostSpans:   v.LostSpans,
+		LostLogs:    v.LostLogs,
+		LostMetrics: v.LostMetrics,
+	}
 }
 
 func (s *Server) handleQuery(
@@ -250,6 +547,9 @@ func (s *Server) handleQuery(
 		MaxDurationNs:   input.MaxDurationNs,
 		HasAttribute:    input.HasAttribute,
 		AttributeEquals: input.AttributeEquals,
+		EventName:       input.EventName,
+		ExceptionType:   input.ExceptionType,
+		LinkedTraceID:   strings.ToLower(input.LinkedTraceID),
 	}
 
 	result, err := s.storage.Query(filter)
@@ -283,11 +583,42 @@ func (s *Server) handleQuery(
 			MetricCount: result.Summary.MetricCount,
 			Services:    result.Summary.Services,
 			TraceIDs:    result.Summary.TraceIDs,
+			Validity:    toRangeValidity(result.Summary.Validity),
 		},
 	}
 
+	// Span links from the queried trace, optionally with the linked traces' spans
+	if input.TraceID != "" {
+		linked := make(map[string]bool)
+		for _, link := range s.storage.FollowLinks(input.TraceID, 0) {
+			output.LinkedTraces = append(output.LinkedTraces, TraceLinkSummary{
+				FromTraceID: link.FromTraceID,
+				FromSpanID:  link.FromSpanID,
+				ToTraceID:   link.ToTraceID,
+				ToSpanID:    link.ToSpanID,
+				Depth:       link.Depth,
+			})
+			for _, id := range []string{link.FromTraceID, link.ToTraceID} {
+				if !input.FollowLinks || id == input.TraceID || linked[id] {
+					continue
+				}
+				linked[id] = true
+				output.Summary.TraceIDs = append(output.Summary.TraceIDs, id)
+				for _, span := range s.storage.Traces().GetSpansByTraceID(id) {
+					output.Traces = append(output.Traces, spanToTraceSummary(span))
+				}
+			}
+		}
+		output.Summary.TraceCount = len(output.Traces)
+	}
+	traces = output.Traces
+
+	vizText, err := buildTraceViz(traces, input.VizFormat)
+	if err != nil {
+		return nil, QueryOutput{}, err
+	}
 	toolResult := &mcp.CallToolResult{}
-	if vizText := buildTraceViz(traces); vizText != "" {
+	if vizText != "" {
 		toolResult.Content = buildVizContent(vizText, output)
 	}
 
@@ -299,6 +630,7 @@ func (s *Server) handleQuery(
 type GetSnapshotDataInput struct {
 	StartSnapshot string `json:"start_snapshot" jsonschema:"Start snapshot name"`
 	EndSnapshot   string `json:"end_snapshot,omitempty" jsonschema:"End snapshot name (empty = current)"`
+	VizFormat     string `json:"viz_format,omitempty" jsonschema:"Visualization format for the text output: waterfall (default, ASCII), mermaid_sequence or mermaid_gantt (one diagram per trace), mermaid_flowchart or dot (service dependency graph)"`
 }
 
 type GetSnapshotDataOutput struct {
@@ -309,6 +641,33 @@ type GetSnapshotDataOutput struct {
 	Logs          []LogSummary    `json:"logs" jsonschema:"All logs in time range"`
 	Metrics       []MetricSummary `json:"metrics" jsonschema:"All metrics in time range"`
 	Summary       DataSummary     `json:"summary" jsonschema:"Data summary"`
+
+	Annotations []SnapshotAnnotation `json:"annotations" jsonschema:"Snapshots taken within the range, including its start and end, oldest first: what happened when"`
+}
+
+type SnapshotAnnotation struct {
+	Name        string            `json:"name" jsonschema:"Snapshot name"`
+	CreatedAt   string            `json:"created_at" jsonschema:"Creation time (RFC3339)"`
+	Description string            `json:"description,omitempty" jsonschema:"What the snapshot marks"`
+	Tags        []string          `json:"tags,omitempty" jsonschema:"Snapshot tags"`
+	Metadata    map[string]string `json:"metadata,omitempty" jsonschema:"Snapshot key/values"`
+	TracePos    int               `json:"trace_position" jsonschema:"Trace buffer position when it was taken"`
+	LogPos      int               `json:"log_position" jsonschema:"Log buffer position when it was taken"`
+	MetricPos   int               `json:"metric_position" jsonschema:"Metric buffer position when it was taken"`
+}
+
+// snapshotAnnotation converts a snapshot for output as an annotation.
+func snapshotAnnotation(snap *storage.Snapshot) SnapshotAnnotation {
+	return SnapshotAnnotation{
+		Name:        snap.Name,
+		CreatedAt:   snap.CreatedAt.Format("2006-01-02T15:04:05.999Z07:00"),
+		Description: snap.Description,
+		Tags:        snap.Tags,
+		Metadata:    snap.Metadata,
+		TracePos:    snap.TracePos,
+		LogPos:      snap.LogPos,
+		MetricPos:   snap.MetricPos,
+	}
 }
 
 type TimeRange struct {
@@ -325,6 +684,7 @@ type DataSummary struct {
 	TraceIDs      []string       `json:"trace_ids" jsonschema:"Distinct trace IDs"`
 	LogSeverities map[string]int `json:"log_severities" jsonschema:"Log severity counts"`
 	MetricNames   []string       `json:"metric_names" jsonschema:"Distinct metric names"`
+	Validity      *RangeValidity `json:"validity,omitempty" jsonschema:"Whether the buffers still hold the whole snapshot range"`
 }
 
 func (s *Server) handleGetSnapshotData(
@@ -357,6 +717,11 @@ func (s *Server) handleGetSnapshotData(
 		metrics[i] = metricToSummary(metric)
 	}
 
+	annotations := make([]SnapshotAnnotation, len(data.Annotations))
+	for i, snap := range data.Annotations {
+		annotations[i] = snapshotAnnotation(snap)
+	}
+
 	output := GetSnapshotDataOutput{
 		StartSnapshot: data.StartSnapshot,
 		EndSnapshot:   data.EndSnapshot,
@@ -376,11 +741,17 @@ func (s *Server) handleGetSnapshotData(
 			TraceIDs:      data.Summary.TraceIDs,
 			LogSeverities: data.Summary.LogSeverities,
 			MetricNames:   data.Summary.MetricNames,
+			Validity:      toRangeValidity(data.Summary.Validity),
 		},
+		Annotations: annotations,
 	}
 
+	vizText, err := buildTraceViz(traces, input.VizFormat)
+	if err != nil {
+		return nil, GetSnapshotDataOutput{}, err
+	}
 	toolResult := &mcp.CallToolResult{}
-	if vizText := buildTraceViz(traces); vizText != "" {
+	if vizText != "" {
 		toolResult.Content = buildVizContent(vizText, output)
 	}
 
@@ -390,14 +761,40 @@ func (s *Server) handleGetSnapshotData(
 // manage_snapshots
 
 type ManageSnapshotsInput struct {
-	Action string `json:"action" jsonschema:"Action: 'list', 'delete', or 'clear'"`
-	Name   string `json:"name,omitempty" jsonschema:"Snapshot name (required for 'delete')"`
+	Action    string `json:"action" jsonschema:"Action: 'list', 'delete', 'clear', 'pin' or 'unpin'"`
+	Name      string `json:"name,omitempty" jsonschema:"Snapshot name (required for 'delete', 'pin' and 'unpin')"`
+	PinBudget int    `json:"pin_budget,omitempty" jsonschema:"For 'pin': most items each buffer may grow by to keep the data (default: its capacity)"`
+	Tag       string `json:"tag,omitempty" jsonschema:"For 'list': only snapshots with this tag"`
 }
 
 type ManageSnapshotsOutput struct {
-	Action    string   `json:"action" jsonschema:"Action performed"`
-	Snapshots []string `json:"snapshots,omitempty" jsonschema:"List of snapshot names (for 'list')"`
-	Message   string   `json:"message" jsonschema:"Status message"`
+	Action    string         `json:"action" jsonschema:"Action performed"`
+	Snapshots []string       `json:"snapshots,omitempty" jsonschema:"List of snapshot names, oldest first (for 'list')"`
+	Details   []SnapshotInfo `json:"details,omitempty" jsonschema:"Each snapshot with its annotations, pin and validity (for 'list')"`
+	Message   string         `json:"message" jsonschema:"Status message"`
+}
+
+type SnapshotInfo struct {
+	Name        string            `json:"name" jsonschema:"Snapshot name"`
+	CreatedAt   string            `json:"created_at" jsonschema:"Creation time (RFC3339)"`
+	Description string            `json:"description,omitempty" jsonschema:"What the snapshot marks"`
+	Tags        []string          `json:"tags,omitempty" jsonschema:"Snapshot tags"`
+	Metadata    map[string]string `json:"metadata,omitempty" jsonschema:"Snapshot key/values"`
+	Pinned      bool              `json:"pinned,omitempty" jsonschema:"Whether the data after it is kept from eviction"`
+	Validity    *RangeValidity    `json:"validity" jsonschema:"Whether the data from this snapshot up to the next one (or now) is still buffered"`
+}
+
+// snapshotInfo converts a snapshot and its validity for output.
+func snapshotInfo(status storage.SnapshotStatus) SnapshotInfo {
+	return SnapshotInfo{
+		Name:        status.Name,
+		CreatedAt:   status.CreatedAt.Format("2006-01-02T15:04:05.999Z07:00"),
+		Description: status.Description,
+		Tags:        status.Tags,
+		Metadata:    status.Metadata,
+		Pinned:      status.Pinned,
+		Validity:    toRangeValidity(&status.Validity),
+	}
 }
 
 func (s *Server) handleManageSnapshots(
@@ -407,18 +804,40 @@ func (s *Server) handleManageSnapshots(
 ) (*mcp.CallToolResult, ManageSnapshotsOutput, error) {
 	switch input.Action {
 	case "list":
-		snapshots := s.storage.Snapshots().List()
+		// Validity is computed over all snapshots, then filtered, so each
+		// range still ends at the next snapshot whatever its tags
+		var names []string
+		var details []SnapshotInfo
+		damaged := 0
+		for _, status := range s.storage.SnapshotStatuses() {
+			if input.Tag != "" && !status.HasTag(input.Tag) {
+				continue
+			}
+			names = append(names, status.Name)
+			details = append(details, snapshotInfo(status))
+			if status.Validity.Status != storage.RangeIntact {
+				damaged++
+			}
+		}
+		message := fmt.Sprintf("Found %d snapshots", len(names))
+		if input.Tag != "" {
+			message += fmt.Sprintf(" tagged '%s'", input.Tag)
+		}
+		if damaged > 0 {
+			message += fmt.Sprintf(" (%d with evicted data)", damaged)
+		}
 		return &mcp.CallToolResult{}, ManageSnapshotsOutput{
 			Action:    "list",
-			Snapshots: snapshots,
-			Message:   fmt.Sprintf("Found %d snapshots", len(snapshots)),
+			Snapshots: names,
+			Details:   details,
+			Message:   message,
 		}, nil
 
 	case "delete":
 		if input.Name == "" {
 			return nil, ManageSnapshotsOutput{}, fmt.Errorf("snapshot name required for delete action")
 		}
-		err := s.storage.Snapshots().Delete(input.Name)
+		err := s.storage.DeleteSnapshot(input.Name)
 		if err != nil {
 			return nil, ManageSnapshotsOutput{}, fmt.Errorf("failed to delete snapshot: %w", err)
 		}
@@ -429,15 +848,37 @@ func (s *Server) handleManageSnapshots(
 		}, nil
 
 	case "clear":
-		s.storage.Snapshots().Clear()
+		s.storage.ClearSnapshots()
 		_ = s.mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "otlp://snapshots"})
 		return &mcp.CallToolResult{}, ManageSnapshotsOutput{
 			Action:  "clear",
 			Message: "Cleared all snapshots",
 		}, nil
 
+	case "pin", "unpin":
+		if input.Name == "" {
+			return nil, ManageSnapshotsOutput{}, fmt.Errorf("snapshot name required for %s action", input.Action)
+		}
+		if input.PinBudget < 0 || input.PinBudget > MaxBufferSize {
+			return nil, ManageSnapshotsOutput{}, fmt.Errorf("pin_budget %d must be between 0 and %d", input.PinBudget, MaxBufferSize)
+		}
+		var err error
+		if input.Action == "pin" {
+			err = s.storage.PinSnapshot(input.Name, input.PinBudget)
+		} else {
+			err = s.storage.UnpinSnapshot(input.Name)
+		}
+		if err != nil {
+			return nil, ManageSnapshotsOutput{}, fmt.Errorf("failed to %s snapshot: %w", input.Action, err)
+		}
+		_ = s.mcpServer.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "otlp://snapshots"})
+		return &mcp.CallToolResult{}, ManageSnapshotsOutput{
+			Action:  input.Action,
+			Message: fmt.Sprintf("%sned snapshot '%s'", strings.ToUpper(input.Action[:1])+input.Action[1:], input.Name),
+		}, nil
+
 	default:
-		return nil, ManageSnapshotsOutput{}, fmt.Errorf("invalid action: %s (must be 'list', 'delete', or 'clear')", input.Action)
+		return nil, ManageSnapshotsOutput{}, fmt.Errorf("invalid action: %s (must be 'list', 'delete', 'clear', 'pin' or 'unpin')", input.Action)
 	}
 }
 
@@ -456,6 +897,7 @@ type StorageStats struct {
 	SpanCount  int `json:"span_count" jsonschema:"Current number of spans"`
 	Capacity   int `json:"capacity" jsonschema:"Maximum spans capacity"`
 	TraceCount int `json:"trace_count" jsonschema:"Number of distinct traces"`
+	Evicted    int `json:"evicted" jsonschema:"Spans received but no longer buffered; raise the capacity with set_buffer_size to keep more"`
 }
 
 type LogStorageStats struct {
@@ -464,6 +906,7 @@ type LogStorageStats struct {
 	TraceCount   int            `json:"trace_count" jsonschema:"Logs linked to traces"`
 	ServiceCount int            `json:"service_count" jsonschema:"Distinct services"`
 	Severities   map[string]int `json:"severities" jsonschema:"Severity level counts"`
+	Evicted      int            `json:"evicted" jsonschema:"Logs received but no longer buffered"`
 }
 
 type MetricStorageStats struct {
@@ -472,6 +915,7 @@ type MetricStorageStats struct {
 	UniqueNames  int            `json:"unique_names" jsonschema:"Distinct metric names"`
 	ServiceCount int            `json:"service_count" jsonschema:"Distinct services"`
 	TypeCounts   map[string]int `json:"type_counts" jsonschema:"Counts by metric type"`
+	Evicted      int            `json:"evicted" jsonschema:"Metrics received but no longer buffered"`
 }
 
 func (s *Server) handleGetStats(
@@ -486,6 +930,7 @@ func (s *Server) handleGetStats(
 			SpanCount:  stats.Traces.SpanCount,
 			Capacity:   stats.Traces.Capacity,
 			TraceCount: stats.Traces.TraceCount,
+			Evicted:    stats.Traces.Evicted,
 		},
 		Logs: LogStorageStats{
 			LogCount:     stats.Logs.LogCount,
@@ -493,6 +938,7 @@ func (s *Server) handleGetStats(
 			TraceCount:   stats.Logs.TraceCount,
 			ServiceCount: stats.Logs.ServiceCount,
 			Severities:   stats.Logs.Severities,
+			Evicted:      stats.Logs.Evicted,
 		},
 		Metrics: MetricStorageStats{
 			MetricCount:  stats.Metrics.MetricCount,
@@ -500,6 +946,7 @@ func (s *Server) handleGetStats(
 			UniqueNames:  stats.Metrics.UniqueNames,
 			ServiceCount: stats.Metrics.ServiceCount,
 			TypeCounts:   stats.Metrics.TypeCounts,
+			Evicted:      stats.Metrics.Evicted,
 		},
 		Snapshots: stats.Snapshots,
 	}
@@ -522,6 +969,81 @@ func (s *Server) handleGetStats(
 	return toolResult, output, nil
 }
 
+// set_buffer_size
+
+// MaxBufferSize is the largest capacity set_buffer_size accepts for one
+// signal, to keep an agent from exhausting memory.
+const MaxBufferSize = 10_000_000
+
+type SetBufferSizeInput struct {
+	Traces  int `json:"traces,omitempty" jsonschema:"New span capacity (omit to keep the current one)"`
+	Logs    int `json:"logs,omitempty" jsonschema:"New log record capacity (omit to keep the current one)"`
+	Metrics int `json:"metrics,omitempty" jsonschema:"New metric capacity (omit to keep the current one)"`
+}
+
+type BufferResize struct {
+	PreviousCapacity int `json:"previous_capacity" jsonschema:"Capacity before the change"`
+	Capacity         int `json:"capacity" jsonschema:"Capacity now"`
+	Dropped          int `json:"dropped" jsonschema:"Oldest items dropped to shrink the buffer"`
+}
+
+type SetBufferSizeOutput struct {
+	Traces  *BufferResize `json:"traces,omitempty" jsonschema:"Span buffer change"`
+	Logs    *BufferResize `json:"logs,omitempty" jsonschema:"Log buffer change"`
+	Metrics *BufferResize `json:"metrics,omitempty" jsonschema:"Metric buffer change"`
+	Message string        `json:"message" jsonschema:"Summary of the change"`
+}
+
+func (s *Server) handleSetBufferSize(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input SetBufferSizeInput,
+) (*mcp.CallToolResult, SetBufferSizeOutput, error) {
+	if input.Traces == 0 && input.Logs == 0 && input.Metrics == 0 {
+		return nil, SetBufferSizeOutput{}, fmt.Errorf("set at least one of traces, logs or metrics")
+	}
+	for _, size := range []struct {
+		name  string
+		value int
+	}{{"traces", input.Traces}, {"logs", input.Logs}, {"metrics", input.Metrics}} {
+		if size.value < 0 || size.value > MaxBufferSize {
+			return nil, SetBufferSizeOutput{}, fmt.Errorf("%s capacity %d must be between 1 and %d", size.name, size.value, MaxBufferSize)
+		}
+	}
+
+	// Resizing keeps positions, so existing snapshots stay usable
+	resize := func(capacity, previous int, fn func(int) int) *BufferResize {
+		if capacity == 0 {
+			return nil
+		}
+		return &BufferResize{PreviousCapacity: previous, Capacity: capacity, Dropped: fn(capacity)}
+	}
+	stats := s.storage.Stats()
+	output := SetBufferSizeOutput{
+		Traces:  resize(input.Traces, stats.Traces.Capacity, s.storage.Traces().Resize),
+		Logs:    resize(input.Logs, stats.Logs.Capacity, s.storage.Logs().Resize),
+		Metrics: resize(input.Metrics, stats.Metrics.Capacity, s.storage.Metrics().Resize),
+	}
+
+	var changes []string
+	for _, c := range []struct {
+		name   string
+		change *BufferResize
+	}{{"traces", output.Traces}, {"logs", output.Logs}, {"metrics", output.Metrics}} {
+		if c.change == nil {
+			continue
+		}
+		change := fmt.Sprintf("%s %d -> %d", c.name, c.change.PreviousCapacity, c.change.Capacity)
+		if c.change.Dropped > 0 {
+			change += fmt.Sprintf(" (%d oldest dropped)", c.change.Dropped)
+		}
+		changes = append(changes, change)
+	}
+	output.Message = "Resized buffers: " + strings.Join(changes, ", ")
+
+	return &mcp.CallToolResult{}, output, nil
+}
+
 // clear_data
 
 type ClearDataInput struct{}
@@ -545,11 +1067,15 @@ func (s *Server) handleClearData(
 // set_file_source
 
 type SetFileSourceInput struct {
-	Directory string `json:"directory" jsonschema:"Path to directory containing OTLP JSONL files (e.g., /tank/otel). Must have traces/, logs/, and/or metrics/ subdirectories."`
+	Directory string `json:"directory" jsonschema:"Path to directory containing OTLP file exporter output - JSONL or protobuf, optionally gzip/zstd compressed (e.g., /tank/otel). Must have traces/, logs/, and/or metrics/ subdirectories."`
 	// ActiveOnly when true (default) only loads active files like traces.jsonl,
 	// skipping rotated archives like traces-2025-12-09T13-10-56.jsonl.
 	// Set to false to load all files including archives.
-	ActiveOnly *bool `json:"active_only,omitempty" jsonschema:"Only load active files, skip rotated archives (default: true)"`
+	ActiveOnly *bool `json:"active_only,omitempty" jsonschema:"Only load active files, skip rotated archives (default: true; ignored when since/until is set)"`
+	// Since/Until select a time window. Archives overlapping it are read in
+	// timestamp order and records outside it are skipped.
+	Since string `json:"since,omitempty" jsonschema:"Only load records at or after this time: RFC3339, '2006-01-02 15:04' (local), or a duration ago like '2h'"`
+	Until string `json:"until,omitempty" jsonschema:"Only load records at or before this time: RFC3339, '2006-01-02 15:04' (local), or a duration ago like '1h'"`
 }
 
 type SetFileSourceOutput struct {
@@ -572,12 +1098,29 @@ func (s *Server) handleSetFileSource(
 	}
 
 	// Default activeOnly to true if not specified
-	activeOnly := true
+	opts := FileSourceOptions{ActiveOnly: true}
 	if input.ActiveOnly != nil {
-		activeOnly = *input.ActiveOnly
+		opts.ActiveOnly = *input.ActiveOnly
 	}
 
-	if err := s.AddFileSource(ctx, input.Directory, activeOnly); err != nil {
+	now := time.Now()
+	var err error
+	if opts.Since, err = filereader.ParseTimeBound(input.Since, now); err != nil {
+		return &mcp.CallToolResult{}, SetFileSourceOutput{
+			Directory: input.Directory,
+			Success:   false,
+			Message:   fmt.Sprintf("since: %v", err),
+		}, nil
+	}
+	if opts.Until, err = filereader.ParseTimeBound(input.Until, now); err != nil {
+		return &mcp.CallToolResult{}, SetFileSourceOutput{
+			Directory: input.Directory,
+			Success:   false,
+			Message:   fmt.Sprintf("until: %v", err),
+		}, nil
+	}
+
+	if err := s.AddFileSource(ctx, input.Directory, opts); err != nil {
 		return &mcp.CallToolResult{}, SetFileSourceOutput{
 			Directory: input.Directory,
 			Success:   false,
@@ -601,7 +1144,7 @@ func (s *Server) handleSetFileSource(
 		Directory:   input.Directory,
 		WatchedDirs: watchedDirs,
 		Success:     true,
-		Message:     fmt.Sprintf("Now watching %s for OTLP JSONL files. Data loaded into ring buffers.", input.Directory),
+		Message:     fmt.Sprintf("Now watching %s for OTLP files. Data loaded into ring buffers.", input.Directory),
 	}, nil
 }
 
@@ -654,6 +1197,8 @@ type FileSourceInfo struct {
 	Directory    string   `json:"directory" jsonschema:"Directory path"`
 	WatchedDirs  []string `json:"watched_dirs" jsonschema:"Subdirectories being watched"`
 	FilesTracked int      `json:"files_tracked" jsonschema:"Number of files being tracked"`
+	Since        string   `json:"since,omitempty" jsonschema:"Start of the time window, if any"`
+	Until        string   `json:"until,omitempty" jsonschema:"End of the time window, if any"`
 }
 
 type ListFileSourcesOutput struct {
@@ -674,6 +1219,8 @@ func (s *Server) handleListFileSources(
 			Directory:    stat.Directory,
 			WatchedDirs:  stat.WatchedDirs,
 			FilesTracked: stat.FilesTracked,
+			Since:        stat.Since,
+			Until:        stat.Until,
 		}
 	}
 
@@ -744,6 +1291,9 @@ type ActivityTraceSummary struct {
 	Status     string  `json:"status" jsonschema:"Status: OK, ERROR, or UNSET"`
 	DurationMs float64 `json:"duration_ms" jsonschema:"Duration in milliseconds"`
 	ErrorMsg   string  `json:"error_msg,omitempty" jsonschema:"Error message if status is ERROR"`
+
+	ExceptionType string `json:"exception_type,omitempty" jsonschema:"Exception type recorded by the failing span"`
+	Stacktrace    string `json:"stacktrace,omitempty" jsonschema:"Stack trace recorded by the failing span"`
 }
 
 type ActivityErrorSummary struct {
@@ -752,6 +1302,9 @@ type ActivityErrorSummary struct {
 	SpanName  string `json:"span_name" jsonschema:"Span name where error occurred"`
 	ErrorMsg  string `json:"error_msg" jsonschema:"Error message"`
 	Timestamp uint64 `json:"timestamp_unix_nano" jsonschema:"Error timestamp (Unix nanoseconds)"`
+
+	ExceptionType string `json:"exception_type,omitempty" jsonschema:"Exception type, from the span's exception event"`
+	Stacktrace    string `json:"stacktrace,omitempty" jsonschema:"Exception stack trace, from the span's exception event"`
 }
 
 type ActivityThroughput struct {
@@ -794,6 +1347,9 @@ func (s *Server) handleRecentActivity(
 			Status:     t.Status,
 			DurationMs: t.DurationMs,
 			ErrorMsg:   t.ErrorMsg,
+
+			ExceptionType: t.ExceptionType,
+			Stacktrace:    t.Stacktrace,
 		}
 	}
 
@@ -807,6 +1363,9 @@ func (s *Server) handleRecentActivity(
 			SpanName:  e.SpanName,
 			ErrorMsg:  e.ErrorMsg,
 			Timestamp: e.Timestamp,
+
+			ExceptionType: e.ExceptionType,
+			Stacktrace:    e.Stacktrace,
 		}
 	}
 
@@ -849,49 +1408,471 @@ func (s *Server) handleRecentActivity(
 	return toolResult, output, nil
 }
 
+// Tool: flame_graph (aggregate span time across many traces)
+
+// maxFlameTopFrames caps the frames listed in flame_graph structured output.
+const maxFlameTopFrames = 20
+
+type FlameGraphInput struct {
+	ServiceName     string            `json:"service_name,omitempty" jsonschema:"Only traces with a span from this service"`
+	SpanName        string            `json:"span_name,omitempty" jsonschema:"Only traces with a span of this name"`
+	TraceID         string            `json:"trace_id,omitempty" jsonschema:"Only this trace"`
+	ErrorsOnly      bool              `json:"errors_only,omitempty" jsonschema:"Only traces with an error span"`
+	SpanStatus      string            `json:"span_status,omitempty" jsonschema:"Only traces with a span of this status: OK, ERROR, or UNSET"`
+	MinDurationNs   *uint64           `json:"min_duration_ns,omitempty" jsonschema:"Only traces with a span at least this long (nanoseconds)"`
+	MaxDurationNs   *uint64           `json:"max_duration_ns,omitempty" jsonschema:"Only traces with a span at most this long (nanoseconds)"`
+	HasAttribute    string            `json:"has_attribute,omitempty" jsonschema:"Only traces with a span carrying this attribute key"`
+	AttributeEquals map[string]string `json:"attribute_equals,omitempty" jsonschema:"Only traces with a span matching these attribute key-value pairs"`
+	StartSnapshot   string            `json:"start_snapshot,omitempty" jsonschema:"Start of time range (snapshot name)"`
+	EndSnapshot     string            `json:"end_snapshot,omitempty" jsonschema:"End of time range (snapshot name, empty = current)"`
+	Limit           int               `json:"limit,omitempty" jsonschema:"Maximum traces to aggregate, most recent first (0 = all)"`
+	Weight          string            `json:"weight,omitempty" jsonschema:"Rank and draw frames by 'total' (inclusive, default) or 'self' (exclusive) time"`
+	Format          string            `json:"format,omitempty" jsonschema:"'text' (default) for an ASCII icicle, or 'folded' for folded stacks (flamegraph.pl, speedscope) in microseconds of self time"`
+}
+
+type FlameGraphOutput struct {
+	TraceCount int          `json:"trace_count" jsonschema:"Number of traces aggregated"`
+	SpanCount  int          `json:"span_count" jsonschema:"Number of spans aggregated"`
+	TotalNs    uint64       `json:"total_ns" jsonschema:"Sum of root span durations (nanoseconds)"`
+	Weight     string       `json:"weight" jsonschema:"Weighting used: total or self"`
+	TopFrames  []FlameFrame `json:"top_frames" jsonschema:"Heaviest frames by the chosen weight"`
+	Folded     string       `json:"folded,omitempty" jsonschema:"Folded stacks (format=folded only)"`
+}
+
+type FlameFrame struct {
+	Path    string `json:"path" jsonschema:"Frame path from the root, service.span frames joined by ';'"`
+	TotalNs uint64 `json:"total_ns" jsonschema:"Inclusive time (nanoseconds)"`
+	SelfNs  uint64 `json:"self_ns" jsonschema:"Exclusive time not covered by child spans (nanoseconds)"`
+	Count   int    `json:"count" jsonschema:"Number of spans merged into this frame"`
+	Errors  int    `json:"errors,omitempty" jsonschema:"Number of error spans in this frame"`
+}
+
+func (s *Server) handleFlameGraph(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input FlameGraphInput,
+) (*mcp.CallToolResult, FlameGraphOutput, error) {
+	weight := viz.FlameWeight(strings.ToLower(input.Weight))
+	switch weight {
+	case "":
+		weight = viz.WeightTotal
+	case viz.WeightTotal, viz.WeightSelf:
+	default:
+		return nil, FlameGraphOutput{}, fmt.Errorf("weight must be 'total' or 'self', got %q", input.Weight)
+	}
+	format := strings.ToLower(input.Format)
+	if format != "" && format != "text" && format != "folded" {
+		return nil, FlameGraphOutput{}, fmt.Errorf("format must be 'text' or 'folded', got %q", input.Format)
+	}
+
+	spans, err := s.storage.QueryTraces(storage.QueryFilter{
+		ServiceName:     input.ServiceName,
+		SpanName:        input.SpanName,
+		TraceID:         input.TraceID,
+		ErrorsOnly:      input.ErrorsOnly,
+		SpanStatus:      input.SpanStatus,
+		MinDurationNs:   input.MinDurationNs,
+		MaxDurationNs:   input.MaxDurationNs,
+		HasAttribute:    input.HasAttribute,
+		AttributeEquals: input.AttributeEquals,
+		StartSnapshot:   input.StartSnapshot,
+		EndSnapshot:     input.EndSnapshot,
+		Limit:           input.Limit,
+	})
+	if err != nil {
+		return nil, FlameGraphOutput{}, fmt.Errorf("flame graph query failed: %w", err)
+	}
+
+	graph := viz.BuildFlameGraph(storedSpansToViz(spans))
+	output := FlameGraphOutput{
+		TraceCount: graph.Traces,
+		SpanCount:  graph.Spans,
+		TotalNs:    graph.Root.TotalNs,
+		Weight:     string(weight),
+		TopFrames:  topFlameFrames(graph.Root, weight, maxFlameTopFrames),
+	}
+
+	vizText := viz.FlameGraphText(graph, weight, 100)
+	if format == "folded" {
+		output.Folded = viz.FoldedStacks(graph)
+		vizText = output.Folded
+	}
+
+	toolResult := &mcp.CallToolResult{}
+	if vizText != "" {
+		toolResult.Content = buildVizContent(vizText, output)
+	}
+
+	return toolResult, output, nil
+}
+
+// export_chrome_trace
+
+type ExportChromeTraceInput struct {
+	TraceID       string `json:"trace_id,omitempty" jsonschema:"Export this trace"`
+	ServiceName   string `json:"service_name,omitempty" jsonschema:"Export traces with a span from this service"`
+	SpanName      string `json:"span_name,omitempty" jsonschema:"Export traces with a span of this name"`
+	ErrorsOnly    bool   `json:"errors_only,omitempty" jsonschema:"Export only traces with an error span"`
+	StartSnapshot string `json:"start_snapshot,omitempty" jsonschema:"Start of time range (snapshot name)"`
+	EndSnapshot   string `json:"end_snapshot,omitempty" jsonschema:"End of time range (snapshot name, empty = current)"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"Maximum traces to export, most recent first (0 = all)"`
+	OutputPath    string `json:"output_path,omitempty" jsonschema:"File to write (default: a new file in the system temp directory)"`
+}
+
+type ExportChromeTraceOutput struct {
+	Path       string `json:"path" jsonschema:"Path of the written Chrome Trace Event JSON file"`
+	Bytes      int64  `json:"bytes" jsonschema:"File size in bytes"`
+	TraceCount int    `json:"trace_count" jsonschema:"Number of traces exported"`
+	SpanCount  int    `json:"span_count" jsonschema:"Number of spans exported"`
+	LogCount   int    `json:"log_count" jsonschema:"Number of correlated logs exported as instant events"`
+	Message    string `json:"message" jsonschema:"How to open the file"`
+}
+
+func (s *Server) handleExportChromeTrace(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ExportChromeTraceInput,
+) (*mcp.CallToolResult, ExportChromeTraceOutput, error) {
+	trace, err := chrometrace.Export(s.storage, storage.QueryFilter{
+		TraceID:       input.TraceID,
+		ServiceName:   input.ServiceName,
+		SpanName:      input.SpanName,
+		ErrorsOnly:    input.ErrorsOnly,
+		StartSnapshot: input.StartSnapshot,
+		EndSnapshot:   input.EndSnapshot,
+		Limit:         input.Limit,
+	})
+	if err != nil {
+		return nil, ExportChromeTraceOutput{}, fmt.Errorf("export failed: %w", err)
+	}
+
+	file, err := s.createOutputFile(input.OutputPath, "otlp-mcp-trace-*.json")
+	if err != nil {
+		return nil, ExportChromeTraceOutput{}, err
+	}
+	path := file.Name()
+	n, err := trace.WriteTo(file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, ExportChromeTraceOutput{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return &mcp.CallToolResult{}, ExportChromeTraceOutput{
+		Path:       path,
+		Bytes:      n,
+		TraceCount: trace.Traces,
+		SpanCount:  trace.Spans,
+		LogCount:   trace.Logs,
+		Message:    fmt.Sprintf("Wrote %d traces to %s. Open it at https://ui.perfetto.dev or chrome://tracing", trace.Traces, path),
+	}, nil
+}
+
+// exceptions
+
+// defaultExceptionGroups is how many groups the exceptions tool returns by default.
+const defaultExceptionGroups = 20
+
+type ExceptionsInput struct {
+	ServiceName   string `json:"service_name,omitempty" jsonschema:"Only exceptions recorded by this service"`
+	SpanName      string `json:"span_name,omitempty" jsonschema:"Only exceptions recorded on spans of this name"`
+	ExceptionType string `json:"exception_type,omitempty" jsonschema:"Only this exception type, qualified or not"`
+	StartSnapshot string `json:"start_snapshot,omitempty" jsonschema:"Start of time range (snapshot name)"`
+	EndSnapshot   string `json:"end_snapshot,omitempty" jsonschema:"End of time range (snapshot name, empty = current)"`
+	Limit         int    `json:"limit,omitempty" jsonschema:"Maximum groups to return, most frequent first (default 20)"`
+}
+
+type ExceptionsOutput struct {
+	Groups         []ExceptionGroupSummary `json:"groups" jsonschema:"Exception groups, most frequent first"`
+	GroupCount     int                     `json:"group_count" jsonschema:"Total number of groups before the limit"`
+	ExceptionCount int                     `json:"exception_count" jsonschema:"Total exceptions matched"`
+}
+
+type ExceptionGroupSummary struct {
+	Type      string   `json:"type" jsonschema:"Exception type (exception.type)"`
+	TopFrame  string   `json:"top_frame,omitempty" jsonschema:"Innermost stack frame, where the exception was raised"`
+	Message   string   `json:"message,omitempty" jsonschema:"Most recent exception message"`
+	Count     int      `json:"count" jsonschema:"Number of exceptions in this group"`
+	Services  []string `json:"services" jsonschema:"Services that recorded the exception"`
+	TraceIDs  []string `json:"trace_ids" jsonschema:"Sample trace IDs, most recent first"`
+	FirstSeen uint64   `json:"first_seen_unix_nano" jsonschema:"Earliest occurrence (Unix nanoseconds)"`
+	LastSeen  uint64   `json:"last_seen_unix_nano" jsonschema:"Latest occurrence (Unix nanoseconds)"`
+}
+
+func (s *Server) handleExceptions(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ExceptionsInput,
+) (*mcp.CallToolResult, ExceptionsOutput, error) {
+	result, err := s.storage.Query(storage.QueryFilter{
+		ServiceName:   input.ServiceName,
+		SpanName:      input.SpanName,
+		EventName:     storage.ExceptionEventName,
+		ExceptionType: input.ExceptionType,
+		StartSnapshot: input.StartSnapshot,
+		EndSnapshot:   input.EndSnapshot,
+	})
+	if err != nil {
+		return nil, ExceptionsOutput{}, fmt.Errorf("exceptions query failed: %w", err)
+	}
+
+	groups := storage.GroupExceptions(result.Traces)
+	output := ExceptionsOutput{
+		Groups:     make([]ExceptionGroupSummary, 0),
+		GroupCount: len(groups),
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultExceptionGroups
+	}
+	vizGroups := make([]viz.ExceptionGroup, 0, min(limit, len(groups)))
+	for i, g := range groups {
+		output.ExceptionCount += g.Count
+		if i >= limit {
+			continue
+		}
+		output.Groups = append(output.Groups, ExceptionGroupSummary{
+			Type:      g.Type,
+			TopFrame:  g.TopFrame,
+			Message:   g.Message,
+			Count:     g.Count,
+			Services:  g.Services,
+			TraceIDs:  g.TraceIDs,
+			FirstSeen: g.FirstSeen,
+			LastSeen:  g.LastSeen,
+		})
+		vizGroups = append(vizGroups, viz.ExceptionGroup{
+			Type:     g.Type,
+			TopFrame: g.TopFrame,
+			Message:  g.Message,
+			Count:    g.Count,
+			Services: g.Services,
+		})
+	}
+
+	toolResult := &mcp.CallToolResult{}
+	if vizText := viz.Exceptions(vizGroups); vizText != "" {
+		toolResult.Content = buildVizContent(vizText, output)
+	}
+
+	return toolResult, output, nil
+}
+
+// topFlameFrames flattens the flame graph and returns the n heaviest frames.
+func topFlameFrames(root *viz.FlameNode, weight viz.FlameWeight, n int) []FlameFrame {
+	frames := make([]FlameFrame, 0)
+	var walk func(node *viz.FlameNode, path string)
+	walk = func(node *viz.FlameNode, path string) {
+		for _, c := range node.Children {
+			p := c.Name
+			if path != "" {
+				p = path + ";" + c.Name
+			}
+			frames = append(frames, FlameFrame{Path: p, TotalNs: c.TotalNs, SelfNs: c.SelfNs, Count: c.Count, Errors: c.Errors})
+			walk(c, p)
+		}
+	}
+	walk(root, "")
+
+	value := func(f FlameFrame) uint64 {
+		if weight == viz.WeightSelf {
+			return f.SelfNs
+		}
+		return f.TotalNs
+	}
+	sort.SliceStable(frames, func(i, j int) bool { return value(frames[i]) > value(frames[j]) })
+	if len(frames) > n {
+		frames = frames[:n]
+	}
+	return frames
+}
+
+// export_snapshot
+
+type ExportSnapshotInput struct {
+	StartSnapshot string `json:"start_snapshot" jsonschema:"Start snapshot name"`
+	EndSnapshot   string `json:"end_snapshot,omitempty" jsonschema:"End snapshot name (empty = current)"`
+	OutputPath    string `json:"output_path,omitempty" jsonschema:"File to write (default: a new file in the system temp directory)"`
+}
+
+type ExportSnapshotOutput struct {
+	Path        string `json:"path" jsonschema:"Path of the written OTLP JSONL file"`
+	Bytes       int64  `json:"bytes" jsonschema:"File size in bytes"`
+	SpanCount   int    `json:"span_count" jsonschema:"Number of spans exported"`
+	LogCount    int    `json:"log_count" jsonschema:"Number of logs exported"`
+	MetricCount int    `json:"metric_count" jsonschema:"Number of metrics exported"`
+	Message     string `json:"message" jsonschema:"How to use the file"`
+}
+
+func (s *Server) handleExportSnapshot(
+	ctx context.Context,
+	req *mcp.CallToolRequest,
+	input ExportSnapshotInput,
+) (*mcp.CallToolResult, ExportSnapshotOutput, error) {
+	data, err := s.storage.GetSnapshotData(input.StartSnapshot, input.EndSnapshot)
+	if err != nil {
+		return nil, ExportSnapshotOutput{}, fmt.Errorf("failed to get snapshot data: %w", err)
+	}
+
+	file, err := s.createOutputFile(input.OutputPath, "otlp-mcp-snapshot-*.jsonl")
+	if err != nil {
+		return nil, ExportSnapshotOutput{}, err
+	}
+	path := file.Name()
+	n, err := data.WriteJSONL(file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, ExportSnapshotOutput{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return &mcp.CallToolResult{}, ExportSnapshotOutput{
+		Path:        path,
+		Bytes:       n,
+		SpanCount:   len(data.Traces),
+		LogCount:    len(data.Logs),
+		MetricCount: len(data.Metrics),
+		Message:     fmt.Sprintf("Wrote %d spans, %d logs and %d metrics to %s. Load it with 'otlp-mcp analyze' or 'otlp-mcp replay'", len(data.Traces), len(data.Logs), len(data.Metrics), path),
+	}, nil
+}
+
+// createOutputFile creates the file an export tool writes to. Without a path
+// it creates a new file in the system temp directory named after pattern (see
+// os.CreateTemp), so no tool input ends up in the default path. A read-only
+// server only creates those, never overwriting an existing file.
+func (s *Server) createOutputFile(path, pattern string) (*os.File, error) {
+	if path != "" && s.readOnly {
+		return nil, fmt.Errorf("output_path is not available in read-only mode; leave it empty to write a new file in the temp directory")
+	}
+	if path == "" {
+		file, err := os.CreateTemp("", pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		return file, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	return file, nil
+}
+
 // Register all tools
 
 func (s *Server) registerTools() error {
+	// Receiver management, unless serving a read-only archive
+	if !s.readOnly {
+		s.registerReceiverTools()
+	}
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "get_otlp_endpoint",
-		Description: "Get OTLP endpoint address. Set OTEL_EXPORTER_OTLP_ENDPOINT=<result> to instrument programs.",
-	}, s.handleGetOTLPEndpoint)
+		Name:        "create_snapshot",
+		Description: "Bookmark current buffer positions for before/after comparison across all signals. Add a description, tags and metadata (e.g. git SHA, test name) to remember what it marks. Set pin to keep the data after it from being evicted.",
+	}, s.handleCreateSnapshot)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "add_otlp_port",
-		Description: "Add a listening port to the OTLP receiver without disrupting existing connections.",
-	}, s.handleAddOTLPPort)
+		Name:        "query",
+		Description: "Search traces, logs, metrics with filters: service, trace_id, errors_only, duration, attributes, span events and exception types, snapshot ranges. Set viz_format for Mermaid or Graphviz diagrams.",
+	}, s.handleQuery)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "remove_otlp_port",
-		Description: "Remove a listening port from the OTLP receiver. Cannot remove the last port.",
-	}, s.handleRemoveOTLPPort)
+		Name:        "flame_graph",
+		Description: "Flame graph of span time aggregated across all traces matching a filter, by service.span path. Weight by total or self time; text or folded-stack output.",
+	}, s.handleFlameGraph)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "create_snapshot",
-		Description: "Bookmark current buffer positions for before/after comparison across all signals.",
-	}, s.handleCreateSnapshot)
+		Name:        "export_chrome_trace",
+		Description: "Write traces (by trace_id, filters or snapshot range) as Chrome Trace Event JSON for Perfetto or chrome://tracing. Services become processes, concurrent spans get their own tracks, correlated logs become instant events.",
+	}, s.handleExportChromeTrace)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "query",
-		Description: "Search traces, logs, metrics with filters: service, trace_id, errors_only, duration, attributes, snapshot ranges.",
-	}, s.handleQuery)
+		Name:        "exceptions",
+		Description: "Group recorded exceptions (span events) by exception type and top stack frame, most frequent first, with counts, services, latest message and sample trace IDs.",
+	}, s.handleExceptions)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_snapshot_data",
-		Description: "Get all telemetry between two snapshots for before/after analysis.",
+		Description: "Get all telemetry between two snapshots for before/after analysis, with the snapshots taken in between as annotations.",
 	}, s.handleGetSnapshotData)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "export_snapshot",
+		Description: "Write all telemetry between two snapshots to a file as OTLP JSONL, to attach to a bug report and load later with 'otlp-mcp analyze' or 'otlp-mcp replay'.",
+	}, s.handleExportSnapshot)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "manage_snapshots",
-		Description: "List, delete, or clear snapshots. Actions: 'list', 'delete', 'clear'.",
+		Description: "List (with annotations and eviction status, optionally by tag), delete, clear, pin or unpin snapshots. Actions: 'list', 'delete', 'clear', 'pin', 'unpin'.",
 	}, s.handleManageSnapshots)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_stats",
-		Description: "Buffer health: span/log/metric counts, capacities, snapshot count.",
+		Description: "Buffer health: span/log/metric counts, capacities, evicted counts, snapshot count.",
 	}, s.handleGetStats)
 
+	// Clearing data and managing file sources, unless read-only
+	if !s.readOnly {
+		s.registerDataTools()
+	}
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "status",
+		Description: "Fast poll: monotonic counters (spans/logs/metrics), generation counter, error count, uptime.",
+	}, s.handleStatus)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "recent_activity",
+		Description: "Recent 5 traces, 5 errors, throughput, optional metric peek (pass metric_names, max 20).",
+	}, s.handleRecentActivity)
+
+	return nil
+}
+
+// registerReceiverTools registers the tools that report and manage OTLP
+// receiver listeners.
+func (s *Server) registerReceiverTools() {
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_otlp_endpoint",
+		Description: "Get OTLP endpoint address. Set OTEL_EXPORTER_OTLP_ENDPOINT=<result> to instrument programs. When serving an otel-collector's output, also lists the collector's receivers, which is where programs should send.",
+	}, s.handleGetOTLPEndpoint)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "add_otlp_port",
+		Description: "Add a listening port to the OTLP receiver without disrupting existing connections.",
+	}, s.handleAddOTLPPort)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "remove_otlp_port",
+		Description: "Remove a listening port from the OTLP receiver. Cannot remove the primary port the receiver started with.",
+	}, s.handleRemoveOTLPPort)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "add_otlp_socket",
+		Description: "Add a Unix domain socket listener to the OTLP receiver (for sandboxes without TCP loopback).",
+	}, s.handleAddOTLPSocket)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "remove_otlp_socket",
+		Description: "Remove a Unix domain socket listener from the OTLP receiver. Cannot remove the primary listener the receiver started with.",
+	}, s.handleRemoveOTLPSocket)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "collector_config",
+		Description: "Generate an otel-collector config fragment (exporters and service.pipelines) that tees an existing collector's telemetry to this server, optionally with file exporters for set_file_source.",
+	}, s.handleCollectorConfig)
+}
+
+// registerDataTools registers the tools that clear stored data or manage
+// the file sources it is loaded from.
+func (s *Server) registerDataTools() {
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "set_buffer_size",
+		Description: "Change span/log/metric buffer capacities at runtime, e.g. to keep more spans for a long test. Shrinking drops the oldest data, except what a pinned snapshot keeps within its budget; snapshots stay valid.",
+	}, s.handleSetBufferSize)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "clear_data",
 		Description: "Wipe ALL telemetry data and snapshots. Irreversible.",
@@ -899,7 +1880,7 @@ func (s *Server) registerTools() error {
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "set_file_source",
-		Description: "Load OTLP JSONL from a collector file exporter directory. Watches for new files.",
+		Description: "Load OTLP data (JSONL or protobuf, plain or gzip/zstd compressed) from a collector file exporter directory. Watches for new files. Optional since/until window backfills from rotated archives.",
 	}, s.handleSetFileSource)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
@@ -911,18 +1892,6 @@ func (s *Server) registerTools() error {
 		Name:        "list_file_sources",
 		Description: "List watched directories and file tracking stats.",
 	}, s.handleListFileSources)
-
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "status",
-		Description: "Fast poll: monotonic counters (spans/logs/metrics), generation counter, error count, uptime.",
-	}, s.handleStatus)
-
-	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "recent_activity",
-		Description: "Recent 5 traces, 5 errors, throughput, optional metric peek (pass metric_names, max 20).",
-	}, s.handleRecentActivity)
-
-	return nil
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -939,6 +1908,19 @@ type TraceSummary struct {
 	EndTime      uint64         `json:"end_time_unix_nano" jsonschema:"End time (Unix nanoseconds)"`
 	Status       string         `json:"status,omitempty" jsonschema:"Span status code"`
 	Attributes   map[string]any `json:"attributes,omitempty" jsonschema:"Span attributes"`
+	Events       []SpanEvent    `json:"events,omitempty" jsonschema:"Span events, including recorded exceptions"`
+	Links        []SpanLink     `json:"links,omitempty" jsonschema:"Span links to other spans, often in other traces (messaging, batching)"`
+}
+
+type SpanLink struct {
+	TraceID string `json:"trace_id" jsonschema:"Linked trace ID (hex)"`
+	SpanID  string `json:"span_id" jsonschema:"Linked span ID (hex)"`
+}
+
+type SpanEvent struct {
+	Name       string         `json:"name" jsonschema:"Event name ('exception' for recorded exceptions)"`
+	Timestamp  uint64         `json:"timestamp_unix_nano" jsonschema:"Event time (Unix nanoseconds)"`
+	Attributes map[string]any `json:"attributes,omitempty" jsonschema:"Event attributes (exception.type, exception.message, exception.stacktrace for exceptions)"`
 }
 
 type LogSummary struct {
@@ -990,7 +1972,29 @@ func spanToTraceSummary(span *storage.StoredSpan) TraceSummary {
 		if i >= 20 { // Limit to 20 attributes
 			break
 		}
-		summary.Attributes[attr.Key] = formatAttributeValue(attr.Value)
+		summary.Attributes[attr.Key] = storage.AttributeValue(attr.Value)
+	}
+
+	// Span events, exceptions included (same limits as attributes)
+	for i, ev := range span.Span.Events {
+		if i >= 20 {
+			break
+		}
+		event := SpanEvent{Name: ev.Name, Timestamp: ev.TimeUnixNano}
+		for j, attr := range ev.Attributes {
+			if j >= 20 {
+				break
+			}
+			if event.Attributes == nil {
+				event.Attributes = make(map[string]any)
+			}
+			event.Attributes[attr.Key] = storage.AttributeValue(attr.Value)
+		}
+		summary.Events = append(summary.Events, event)
+	}
+
+	for _, link := range span.Links {
+		summary.Links = append(summary.Links, SpanLink{TraceID: link.TraceID, SpanID: link.SpanID})
 	}
 
 	return summary
@@ -1014,7 +2018,7 @@ func logToSummary(log *storage.StoredLog) LogSummary {
 			if i >= 20 { // Limit to 20 attributes
 				break
 			}
-			summary.Attributes[attr.Key] = formatAttributeValue(attr.Value)
+			summary.Attributes[attr.Key] = storage.AttributeValue(attr.Value)
 		}
 	}
 
@@ -1038,12 +2042,57 @@ func metricToSummary(metric *storage.StoredMetric) MetricSummary {
 // VIZ HELPERS - Convert tool output types to viz input types
 // ═══════════════════════════════════════════════════════════════════════════
 
-// buildTraceViz creates a waterfall visualization from TraceSummary slices.
-func buildTraceViz(traces []TraceSummary) string {
+// Trace visualization formats accepted by query and get_snapshot_data.
+const (
+	vizWaterfall = "waterfall"
+	vizSequence  = "mermaid_sequence"
+	vizGantt     = "mermaid_gantt"
+	vizFlowchart = "mermaid_flowchart"
+	vizDOT       = "dot"
+)
+
+// buildTraceViz renders TraceSummary slices in the requested format.
+// Mermaid diagrams are fenced so they can be pasted straight into Markdown.
+func buildTraceViz(traces []TraceSummary, format string) (string, error) {
+	switch format {
+	case "", vizWaterfall, vizSequence, vizGantt, vizFlowchart, vizDOT:
+	default:
+		return "", fmt.Errorf("invalid viz_format %q: must be waterfall, mermaid_sequence, mermaid_gantt, mermaid_flowchart or dot", format)
+	}
 	if len(traces) == 0 {
-		return ""
+		return "", nil
 	}
+	spans := traceSummariesToViz(traces)
 
+	switch format {
+	case vizSequence, vizGantt:
+		render := viz.MermaidSequence
+		if format == vizGantt {
+			render = viz.MermaidGantt
+		}
+		groups := viz.GroupByTrace(spans)
+		var parts []string
+		for i, trace := range groups {
+			if i == maxDiagramTraces {
+				parts = append(parts, fmt.Sprintf("... +%d more traces", len(groups)-i))
+				break
+			}
+			parts = append(parts, "```mermaid\n"+render(trace)+"```")
+		}
+		return strings.Join(parts, "\n\n"), nil
+	case vizFlowchart:
+		return "```mermaid\n" + viz.MermaidFlowchart(viz.BuildServiceGraph(spans)) + "```", nil
+	case vizDOT:
+		return "```dot\n" + viz.DOT(viz.BuildServiceGraph(spans)) + "```", nil
+	}
+	return viz.Waterfall(spans, 80), nil
+}
+
+// maxDiagramTraces caps how many per-trace Mermaid diagrams one result holds.
+const maxDiagramTraces = 5
+
+// traceSummariesToViz converts TraceSummary slices to viz input.
+func traceSummariesToViz(traces []TraceSummary) []viz.SpanInfo {
 	spans := make([]viz.SpanInfo, len(traces))
 	for i, t := range traces {
 		spans[i] = viz.SpanInfo{
@@ -1056,9 +2105,36 @@ func buildTraceViz(traces []TraceSummary) string {
 			EndNano:     t.EndTime,
 			StatusCode:  t.Status,
 		}
+		for _, link := range t.Links {
+			spans[i].LinkedTraces = append(spans[i].LinkedTraces, link.TraceID)
+		}
 	}
+	return spans
+}
 
-	return viz.Waterfall(spans, 80)
+// storedSpansToViz converts stored spans to viz input, skipping spans
+// without a payload.
+func storedSpansToViz(spans []*storage.StoredSpan) []viz.SpanInfo {
+	infos := make([]viz.SpanInfo, 0, len(spans))
+	for _, span := range spans {
+		if span.Span == nil {
+			continue
+		}
+		info := viz.SpanInfo{
+			TraceID:     span.TraceID,
+			SpanID:      span.SpanID,
+			ParentID:    fmt.Sprintf("%x", span.Span.ParentSpanId),
+			ServiceName: span.ServiceName,
+			SpanName:    span.SpanName,
+			StartNano:   span.Span.StartTimeUnixNano,
+			EndNano:     span.Span.EndTimeUnixNano,
+		}
+		if span.Span.Status != nil {
+			info.StatusCode = span.Span.Status.Code.String()
+		}
+		infos = append(infos, info)
+	}
+	return infos
 }
 
 // buildActivityViz creates combined recent traces + errors visualization.
@@ -1089,6 +2165,7 @@ func buildActivityViz(traces []ActivityTraceSummary, errors []ActivityErrorSumma
 				SpanName:  e.SpanName,
 				ErrorMsg:  e.ErrorMsg,
 				Timestamp: e.Timestamp,
+				Exception: e.ExceptionType,
 			}
 		}
 		parts = append(parts, viz.RecentErrors(vizErrors))
@@ -1104,42 +2181,3 @@ func buildVizContent[T any](vizText string, _ T) []mcp.Content {
 		&mcp.TextContent{Text: vizText},
 	}
 }
-
-// maxAttributeDepth limits recursion depth for nested OTLP attributes.
-const maxAttributeDepth = 10
-
-// formatAttributeValue converts an OTLP attribute value to a Go any type.
-func formatAttributeValue(value *commonpb.AnyValue) any {
-	return formatAttributeValueDepth(value, 0)
-}
-
-func formatAttributeValueDepth(value *commonpb.AnyValue, depth int) any {
-	if value == nil || depth >= maxAttributeDepth {
-		return nil
-	}
-
-	switch v := value.Value.(type) {
-	case *commonpb.AnyValue_StringValue:
-		return v.StringValue
-	case *commonpb.AnyValue_IntValue:
-		return v.IntValue
-	case *commonpb.AnyValue_DoubleValue:
-		return v.DoubleValue
-	case *commonpb.AnyValue_BoolValue:
-		return v.BoolValue
-	case *commonpb.AnyValue_ArrayValue:
-		result := make([]any, len(v.ArrayValue.Values))
-		for i, val := range v.ArrayValue.Values {
-			result[i] = formatAttributeValueDepth(val, depth+1)
-		}
-		return result
-	case *commonpb.AnyValue_KvlistValue:
-		result := make(map[string]any)
-		for _, kv := range v.KvlistValue.Values {
-			result[kv.Key] = formatAttributeValueDepth(kv.Value, depth+1)
-		}
-		return result
-	default:
-		return nil
-	}
-}
@@ -0,0 +1,373 @@
+// Package otelconfig reads an OpenTelemetry Collector configuration the way
+// the collector does: components are only in use when a service pipeline
+// references them, and the pipelines decide which signals reach each file
+// exporter. It reports where the collector writes telemetry to disk and
+// where its OTLP receivers listen, so otlp-mcp can load the former and
+// point users at the latter.
+package otelconfig
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Signals in the order pipelines are reported.
+var Signals = []string{"traces", "logs", "metrics"}
+
+// Default OTLP receiver endpoints, used when a protocol is enabled without
+// one. Collector releases since v0.104 bind to localhost by default.
+const (
+	DefaultGRPCEndpoint = "localhost:4317"
+	DefaultHTTPEndpoint = "localhost:4318"
+)
+
+// Config is the part of a collector configuration otlp-mcp cares about.
+type Config struct {
+	Path          string         // File the config was loaded from, if any
+	Pipelines     []Pipeline     // Service pipelines, sorted by name
+	FileExporters []FileExporter // File exporters used by at least one pipeline
+	Receivers     []Receiver     // OTLP receiver endpoints used by at least one pipeline
+	Warnings      []string       // Things that were ignored or cannot be followed
+}
+
+// Pipeline is one entry of service.pipelines, e.g. "traces" or "logs/audit".
+type Pipeline struct {
+	Name       string
+	Signal     string // traces, logs or metrics
+	Receivers  []string
+	Processors []string
+	Exporters  []string
+}
+
+// FileExporter is a file exporter and the signals routed to it.
+type FileExporter struct {
+	Name        string   // Component ID, e.g. "file" or "file/traces"
+	Path        string   // Active output file, environment variables expanded
+	Format      string   // json (default) or proto
+	Compression string   // "" or zstd
+	Rotation    bool     // Whether rotation is configured
+	GroupBy     bool     // Whether output is split into one file per resource attribute value
+	Signals     []string // Signals from the pipelines using it
+}
+
+// Receiver is one protocol endpoint of an OTLP receiver.
+type Receiver struct {
+	Name     string   // Component ID, e.g. "otlp" or "otlp/internal"
+	Protocol string   // grpc or http
+	Endpoint string   // As configured, or the default; unix:// for sockets
+	Signals  []string // Signals from the pipelines using it
+}
+
+// FileSource is where to read one part of the collector's file output.
+// Directory sources follow the base/signal/signal.jsonl layout; file sources
+// name one exporter file and the signals written to it.
+type FileSource struct {
+	Path      string
+	Signals   []string // Empty for directory sources
+	Exporters []string // File exporters writing here
+}
+
+// Load reads and parses a collector config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read otel config: %w", err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse otel config %s: %w", path, err)
+	}
+	cfg.Path = path
+	return cfg, nil
+}
+
+// rawConfig mirrors the collector's top-level layout. Component bodies are
+// decoded by type once the pipelines say which are in use.
+type rawConfig struct {
+	Receivers map[string]yaml.Node `yaml:"receivers"`
+	Exporters map[string]yaml.Node `yaml:"exporters"`
+	Service   struct {
+		Pipelines map[string]struct {
+			Receivers  []string `yaml:"receivers"`
+			Processors []string `yaml:"processors"`
+			Exporters  []string `yaml:"exporters"`
+		} `yaml:"pipelines"`
+	} `yaml:"service"`
+}
+
+type rawFileExporter struct {
+	Path        string     `yaml:"path"`
+	Format      string     `yaml:"format"`
+	Compression string     `yaml:"compression"`
+	Rotation    *yaml.Node `yaml:"rotation"`
+	GroupBy     struct {
+		Enabled bool `yaml:"enabled"`
+	} `yaml:"group_by"`
+}
+
+type rawOTLPReceiver struct {
+	Protocols map[string]*struct {
+		Endpoint  string `yaml:"endpoint"`
+		Transport string `yaml:"transport"`
+	} `yaml:"protocols"`
+}
+
+// Parse parses collector config YAML.
+func Parse(data []byte) (*Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	exporterSignals := make(map[string][]string)
+	receiverSignals := make(map[string][]string)
+
+	for _, name := range slices.Sorted(maps.Keys(raw.Service.Pipelines)) {
+		p := raw.Service.Pipelines[name]
+		signal, _, _ := strings.Cut(name, "/")
+		if !isSignal(signal) {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("pipeline %q: unsupported signal %q ignored", name, signal))
+			continue
+		}
+		cfg.Pipelines = append(cfg.Pipelines, Pipeline{
+			Name:       name,
+			Signal:     signal,
+			Receivers:  p.Receivers,
+			Processors: p.Processors,
+			Exporters:  p.Exporters,
+		})
+		for _, id := range p.Exporters {
+			exporterSignals[id] = addSignal(exporterSignals[id], signal)
+		}
+		for _, id := range p.Receivers {
+			receiverSignals[id] = addSignal(receiverSignals[id], signal)
+		}
+	}
+	if len(raw.Service.Pipelines) == 0 && len(raw.Exporters) > 0 {
+		cfg.Warnings = append(cfg.Warnings, "no service.pipelines: the collector would not run any exporter")
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(raw.Exporters)) {
+		if componentType(id) != "file" {
+			continue
+		}
+		signals, used := exporterSignals[id]
+		if !used {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("exporter %q is not used by any pipeline", id))
+			continue
+		}
+		node := raw.Exporters[id]
+		var fe rawFileExporter
+		if err := node.Decode(&fe); err != nil {
+			return nil, fmt.Errorf("exporter %q: %w", id, err)
+		}
+		exporter := FileExporter{
+			Name:        id,
+			Path:        expandEnv(fe.Path),
+			Format:      expandEnv(fe.Format),
+			Compression: expandEnv(fe.Compression),
+			Rotation:    fe.Rotation != nil,
+			GroupBy:     fe.GroupBy.Enabled,
+			Signals:     signals,
+		}
+		if exporter.Format == "" {
+			exporter.Format = "json"
+		}
+		if exporter.Path == "" {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("exporter %q has no path", id))
+			continue
+		}
+		cfg.FileExporters = append(cfg.FileExporters, exporter)
+	}
+	for id := range exporterSignals {
+		if _, ok := raw.Exporters[id]; !ok {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("pipelines reference undefined exporter %q", id))
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(raw.Receivers)) {
+		if componentType(id) != "otlp" {
+			continue
+		}
+		signals, used := receiverSignals[id]
+		if !used {
+			continue
+		}
+		node := raw.Receivers[id]
+		var rr rawOTLPReceiver
+		if err := node.Decode(&rr); err != nil {
+			return nil, fmt.Errorf("receiver %q: %w", id, err)
+		}
+		for _, protocol := range []string{"grpc", "http"} {
+			p, enabled := rr.Protocols[protocol]
+			if !enabled {
+				continue
+			}
+			endpoint, transport := "", ""
+			if p != nil {
+				endpoint, transport = expandEnv(p.Endpoint), p.Transport
+			}
+			switch {
+			case transport == "unix" && endpoint != "":
+				endpoint = "unix://" + endpoint
+			case endpoint == "" && protocol == "grpc":
+				endpoint = DefaultGRPCEndpoint
+			case endpoint == "":
+				endpoint = DefaultHTTPEndpoint
+			}
+			cfg.Receivers = append(cfg.Receivers, Receiver{Name: id, Protocol: protocol, Endpoint: endpoint, Signals: signals})
+		}
+	}
+	sort.Strings(cfg.Warnings)
+
+	return cfg, nil
+}
+
+// FileSources returns where to read the file exporters' output. Exporters
+// following the base/signal/signal.jsonl layout collapse into their base
+// directory; any other path is followed as a single file with the signals
+// its pipelines send it. Exporters splitting output by resource (group_by)
+// are skipped.
+func (c *Config) FileSources() []FileSource {
+	var sources []FileSource
+	index := make(map[string]int)
+	add := func(path string, signals []string, exporter string) {
+		if i, ok := index[path]; ok {
+			sources[i].Exporters = append(sources[i].Exporters, exporter)
+			for _, s := range signals {
+				sources[i].Signals = addSignal(sources[i].Signals, s)
+			}
+			return
+		}
+		index[path] = len(sources)
+		sources = append(sources, FileSource{Path: path, Signals: slices.Clone(signals), Exporters: []string{exporter}})
+	}
+
+	for _, fe := range c.FileExporters {
+		if fe.GroupBy {
+			continue
+		}
+		if base, ok := layoutBase(fe); ok {
+			add(base, nil, fe.Name)
+		} else {
+			add(fe.Path, fe.Signals, fe.Name)
+		}
+	}
+	return sources
+}
+
+// SkippedExporters returns file exporters FileSources cannot follow.
+func (c *Config) SkippedExporters() []FileExporter {
+	var skipped []FileExporter
+	for _, fe := range c.FileExporters {
+		if fe.GroupBy {
+			skipped = append(skipped, fe)
+		}
+	}
+	return skipped
+}
+
+// layoutBase reports whether an exporter writes only the signal its
+// directory is named for, as base/traces/traces.jsonl, and returns base.
+func layoutBase(fe FileExporter) (string, bool) {
+	signalDir := filepath.Dir(fe.Path)
+	signal := filepath.Base(signalDir)
+	if !isSignal(signal) || len(fe.Signals) != 1 || fe.Signals[0] != signal {
+		return "", false
+	}
+	name := filepath.Base(fe.Path)
+	if strings.TrimSuffix(name, filepath.Ext(name)) != signal {
+		return "", false
+	}
+	return filepath.Dir(signalDir), true
+}
+
+// ClientEndpoint is the address an application on this host should send to:
+// wildcard bind addresses become localhost.
+func (r Receiver) ClientEndpoint() string {
+	return clientEndpoint(r.Endpoint)
+}
+
+// clientEndpoint turns a listen address into one a client on the same host
+// can connect to.
+func clientEndpoint(endpoint string) string {
+	if strings.HasPrefix(endpoint, "unix://") {
+		return endpoint
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// EnvVars returns the OpenTelemetry SDK exporter settings for sending to
+// the receiver.
+func (r Receiver) EnvVars() map[string]string {
+	endpoint := r.ClientEndpoint()
+	env := map[string]string{}
+	if r.Protocol == "http" {
+		env["OTEL_EXPORTER_OTLP_PROTOCOL"] = "http/protobuf"
+	} else {
+		env["OTEL_EXPORTER_OTLP_PROTOCOL"] = "grpc"
+	}
+	if strings.HasPrefix(endpoint, "unix://") {
+		env["OTEL_EXPORTER_OTLP_INSECURE"] = "true"
+	} else {
+		endpoint = "http://" + endpoint
+	}
+	env["OTEL_EXPORTER_OTLP_ENDPOINT"] = endpoint
+	return env
+}
+
+// envPattern matches the collector's ${env:NAME}, ${NAME} and
+// ${env:NAME:-default} references.
+var envPattern = regexp.MustCompile(`\$\{(?:env:)?([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// expandEnv substitutes environment variable references as the collector does.
+func expandEnv(s string) string {
+	return envPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		m := envPattern.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok && v != "" {
+			return v
+		}
+		return m[2]
+	})
+}
+
+// componentType returns the type part of a component ID ("file/traces" -> "file").
+func componentType(id string) string {
+	typ, _, _ := strings.Cut(id, "/")
+	return typ
+}
+
+func isSignal(s string) bool {
+	return s == "traces" || s == "logs" || s == "metrics"
+}
+
+// addSignal adds a signal to a list kept in Signals order.
+func addSignal(signals []string, signal string) []string {
+	if slices.Contains(signals, signal) {
+		return signals
+	}
+	signals = append(signals, signal)
+	sort.Slice(signals, func(i, j int) bool { return signalRank(signals[i]) < signalRank(signals[j]) })
+	return signals
+}
+
+func signalRank(signal string) int {
+	return slices.Index(Signals, signal)
+}
@@ -0,0 +1,180 @@
+package otelconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const collectorConfig = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+  otlp/unused:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:14317
+  otlp/socket:
+    protocols:
+      grpc:
+        endpoint: /run/otel.sock
+        transport: unix
+
+exporters:
+  debug:
+  file/traces:
+    path: ${env:OTEL_DIR:-/var/otel}/traces/traces.jsonl
+  file/logs:
+    path: /var/otel/logs/logs.jsonl
+  file/everything:
+    path: /data/all.json
+    format: proto
+    compression: zstd
+    rotation:
+      max_megabytes: 10
+  file/spare:
+    path: /data/spare.jsonl
+  file/split:
+    path: /data/split/*.jsonl
+    group_by:
+      enabled: true
+
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [file/traces, file/everything, debug]
+    logs:
+      receivers: [otlp, otlp/socket]
+      exporters: [file/logs, file/everything]
+    metrics/internal:
+      receivers: [otlp]
+      exporters: [file/everything, file/split, missing]
+    profiles:
+      receivers: [otlp]
+      exporters: [debug]
+`
+
+func TestParse(t *testing.T) {
+	t.Setenv("OTEL_DIR", "")
+
+	cfg, err := Parse([]byte(collectorConfig))
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Pipelines, 3)
+	assert.Equal(t, "metrics/internal", cfg.Pipelines[1].Name)
+	assert.Equal(t, "metrics", cfg.Pipelines[1].Signal)
+
+	exporters := make(map[string]FileExporter)
+	for _, fe := range cfg.FileExporters {
+		exporters[fe.Name] = fe
+	}
+	require.Len(t, exporters, 4, "unused file/spare is left out")
+	assert.Equal(t, "/var/otel/traces/traces.jsonl", exporters["file/traces"].Path, "env default applies")
+	assert.Equal(t, []string{"traces"}, exporters["file/traces"].Signals)
+	everything := exporters["file/everything"]
+	assert.Equal(t, []string{"traces", "logs", "metrics"}, everything.Signals)
+	assert.Equal(t, "proto", everything.Format)
+	assert.Equal(t, "zstd", everything.Compression)
+	assert.True(t, everything.Rotation)
+	assert.Equal(t, "json", exporters["file/logs"].Format)
+	assert.True(t, exporters["file/split"].GroupBy)
+
+	assert.Equal(t, []Receiver{
+		{Name: "otlp", Protocol: "grpc", Endpoint: "0.0.0.0:4317", Signals: []string{"traces", "logs", "metrics"}},
+		{Name: "otlp", Protocol: "http", Endpoint: DefaultHTTPEndpoint, Signals: []string{"traces", "logs", "metrics"}},
+		{Name: "otlp/socket", Protocol: "grpc", Endpoint: "unix:///run/otel.sock", Signals: []string{"logs"}},
+	}, cfg.Receivers)
+
+	assert.Equal(t, []string{
+		`exporter "file/spare" is not used by any pipeline`,
+		`pipeline "profiles": unsupported signal "profiles" ignored`,
+		`pipelines reference undefined exporter "missing"`,
+	}, cfg.Warnings)
+}
+
+func TestFileSources(t *testing.T) {
+	t.Setenv("OTEL_DIR", "/srv/otel")
+
+	cfg, err := Parse([]byte(collectorConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, []FileSource{
+		{Path: "/data/all.json", Signals: []string{"traces", "logs", "metrics"}, Exporters: []string{"file/everything"}},
+		{Path: "/var/otel", Exporters: []string{"file/logs"}},
+		{Path: "/srv/otel", Exporters: []string{"file/traces"}},
+	}, cfg.FileSources())
+
+	skipped := cfg.SkippedExporters()
+	require.Len(t, skipped, 1)
+	assert.Equal(t, "file/split", skipped[0].Name)
+}
+
+func TestFileSourcesSharedLayout(t *testing.T) {
+	cfg, err := Parse([]byte(`
+exporters:
+  file/traces:
+    path: /otel/traces/traces.jsonl
+  file/logs:
+    path: /otel/logs/logs.jsonl
+  file/odd:
+    path: /otel/logs/traces.jsonl
+service:
+  pipelines:
+    traces:
+      exporters: [file/traces, file/odd]
+    logs:
+      exporters: [file/logs]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []FileSource{
+		{Path: "/otel", Exporters: []string{"file/logs", "file/traces"}},
+		{Path: "/otel/logs/traces.jsonl", Signals: []string{"traces"}, Exporters: []string{"file/odd"}},
+	}, cfg.FileSources(), "a traces file under logs/ is not the standard layout")
+}
+
+func TestReceiverEnvVars(t *testing.T) {
+	testCases := []struct {
+		receiver Receiver
+		endpoint string
+		protocol string
+		insecure bool
+	}{
+		{Receiver{Protocol: "grpc", Endpoint: "0.0.0.0:4317"}, "http://localhost:4317", "grpc", false},
+		{Receiver{Protocol: "http", Endpoint: "[::]:4318"}, "http://localhost:4318", "http/protobuf", false},
+		{Receiver{Protocol: "grpc", Endpoint: "10.0.0.5:4317"}, "http://10.0.0.5:4317", "grpc", false},
+		{Receiver{Protocol: "grpc", Endpoint: "unix:///run/otel.sock"}, "unix:///run/otel.sock", "grpc", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.receiver.Endpoint, func(t *testing.T) {
+			env := tc.receiver.EnvVars()
+			assert.Equal(t, tc.endpoint, env["OTEL_EXPORTER_OTLP_ENDPOINT"])
+			assert.Equal(t, tc.protocol, env["OTEL_EXPORTER_OTLP_PROTOCOL"])
+			_, insecure := env["OTEL_EXPORTER_OTLP_INSECURE"]
+			assert.Equal(t, tc.insecure, insecure)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(collectorConfig), 0o644))
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, path, cfg.Path)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read otel config")
+
+	require.NoError(t, os.WriteFile(path, []byte("service: [unclosed"), 0o644))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "failed to parse otel config")
+}
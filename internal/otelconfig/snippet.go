@@ -0,0 +1,189 @@
+package otelconfig
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultExporterName names the exporters a snippet adds: otlp/otlp-mcp and,
+// with a file layout, file/otlp-mcp-traces and so on.
+const DefaultExporterName = "otlp-mcp"
+
+// SnippetOptions configures a collector config fragment that tees
+// telemetry to otlp-mcp.
+type SnippetOptions struct {
+	// Endpoint is the otlp-mcp OTLP gRPC address the collector exports to:
+	// host:port or unix:///path. Wildcard hosts become localhost.
+	Endpoint string
+
+	Name    string   // Exporter name suffix, default DefaultExporterName
+	Signals []string // Signals to tee, default all
+
+	// Base is the collector config being extended. When set, its pipelines
+	// carrying the signals are reproduced with the new exporters appended,
+	// since the collector replaces lists when merging config files.
+	Base *Config
+
+	// FileDir, when set, also adds file exporters writing the
+	// FileDir/<signal>/<signal>.jsonl layout file sources read.
+	FileDir  string
+	Rotation Rotation
+}
+
+// Rotation is the file exporter rotation policy. Zero fields are left to
+// the collector's defaults.
+type Rotation struct {
+	MaxMegabytes int
+	MaxDays      int
+	MaxBackups   int
+	LocalTime    bool
+}
+
+// Snippet is a generated collector config fragment.
+type Snippet struct {
+	YAML      string
+	Exporters []string // Exporter IDs added
+	Pipelines []string // Pipelines the exporters were added to
+}
+
+type snippetDoc struct {
+	Exporters map[string]any `yaml:"exporters"`
+	Service   struct {
+		Pipelines map[string]snippetPipeline `yaml:"pipelines"`
+	} `yaml:"service"`
+}
+
+type snippetPipeline struct {
+	Exporters []string `yaml:"exporters,flow"`
+}
+
+type snippetOTLPExporter struct {
+	Endpoint string `yaml:"endpoint"`
+	TLS      struct {
+		Insecure bool `yaml:"insecure"`
+	} `yaml:"tls"`
+}
+
+type snippetFileExporter struct {
+	Path     string           `yaml:"path"`
+	Rotation *snippetRotation `yaml:"rotation,omitempty"`
+}
+
+type snippetRotation struct {
+	MaxMegabytes int  `yaml:"max_megabytes,omitempty"`
+	MaxDays      int  `yaml:"max_days,omitempty"`
+	MaxBackups   int  `yaml:"max_backups,omitempty"`
+	LocalTime    bool `yaml:"localtime,omitempty"`
+}
+
+// NewSnippet generates the exporters and service.pipelines fragment that
+// sends a collector's telemetry to otlp-mcp as well as wherever it goes now.
+func NewSnippet(opts SnippetOptions) (*Snippet, error) {
+	if opts.Endpoint == "" {
+		return nil, fmt.Errorf("no otlp-mcp OTLP endpoint to export to")
+	}
+	if opts.Name == "" {
+		opts.Name = DefaultExporterName
+	}
+	signals := Signals
+	if len(opts.Signals) > 0 {
+		signals = nil
+		for _, s := range opts.Signals {
+			if !isSignal(s) {
+				return nil, fmt.Errorf("unknown signal %q: want traces, logs or metrics", s)
+			}
+			signals = addSignal(signals, s)
+		}
+	}
+
+	doc := snippetDoc{Exporters: make(map[string]any)}
+	doc.Service.Pipelines = make(map[string]snippetPipeline)
+
+	otlpID := "otlp/" + opts.Name
+	otlp := snippetOTLPExporter{Endpoint: clientEndpoint(opts.Endpoint)}
+	otlp.TLS.Insecure = true
+	doc.Exporters[otlpID] = otlp
+
+	fileIDs := make(map[string]string)
+	if opts.FileDir != "" {
+		var rotation *snippetRotation
+		if opts.Rotation != (Rotation{}) {
+			rotation = &snippetRotation{
+				MaxMegabytes: opts.Rotation.MaxMegabytes,
+				MaxDays:      opts.Rotation.MaxDays,
+				MaxBackups:   opts.Rotation.MaxBackups,
+				LocalTime:    opts.Rotation.LocalTime,
+			}
+		}
+		for _, signal := range signals {
+			id := "file/" + opts.Name + "-" + signal
+			fileIDs[signal] = id
+			doc.Exporters[id] = snippetFileExporter{
+				Path:     filepath.Join(opts.FileDir, signal, signal+".jsonl"),
+				Rotation: rotation,
+			}
+		}
+	}
+
+	added := func(signal string) []string {
+		ids := []string{otlpID}
+		if id, ok := fileIDs[signal]; ok {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	var pipelines []string
+	if opts.Base != nil {
+		for _, p := range opts.Base.Pipelines {
+			if !slices.Contains(signals, p.Signal) {
+				continue
+			}
+			exporters := slices.Clone(p.Exporters)
+			for _, id := range added(p.Signal) {
+				if !slices.Contains(exporters, id) {
+					exporters = append(exporters, id)
+				}
+			}
+			doc.Service.Pipelines[p.Name] = snippetPipeline{Exporters: exporters}
+			pipelines = append(pipelines, p.Name)
+		}
+		if len(pipelines) == 0 {
+			return nil, fmt.Errorf("the collector config has no %s pipelines", strings.Join(signals, ", "))
+		}
+	} else {
+		for _, signal := range signals {
+			doc.Service.Pipelines[signal] = snippetPipeline{Exporters: added(signal)}
+			pipelines = append(pipelines, signal)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Tee telemetry to otlp-mcp. Merge into the collector config, or pass\n")
+	buf.WriteString("# as an extra --config: the collector merges maps but replaces lists.\n")
+	if opts.Base == nil {
+		buf.WriteString("# Add your pipelines' existing exporters to each list below, or they will\n")
+		buf.WriteString("# be dropped; generate with the collector config to have them filled in.\n")
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	exporters := []string{otlpID}
+	for _, signal := range signals {
+		if id, ok := fileIDs[signal]; ok {
+			exporters = append(exporters, id)
+		}
+	}
+	return &Snippet{YAML: buf.String(), Exporters: exporters, Pipelines: pipelines}, nil
+}
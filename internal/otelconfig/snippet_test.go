@@ -0,0 +1,84 @@
+package otelconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestNewSnippet(t *testing.T) {
+	snippet, err := NewSnippet(SnippetOptions{
+		Endpoint: "0.0.0.0:4317",
+		Signals:  []string{"logs", "traces"},
+		FileDir:  "/var/otel",
+		Rotation: Rotation{MaxMegabytes: 50, MaxBackups: 3},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"otlp/otlp-mcp", "file/otlp-mcp-traces", "file/otlp-mcp-logs"}, snippet.Exporters)
+	assert.Equal(t, []string{"traces", "logs"}, snippet.Pipelines)
+	assert.Contains(t, snippet.YAML, "existing exporters", "without a base config, warn that lists are replaced")
+
+	// The fragment is itself a config this package reads back: the file
+	// exporters follow the layout file sources expect.
+	cfg, err := Parse([]byte(snippet.YAML))
+	require.NoError(t, err)
+	assert.Equal(t, []FileSource{{Path: "/var/otel", Exporters: []string{"file/otlp-mcp-logs", "file/otlp-mcp-traces"}}}, cfg.FileSources())
+
+	var doc struct {
+		Exporters map[string]struct {
+			Endpoint string `yaml:"endpoint"`
+			TLS      struct {
+				Insecure bool `yaml:"insecure"`
+			} `yaml:"tls"`
+			Rotation map[string]int `yaml:"rotation"`
+		} `yaml:"exporters"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(snippet.YAML), &doc))
+	otlp := doc.Exporters["otlp/otlp-mcp"]
+	assert.Equal(t, "localhost:4317", otlp.Endpoint)
+	assert.True(t, otlp.TLS.Insecure)
+	assert.Equal(t, map[string]int{"max_megabytes": 50, "max_backups": 3}, doc.Exporters["file/otlp-mcp-traces"].Rotation)
+}
+
+func TestNewSnippetExtendsBase(t *testing.T) {
+	base, err := Parse([]byte(collectorConfig))
+	require.NoError(t, err)
+
+	snippet, err := NewSnippet(SnippetOptions{Endpoint: "unix:///run/otlp-mcp.sock", Name: "mcp", Base: base})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logs", "metrics/internal", "traces"}, snippet.Pipelines)
+	assert.NotContains(t, snippet.YAML, "existing exporters")
+
+	var doc struct {
+		Service struct {
+			Pipelines map[string]struct {
+				Exporters []string `yaml:"exporters"`
+			} `yaml:"pipelines"`
+		} `yaml:"service"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(snippet.YAML), &doc))
+	assert.Equal(t, []string{"file/traces", "file/everything", "debug", "otlp/mcp"}, doc.Service.Pipelines["traces"].Exporters)
+	assert.Contains(t, snippet.YAML, "endpoint: unix:///run/otlp-mcp.sock")
+
+	// Regenerating over a config that already has the exporter is a no-op
+	base.Pipelines[0].Exporters = append(base.Pipelines[0].Exporters, "otlp/mcp")
+	snippet, err = NewSnippet(SnippetOptions{Endpoint: "localhost:4317", Name: "mcp", Base: base, Signals: []string{"logs"}})
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal([]byte(snippet.YAML), &doc))
+	assert.Equal(t, []string{"file/logs", "file/everything", "otlp/mcp"}, doc.Service.Pipelines["logs"].Exporters)
+}
+
+func TestNewSnippetErrors(t *testing.T) {
+	_, err := NewSnippet(SnippetOptions{})
+	assert.ErrorContains(t, err, "no otlp-mcp OTLP endpoint")
+
+	_, err = NewSnippet(SnippetOptions{Endpoint: "localhost:4317", Signals: []string{"profiles"}})
+	assert.ErrorContains(t, err, "unknown signal")
+
+	base, err := Parse([]byte("service:\n  pipelines:\n    logs:\n      exporters: [debug]\n"))
+	require.NoError(t, err)
+	_, err = NewSnippet(SnippetOptions{Endpoint: "localhost:4317", Signals: []string{"traces"}, Base: base})
+	assert.ErrorContains(t, err, "no traces pipelines")
+}
@@ -0,0 +1,203 @@
+// Package otlpclient sends OTLP telemetry to a receiver over gRPC or
+// HTTP/protobuf. It is the sending half used by the replay and generate
+// commands, and works against otlp-mcp itself or any OTLP collector.
+package otlpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Supported export protocols, named as in OTEL_EXPORTER_OTLP_PROTOCOL.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+// Exporter sends OTLP batches to a single endpoint.
+type Exporter interface {
+	ExportSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error
+	ExportLogs(ctx context.Context, logs []*logspb.ResourceLogs) error
+	ExportMetrics(ctx context.Context, metrics []*metricspb.ResourceMetrics) error
+	Close() error
+}
+
+// Config selects the endpoint and protocol for New.
+type Config struct {
+	// Endpoint is host:port or unix:///path for gRPC, and a base URL such as
+	// http://127.0.0.1:4318 for HTTP (/v1/traces etc. are appended).
+	Endpoint string
+
+	// Protocol is "grpc" (default) or "http/protobuf" ("http" is accepted too).
+	Protocol string
+
+	// Headers are sent with every request (gRPC metadata or HTTP headers).
+	Headers map[string]string
+
+	// Timeout bounds each export call. Zero means 10s.
+	Timeout time.Duration
+}
+
+// New creates an Exporter for cfg. Connections are plaintext; OTLP over
+// TLS to remote collectors is out of scope for a local debugging tool.
+func New(cfg Config) (Exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("endpoint is required")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		return newGRPCExporter(cfg)
+	case ProtocolHTTP, "http":
+		return newHTTPExporter(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown protocol %q (use %q or %q)", cfg.Protocol, ProtocolGRPC, ProtocolHTTP)
+	}
+}
+
+// gRPC
+
+type grpcExporter struct {
+	conn    *grpc.ClientConn
+	traces  collectortrace.TraceServiceClient
+	logs    collectorlogs.LogsServiceClient
+	metrics collectormetrics.MetricsServiceClient
+	headers map[string]string
+	timeout time.Duration
+}
+
+func newGRPCExporter(cfg Config) (*grpcExporter, error) {
+	// Accept the http:// form exporters commonly use for gRPC endpoints too
+	target := strings.TrimPrefix(strings.TrimPrefix(cfg.Endpoint, "http://"), "https://")
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("create grpc client for %s: %w", target, err)
+	}
+	return &grpcExporter{
+		conn:    conn,
+		traces:  collectortrace.NewTraceServiceClient(conn),
+		logs:    collectorlogs.NewLogsServiceClient(conn),
+		metrics: collectormetrics.NewMetricsServiceClient(conn),
+		headers: cfg.Headers,
+		timeout: cfg.Timeout,
+	}, nil
+}
+
+func (e *grpcExporter) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	for k, v := range e.headers {
+		ctx = metadata.AppendToOutgoingContext(ctx, k, v)
+	}
+	return ctx, cancel
+}
+
+func (e *grpcExporter) ExportSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	_, err := e.traces.Export(ctx, &collectortrace.ExportTraceServiceRequest{ResourceSpans: spans})
+	return err
+}
+
+func (e *grpcExporter) ExportLogs(ctx context.Context, logs []*logspb.ResourceLogs) error {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	_, err := e.logs.Export(ctx, &collectorlogs.ExportLogsServiceRequest{ResourceLogs: logs})
+	return err
+}
+
+func (e *grpcExporter) ExportMetrics(ctx context.Context, metrics []*metricspb.ResourceMetrics) error {
+	ctx, cancel := e.callContext(ctx)
+	defer cancel()
+	_, err := e.metrics.Export(ctx, &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: metrics})
+	return err
+}
+
+func (e *grpcExporter) Close() error {
+	return e.conn.Close()
+}
+
+// HTTP/protobuf
+
+type httpExporter struct {
+	baseURL string
+	client  *http.Client
+	headers map[string]string
+}
+
+func newHTTPExporter(cfg Config) *httpExporter {
+	base := cfg.Endpoint
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	return &httpExporter{
+		baseURL: strings.TrimRight(base, "/"),
+		client:  &http.Client{Timeout: cfg.Timeout},
+		headers: cfg.Headers,
+	}
+}
+
+func (e *httpExporter) ExportSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	return e.post(ctx, "/v1/traces", &collectortrace.ExportTraceServiceRequest{ResourceSpans: spans})
+}
+
+func (e *httpExporter) ExportLogs(ctx context.Context, logs []*logspb.ResourceLogs) error {
+	return e.post(ctx, "/v1/logs", &collectorlogs.ExportLogsServiceRequest{ResourceLogs: logs})
+}
+
+func (e *httpExporter) ExportMetrics(ctx context.Context, metrics []*metricspb.ResourceMetrics) error {
+	return e.post(ctx, "/v1/metrics", &collectormetrics.ExportMetricsServiceRequest{ResourceMetrics: metrics})
+}
+
+func (e *httpExporter) post(ctx context.Context, path string, msg proto.Message) error {
+	body, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("POST %s: %s: %s", path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (e *httpExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 
 	collectorlogs "go.opentelemetry.io/proto/otlp/collector/logs/v1"
@@ -19,8 +23,16 @@ import (
 type Config struct {
 	Host string // e.g., "127.0.0.1"
 	Port int    // 0 for ephemeral port assignment
+
+	// Socket, when set, makes the primary listener a Unix domain socket
+	// instead of TCP. Accepts a plain path or "unix:///path".
+	Socket string
 }
 
+// UnixScheme is the prefix used to report Unix socket endpoints.
+// gRPC clients and OTLP exporters accept targets of the form unix:///path.
+const UnixScheme = "unix://"
+
 // UnifiedReceiver defines the interface for receiving all OTLP signal types.
 // This is typically implemented by storage.ObservabilityStorage.
 type UnifiedReceiver interface {
@@ -31,7 +43,8 @@ type UnifiedReceiver interface {
 
 // UnifiedServer is a single OTLP gRPC server that handles all three signal types.
 // This simplifies application configuration - only one endpoint needed.
-// It can listen on multiple ports simultaneously via AddPort().
+// It can listen on multiple ports simultaneously via AddPort(), and on Unix
+// domain sockets via AddSocket().
 type UnifiedServer struct {
 	host        string
 	listeners   []net.Listener
@@ -52,18 +65,22 @@ func NewUnifiedServer(cfg Config, receiver UnifiedReceiver) (*UnifiedServer, err
 		return nil, fmt.Errorf("receiver cannot be nil")
 	}
 
-	addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	var listener net.Listener
+	var err error
+	if cfg.Socket != "" {
+		listener, err = ListenUnix(cfg.Socket)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		addr := fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)
+		listener, err = net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
 	}
 
-	grpcServer := grpc.NewServer()
-
-	// Register all three OTLP services on the gRPC server
-	collectortrace.RegisterTraceServiceServer(grpcServer, &unifiedTraceService{receiver: receiver})
-	collectorlogs.RegisterLogsServiceServer(grpcServer, &unifiedLogsService{receiver: receiver})
-	collectormetrics.RegisterMetricsServiceServer(grpcServer, &unifiedMetricsService{receiver: receiver})
+	grpcServer := newGRPCServer(receiver)
 
 	server := &UnifiedServer{
 		host:        cfg.Host,
@@ -92,12 +109,17 @@ func (s *UnifiedServer) Start(ctx context.Context) error {
 		}
 	}()
 
-	// Serve on primary listener (index 0)
+	// Serve on primary listener (index 0). Ports and sockets may be added
+	// concurrently, so read it under the lock.
+	s.mu.Lock()
 	if len(s.listeners) == 0 {
+		s.mu.Unlock()
 		return fmt.Errorf("no listeners available")
 	}
+	grpcServer, listener := s.grpcServers[0], s.listeners[0]
+	s.mu.Unlock()
 
-	err := s.grpcServers[0].Serve(s.listeners[0])
+	err := grpcServer.Serve(listener)
 	s.stopDone <- struct{}{}
 	return err
 }
@@ -106,7 +128,11 @@ func (s *UnifiedServer) Start(ctx context.Context) error {
 // Safe to call multiple times.
 func (s *UnifiedServer) Stop() {
 	s.stopOnce.Do(func() {
-		for _, grpcServer := range s.grpcServers {
+		s.mu.Lock()
+		grpcServers := slices.Clone(s.grpcServers)
+		s.mu.Unlock()
+
+		for _, grpcServer := range grpcServers {
 			grpcServer.GracefulStop()
 		}
 		close(s.stopChan)
@@ -123,10 +149,13 @@ func (s *UnifiedServer) StopWait() {
 // This is particularly useful when using ephemeral ports (port 0).
 // Returns format "host:port", e.g., "127.0.0.1:54321"
 func (s *UnifiedServer) Endpoint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if len(s.listeners) == 0 {
 		return ""
 	}
-	return s.listeners[0].Addr().String()
+	return listenerEndpoint(s.listeners[0])
 }
 
 // Endpoints returns all listening addresses.
@@ -137,7 +166,7 @@ func (s *UnifiedServer) Endpoints() []string {
 
 	endpoints := make([]string, len(s.listeners))
 	for i, listener := range s.listeners {
-		endpoints[i] = listener.Addr().String()
+		endpoints[i] = listenerEndpoint(listener)
 	}
 	return endpoints
 }
@@ -156,30 +185,13 @@ func (s *UnifiedServer) AddPort(ctx context.Context, port int) error {
 		return fmt.Errorf("failed to bind to %s: %w", addr, err)
 	}
 
-	// Create new gRPC server
-	grpcServer := grpc.NewServer()
-
-	// Register all three OTLP services with existing receiver (shared storage)
-	collectortrace.RegisterTraceServiceServer(grpcServer, &unifiedTraceService{receiver: s.receiver})
-	collectorlogs.RegisterLogsServiceServer(grpcServer, &unifiedLogsService{receiver: s.receiver})
-	collectormetrics.RegisterMetricsServiceServer(grpcServer, &unifiedMetricsService{receiver: s.receiver})
-
-	// Add to lists
-	s.listeners = append(s.listeners, listener)
-	s.grpcServers = append(s.grpcServers, grpcServer)
-
-	// Start serving on new port in background.
-	// The listener is already bound, so it accepts connections immediately.
-	go func() {
-		_ = grpcServer.Serve(listener)
-	}()
-
+	s.serveAdditional(listener)
 	return nil
 }
 
 // RemovePort removes a listening port from the server.
 // The grpcServer on that port is gracefully stopped.
-// Cannot remove the last port - at least one port must remain active.
+// Cannot remove the last port, or the primary listener Start serves.
 func (s *UnifiedServer) RemovePort(port int) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -193,7 +205,7 @@ func (s *UnifiedServer) RemovePort(port int) error {
 	addr := fmt.Sprintf("%s:%d", s.host, port)
 	foundIndex := -1
 	for i, listener := range s.listeners {
-		if listener.Addr().String() == addr {
+		if listener.Addr().Network() == "tcp" && listener.Addr().String() == addr {
 			foundIndex = i
 			break
 		}
@@ -202,17 +214,160 @@ func (s *UnifiedServer) RemovePort(port int) error {
 	if foundIndex == -1 {
 		return fmt.Errorf("port %d not found in active listeners", port)
 	}
+	if foundIndex == 0 {
+		return fmt.Errorf("cannot remove port %d - it is the primary listener", port)
+	}
+
+	s.removeAt(foundIndex)
+	return nil
+}
+
+// AddSocket adds a Unix domain socket listener to the server, alongside any
+// existing TCP ports. The path may be given as a plain path or "unix:///path".
+// A stale socket file left behind by a previous run is replaced.
+func (s *UnifiedServer) AddSocket(ctx context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path = SocketPath(path)
+	for _, listener := range s.listeners {
+		if listener.Addr().Network() == "unix" && listener.Addr().String() == path {
+			return fmt.Errorf("already listening on socket %s", path)
+		}
+	}
+
+	listener, err := ListenUnix(path)
+	if err != nil {
+		return err
+	}
+
+	s.serveAdditional(listener)
+	return nil
+}
+
+// RemoveSocket removes a Unix domain socket listener from the server.
+// The socket file is unlinked when its listener closes.
+// Cannot remove the last listener, or the primary listener Start serves.
+func (s *UnifiedServer) RemoveSocket(path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.listeners) <= 1 {
+		return fmt.Errorf("cannot remove last listener - server must have at least one active listener")
+	}
 
-	// Gracefully stop the grpcServer for this port
-	s.grpcServers[foundIndex].GracefulStop()
+	path = SocketPath(path)
+	foundIndex := -1
+	for i, listener := range s.listeners {
+		if listener.Addr().Network() == "unix" && listener.Addr().String() == path {
+			foundIndex = i
+			break
+		}
+	}
 
-	// Remove from slices (preserve order)
-	s.listeners = append(s.listeners[:foundIndex], s.listeners[foundIndex+1:]...)
-	s.grpcServers = append(s.grpcServers[:foundIndex], s.grpcServers[foundIndex+1:]...)
+	if foundIndex == -1 {
+		return fmt.Errorf("socket %s not found in active listeners", path)
+	}
+	if foundIndex == 0 {
+		return fmt.Errorf("cannot remove socket %s - it is the primary listener", path)
+	}
 
+	s.removeAt(foundIndex)
 	return nil
 }
 
+// serveAdditional registers a new gRPC server for an already-bound listener
+// and starts serving it in the background. Caller must hold s.mu.
+func (s *UnifiedServer) serveAdditional(listener net.Listener) {
+	// All listeners share the same receiver (shared storage)
+	grpcServer := newGRPCServer(s.receiver)
+
+	s.listeners = append(s.listeners, listener)
+	s.grpcServers = append(s.grpcServers, grpcServer)
+
+	// The listener is already bound, so it accepts connections immediately.
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+}
+
+// removeAt gracefully stops the gRPC server at index i and drops it from
+// the listener lists, preserving order. i must not be 0, the primary
+// listener owned by Start. Caller must hold s.mu.
+func (s *UnifiedServer) removeAt(i int) {
+	s.grpcServers[i].GracefulStop()
+	// Serve may not have taken ownership of the listener yet; closing it
+	// here also unlinks a Unix socket file immediately.
+	s.listeners[i].Close()
+
+	s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+	s.grpcServers = append(s.grpcServers[:i], s.grpcServers[i+1:]...)
+}
+
+// newGRPCServer creates a gRPC server with all three OTLP services registered.
+func newGRPCServer(receiver UnifiedReceiver) *grpc.Server {
+	grpcServer := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(grpcServer, &unifiedTraceService{receiver: receiver})
+	collectorlogs.RegisterLogsServiceServer(grpcServer, &unifiedLogsService{receiver: receiver})
+	collectormetrics.RegisterMetricsServiceServer(grpcServer, &unifiedMetricsService{receiver: receiver})
+	return grpcServer
+}
+
+// SocketPath strips an optional "unix://" prefix from a socket address,
+// so "unix:///run/otlp-mcp/otlp.sock" and "/run/otlp-mcp/otlp.sock" are equivalent.
+func SocketPath(addr string) string {
+	return strings.TrimPrefix(addr, UnixScheme)
+}
+
+// IsSocketEndpoint reports whether an endpoint string refers to a Unix socket.
+func IsSocketEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, UnixScheme)
+}
+
+// ListenUnix binds a Unix domain socket at path (plain or "unix:///path").
+// The parent directory is created if needed, and a stale socket file from a
+// previous run is removed first. Regular files at the path are never removed.
+func ListenUnix(path string) (net.Listener, error) {
+	path = SocketPath(path)
+	if path == "" {
+		return nil, fmt.Errorf("socket path cannot be empty")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory for %s: %w", path, err)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("cannot bind to %s: file exists and is not a socket", path)
+		}
+		// A socket file nobody is listening on is left over from a crash
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("cannot bind to %s: socket is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// listenerEndpoint formats a listener address for clients:
+// "host:port" for TCP and "unix:///path" for Unix sockets.
+func listenerEndpoint(listener net.Listener) string {
+	addr := listener.Addr()
+	if addr.Network() == "unix" {
+		return UnixScheme + addr.String()
+	}
+	return addr.String()
+}
+
 // Service implementations
 
 type unifiedTraceService struct {
@@ -0,0 +1,211 @@
+package otlpreceiver
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// countingReceiver counts the spans it receives.
+type countingReceiver struct {
+	mu    sync.Mutex
+	spans int
+}
+
+func (r *countingReceiver) ReceiveSpans(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans += len(spans)
+	return nil
+}
+
+func (r *countingReceiver) ReceiveLogs(ctx context.Context, logs []*logspb.ResourceLogs) error {
+	return nil
+}
+
+func (r *countingReceiver) ReceiveMetrics(ctx context.Context, metrics []*metricspb.ResourceMetrics) error {
+	return nil
+}
+
+// socketDir returns a short temporary directory, since Unix socket paths
+// are limited to about 100 bytes and t.TempDir can be longer than that.
+func socketDir(t *testing.T) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "otlp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// exportSpan sends one empty resource span to endpoint over gRPC.
+func exportSpan(t *testing.T, endpoint string) {
+	t.Helper()
+	if !IsSocketEndpoint(endpoint) {
+		endpoint = "passthrough:///" + endpoint
+	}
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial %s: %v", endpoint, err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = collectortrace.NewTraceServiceClient(conn).Export(ctx, &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{}},
+	})
+	if err != nil {
+		t.Fatalf("export to %s: %v", endpoint, err)
+	}
+}
+
+func TestUnifiedServerSockets(t *testing.T) {
+	receiver := &countingReceiver{}
+	server, err := NewUnifiedServer(Config{Host: "127.0.0.1", Port: 0}, receiver)
+	if err != nil {
+		t.Fatalf("NewUnifiedServer failed: %v", err)
+	}
+	go server.Start(context.Background())
+	defer server.Stop()
+
+	path := filepath.Join(socketDir(t), "otlp.sock")
+	if err := server.AddSocket(context.Background(), UnixScheme+path); err != nil {
+		t.Fatalf("AddSocket failed: %v", err)
+	}
+	if err := server.AddSocket(context.Background(), path); err == nil {
+		t.Error("expected error adding the same socket twice")
+	}
+
+	endpoints := server.Endpoints()
+	if len(endpoints) != 2 || endpoints[1] != UnixScheme+path {
+		t.Fatalf("expected the socket as the second endpoint, got %v", endpoints)
+	}
+	exportSpan(t, endpoints[0])
+	exportSpan(t, endpoints[1])
+	receiver.mu.Lock()
+	if receiver.spans != 2 {
+		t.Errorf("expected a span over TCP and one over the socket, got %d", receiver.spans)
+	}
+	receiver.mu.Unlock()
+
+	if err := server.RemoveSocket(path); err != nil {
+		t.Fatalf("RemoveSocket failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the socket file to be removed, got %v", err)
+	}
+	if err := server.RemoveSocket(path); err == nil {
+		t.Error("expected error removing a socket that is gone")
+	}
+	if len(server.Endpoints()) != 1 {
+		t.Errorf("expected only the primary endpoint, got %v", server.Endpoints())
+	}
+}
+
+func TestUnifiedServerPrimarySocket(t *testing.T) {
+	path := filepath.Join(socketDir(t), "primary.sock")
+	server, err := NewUnifiedServer(Config{Socket: path}, &countingReceiver{})
+	if err != nil {
+		t.Fatalf("NewUnifiedServer failed: %v", err)
+	}
+	defer server.Stop()
+
+	if got := server.Endpoint(); got != UnixScheme+path {
+		t.Errorf("expected endpoint %s, got %s", UnixScheme+path, got)
+	}
+
+	// The primary listener stays, even with others to fall back on
+	if err := server.AddPort(context.Background(), 0); err != nil {
+		t.Fatalf("AddPort failed: %v", err)
+	}
+	if err := server.RemoveSocket(path); err == nil || !strings.Contains(err.Error(), "primary") {
+		t.Errorf("expected refusal to remove the primary socket, got %v", err)
+	}
+}
+
+func TestUnifiedServerRemovePrimaryPort(t *testing.T) {
+	server, err := NewUnifiedServer(Config{Host: "127.0.0.1", Port: 0}, &countingReceiver{})
+	if err != nil {
+		t.Fatalf("NewUnifiedServer failed: %v", err)
+	}
+	defer server.Stop()
+
+	_, portStr, err := net.SplitHostPort(server.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RemovePort(port); err == nil {
+		t.Error("expected error removing the last port")
+	}
+	if err := server.AddSocket(context.Background(), filepath.Join(socketDir(t), "extra.sock")); err != nil {
+		t.Fatalf("AddSocket failed: %v", err)
+	}
+	if err := server.RemovePort(port); err == nil || !strings.Contains(err.Error(), "primary") {
+		t.Errorf("expected refusal to remove the primary port, got %v", err)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := socketDir(t)
+
+	// A socket file nobody listens on is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	old, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.(*net.UnixListener).SetUnlinkOnClose(false)
+	old.Close()
+	if _, err := os.Stat(stale); err != nil {
+		t.Fatalf("expected the stale socket file to remain: %v", err)
+	}
+	listener, err := ListenUnix(UnixScheme + stale)
+	if err != nil {
+		t.Fatalf("ListenUnix over a stale socket failed: %v", err)
+	}
+	defer listener.Close()
+
+	// One in use is not
+	if _, err := ListenUnix(stale); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Errorf("expected error for a socket in use, got %v", err)
+	}
+
+	// Nor is a regular file
+	regular := filepath.Join(dir, "file")
+	if err := os.WriteFile(regular, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListenUnix(regular); err == nil {
+		t.Error("expected error binding over a regular file")
+	}
+
+	// Missing parent directories are created
+	nested, err := ListenUnix(filepath.Join(dir, "a", "b.sock"))
+	if err != nil {
+		t.Fatalf("ListenUnix in a new directory failed: %v", err)
+	}
+	nested.Close()
+
+	if _, err := ListenUnix(""); err == nil {
+		t.Error("expected error for an empty path")
+	}
+}
@@ -0,0 +1,428 @@
+// Package replay re-sends captured OTLP telemetry to a live endpoint,
+// optionally preserving the original inter-arrival timing. Input is the
+// collector file exporter layout that filereader understands, or a snapshot
+// range written by the export_snapshot tool.
+package replay
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/filereader"
+	"github.com/tobert/otlp-mcp/internal/otlpclient"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Options controls how items are re-sent.
+type Options struct {
+	// Speed scales the original inter-arrival timing: 1 replays in real
+	// time, 2 twice as fast, 0.5 at half speed. Zero sends as fast as possible.
+	Speed float64
+
+	// Loop repeats the replay until the context is cancelled.
+	Loop bool
+
+	// RewriteTimestamps shifts every timestamp by the same offset so the
+	// first record lands at the start of each iteration. Relative spacing
+	// and durations are preserved.
+	RewriteTimestamps bool
+
+	// RewriteTraceIDs replaces every trace ID with a fresh random one,
+	// consistently within an iteration so traces stay intact. Links, log
+	// records and exemplars referencing a trace are rewritten too.
+	RewriteTraceIDs bool
+
+	// OnIteration, if set, is called after each completed pass.
+	OnIteration func(Stats)
+}
+
+// Item is one batch to replay, with the time it was originally recorded.
+type Item struct {
+	Time  time.Time
+	Batch filereader.Batch
+}
+
+// Stats counts what has been sent.
+type Stats struct {
+	Iterations int
+	Batches    int
+	Spans      int
+	Logs       int
+	Metrics    int // data points
+}
+
+// Load reads items from files or directories. A directory may be a file
+// exporter base (with traces/, logs/, metrics/) or a single signal directory.
+// Files are decoded with filereader, so JSONL, protobuf and compressed files
+// all work, as do snapshot exports, whose JSONL mixes signals and has each
+// record's signal detected. Items are returned in original timestamp order.
+func Load(ctx context.Context, paths []string) ([]Item, error) {
+	var items []Item
+	var last time.Time
+
+	add := func(path, signal string) error {
+		return filereader.ReadFile(ctx, path, signal, func(b filereader.Batch) error {
+			t := batchTime(b)
+			if t.IsZero() {
+				t = last // Keep untimed batches next to their neighbours
+			}
+			last = t
+			items = append(items, Item{Time: t, Batch: b})
+			return nil
+		})
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(path, ""); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		signalDirs := []string{path}
+		signals := []string{filepath.Base(path)}
+		if !isSignal(signals[0]) {
+			signalDirs, signals = nil, nil
+			for _, signal := range []string{"traces", "logs", "metrics"} {
+				dir := filepath.Join(path, signal)
+				if st, err := os.Stat(dir); err == nil && st.IsDir() {
+					signalDirs = append(signalDirs, dir)
+					signals = append(signals, signal)
+				}
+			}
+			if len(signalDirs) == 0 {
+				return nil, fmt.Errorf("%s has no traces/, logs/ or metrics/ subdirectories", path)
+			}
+		}
+
+		for i, dir := range signalDirs {
+			files, err := filereader.DataFiles(dir)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				if err := add(file, signals[i]); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time.Before(items[j].Time)
+	})
+	return items, nil
+}
+
+// Run sends items to exp according to opts and returns totals across all
+// iterations. It stops at the first export error or when ctx is cancelled.
+func Run(ctx context.Context, exp otlpclient.Exporter, items []Item, opts Options) (Stats, error) {
+	var total Stats
+	if len(items) == 0 {
+		return total, fmt.Errorf("nothing to replay")
+	}
+
+	base := items[0].Time
+	for {
+		start := time.Now()
+		var ids map[string][]byte
+		if opts.RewriteTraceIDs {
+			ids = make(map[string][]byte)
+		}
+
+		for _, item := range items {
+			if opts.Speed > 0 && !base.IsZero() {
+				offset := time.Duration(float64(item.Time.Sub(base)) / opts.Speed)
+				if err := sleepUntil(ctx, start.Add(offset)); err != nil {
+					return total, err
+				}
+			} else if err := ctx.Err(); err != nil {
+				return total, err
+			}
+
+			batch := cloneBatch(item.Batch)
+			if opts.RewriteTimestamps && !base.IsZero() {
+				shiftBatch(batch, start.Sub(base))
+			}
+			if ids != nil {
+				rewriteTraceIDs(batch, ids)
+			}
+
+			if err := send(ctx, exp, batch, &total); err != nil {
+				return total, err
+			}
+		}
+
+		total.Iterations++
+		if opts.OnIteration != nil {
+			opts.OnIteration(total)
+		}
+		if !opts.Loop {
+			return total, nil
+		}
+	}
+}
+
+func isSignal(name string) bool {
+	return name == "traces" || name == "logs" || name == "metrics"
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func send(ctx context.Context, exp otlpclient.Exporter, b filereader.Batch, stats *Stats) error {
+	switch {
+	case b.Traces != nil && len(b.Traces.ResourceSpans) > 0:
+		if err := exp.ExportSpans(ctx, b.Traces.ResourceSpans); err != nil {
+			return fmt.Errorf("export spans: %w", err)
+		}
+		stats.Spans += countSpans(b.Traces)
+	case b.Logs != nil && len(b.Logs.ResourceLogs) > 0:
+		if err := exp.ExportLogs(ctx, b.Logs.ResourceLogs); err != nil {
+			return fmt.Errorf("export logs: %w", err)
+		}
+		stats.Logs += countLogs(b.Logs)
+	case b.Metrics != nil && len(b.Metrics.ResourceMetrics) > 0:
+		if err := exp.ExportMetrics(ctx, b.Metrics.ResourceMetrics); err != nil {
+			return fmt.Errorf("export metrics: %w", err)
+		}
+		stats.Metrics += countPoints(b.Metrics)
+	default:
+		return nil
+	}
+	stats.Batches++
+	return nil
+}
+
+func cloneBatch(b filereader.Batch) filereader.Batch {
+	var out filereader.Batch
+	if b.Traces != nil {
+		out.Traces = proto.Clone(b.Traces).(*tracepb.TracesData)
+	}
+	if b.Logs != nil {
+		out.Logs = proto.Clone(b.Logs).(*logspb.LogsData)
+	}
+	if b.Metrics != nil {
+		out.Metrics = proto.Clone(b.Metrics).(*metricspb.MetricsData)
+	}
+	return out
+}
+
+// batchTime returns the earliest record timestamp in a batch, or zero.
+func batchTime(b filereader.Batch) time.Time {
+	var earliest uint64
+	visit := func(ts *uint64) {
+		if *ts != 0 && (earliest == 0 || *ts < earliest) {
+			earliest = *ts
+		}
+	}
+	forEachRecordTime(b, visit)
+	if earliest == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(earliest))
+}
+
+// shiftBatch moves every non-zero timestamp in a batch by d.
+func shiftBatch(b filereader.Batch, d time.Duration) {
+	forEachTimestamp(b, func(ts *uint64) {
+		if *ts != 0 {
+			*ts = uint64(int64(*ts) + int64(d))
+		}
+	})
+}
+
+// forEachRecordTime visits the primary timestamp of each record: span start,
+// log time (or observed time), and data point time.
+func forEachRecordTime(b filereader.Batch, fn func(*uint64)) {
+	if b.Traces != nil {
+		for _, rs := range b.Traces.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					fn(&span.StartTimeUnixNano)
+				}
+			}
+		}
+	}
+	if b.Logs != nil {
+		for _, rl := range b.Logs.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				for _, rec := range sl.LogRecords {
+					if rec.TimeUnixNano != 0 {
+						fn(&rec.TimeUnixNano)
+					} else {
+						fn(&rec.ObservedTimeUnixNano)
+					}
+				}
+			}
+		}
+	}
+	if b.Metrics != nil {
+		forEachPoint(b.Metrics, func(ts, _ *uint64, _ []*metricspb.Exemplar) { fn(ts) })
+	}
+}
+
+// forEachTimestamp visits every timestamp field in a batch.
+func forEachTimestamp(b filereader.Batch, fn func(*uint64)) {
+	if b.Traces != nil {
+		for _, rs := range b.Traces.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					fn(&span.StartTimeUnixNano)
+					fn(&span.EndTimeUnixNano)
+					for _, ev := range span.Events {
+						fn(&ev.TimeUnixNano)
+					}
+				}
+			}
+		}
+	}
+	if b.Logs != nil {
+		for _, rl := range b.Logs.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				for _, rec := range sl.LogRecords {
+					fn(&rec.TimeUnixNano)
+					fn(&rec.ObservedTimeUnixNano)
+				}
+			}
+		}
+	}
+	if b.Metrics != nil {
+		forEachPoint(b.Metrics, func(ts, start *uint64, exemplars []*metricspb.Exemplar) {
+			fn(ts)
+			fn(start)
+			for _, ex := range exemplars {
+				fn(&ex.TimeUnixNano)
+			}
+		})
+	}
+}
+
+// forEachPoint visits the time, start time and exemplars of every data point.
+func forEachPoint(data *metricspb.MetricsData, fn func(ts, start *uint64, exemplars []*metricspb.Exemplar)) {
+	for _, rm := range data.ResourceMetrics {
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				switch d := m.Data.(type) {
+				case *metricspb.Metric_Gauge:
+					for _, dp := range d.Gauge.DataPoints {
+						fn(&dp.TimeUnixNano, &dp.StartTimeUnixNano, dp.Exemplars)
+					}
+				case *metricspb.Metric_Sum:
+					for _, dp := range d.Sum.DataPoints {
+						fn(&dp.TimeUnixNano, &dp.StartTimeUnixNano, dp.Exemplars)
+					}
+				case *metricspb.Metric_Histogram:
+					for _, dp := range d.Histogram.DataPoints {
+						fn(&dp.TimeUnixNano, &dp.StartTimeUnixNano, dp.Exemplars)
+					}
+				case *metricspb.Metric_ExponentialHistogram:
+					for _, dp := range d.ExponentialHistogram.DataPoints {
+						fn(&dp.TimeUnixNano, &dp.StartTimeUnixNano, dp.Exemplars)
+					}
+				case *metricspb.Metric_Summary:
+					for _, dp := range d.Summary.DataPoints {
+						fn(&dp.TimeUnixNano, &dp.StartTimeUnixNano, nil)
+					}
+				}
+			}
+		}
+	}
+}
+
+// rewriteTraceIDs replaces trace IDs using ids, which maps original IDs to
+// fresh ones and is filled in as new IDs are seen.
+func rewriteTraceIDs(b filereader.Batch, ids map[string][]byte) {
+	remap := func(id []byte) []byte {
+		if len(id) == 0 {
+			return id
+		}
+		fresh, ok := ids[string(id)]
+		if !ok {
+			fresh = make([]byte, len(id))
+			_, _ = rand.Read(fresh)
+			ids[string(id)] = fresh
+		}
+		return fresh
+	}
+
+	if b.Traces != nil {
+		for _, rs := range b.Traces.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, span := range ss.Spans {
+					span.TraceId = remap(span.TraceId)
+					for _, link := range span.Links {
+						link.TraceId = remap(link.TraceId)
+					}
+				}
+			}
+		}
+	}
+	if b.Logs != nil {
+		for _, rl := range b.Logs.ResourceLogs {
+			for _, sl := range rl.ScopeLogs {
+				for _, rec := range sl.LogRecords {
+					rec.TraceId = remap(rec.TraceId)
+				}
+			}
+		}
+	}
+	if b.Metrics != nil {
+		forEachPoint(b.Metrics, func(_, _ *uint64, exemplars []*metricspb.Exemplar) {
+			for _, ex := range exemplars {
+				ex.TraceId = remap(ex.TraceId)
+			}
+		})
+	}
+}
+
+func countSpans(data *tracepb.TracesData) int {
+	n := 0
+	for _, rs := range data.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			n += len(ss.Spans)
+		}
+	}
+	return n
+}
+
+func countLogs(data *logspb.LogsData) int {
+	n := 0
+	for _, rl := range data.ResourceLogs {
+		for _, sl := range rl.ScopeLogs {
+			n += len(sl.LogRecords)
+		}
+	}
+	return n
+}
+
+func countPoints(data *metricspb.MetricsData) int {
+	n := 0
+	forEachPoint(data, func(_, _ *uint64, _ []*metricspb.Exemplar) { n++ })
+	return n
+}
@@ -0,0 +1,260 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/filereader"
+	"github.com/tobert/otlp-mcp/internal/storage"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeExporter records everything sent to it.
+type fakeExporter struct {
+	mu    sync.Mutex
+	spans []*tracepb.Span
+	logs  []*logspb.LogRecord
+	sent  []time.Time
+}
+
+func (f *fakeExporter) ExportSpans(ctx context.Context, rs []*tracepb.ResourceSpans) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range rs {
+		for _, ss := range r.ScopeSpans {
+			f.spans = append(f.spans, ss.Spans...)
+		}
+	}
+	f.sent = append(f.sent, time.Now())
+	return nil
+}
+
+func (f *fakeExporter) ExportLogs(ctx context.Context, rl []*logspb.ResourceLogs) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range rl {
+		for _, sl := range r.ScopeLogs {
+			f.logs = append(f.logs, sl.LogRecords...)
+		}
+	}
+	f.sent = append(f.sent, time.Now())
+	return nil
+}
+
+func (f *fakeExporter) ExportMetrics(ctx context.Context, rm []*metricspb.ResourceMetrics) error {
+	return nil
+}
+
+func (f *fakeExporter) Close() error { return nil }
+
+var (
+	traceA = bytes.Repeat([]byte{0xaa}, 16)
+	traceB = bytes.Repeat([]byte{0xbb}, 16)
+	t0     = time.Date(2025, 12, 9, 14, 0, 0, 0, time.UTC)
+)
+
+func spanBatch(traceID []byte, name string, start time.Time) *tracepb.TracesData {
+	return &tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           traceID,
+			SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+			Name:              name,
+			StartTimeUnixNano: uint64(start.UnixNano()),
+			EndTimeUnixNano:   uint64(start.Add(50 * time.Millisecond).UnixNano()),
+		}}}},
+	}}}
+}
+
+func writeJSONL(t *testing.T, path string, msgs ...proto.Message) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, m := range msgs {
+		b, err := protojson.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(append(b, '\n'))
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOrdersAcrossSignals(t *testing.T) {
+	dir := t.TempDir()
+	writeJSONL(t, filepath.Join(dir, "traces", "traces.jsonl"),
+		spanBatch(traceA, "second", t0.Add(2*time.Second)),
+		spanBatch(traceB, "fourth", t0.Add(4*time.Second)),
+	)
+	writeJSONL(t, filepath.Join(dir, "logs", "logs.jsonl"),
+		&logspb.LogsData{ResourceLogs: []*logspb.ResourceLogs{{ScopeLogs: []*logspb.ScopeLogs{{
+			LogRecords: []*logspb.LogRecord{{TimeUnixNano: uint64(t0.Add(3 * time.Second).UnixNano()), TraceId: traceA}},
+		}}}}},
+	)
+
+	items, err := Load(context.Background(), []string{dir})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	if items[1].Batch.Logs == nil {
+		t.Error("expected the log batch between the two span batches")
+	}
+	if !items[0].Time.Equal(t0.Add(2 * time.Second)) {
+		t.Errorf("unexpected first item time %v", items[0].Time)
+	}
+}
+
+func TestRunRewrites(t *testing.T) {
+	items := []Item{
+		{Time: t0, Batch: batchOf(spanBatch(traceA, "root", t0))},
+		{Time: t0.Add(time.Second), Batch: batchOf(spanBatch(traceA, "child", t0.Add(time.Second)))},
+		{Time: t0.Add(2 * time.Second), Batch: batchOf(spanBatch(traceB, "other", t0.Add(2*time.Second)))},
+	}
+
+	exp := &fakeExporter{}
+	before := time.Now()
+	stats, err := Run(context.Background(), exp, items, Options{
+		RewriteTimestamps: true,
+		RewriteTraceIDs:   true,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if stats.Spans != 3 || stats.Batches != 3 || stats.Iterations != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	root, child, other := exp.spans[0], exp.spans[1], exp.spans[2]
+	if !bytes.Equal(root.TraceId, child.TraceId) {
+		t.Error("spans of one trace should share the rewritten trace ID")
+	}
+	if bytes.Equal(root.TraceId, traceA) || bytes.Equal(other.TraceId, traceB) {
+		t.Error("trace IDs should be rewritten")
+	}
+	if bytes.Equal(root.TraceId, other.TraceId) {
+		t.Error("distinct traces should get distinct IDs")
+	}
+
+	start := time.Unix(0, int64(root.StartTimeUnixNano))
+	if start.Before(before) || time.Since(start) > time.Minute {
+		t.Errorf("rewritten start %v should be near now", start)
+	}
+	if d := time.Duration(child.StartTimeUnixNano - root.StartTimeUnixNano); d != time.Second {
+		t.Errorf("expected original spacing of 1s, got %v", d)
+	}
+	if d := time.Duration(root.EndTimeUnixNano - root.StartTimeUnixNano); d != 50*time.Millisecond {
+		t.Errorf("expected duration preserved, got %v", d)
+	}
+
+	// The source items must not be modified
+	if !bytes.Equal(items[0].Batch.Traces.ResourceSpans[0].ScopeSpans[0].Spans[0].TraceId, traceA) {
+		t.Error("Run should not mutate the input items")
+	}
+}
+
+func TestRunTiming(t *testing.T) {
+	items := []Item{
+		{Time: t0, Batch: batchOf(spanBatch(traceA, "a", t0))},
+		{Time: t0.Add(2 * time.Second), Batch: batchOf(spanBatch(traceA, "b", t0.Add(2*time.Second)))},
+	}
+
+	exp := &fakeExporter{}
+	if _, err := Run(context.Background(), exp, items, Options{Speed: 20}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// 2s of original time at 20x is 100ms
+	gap := exp.sent[1].Sub(exp.sent[0])
+	if gap < 80*time.Millisecond || gap > time.Second {
+		t.Errorf("expected ~100ms between sends, got %v", gap)
+	}
+}
+
+func TestRunLoopStopsOnCancel(t *testing.T) {
+	items := []Item{{Time: t0, Batch: batchOf(spanBatch(traceA, "a", t0))}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var passes int
+	stats, err := Run(ctx, &fakeExporter{}, items, Options{
+		Loop:            true,
+		RewriteTraceIDs: true,
+		OnIteration: func(Stats) {
+			passes++
+			if passes == 3 {
+				cancel()
+			}
+		},
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if stats.Iterations != 3 {
+		t.Errorf("expected 3 iterations, got %d", stats.Iterations)
+	}
+}
+
+func batchOf(data *tracepb.TracesData) filereader.Batch {
+	return filereader.Batch{Traces: data}
+}
+
+func TestLoadExportedSnapshot(t *testing.T) {
+	obs := storage.NewObservabilityStorage(100, 100, 100)
+	ctx := context.Background()
+	if err := obs.CreateSnapshot("start"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if err := obs.ReceiveSpans(ctx, spanBatch(traceA, "root", t0).ResourceSpans); err != nil {
+		t.Fatalf("ReceiveSpans failed: %v", err)
+	}
+	if err := obs.ReceiveLogs(ctx, []*logspb.ResourceLogs{{ScopeLogs: []*logspb.ScopeLogs{{
+		LogRecords: []*logspb.LogRecord{{TimeUnixNano: uint64(t0.Add(time.Second).UnixNano()), TraceId: traceA}},
+	}}}}); err != nil {
+		t.Fatalf("ReceiveLogs failed: %v", err)
+	}
+	if err := obs.ReceiveSpans(ctx, spanBatch(traceB, "later", t0.Add(2*time.Second)).ResourceSpans); err != nil {
+		t.Fatalf("ReceiveSpans failed: %v", err)
+	}
+
+	data, err := obs.GetSnapshotData("start", "")
+	if err != nil {
+		t.Fatalf("GetSnapshotData failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "otlp-mcp-start.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := data.WriteJSONL(file); err != nil {
+		t.Fatalf("WriteJSONL failed: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Signals are detected per record and put back in timestamp order
+	items, err := Load(ctx, []string{path})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(items))
+	}
+	if items[0].Batch.Traces == nil || items[1].Batch.Logs == nil || items[2].Batch.Traces == nil {
+		t.Errorf("expected span, log, span batches in time order, got %+v", items)
+	}
+}
@@ -52,6 +52,10 @@ type ErrorEntry struct {
 	SpanName  string
 	ErrorMsg  string
 	Timestamp uint64 // Unix nano
+
+	// From the span's first exception event, if any
+	ExceptionType string
+	Stacktrace    string
 }
 
 // TraceEntry captures trace-level info for activity tracking.
@@ -65,6 +69,10 @@ type TraceEntry struct {
 	Timestamp  uint64 // Start time
 	SpanCount  int
 	HasRoot    bool // True if we've seen the actual root span
+
+	// Exception of the failing span, if it recorded one
+	ExceptionType string
+	Stacktrace    string
 }
 
 // MetricPeek holds the current value(s) of a metric for quick access.
@@ -149,14 +157,16 @@ func (h *ActivityCache) RecordSpan(span *StoredSpan) {
 
 	// Check for errors
 	if span.Span.Status != nil && span.Span.Status.Code == tracepb.Status_STATUS_CODE_ERROR {
-		errorMsg := span.Span.Status.Message
+		exc := firstException(span)
 		h.recentErrors.Add(&ErrorEntry{
-			TraceID:   span.TraceID,
-			SpanID:    span.SpanID,
-			Service:   span.ServiceName,
-			SpanName:  span.SpanName,
-			ErrorMsg:  errorMsg,
-			Timestamp: span.Span.StartTimeUnixNano,
+			TraceID:       span.TraceID,
+			SpanID:        span.SpanID,
+			Service:       span.ServiceName,
+			SpanName:      span.SpanName,
+			ErrorMsg:      errorMessage(span, exc),
+			Timestamp:     span.Span.StartTimeUnixNano,
+			ExceptionType: exc.Type,
+			Stacktrace:    exc.Stacktrace,
 		})
 	}
 
@@ -179,13 +189,15 @@ func (h *ActivityCache) updateTraceEntry(span *StoredSpan) {
 	// Calculate status
 	status := "UNSET"
 	var errorMsg string
+	var exc Exception
 	if span.Span.Status != nil {
 		switch span.Span.Status.Code {
 		case tracepb.Status_STATUS_CODE_OK:
 			status = "OK"
 		case tracepb.Status_STATUS_CODE_ERROR:
 			status = "ERROR"
-			errorMsg = span.Span.Status.Message
+			exc = firstException(span)
+			errorMsg = errorMessage(span, exc)
 		}
 	}
 
@@ -218,6 +230,8 @@ func (h *ActivityCache) updateTraceEntry(span *StoredSpan) {
 			if status == "ERROR" {
 				entry.Status = "ERROR"
 				entry.ErrorMsg = errorMsg
+				entry.ExceptionType = exc.Type
+				entry.Stacktrace = exc.Stacktrace
 			}
 			return
 		}
@@ -244,6 +258,9 @@ func (h *ActivityCache) updateTraceEntry(span *StoredSpan) {
 		Timestamp:  span.Span.StartTimeUnixNano,
 		SpanCount:  1,
 		HasRoot:    isRoot,
+
+		ExceptionType: exc.Type,
+		Stacktrace:    exc.Stacktrace,
 	}
 
 	h.recentTraces[key] = entry
@@ -256,6 +273,23 @@ func (h *ActivityCache) updateTraceEntry(span *StoredSpan) {
 	h.evictOldestTraces()
 }
 
+// firstException returns the first exception recorded on a span, or the
+// zero Exception if there is none.
+func firstException(span *StoredSpan) Exception {
+	if len(span.Exceptions) == 0 {
+		return Exception{}
+	}
+	return span.Exceptions[0]
+}
+
+// errorMessage prefers the status message, falling back to the exception's.
+func errorMessage(span *StoredSpan, exc Exception) string {
+	if span.Span.Status != nil && span.Span.Status.Message != "" {
+		return span.Span.Status.Message
+	}
+	return exc.Message
+}
+
 // updateInsertOrder updates the insertion order tracking.
 // If oldKey is non-empty, removes it. Adds newKey to the end.
 func (h *ActivityCache) updateInsertOrder(oldKey, newKey string) {
@@ -115,6 +115,39 @@ func TestActivityCacheRecordSpanWithError(t *testing.T) {
 	}
 }
 
+func TestActivityCacheRecordSpanWithException(t *testing.T) {
+	cache := NewActivityCache()
+
+	// Error status without a message; the exception event supplies one
+	span := &StoredSpan{
+		TraceID:     "error123",
+		SpanID:      "span456",
+		ServiceName: "test-service",
+		SpanName:    "failing-span",
+		Span: &tracepb.Span{
+			StartTimeUnixNano: 1000000000,
+			EndTimeUnixNano:   2000000000,
+			Status:            &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR},
+		},
+		Exceptions: []Exception{{Type: "ValueError", Message: "bad input", Stacktrace: "Traceback ..."}},
+	}
+
+	cache.RecordSpan(span)
+
+	errors := cache.RecentErrors(5)
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(errors))
+	}
+	if errors[0].ErrorMsg != "bad input" || errors[0].ExceptionType != "ValueError" || errors[0].Stacktrace != "Traceback ..." {
+		t.Errorf("expected exception details on the error entry, got %+v", errors[0])
+	}
+
+	traces := cache.RecentTraces(5)
+	if len(traces) != 1 || traces[0].ExceptionType != "ValueError" || traces[0].Stacktrace != "Traceback ..." {
+		t.Errorf("expected exception details on the trace entry, got %+v", traces)
+	}
+}
+
 func TestActivityCacheRecordLog(t *testing.T) {
 	cache := NewActivityCache()
 
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"encoding/hex"
 	"strconv"
 	"strings"
 
@@ -86,7 +87,7 @@ func matchesAttributeFilter(attributes []*commonpb.KeyValue, filter QueryFilter)
 		// Convert attributes to map for easier lookup
 		attrMap := make(map[string]string)
 		for _, attr := range attributes {
-			attrMap[attr.Key] = getAttributeStringValue(attr.Value)
+			attrMap[attr.Key] = AttributeString(attr.Value)
 		}
 
 		// All specified attribute key-value pairs must match
@@ -101,8 +102,9 @@ func matchesAttributeFilter(attributes []*commonpb.KeyValue, filter QueryFilter)
 	return true
 }
 
-// getAttributeStringValue extracts string representation of an attribute value
-func getAttributeStringValue(value *commonpb.AnyValue) string {
+// AttributeString returns the string form of a scalar attribute value, or
+// "" for arrays, maps and bytes.
+func AttributeString(value *commonpb.AnyValue) string {
 	if value == nil {
 		return ""
 	}
@@ -123,3 +125,59 @@ func getAttributeStringValue(value *commonpb.AnyValue) string {
 		return ""
 	}
 }
+
+// maxAttributeDepth limits recursion depth for nested OTLP attributes.
+const maxAttributeDepth = 10
+
+// AttributeValue converts an OTLP attribute value to a JSON-friendly Go
+// value. Bytes become hex strings; arrays and maps nested deeper than
+// maxAttributeDepth become nil.
+func AttributeValue(value *commonpb.AnyValue) any {
+	return attributeValueDepth(value, 0)
+}
+
+func attributeValueDepth(value *commonpb.AnyValue, depth int) any {
+	if value == nil || depth >= maxAttributeDepth {
+		return nil
+	}
+
+	switch v := value.Value.(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_IntValue:
+		return v.IntValue
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_BytesValue:
+		return hex.EncodeToString(v.BytesValue)
+	case *commonpb.AnyValue_ArrayValue:
+		result := make([]any, len(v.ArrayValue.Values))
+		for i, val := range v.ArrayValue.Values {
+			result[i] = attributeValueDepth(val, depth+1)
+		}
+		return result
+	case *commonpb.AnyValue_KvlistValue:
+		result := make(map[string]any)
+		for _, kv := range v.KvlistValue.Values {
+			result[kv.Key] = attributeValueDepth(kv.Value, depth+1)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// AttributeMap converts OTLP key/values with AttributeValue, or returns nil
+// if there are none.
+func AttributeMap(attrs []*commonpb.KeyValue) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, kv := range attrs {
+		m[kv.Key] = AttributeValue(kv.Value)
+	}
+	return m
+}
@@ -228,3 +228,30 @@ func TestMatchesAttributeFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestAttributeValue(t *testing.T) {
+	if got := AttributeValue(&commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte{0xab, 0x01}}}); got != "ab01" {
+		t.Errorf("expected bytes as hex, got %v", got)
+	}
+
+	// Nest arrays past the depth limit
+	value := &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "leaf"}}
+	for i := 0; i < maxAttributeDepth+2; i++ {
+		value = &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: []*commonpb.AnyValue{value}}}}
+	}
+	got := AttributeValue(value)
+	for depth := 0; depth < maxAttributeDepth; depth++ {
+		arr, ok := got.([]any)
+		if !ok || len(arr) != 1 {
+			t.Fatalf("depth %d: expected a one-element array, got %v", depth, got)
+		}
+		got = arr[0]
+	}
+	if got != nil {
+		t.Errorf("expected nil past the depth limit, got %v", got)
+	}
+
+	if m := AttributeMap(nil); m != nil {
+		t.Errorf("expected nil for no attributes, got %v", m)
+	}
+}
@@ -90,6 +90,24 @@ func (ls *LogStorage) GetLogsByTraceID(traceID string) []*StoredLog {
 	return result
 }
 
+// GetLogsByTraceIDs returns all currently stored logs belonging to any of
+// the given traces, in buffer order. This performs an in-memory scan.
+func (ls *LogStorage) GetLogsByTraceIDs(traceIDs []string) []*StoredLog {
+	want := make(map[string]bool, len(traceIDs))
+	for _, id := range traceIDs {
+		want[id] = true
+	}
+
+	var result []*StoredLog
+	for _, log := range ls.logs.GetAll() {
+		if want[log.TraceID] {
+			result = append(result, log)
+		}
+	}
+
+	return result
+}
+
 // GetLogsBySeverity returns all logs matching a severity level.
 // This performs an in-memory scan.
 func (ls *LogStorage) GetLogsBySeverity(severity string) []*StoredLog {
@@ -120,6 +138,12 @@ func (ls *LogStorage) GetLogsByService(serviceName string) []*StoredLog {
 	return result
 }
 
+// Resize changes how many logs are kept, dropping the oldest when shrinking.
+// Snapshot positions stay valid. It returns the number of logs dropped.
+func (ls *LogStorage) Resize(capacity int) int {
+	return ls.logs.Resize(capacity)
+}
+
 // GetRange returns logs between start and end positions (inclusive).
 // Positions are absolute and represent the logical sequence of logs added.
 func (ls *LogStorage) GetRange(start, end int) []*StoredLog {
@@ -155,6 +179,7 @@ func (ls *LogStorage) Stats() LogStorageStats {
 		TraceCount:   len(traceIDs),
 		ServiceCount: len(serviceNames),
 		Severities:   severities,
+		Evicted:      ls.logs.CurrentPosition() - len(all),
 	}
 }
 
@@ -170,6 +195,7 @@ type LogStorageStats struct {
 	TraceCount   int
 	ServiceCount int
 	Severities   map[string]int
+	Evicted      int // Logs received but since overwritten or resized away
 }
 
 // extractLogBody extracts the string body from an AnyValue.
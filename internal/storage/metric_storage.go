@@ -169,6 +169,12 @@ func (ms *MetricStorage) GetMetricNames() []string {
 	return names
 }
 
+// Resize changes how many metrics are kept, dropping the oldest when shrinking.
+// Snapshot positions stay valid. It returns the number of metrics dropped.
+func (ms *MetricStorage) Resize(capacity int) int {
+	return ms.metrics.Resize(capacity)
+}
+
 // GetRange returns metrics between start and end positions (inclusive).
 // Positions are absolute and represent the logical sequence of metrics added.
 func (ms *MetricStorage) GetRange(start, end int) []*StoredMetric {
@@ -205,6 +211,7 @@ func (ms *MetricStorage) Stats() MetricStorageStats {
 		ServiceCount:    len(serviceSet),
 		TypeCounts:      typeCounts,
 		TotalDataPoints: totalDataPoints,
+		Evicted:         ms.metrics.CurrentPosition() - len(all),
 	}
 }
 
@@ -221,6 +228,7 @@ type MetricStorageStats struct {
 	ServiceCount    int
 	TypeCounts      map[string]int
 	TotalDataPoints int
+	Evicted         int // Metrics received but since overwritten or resized away
 }
 
 // determineMetricType identifies the metric type from the proto message.
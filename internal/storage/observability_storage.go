@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
@@ -19,6 +20,8 @@ type ObservabilityStorage struct {
 	metrics       *MetricStorage
 	snapshots     *SnapshotManager
 	activityCache *ActivityCache
+
+	pinMu sync.Mutex // serializes applying snapshot pins to the buffers
 }
 
 // NewObservabilityStorage creates a unified storage layer with the specified capacities.
@@ -76,6 +79,10 @@ type SnapshotData struct {
 	Logs          []*StoredLog        `json:"logs"`
 	Metrics       []*StoredMetric     `json:"metrics"`
 	Summary       SnapshotDataSummary `json:"summary"`
+
+	// Annotations are the snapshots taken within the range, including its
+	// start and end, oldest first.
+	Annotations []*Snapshot `json:"annotations"`
 }
 
 // TimeRange represents a time window.
@@ -94,6 +101,9 @@ type SnapshotDataSummary struct {
 	TraceIDs      []string       `json:"trace_ids"`
 	LogSeverities map[string]int `json:"log_severities"`
 	MetricNames   []string       `json:"metric_names"`
+
+	// Validity of the snapshot range the data came from, if any
+	Validity *RangeValidity `json:"validity,omitempty"`
 }
 
 // GetSnapshotData retrieves all telemetry data between two snapshots.
@@ -108,13 +118,7 @@ func (os *ObservabilityStorage) GetSnapshotData(startSnapshot, endSnapshot strin
 	// Get end snapshot (or use current positions)
 	var endSnap *Snapshot
 	if endSnapshot == "" {
-		endSnap = &Snapshot{
-			Name:      "current",
-			CreatedAt: time.Now(),
-			TracePos:  os.traces.CurrentPosition(),
-			LogPos:    os.logs.CurrentPosition(),
-			MetricPos: os.metrics.CurrentPosition(),
-		}
+		endSnap = os.currentSnapshot()
 	} else {
 		endSnap, err = os.snapshots.Get(endSnapshot)
 		if err != nil {
@@ -136,8 +140,17 @@ func (os *ObservabilityStorage) GetSnapshotData(startSnapshot, endSnapshot strin
 	logs := os.logs.GetRange(startSnap.LogPos, endSnap.LogPos-1)
 	metrics := os.metrics.GetRange(startSnap.MetricPos, endSnap.MetricPos-1)
 
-	// Build summary
+	// Build summary, noting anything the buffers have already overwritten
 	summary := buildSnapshotSummary(traces, logs, metrics)
+	validity := os.rangeValidity(startSnap, endSnap)
+	summary.Validity = &validity
+
+	var annotations []*Snapshot
+	for _, snap := range os.snapshots.All() {
+		if !snap.CreatedAt.Before(startSnap.CreatedAt) && !snap.CreatedAt.After(endSnap.CreatedAt) {
+			annotations = append(annotations, snap)
+		}
+	}
 
 	return &SnapshotData{
 		StartSnapshot: startSnapshot,
@@ -147,10 +160,11 @@ func (os *ObservabilityStorage) GetSnapshotData(startSnapshot, endSnapshot strin
 			EndTime:   endSnap.CreatedAt,
 			Duration:  endSnap.CreatedAt.Sub(startSnap.CreatedAt).String(),
 		},
-		Traces:  traces,
-		Logs:    logs,
-		Metrics: metrics,
-		Summary: summary,
+		Traces:      traces,
+		Logs:        logs,
+		Metrics:     metrics,
+		Summary:     summary,
+		Annotations: annotations,
 	}, nil
 }
 
@@ -177,6 +191,13 @@ type QueryFilter struct {
 	// Attribute filters
 	HasAttribute    string            `json:"has_attribute,omitempty"`
 	AttributeEquals map[string]string `json:"attribute_equals,omitempty"`
+
+	// Span event filters
+	EventName     string `json:"event_name,omitempty"`     // span has an event with this name
+	ExceptionType string `json:"exception_type,omitempty"` // span recorded this exception type
+
+	// Span link filter: spans linked to or from this trace
+	LinkedTraceID string `json:"linked_trace_id,omitempty"`
 }
 
 // QueryResult contains filtered telemetry data across all signals.
@@ -193,6 +214,7 @@ func (os *ObservabilityStorage) Query(filter QueryFilter) (*QueryResult, error)
 	var traces []*StoredSpan
 	var logs []*StoredLog
 	var metrics []*StoredMetric
+	var validity *RangeValidity
 
 	// Determine data range
 	if filter.StartSnapshot != "" {
@@ -201,6 +223,7 @@ func (os *ObservabilityStorage) Query(filter QueryFilter) (*QueryResult, error)
 		if err != nil {
 			return nil, err
 		}
+		validity = data.Summary.Validity
 		traces = data.Traces
 		logs = data.Logs
 		metrics = data.Metrics
@@ -212,13 +235,13 @@ func (os *ObservabilityStorage) Query(filter QueryFilter) (*QueryResult, error)
 	}
 
 	// Apply filters to traces
-	traces = filterTraces(traces, filter)
+	traces = FilterTraces(traces, filter)
 
 	// Apply filters to logs
-	logs = filterLogs(logs, filter)
+	logs = FilterLogs(logs, filter)
 
 	// Apply filters to metrics
-	metrics = filterMetrics(metrics, filter)
+	metrics = FilterMetrics(metrics, filter)
 
 	// Apply limit if specified
 	if filter.Limit > 0 {
@@ -234,6 +257,7 @@ func (os *ObservabilityStorage) Query(filter QueryFilter) (*QueryResult, error)
 	}
 
 	summary := buildSnapshotSummary(traces, logs, metrics)
+	summary.Validity = validity
 
 	return &QueryResult{
 		Filter:  filter,
@@ -244,6 +268,53 @@ func (os *ObservabilityStorage) Query(filter QueryFilter) (*QueryResult, error)
 	}, nil
 }
 
+// QueryTraces returns every span of each trace with at least one span
+// matching filter, in buffer order. Whole traces are returned (within the
+// snapshot range, if any) so callers can rebuild span trees; Limit caps the
+// number of traces, keeping the most recent.
+func (os *ObservabilityStorage) QueryTraces(filter QueryFilter) ([]*StoredSpan, error) {
+	var spans []*StoredSpan
+	if filter.StartSnapshot != "" {
+		data, err := os.GetSnapshotData(filter.StartSnapshot, filter.EndSnapshot)
+		if err != nil {
+			return nil, err
+		}
+		spans = data.Traces
+	} else {
+		spans = os.traces.GetAllSpans()
+	}
+
+	// Trace IDs with a match, ordered by their last matching span
+	matched := make(map[string]int)
+	for i, span := range FilterTraces(spans, filter) {
+		matched[span.TraceID] = i
+	}
+	if filter.Limit > 0 && len(matched) > filter.Limit {
+		ids := make([]string, 0, len(matched))
+		for id := range matched {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return matched[ids[i]] > matched[ids[j]] })
+		for _, id := range ids[filter.Limit:] {
+			delete(matched, id)
+		}
+	}
+
+	result := make([]*StoredSpan, 0)
+	for _, span := range spans {
+		if _, ok := matched[span.TraceID]; ok {
+			result = append(result, span)
+		}
+	}
+	return result, nil
+}
+
+// FollowLinks walks span links across the whole buffer from traceID in both
+// directions; see FollowLinks.
+func (os *ObservabilityStorage) FollowLinks(traceID string, maxDepth int) []TraceLink {
+	return FollowLinks(os.traces.GetAllSpans(), traceID, maxDepth)
+}
+
 // AllStats returns comprehensive statistics across all signal types.
 type AllStats struct {
 	Traces    StorageStats       `json:"traces"`
@@ -290,7 +361,7 @@ func (os *ObservabilityStorage) Clear() {
 	os.traces.Clear()
 	os.logs.Clear()
 	os.metrics.Clear()
-	os.snapshots.Clear()
+	os.ClearSnapshots()
 	os.activityCache.Clear()
 }
 
@@ -313,6 +384,8 @@ func (os *ObservabilityStorage) ReceiveSpans(ctx context.Context, resourceSpans
 					SpanID:       spanIDToString(span.SpanId),
 					ServiceName:  serviceName,
 					SpanName:     span.Name,
+					Exceptions:   ExtractExceptions(span),
+					Links:        ExtractLinks(span),
 				}
 
 				os.traces.addSpan(stored)
@@ -432,7 +505,17 @@ func buildSnapshotSummary(traces []*StoredSpan, logs []*StoredLog, metrics []*St
 	}
 }
 
-func filterTraces(traces []*StoredSpan, filter QueryFilter) []*StoredSpan {
+// FilterTraces returns the spans matching every trace-applicable field of filter.
+// Snapshot range and limit are ignored; Query applies those.
+func FilterTraces(traces []*StoredSpan, filter QueryFilter) []*StoredSpan {
+	return FilterTracesLinked(traces, filter, traces)
+}
+
+// FilterTracesLinked is FilterTraces for part of the buffer, such as the
+// spans that just arrived on a stream. For filter.LinkedTraceID, the spans
+// it links to are found in linkedSpans, which should hold that trace's
+// stored spans, instead of in traces.
+func FilterTracesLinked(traces []*StoredSpan, filter QueryFilter, linkedSpans []*StoredSpan) []*StoredSpan {
 	// Check if ANY filter is set that applies to traces
 	hasServiceFilter := filter.ServiceName != ""
 	hasTraceIDFilter := filter.TraceID != ""
@@ -440,13 +523,21 @@ func filterTraces(traces []*StoredSpan, filter QueryFilter) []*StoredSpan {
 	hasStatusFilter := filter.ErrorsOnly || filter.SpanStatus != ""
 	hasDurationFilter := filter.MinDurationNs != nil || filter.MaxDurationNs != nil
 	hasAttributeFilter := filter.HasAttribute != "" || len(filter.AttributeEquals) > 0
+	hasEventFilter := filter.EventName != "" || filter.ExceptionType != ""
+	hasLinkFilter := filter.LinkedTraceID != ""
 
 	// If no filters, return all
 	if !hasServiceFilter && !hasTraceIDFilter && !hasSpanNameFilter &&
-		!hasStatusFilter && !hasDurationFilter && !hasAttributeFilter {
+		!hasStatusFilter && !hasDurationFilter && !hasAttributeFilter &&
+		!hasEventFilter && !hasLinkFilter {
 		return traces
 	}
 
+	var targets map[SpanLink]bool
+	if hasLinkFilter {
+		targets = linkTargets(linkedSpans, filter.LinkedTraceID)
+	}
+
 	result := make([]*StoredSpan, 0)
 	for _, span := range traces {
 		// Must match ALL specified filters
@@ -481,12 +572,27 @@ func filterTraces(traces []*StoredSpan, filter QueryFilter) []*StoredSpan {
 			}
 		}
 
+		// Span event filter
+		if hasEventFilter {
+			if !matchesEventFilter(span, filter) {
+				continue
+			}
+		}
+
+		// Span link filter
+		if hasLinkFilter {
+			if !matchesLinkFilter(span, filter, targets) {
+				continue
+			}
+		}
+
 		result = append(result, span)
 	}
 	return result
 }
 
-func filterLogs(logs []*StoredLog, filter QueryFilter) []*StoredLog {
+// FilterLogs returns the logs matching every log-applicable field of filter.
+func FilterLogs(logs []*StoredLog, filter QueryFilter) []*StoredLog {
 	// Check if ANY filter is set that applies to logs
 	hasServiceFilter := filter.ServiceName != ""
 	hasTraceIDFilter := filter.TraceID != ""
@@ -523,7 +629,9 @@ func filterLogs(logs []*StoredLog, filter QueryFilter) []*StoredLog {
 	return result
 }
 
-func filterMetrics(metrics []*StoredMetric, filter QueryFilter) []*StoredMetric {
+// FilterMetrics returns the metrics matching every metric-applicable field of
+// filter. A trace ID filter matches no metrics.
+func FilterMetrics(metrics []*StoredMetric, filter QueryFilter) []*StoredMetric {
 	// Check if ANY filter is set that applies to metrics
 	hasServiceFilter := filter.ServiceName != ""
 	hasMetricNamesFilter := len(filter.MetricNames) > 0
@@ -134,6 +134,43 @@ func TestObservabilityStorage_GetSnapshotData_CurrentEnd(t *testing.T) {
 	}
 }
 
+func TestObservabilityStorage_GetSnapshotData_Annotations(t *testing.T) {
+	obs := NewObservabilityStorage(100, 100, 100)
+
+	obs.CreateSnapshot("before")
+	time.Sleep(time.Millisecond)
+	obs.CreateSnapshot("deploy")
+	obs.Snapshots().Annotate("deploy", "rolled out v2", []string{"deploy"}, nil)
+	time.Sleep(time.Millisecond)
+	obs.CreateSnapshot("after")
+	time.Sleep(time.Millisecond)
+	obs.CreateSnapshot("later")
+
+	data, err := obs.GetSnapshotData("before", "after")
+	if err != nil {
+		t.Fatalf("GetSnapshotData failed: %v", err)
+	}
+	var names []string
+	for _, snap := range data.Annotations {
+		names = append(names, snap.Name)
+	}
+	if fmt.Sprint(names) != "[before deploy after]" {
+		t.Errorf("Expected annotations [before deploy after], got %v", names)
+	}
+	if data.Annotations[1].Description != "rolled out v2" {
+		t.Errorf("Expected the deploy description, got %q", data.Annotations[1].Description)
+	}
+
+	// Up to now includes every later snapshot
+	data, err = obs.GetSnapshotData("deploy", "")
+	if err != nil {
+		t.Fatalf("GetSnapshotData failed: %v", err)
+	}
+	if len(data.Annotations) != 3 {
+		t.Errorf("Expected 3 annotations up to now, got %d", len(data.Annotations))
+	}
+}
+
 func TestObservabilityStorage_Query(t *testing.T) {
 	obs := NewObservabilityStorage(100, 100, 100)
 
@@ -275,6 +312,39 @@ func TestObservabilityStorage_Query_WithSnapshots(t *testing.T) {
 	}
 }
 
+func TestObservabilityStorage_QueryTraces(t *testing.T) {
+	obs := NewObservabilityStorage(100, 100, 100)
+
+	addTestTrace(t, obs, "frontend", "trace1", "GET /")
+	addTestTrace(t, obs, "db", "trace1", "query")
+	addTestTrace(t, obs, "frontend", "trace2", "GET /")
+	addTestTrace(t, obs, "cache", "trace3", "get")
+	addTestTrace(t, obs, "db", "trace3", "query")
+
+	// Matching one span pulls in its whole trace
+	spans, err := obs.QueryTraces(QueryFilter{ServiceName: "db"})
+	if err != nil {
+		t.Fatalf("QueryTraces failed: %v", err)
+	}
+	if len(spans) != 4 {
+		t.Errorf("Expected 4 spans from trace1 and trace3, got %d", len(spans))
+	}
+
+	// Limit keeps the most recently matched traces
+	spans, err = obs.QueryTraces(QueryFilter{ServiceName: "db", Limit: 1})
+	if err != nil {
+		t.Fatalf("QueryTraces failed: %v", err)
+	}
+	trace3HexID := fmt.Sprintf("%x", []byte("trace3"))
+	if len(spans) != 2 || spans[0].TraceID != trace3HexID {
+		t.Errorf("Expected the 2 spans of trace3, got %d", len(spans))
+	}
+
+	if _, err := obs.QueryTraces(QueryFilter{StartSnapshot: "missing"}); err == nil {
+		t.Error("Expected error for unknown snapshot")
+	}
+}
+
 func TestObservabilityStorage_Stats(t *testing.T) {
 	obs := NewObservabilityStorage(100, 200, 300)
 
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseQuery parses a compact, space-separated query string into a QueryFilter.
+// Terms are ANDed together:
+//
+//	service:NAME        spans, logs and metrics from one service
+//	span:NAME           span name (alias name:)
+//	trace:ID            one trace ID
+//	status:ok|error|unset
+//	errors              shortcut for status:error
+//	severity:TEXT       log severity text, e.g. severity:ERROR
+//	metric:A,B          metric names (repeatable)
+//	has:KEY             attribute KEY is present
+//	KEY=VALUE           attribute KEY equals VALUE (repeatable)
+//	duration>100ms      minimum span duration (a bare number means ms)
+//	duration<2s         maximum span duration
+//	event:NAME          span has an event NAME (event:exception for any exception)
+//	exception:TYPE      span recorded exception TYPE (qualified or not)
+//	linked:ID           spans linked to or from trace ID by span links
+//	from:SNAP to:SNAP   snapshot range
+//	limit:N             max results per signal
+//
+// Values containing spaces can be double-quoted: span:"GET /cart".
+func ParseQuery(query string) (QueryFilter, error) {
+	var filter QueryFilter
+
+	terms, err := splitQueryTerms(query)
+	if err != nil {
+		return filter, err
+	}
+
+	for _, term := range terms {
+		if term.key == "duration" {
+			if err := parseDurationTerm(&filter, term); err != nil {
+				return filter, err
+			}
+			continue
+		}
+
+		switch term.op {
+		case "":
+			if term.key != "errors" {
+				return filter, fmt.Errorf("unknown query term %q (want key:value, key=value or duration>N)", term.key)
+			}
+			filter.ErrorsOnly = true
+			continue
+		case "=":
+			if filter.AttributeEquals == nil {
+				filter.AttributeEquals = make(map[string]string)
+			}
+			filter.AttributeEquals[term.key] = term.value
+			continue
+		case ":":
+		default:
+			return filter, fmt.Errorf("operator %q is only supported for duration", term.op)
+		}
+
+		if term.value == "" {
+			return filter, fmt.Errorf("query term %q needs a value", term.key+":")
+		}
+		switch term.key {
+		case "service", "svc":
+			filter.ServiceName = term.value
+		case "span", "name":
+			filter.SpanName = term.value
+		case "trace":
+			filter.TraceID = strings.ToLower(term.value)
+		case "status":
+			status := strings.ToUpper(term.value)
+			if status != "OK" && status != "ERROR" && status != "UNSET" {
+				return filter, fmt.Errorf("status must be ok, error or unset, got %q", term.value)
+			}
+			filter.SpanStatus = status
+		case "severity", "sev":
+			filter.LogSeverity = term.value
+		case "metric":
+			for _, name := range strings.Split(term.value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					filter.MetricNames = append(filter.MetricNames, name)
+				}
+			}
+		case "has":
+			filter.HasAttribute = term.value
+		case "event":
+			filter.EventName = term.value
+		case "exception", "exc":
+			filter.ExceptionType = term.value
+		case "linked":
+			filter.LinkedTraceID = strings.ToLower(term.value)
+		case "from":
+			filter.StartSnapshot = term.value
+		case "to":
+			filter.EndSnapshot = term.value
+		case "limit":
+			n, err := strconv.Atoi(term.value)
+			if err != nil || n < 0 {
+				return filter, fmt.Errorf("limit must be a non-negative integer, got %q", term.value)
+			}
+			filter.Limit = n
+		default:
+			return filter, fmt.Errorf("unknown query key %q", term.key)
+		}
+	}
+
+	return filter, nil
+}
+
+// queryTerm is one parsed term: key, operator (":", "=", ">", "<", ">=",
+// "<=" or empty for bare words) and unquoted value.
+type queryTerm struct {
+	key, op, value string
+}
+
+// splitQueryTerms tokenizes on whitespace outside double quotes and splits
+// each token at its first operator.
+func splitQueryTerms(query string) ([]queryTerm, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote, started := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if started {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				started = false
+			}
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in query %q", query)
+	}
+	if started {
+		tokens = append(tokens, cur.String())
+	}
+
+	terms := make([]queryTerm, 0, len(tokens))
+	for _, tok := range tokens {
+		i := strings.IndexAny(tok, ":=<>")
+		if i < 0 {
+			terms = append(terms, queryTerm{key: tok})
+			continue
+		}
+		if i == 0 {
+			return nil, fmt.Errorf("query term %q has no key", tok)
+		}
+		op := tok[i : i+1]
+		if (op == "<" || op == ">") && i+1 < len(tok) && tok[i+1] == '=' {
+			op += "="
+		}
+		terms = append(terms, queryTerm{key: tok[:i], op: op, value: tok[i+len(op):]})
+	}
+	return terms, nil
+}
+
+// parseDurationTerm handles duration>X and duration<X. The bounds are
+// inclusive, so > and >= (and < and <=) mean the same thing.
+func parseDurationTerm(filter *QueryFilter, term queryTerm) error {
+	d, err := time.ParseDuration(term.value)
+	if err != nil {
+		ms, numErr := strconv.ParseFloat(term.value, 64)
+		if numErr != nil {
+			return fmt.Errorf("invalid duration %q (use e.g. 250ms, 1.5s or a number of ms)", term.value)
+		}
+		d = time.Duration(ms * float64(time.Millisecond))
+	}
+	if d < 0 {
+		return fmt.Errorf("duration cannot be negative: %q", term.value)
+	}
+	ns := uint64(d)
+
+	switch term.op {
+	case ">", ">=":
+		filter.MinDurationNs = &ns
+	case "<", "<=":
+		filter.MaxDurationNs = &ns
+	default:
+		return fmt.Errorf("duration needs > or <, e.g. duration>100ms")
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	ms := func(n uint64) *uint64 { v := n * 1_000_000; return &v }
+
+	testCases := []struct {
+		name     string
+		query    string
+		expected QueryFilter
+	}{
+		{
+			name:     "empty",
+			query:    "  ",
+			expected: QueryFilter{},
+		},
+		{
+			name:  "basic_keys",
+			query: "service:cart span:checkout trace:ABCDEF status:error severity:WARN limit:5",
+			expected: QueryFilter{
+				ServiceName: "cart",
+				SpanName:    "checkout",
+				TraceID:     "abcdef",
+				SpanStatus:  "ERROR",
+				LogSeverity: "WARN",
+				Limit:       5,
+			},
+		},
+		{
+			name:  "quoted_values_and_attributes",
+			query: `span:"GET /cart" http.route="/cart/{id}" has:user.id errors`,
+			expected: QueryFilter{
+				SpanName:        "GET /cart",
+				AttributeEquals: map[string]string{"http.route": "/cart/{id}"},
+				HasAttribute:    "user.id",
+				ErrorsOnly:      true,
+			},
+		},
+		{
+			name:  "durations",
+			query: "duration>100ms duration<=2s",
+			expected: QueryFilter{
+				MinDurationNs: ms(100),
+				MaxDurationNs: ms(2000),
+			},
+		},
+		{
+			name:     "bare_number_duration_is_ms",
+			query:    "duration>=250",
+			expected: QueryFilter{MinDurationNs: ms(250)},
+		},
+		{
+			name:  "span_events",
+			query: "event:exception exception:IOException linked:ABC123",
+			expected: QueryFilter{
+				EventName:     "exception",
+				ExceptionType: "IOException",
+				LinkedTraceID: "abc123",
+			},
+		},
+		{
+			name:  "metrics_and_snapshots",
+			query: "metric:a,b metric:c from:before to:after",
+			expected: QueryFilter{
+				MetricNames:   []string{"a", "b", "c"},
+				StartSnapshot: "before",
+				EndSnapshot:   "after",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tc.query, err)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("ParseQuery(%q)\n got: %+v\nwant: %+v", tc.query, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"bogus",
+		"color:red",
+		"status:fine",
+		"service:",
+		"service>cart",
+		"duration:5s",
+		"duration>soon",
+		`span:"unterminated`,
+		":value",
+		"limit:-1",
+	} {
+		if _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) should fail", query)
+		}
+	}
+}
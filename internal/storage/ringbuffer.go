@@ -12,6 +12,13 @@ type RingBuffer[T any] struct {
 	head         int // next write position (wraps at capacity)
 	size         int // current number of items
 	totalWritten int // monotonically increasing count of all items ever added
+
+	// Pinning: while pinFrom >= 0, the buffer grows instead of overwriting
+	// items at or after position pinFrom, by up to pinBudget items beyond
+	// baseCapacity.
+	baseCapacity int
+	pinFrom      int
+	pinBudget    int
 }
 
 // NewRingBuffer creates a new ring buffer with the specified capacity.
@@ -22,10 +29,12 @@ func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
 	}
 
 	return &RingBuffer[T]{
-		items:    make([]T, capacity),
-		capacity: capacity,
-		head:     0,
-		size:     0,
+		items:        make([]T, capacity),
+		capacity:     capacity,
+		head:         0,
+		size:         0,
+		baseCapacity: capacity,
+		pinFrom:      -1,
 	}
 }
 
@@ -35,6 +44,13 @@ func (rb *RingBuffer[T]) Add(item T) {
 	rb.Lock()
 	defer rb.Unlock()
 
+	// Grow rather than overwrite a pinned item, while the budget lasts
+	if rb.size == rb.capacity && rb.pinFrom >= 0 && rb.totalWritten-rb.size >= rb.pinFrom {
+		if limit := rb.baseCapacity + rb.pinBudget; rb.capacity < limit {
+			rb.resize(min(rb.capacity+max(rb.baseCapacity/4, 1), limit))
+		}
+	}
+
 	rb.items[rb.head] = item
 	rb.head = (rb.head + 1) % rb.capacity
 	rb.totalWritten++
@@ -54,17 +70,12 @@ func (rb *RingBuffer[T]) GetAll() []T {
 		return nil
 	}
 
+	// Position p lives at index p % capacity; the oldest item may sit
+	// anywhere after a Resize, so copy from it to the end, then wrap.
 	result := make([]T, rb.size)
-
-	if rb.size < rb.capacity {
-		// Haven't wrapped yet - items are at beginning of buffer
-		copy(result, rb.items[:rb.size])
-	} else {
-		// Buffer has wrapped - head points to oldest item
-		// Copy from head to end, then from beginning to head
-		n := copy(result, rb.items[rb.head:])
-		copy(result[n:], rb.items[:rb.head])
-	}
+	oldest := (rb.totalWritten - rb.size) % rb.capacity
+	n := copy(result, rb.items[oldest:min(oldest+rb.size, rb.capacity)])
+	copy(result[n:], rb.items[:rb.size-n])
 
 	return result
 }
@@ -86,11 +97,121 @@ func (rb *RingBuffer[T]) Size() int {
 	return rb.size
 }
 
-// Capacity returns the maximum capacity of the buffer.
+// Capacity returns the maximum capacity of the buffer, including any
+// growth to keep pinned items.
 func (rb *RingBuffer[T]) Capacity() int {
+	rb.RLock()
+	defer rb.RUnlock()
 	return rb.capacity
 }
 
+// Resize changes the capacity of the buffer, keeping the newest items that
+// fit. Positions are unchanged, so CurrentPosition bookmarks taken before
+// a resize still work with GetRange. While pinned, the buffer keeps the
+// pinned items up to the new capacity plus the pin budget, as if it had
+// grown from the new capacity. It returns the number of items dropped to
+// shrink the buffer. The capacity must be greater than zero.
+func (rb *RingBuffer[T]) Resize(capacity int) int {
+	if capacity <= 0 {
+		panic("ring buffer capacity must be greater than zero")
+	}
+
+	rb.Lock()
+	defer rb.Unlock()
+
+	rb.baseCapacity = capacity
+	return rb.resize(rb.pinnedCapacity())
+}
+
+// pinnedCapacity returns the capacity needed to keep the pinned items
+// still in the buffer, within the pin budget, and never less than
+// baseCapacity. Caller must hold the lock.
+func (rb *RingBuffer[T]) pinnedCapacity() int {
+	if rb.pinFrom < 0 {
+		return rb.baseCapacity
+	}
+	pinned := rb.totalWritten - max(rb.pinFrom, rb.totalWritten-rb.size)
+	return max(rb.baseCapacity, min(pinned, rb.baseCapacity+rb.pinBudget))
+}
+
+// resize reallocates the buffer with a new capacity, keeping the newest
+// items and their positions. Caller must hold the lock.
+func (rb *RingBuffer[T]) resize(capacity int) int {
+	if capacity == rb.capacity {
+		return 0
+	}
+
+	kept := min(rb.size, capacity)
+	items := make([]T, capacity)
+	for pos := rb.totalWritten - kept; pos < rb.totalWritten; pos++ {
+		items[pos%capacity] = rb.items[pos%rb.capacity]
+	}
+
+	dropped := rb.size - kept
+	rb.items = items
+	rb.capacity = capacity
+	rb.head = rb.totalWritten % capacity
+	rb.size = kept
+	return dropped
+}
+
+// Pin keeps items at or after position from from being overwritten: when
+// full, the buffer grows instead, by up to budget items beyond its
+// capacity (budget <= 0 means the capacity itself). Once the budget is
+// spent, the oldest items are overwritten as usual. Pinning again from a
+// later position, or with a smaller budget, shrinks a grown buffer to what
+// the new pin still needs. It returns the number of items dropped.
+func (rb *RingBuffer[T]) Pin(from, budget int) int {
+	rb.Lock()
+	defer rb.Unlock()
+
+	if budget <= 0 {
+		budget = rb.baseCapacity
+	}
+	rb.pinFrom = from
+	rb.pinBudget = budget
+	if capacity := rb.pinnedCapacity(); capacity < rb.capacity {
+		return rb.resize(capacity)
+	}
+	return 0
+}
+
+// maxPinBudget returns the largest of budgets, each read the way Pin reads
+// it, so a budget <= 0 counts as the buffer's capacity.
+func (rb *RingBuffer[T]) maxPinBudget(budgets []int) int {
+	rb.RLock()
+	defer rb.RUnlock()
+
+	largest := 0
+	for _, budget := range budgets {
+		if budget <= 0 {
+			budget = rb.baseCapacity
+		}
+		largest = max(largest, budget)
+	}
+	return largest
+}
+
+// Unpin ends pinning and shrinks the buffer back to its capacity, dropping
+// the oldest items kept beyond it. It returns the number dropped.
+func (rb *RingBuffer[T]) Unpin() int {
+	rb.Lock()
+	defer rb.Unlock()
+
+	rb.pinFrom = -1
+	rb.pinBudget = 0
+	return rb.resize(rb.baseCapacity)
+}
+
+// OldestPosition returns the position of the oldest item still in the
+// buffer; items before it have been overwritten. It equals
+// CurrentPosition when the buffer is empty.
+func (rb *RingBuffer[T]) OldestPosition() int {
+	rb.RLock()
+	defer rb.RUnlock()
+	return rb.totalWritten - rb.size
+}
+
 // Clear removes all items from the buffer.
 func (rb *RingBuffer[T]) Clear() {
 	rb.Lock()
@@ -438,3 +438,150 @@ func TestRingBufferEmpty(t *testing.T) {
 		t.Errorf("expected size 0, got %d", rb.Size())
 	}
 }
+
+// TestRingBufferResize tests growing and shrinking keeps the newest items
+// and their positions.
+func TestRingBufferResize(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+	for i := 0; i < 6; i++ {
+		rb.Add(i) // wraps: holds 2, 3, 4, 5
+	}
+
+	if dropped := rb.Resize(6); dropped != 0 {
+		t.Fatalf("growing dropped %d items", dropped)
+	}
+	if rb.Capacity() != 6 || rb.Size() != 4 {
+		t.Fatalf("after grow: capacity %d size %d, want 6 and 4", rb.Capacity(), rb.Size())
+	}
+	assertInts(t, "after grow", rb.GetAll(), []int{2, 3, 4, 5})
+
+	rb.Add(6)
+	rb.Add(7)
+	rb.Add(8) // full again: 3..8
+	assertInts(t, "after grow and wrap", rb.GetAll(), []int{3, 4, 5, 6, 7, 8})
+
+	if dropped := rb.Resize(3); dropped != 3 {
+		t.Fatalf("shrinking dropped %d items, want 3", dropped)
+	}
+	assertInts(t, "after shrink", rb.GetAll(), []int{6, 7, 8})
+	if rb.CurrentPosition() != 9 {
+		t.Fatalf("position changed to %d", rb.CurrentPosition())
+	}
+
+	// Positions survive the resize: 6, 7, 8 were added at positions 6, 7, 8.
+	assertInts(t, "range", rb.GetRange(5, 7), []int{6, 7})
+
+	rb.Add(9)
+	assertInts(t, "after shrink and add", rb.GetAll(), []int{7, 8, 9})
+}
+
+func assertInts(t *testing.T, what string, got, want []int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %v, want %v", what, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got %v, want %v", what, got, want)
+		}
+	}
+}
+
+// TestRingBufferPin tests a pinned buffer grows instead of overwriting
+// pinned items, within its budget, and shrinks back when unpinned.
+func TestRingBufferPin(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+	for i := 0; i < 4; i++ {
+		rb.Add(i)
+	}
+	rb.Pin(2, 2)
+
+	// Items 0 and 1 are before the pin and still overwritten
+	rb.Add(4)
+	rb.Add(5)
+	if rb.OldestPosition() != 2 || rb.Capacity() != 4 {
+		t.Fatalf("before pinned items: oldest %d capacity %d, want 2 and 4", rb.OldestPosition(), rb.Capacity())
+	}
+
+	// Item 2 is pinned: the buffer grows by the budget instead
+	rb.Add(6)
+	rb.Add(7)
+	if rb.OldestPosition() != 2 || rb.Capacity() != 6 {
+		t.Fatalf("within budget: oldest %d capacity %d, want 2 and 6", rb.OldestPosition(), rb.Capacity())
+	}
+	assertInts(t, "within budget", rb.GetAll(), []int{2, 3, 4, 5, 6, 7})
+
+	// Budget spent: the oldest items go as usual
+	rb.Add(8)
+	if rb.OldestPosition() != 3 || rb.Capacity() != 6 {
+		t.Fatalf("budget spent: oldest %d capacity %d, want 3 and 6", rb.OldestPosition(), rb.Capacity())
+	}
+
+	if dropped := rb.Unpin(); dropped != 2 {
+		t.Fatalf("unpin dropped %d items, want 2", dropped)
+	}
+	assertInts(t, "after unpin", rb.GetAll(), []int{5, 6, 7, 8})
+	if rb.OldestPosition() != 5 || rb.CurrentPosition() != 9 {
+		t.Fatalf("after unpin: oldest %d current %d, want 5 and 9", rb.OldestPosition(), rb.CurrentPosition())
+	}
+}
+
+func TestRingBufferResizePinned(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+	rb.Pin(0, 4)
+	for i := 0; i < 8; i++ {
+		rb.Add(i)
+	}
+	if rb.Capacity() != 8 {
+		t.Fatalf("expected the pinned buffer to grow to 8, got %d", rb.Capacity())
+	}
+
+	// Shrinking keeps the pinned items the smaller capacity plus budget allows
+	if dropped := rb.Resize(2); dropped != 2 {
+		t.Fatalf("resize dropped %d items, want 2", dropped)
+	}
+	if rb.Capacity() != 6 {
+		t.Errorf("expected capacity 6 (2 plus a budget of 4), got %d", rb.Capacity())
+	}
+	assertInts(t, "after resize", rb.GetAll(), []int{2, 3, 4, 5, 6, 7})
+
+	// Growing past what the pin holds is a plain resize
+	if dropped := rb.Resize(10); dropped != 0 || rb.Capacity() != 10 {
+		t.Errorf("grow: dropped %d capacity %d, want 0 and 10", dropped, rb.Capacity())
+	}
+
+	// Unpinned items beyond the capacity are not kept
+	rb = NewRingBuffer[int](4)
+	for i := 0; i < 4; i++ {
+		rb.Add(i)
+	}
+	rb.Pin(3, 4)
+	if dropped := rb.Resize(2); dropped != 2 || rb.Capacity() != 2 {
+		t.Errorf("unpinned items: dropped %d capacity %d, want 2 and 2", dropped, rb.Capacity())
+	}
+}
+
+func TestRingBufferRepinShrinks(t *testing.T) {
+	rb := NewRingBuffer[int](4)
+	rb.Pin(0, 8)
+	for i := 0; i < 10; i++ {
+		rb.Add(i)
+	}
+	if rb.Capacity() != 10 {
+		t.Fatalf("expected the pinned buffer to grow to 10, got %d", rb.Capacity())
+	}
+
+	// Moving the pin forward releases what was kept only for the old one
+	if dropped := rb.Pin(5, 8); dropped != 5 {
+		t.Fatalf("repin dropped %d items, want 5", dropped)
+	}
+	if rb.Capacity() != 5 {
+		t.Errorf("expected capacity 5, got %d", rb.Capacity())
+	}
+	assertInts(t, "after repin", rb.GetAll(), []int{5, 6, 7, 8, 9})
+
+	// Never below the base capacity
+	if dropped := rb.Pin(9, 8); dropped != 1 || rb.Capacity() != 4 {
+		t.Errorf("repin near head: dropped %d capacity %d, want 1 and 4", dropped, rb.Capacity())
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// WriteJSONL writes the snapshot data as OTLP JSONL, the format of the
+// collector's file exporter: one TracesData, LogsData or MetricsData record
+// per line. Consecutive items that arrived in the same resource and scope
+// share a record, so the output is close to the original export batches.
+// Traces come first, then logs, then metrics. Returns the bytes written.
+func (d *SnapshotData) WriteJSONL(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var written int64
+	writeRecord := func(m proto.Message) error {
+		line, err := protojson.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		n, err := bw.Write(append(line, '\n'))
+		written += int64(n)
+		return err
+	}
+
+	for i := 0; i < len(d.Traces); {
+		first := d.Traces[i]
+		scope := &tracepb.ScopeSpans{Scope: first.ScopeSpan.GetScope(), SchemaUrl: first.ScopeSpan.GetSchemaUrl()}
+		for ; i < len(d.Traces) && d.Traces[i].ResourceSpan == first.ResourceSpan && d.Traces[i].ScopeSpan == first.ScopeSpan; i++ {
+			scope.Spans = append(scope.Spans, d.Traces[i].Span)
+		}
+		if err := writeRecord(&tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource:   first.ResourceSpan.GetResource(),
+			SchemaUrl:  first.ResourceSpan.GetSchemaUrl(),
+			ScopeSpans: []*tracepb.ScopeSpans{scope},
+		}}}); err != nil {
+			return written, err
+		}
+	}
+
+	for i := 0; i < len(d.Logs); {
+		first := d.Logs[i]
+		scope := &logspb.ScopeLogs{Scope: first.ScopeLog.GetScope(), SchemaUrl: first.ScopeLog.GetSchemaUrl()}
+		for ; i < len(d.Logs) && d.Logs[i].ResourceLog == first.ResourceLog && d.Logs[i].ScopeLog == first.ScopeLog; i++ {
+			scope.LogRecords = append(scope.LogRecords, d.Logs[i].LogRecord)
+		}
+		if err := writeRecord(&logspb.LogsData{ResourceLogs: []*logspb.ResourceLogs{{
+			Resource:  first.ResourceLog.GetResource(),
+			SchemaUrl: first.ResourceLog.GetSchemaUrl(),
+			ScopeLogs: []*logspb.ScopeLogs{scope},
+		}}}); err != nil {
+			return written, err
+		}
+	}
+
+	for i := 0; i < len(d.Metrics); {
+		first := d.Metrics[i]
+		scope := &metricspb.ScopeMetrics{Scope: first.ScopeMetric.GetScope(), SchemaUrl: first.ScopeMetric.GetSchemaUrl()}
+		for ; i < len(d.Metrics) && d.Metrics[i].ResourceMetric == first.ResourceMetric && d.Metrics[i].ScopeMetric == first.ScopeMetric; i++ {
+			scope.Metrics = append(scope.Metrics, d.Metrics[i].Metric)
+		}
+		if err := writeRecord(&metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource:     first.ResourceMetric.GetResource(),
+			SchemaUrl:    first.ResourceMetric.GetSchemaUrl(),
+			ScopeMetrics: []*metricspb.ScopeMetrics{scope},
+		}}}); err != nil {
+			return written, err
+		}
+	}
+
+	return written, bw.Flush()
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestSnapshotData_WriteJSONL(t *testing.T) {
+	obs := NewObservabilityStorage(100, 100, 100)
+	addTestTrace(t, obs, "ignored", "trace0", "before")
+	if err := obs.CreateSnapshot("start"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	// Two spans in one export share a record; a second export gets its own
+	now := uint64(time.Now().UnixNano())
+	if err := obs.ReceiveSpans(context.Background(), []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{
+			{TraceId: []byte("trace1"), SpanId: []byte("span1"), Name: "a", StartTimeUnixNano: now},
+			{TraceId: []byte("trace1"), SpanId: []byte("span2"), Name: "b", StartTimeUnixNano: now},
+		}}},
+	}}); err != nil {
+		t.Fatalf("ReceiveSpans failed: %v", err)
+	}
+	addTestTrace(t, obs, "service1", "trace2", "c")
+	addTestLog(t, obs, "service1", "INFO", "hello")
+	addTestMetric(t, obs, "service1", "requests", 1)
+
+	data, err := obs.GetSnapshotData("start", "")
+	if err != nil {
+		t.Fatalf("GetSnapshotData failed: %v", err)
+	}
+	var buf bytes.Buffer
+	n, err := data.WriteJSONL(&buf)
+	if err != nil {
+		t.Fatalf("WriteJSONL failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("expected %d bytes written, got %d", buf.Len(), n)
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		lines = append(lines, bytes.Clone(scanner.Bytes()))
+	}
+	if len(lines) != 4 {
+		t.Fatalf("expected 2 trace records, 1 log and 1 metric record, got %d lines", len(lines))
+	}
+
+	var traces tracepb.TracesData
+	if err := protojson.Unmarshal(lines[0], &traces); err != nil {
+		t.Fatalf("first line is not TracesData: %v", err)
+	}
+	if spans := traces.ResourceSpans[0].ScopeSpans[0].Spans; len(spans) != 2 || spans[0].Name != "a" || spans[1].Name != "b" {
+		t.Errorf("expected spans a and b in the first record, got %v", spans)
+	}
+	if err := protojson.Unmarshal(lines[1], &traces); err != nil {
+		t.Fatalf("second line is not TracesData: %v", err)
+	}
+	if got := extractServiceName(traces.ResourceSpans[0].Resource); got != "service1" {
+		t.Errorf("expected the resource to be kept, got service %q", got)
+	}
+
+	var logs logspb.LogsData
+	if err := protojson.Unmarshal(lines[2], &logs); err != nil {
+		t.Fatalf("third line is not LogsData: %v", err)
+	}
+	if body := logs.ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body.GetStringValue(); body != "hello" {
+		t.Errorf("expected log body hello, got %q", body)
+	}
+
+	var metrics metricspb.MetricsData
+	if err := protojson.Unmarshal(lines[3], &metrics); err != nil {
+		t.Fatalf("fourth line is not MetricsData: %v", err)
+	}
+	if name := metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Name; name != "requests" {
+		t.Errorf("expected metric requests, got %q", name)
+	}
+}
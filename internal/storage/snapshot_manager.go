@@ -2,6 +2,9 @@ package storage
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
@@ -14,13 +17,31 @@ type SnapshotManager struct {
 }
 
 // Snapshot represents a point-in-time bookmark across all storage buffers.
-// Each snapshot is only 24 bytes (3 ints) making them extremely lightweight.
+// A snapshot is just three buffer positions, making them extremely lightweight.
 type Snapshot struct {
 	Name      string
 	CreatedAt time.Time
 	TracePos  int // Position in trace buffer
 	LogPos    int // Position in log buffer
 	MetricPos int // Position in metric buffer
+
+	// Pinned snapshots keep the buffers from overwriting data after them,
+	// growing each buffer by up to PinBudget items instead. The same budget
+	// applies to the trace, log and metric buffers alike; 0 means each
+	// buffer's own capacity.
+	Pinned    bool
+	PinBudget int
+
+	// Annotations saying what the snapshot marks. Annotate replaces them
+	// rather than modifying them, so copies may share them.
+	Description string
+	Tags        []string
+	Metadata    map[string]string // e.g. git SHA, test name
+}
+
+// HasTag reports whether the snapshot has the given tag.
+func (s *Snapshot) HasTag(tag string) bool {
+	return slices.Contains(s.Tags, tag)
 }
 
 // NewSnapshotManager creates a new snapshot manager.
@@ -63,13 +84,75 @@ func (sm *SnapshotManager) Get(name string) (*Snapshot, error) {
 	}
 
 	// Return a copy to avoid concurrent modification
-	return &Snapshot{
-		Name:      snap.Name,
-		CreatedAt: snap.CreatedAt,
-		TracePos:  snap.TracePos,
-		LogPos:    snap.LogPos,
-		MetricPos: snap.MetricPos,
-	}, nil
+	copied := *snap
+	return &copied, nil
+}
+
+// All returns copies of all snapshots, oldest first.
+func (sm *SnapshotManager) All() []*Snapshot {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	all := make([]*Snapshot, 0, len(sm.snapshots))
+	for _, snap := range sm.snapshots {
+		copied := *snap
+		all = append(all, &copied)
+	}
+	slices.SortFunc(all, func(a, b *Snapshot) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return all
+}
+
+// Annotate sets a snapshot's description, tags and metadata, replacing
+// any it had. Tags are trimmed, and empty or repeated tags dropped.
+// Returns an error if the snapshot does not exist.
+func (sm *SnapshotManager) Annotate(name, description string, tags []string, metadata map[string]string) error {
+	var cleaned []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(cleaned, tag) {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	var copied map[string]string
+	if len(metadata) > 0 {
+		copied = maps.Clone(metadata)
+	}
+
+	sm.Lock()
+	defer sm.Unlock()
+
+	snap, exists := sm.snapshots[name]
+	if !exists {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+	snap.Description = description
+	snap.Tags = cleaned
+	snap.Metadata = copied
+	return nil
+}
+
+// SetPin pins or unpins a snapshot. budget is the most items each buffer
+// may grow by to keep its data (0 for the buffer's capacity).
+// Returns an error if the snapshot does not exist.
+func (sm *SnapshotManager) SetPin(name string, pinned bool, budget int) error {
+	sm.Lock()
+	defer sm.Unlock()
+
+	snap, exists := sm.snapshots[name]
+	if !exists {
+		return fmt.Errorf("snapshot %q not found", name)
+	}
+	snap.Pinned = pinned
+	snap.PinBudget = 0
+	if pinned {
+		snap.PinBudget = budget
+	}
+	return nil
 }
 
 // List returns the names of all snapshots.
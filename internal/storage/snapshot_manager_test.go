@@ -105,6 +105,36 @@ func TestSnapshotManagerDelete(t *testing.T) {
 	}
 }
 
+func TestSnapshotManagerAnnotate(t *testing.T) {
+	sm := NewSnapshotManager()
+	sm.Create("snap1", 100, 200, 300)
+
+	metadata := map[string]string{"git_sha": "abc123"}
+	err := sm.Annotate("snap1", "before deploy", []string{" deploy ", "", "baseline", "deploy"}, metadata)
+	if err != nil {
+		t.Fatalf("failed to annotate snapshot: %v", err)
+	}
+	metadata["git_sha"] = "changed"
+
+	snap, _ := sm.Get("snap1")
+	if snap.Description != "before deploy" {
+		t.Errorf("expected description 'before deploy', got %q", snap.Description)
+	}
+	if len(snap.Tags) != 2 || snap.Tags[0] != "deploy" || snap.Tags[1] != "baseline" {
+		t.Errorf("expected tags [deploy baseline], got %v", snap.Tags)
+	}
+	if !snap.HasTag("baseline") || snap.HasTag("other") {
+		t.Errorf("unexpected HasTag results for %v", snap.Tags)
+	}
+	if snap.Metadata["git_sha"] != "abc123" {
+		t.Errorf("expected metadata to be copied, got %v", snap.Metadata)
+	}
+
+	if err := sm.Annotate("missing", "", nil, nil); err == nil {
+		t.Error("expected error annotating non-existent snapshot")
+	}
+}
+
 func TestSnapshotManagerClear(t *testing.T) {
 	sm := NewSnapshotManager()
 
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Range validity statuses: whether the data between two snapshots is
+// still in the buffers.
+const (
+	RangeIntact  = "intact"  // every item in the range is buffered
+	RangePartial = "partial" // the oldest items in the range were overwritten
+	RangeEvicted = "evicted" // every item in the range was overwritten
+)
+
+// RangeValidity reports how much of a snapshot range has been evicted
+// from the ring buffers. Queries over a partial range silently miss the
+// lost items, so callers should surface it.
+type RangeValidity struct {
+	Status      string `json:"status"` // RangeIntact, RangePartial or RangeEvicted
+	LostSpans   int    `json:"lost_spans"`
+	LostLogs    int    `json:"lost_logs"`
+	LostMetrics int    `json:"lost_metrics"`
+}
+
+// Lost returns the number of items lost across all signals.
+func (v RangeValidity) Lost() int {
+	return v.LostSpans + v.LostLogs + v.LostMetrics
+}
+
+// SnapshotStatus is a snapshot with the validity of its range: the data
+// from it up to the next snapshot, or up to now for the latest one.
+type SnapshotStatus struct {
+	*Snapshot
+	Validity RangeValidity
+}
+
+// rangeValidity checks the range [start, end) against the oldest
+// positions still buffered.
+func (os *ObservabilityStorage) rangeValidity(start, end *Snapshot) RangeValidity {
+	v := RangeValidity{
+		LostSpans:   lostInRange(start.TracePos, end.TracePos, os.traces.spans.OldestPosition()),
+		LostLogs:    lostInRange(start.LogPos, end.LogPos, os.logs.logs.OldestPosition()),
+		LostMetrics: lostInRange(start.MetricPos, end.MetricPos, os.metrics.metrics.OldestPosition()),
+	}
+	total := max(end.TracePos-start.TracePos, 0) +
+		max(end.LogPos-start.LogPos, 0) +
+		max(end.MetricPos-start.MetricPos, 0)
+
+	switch lost := v.Lost(); {
+	case lost == 0:
+		v.Status = RangeIntact
+	case lost == total:
+		v.Status = RangeEvicted
+	default:
+		v.Status = RangePartial
+	}
+	return v
+}
+
+// lostInRange counts the positions in [start, end) before oldest.
+func lostInRange(start, end, oldest int) int {
+	return max(min(oldest, end)-start, 0)
+}
+
+// currentSnapshot is an unnamed snapshot of the current positions.
+func (os *ObservabilityStorage) currentSnapshot() *Snapshot {
+	return &Snapshot{
+		Name:      "current",
+		CreatedAt: time.Now(),
+		TracePos:  os.traces.CurrentPosition(),
+		LogPos:    os.logs.CurrentPosition(),
+		MetricPos: os.metrics.CurrentPosition(),
+	}
+}
+
+// SnapshotStatuses returns all snapshots, oldest first, each with the
+// validity of the data from it up to the next snapshot (or now).
+func (os *ObservabilityStorage) SnapshotStatuses() []SnapshotStatus {
+	all := os.snapshots.All()
+	current := os.currentSnapshot()
+
+	statuses := make([]SnapshotStatus, len(all))
+	for i, snap := range all {
+		end := current
+		if i+1 < len(all) {
+			end = all[i+1]
+		}
+		statuses[i] = SnapshotStatus{Snapshot: snap, Validity: os.rangeValidity(snap, end)}
+	}
+	return statuses
+}
+
+// SnapshotStatus returns one snapshot with the validity of its range, as
+// in SnapshotStatuses.
+func (os *ObservabilityStorage) SnapshotStatus(name string) (SnapshotStatus, error) {
+	for _, status := range os.SnapshotStatuses() {
+		if status.Name == name {
+			return status, nil
+		}
+	}
+	return SnapshotStatus{}, fmt.Errorf("snapshot %q not found", name)
+}
+
+// PinSnapshot keeps the data after a snapshot from being overwritten:
+// full buffers grow by up to budget items each instead (budget <= 0 means
+// each buffer's capacity). When several snapshots are pinned, the oldest
+// position and the largest budget apply.
+func (os *ObservabilityStorage) PinSnapshot(name string, budget int) error {
+	if err := os.snapshots.SetPin(name, true, budget); err != nil {
+		return err
+	}
+	os.applyPins()
+	return nil
+}
+
+// UnpinSnapshot releases a pinned snapshot. Buffers no longer pinned by
+// any snapshot shrink back to their capacity, and those still pinned from
+// a later snapshot shrink to what it needs, dropping the oldest data.
+func (os *ObservabilityStorage) UnpinSnapshot(name string) error {
+	if err := os.snapshots.SetPin(name, false, 0); err != nil {
+		return err
+	}
+	os.applyPins()
+	return nil
+}
+
+// DeleteSnapshot deletes a snapshot, releasing its pin if it had one.
+func (os *ObservabilityStorage) DeleteSnapshot(name string) error {
+	if err := os.snapshots.Delete(name); err != nil {
+		return err
+	}
+	os.applyPins()
+	return nil
+}
+
+// ClearSnapshots deletes all snapshots, releasing any pins.
+func (os *ObservabilityStorage) ClearSnapshots() {
+	os.snapshots.Clear()
+	os.applyPins()
+}
+
+// applyPins pins each buffer from the oldest pinned snapshot's position,
+// or unpins it when no snapshot is pinned. Either way, a buffer grown for
+// a pin that has been released shrinks back.
+func (os *ObservabilityStorage) applyPins() {
+	os.pinMu.Lock()
+	defer os.pinMu.Unlock()
+
+	var pinned []*Snapshot
+	for _, snap := range os.snapshots.All() {
+		if snap.Pinned {
+			pinned = append(pinned, snap)
+		}
+	}
+	if len(pinned) == 0 {
+		os.traces.spans.Unpin()
+		os.logs.logs.Unpin()
+		os.metrics.metrics.Unpin()
+		return
+	}
+
+	// Each buffer gets the largest budget of the pins, where a default
+	// budget is that buffer's own capacity
+	oldest := *pinned[0]
+	budgets := make([]int, 0, len(pinned))
+	for _, snap := range pinned {
+		oldest.TracePos = min(oldest.TracePos, snap.TracePos)
+		oldest.LogPos = min(oldest.LogPos, snap.LogPos)
+		oldest.MetricPos = min(oldest.MetricPos, snap.MetricPos)
+		budgets = append(budgets, snap.PinBudget)
+	}
+	os.traces.spans.Pin(oldest.TracePos, os.traces.spans.maxPinBudget(budgets))
+	os.logs.logs.Pin(oldest.LogPos, os.logs.logs.maxPinBudget(budgets))
+	os.metrics.metrics.Pin(oldest.MetricPos, os.metrics.metrics.maxPinBudget(budgets))
+}
@@ -0,0 +1,154 @@
+package storage
+
+import "testing"
+
+func TestObservabilityStorage_SnapshotValidity(t *testing.T) {
+	obs := NewObservabilityStorage(4, 4, 4)
+
+	obs.CreateSnapshot("first")
+	for i := 0; i < 3; i++ {
+		addTestLog(t, obs, "service1", "INFO", "first")
+	}
+	obs.CreateSnapshot("second")
+	for i := 0; i < 3; i++ {
+		addTestLog(t, obs, "service1", "INFO", "second")
+	}
+
+	// 6 logs in a buffer of 4: the first two are gone
+	statuses := obs.SnapshotStatuses()
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(statuses))
+	}
+	if v := statuses[0].Validity; v.Status != RangePartial || v.LostLogs != 2 {
+		t.Errorf("first: expected partial with 2 lost logs, got %+v", v)
+	}
+	if v := statuses[1].Validity; v.Status != RangeIntact || v.Lost() != 0 {
+		t.Errorf("second: expected intact, got %+v", v)
+	}
+
+	data, err := obs.GetSnapshotData("first", "")
+	if err != nil {
+		t.Fatalf("GetSnapshotData failed: %v", err)
+	}
+	if v := data.Summary.Validity; v == nil || v.Status != RangePartial || v.LostLogs != 2 {
+		t.Errorf("snapshot data: expected partial with 2 lost logs, got %+v", v)
+	}
+
+	for i := 0; i < 4; i++ {
+		addTestLog(t, obs, "service1", "INFO", "third")
+	}
+	status, err := obs.SnapshotStatus("first")
+	if err != nil {
+		t.Fatalf("SnapshotStatus failed: %v", err)
+	}
+	if status.Validity.Status != RangeEvicted || status.Validity.LostLogs != 3 {
+		t.Errorf("first: expected evicted with 3 lost logs, got %+v", status.Validity)
+	}
+
+	result, err := obs.Query(QueryFilter{StartSnapshot: "second"})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if v := result.Summary.Validity; v == nil || v.Status != RangePartial {
+		t.Errorf("query: expected partial validity, got %+v", v)
+	}
+}
+
+func TestObservabilityStorage_PinSnapshot(t *testing.T) {
+	obs := NewObservabilityStorage(4, 4, 4)
+	addTestLog(t, obs, "service1", "INFO", "before")
+
+	obs.CreateSnapshot("test")
+	if err := obs.PinSnapshot("test", 4); err != nil {
+		t.Fatalf("PinSnapshot failed: %v", err)
+	}
+	if err := obs.PinSnapshot("missing", 0); err == nil {
+		t.Error("Expected error pinning a missing snapshot")
+	}
+
+	for i := 0; i < 8; i++ {
+		addTestLog(t, obs, "service1", "INFO", "pinned")
+	}
+
+	// The log before the snapshot goes, then the buffer grows to keep all 8
+	status, _ := obs.SnapshotStatus("test")
+	if !status.Pinned || status.Validity.Status != RangeIntact {
+		t.Errorf("Expected pinned and intact, got pinned=%v %+v", status.Pinned, status.Validity)
+	}
+	if stats := obs.Logs().Stats(); stats.Capacity != 8 || stats.LogCount != 8 {
+		t.Errorf("Expected 8 logs in capacity 8, got %d in %d", stats.LogCount, stats.Capacity)
+	}
+
+	// Past the budget the oldest pinned logs go
+	addTestLog(t, obs, "service1", "INFO", "over budget")
+	status, _ = obs.SnapshotStatus("test")
+	if status.Validity.Status != RangePartial || status.Validity.LostLogs != 1 {
+		t.Errorf("Expected partial with 1 lost log, got %+v", status.Validity)
+	}
+
+	// Deleting the snapshot releases its pin
+	if err := obs.DeleteSnapshot("test"); err != nil {
+		t.Fatalf("DeleteSnapshot failed: %v", err)
+	}
+	if stats := obs.Logs().Stats(); stats.Capacity != 4 || stats.LogCount != 4 {
+		t.Errorf("Expected 4 logs in capacity 4 after unpin, got %d in %d", stats.LogCount, stats.Capacity)
+	}
+}
+
+func TestObservabilityStorage_PinnedResizeAndRelease(t *testing.T) {
+	obs := NewObservabilityStorage(4, 4, 4)
+	obs.CreateSnapshot("first")
+	if err := obs.PinSnapshot("first", 8); err != nil {
+		t.Fatalf("PinSnapshot failed: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		addTestLog(t, obs, "service1", "INFO", "first")
+	}
+	obs.CreateSnapshot("second")
+	if err := obs.PinSnapshot("second", 8); err != nil {
+		t.Fatalf("PinSnapshot failed: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		addTestLog(t, obs, "service1", "INFO", "second")
+	}
+	if stats := obs.Logs().Stats(); stats.Capacity != 8 || stats.LogCount != 8 {
+		t.Fatalf("Expected 8 logs in capacity 8, got %d in %d", stats.LogCount, stats.Capacity)
+	}
+
+	// Resizing while pinned keeps the snapshot's data
+	obs.Logs().Resize(2)
+	if status, _ := obs.SnapshotStatus("first"); status.Validity.Status != RangeIntact {
+		t.Errorf("Expected the pinned snapshot intact after resize, got %+v", status.Validity)
+	}
+
+	// Releasing the older pin shrinks to what the newer one needs
+	if err := obs.UnpinSnapshot("first"); err != nil {
+		t.Fatalf("UnpinSnapshot failed: %v", err)
+	}
+	if stats := obs.Logs().Stats(); stats.Capacity != 4 || stats.LogCount != 4 {
+		t.Errorf("Expected 4 logs in capacity 4 after releasing the older pin, got %d in %d", stats.LogCount, stats.Capacity)
+	}
+	if status, _ := obs.SnapshotStatus("second"); status.Validity.Status != RangeIntact {
+		t.Errorf("Expected the still pinned snapshot intact, got %+v", status.Validity)
+	}
+}
+
+func TestObservabilityStorage_PinDefaultBudget(t *testing.T) {
+	obs := NewObservabilityStorage(4, 4, 4)
+	obs.CreateSnapshot("small")
+	if err := obs.PinSnapshot("small", 2); err != nil {
+		t.Fatalf("PinSnapshot failed: %v", err)
+	}
+	obs.CreateSnapshot("default")
+	if err := obs.PinSnapshot("default", 0); err != nil {
+		t.Fatalf("PinSnapshot failed: %v", err)
+	}
+
+	// The default budget is the capacity, larger than the explicit 2
+	for i := 0; i < 12; i++ {
+		addTestLog(t, obs, "service1", "INFO", "pinned")
+	}
+	if stats := obs.Logs().Stats(); stats.Capacity != 8 || stats.LogCount != 8 {
+		t.Errorf("Expected 8 logs in capacity 8, got %d in %d", stats.LogCount, stats.Capacity)
+	}
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// ExceptionEventName is the span event name OpenTelemetry SDKs use when
+// recording an exception.
+const ExceptionEventName = "exception"
+
+// maxGroupTraceIDs caps the sample trace IDs kept per exception group.
+const maxGroupTraceIDs = 5
+
+// Exception is an exception recorded as a span event, following the
+// OpenTelemetry exception semantic conventions.
+type Exception struct {
+	Type       string // exception.type
+	Message    string // exception.message
+	Stacktrace string // exception.stacktrace
+	Timestamp  uint64 // Unix nano
+}
+
+// TopFrame returns the innermost frame of the exception's stack trace.
+func (e Exception) TopFrame() string {
+	return TopStackFrame(e.Stacktrace)
+}
+
+// ExtractExceptions returns the exceptions recorded on a span, in event order.
+func ExtractExceptions(span *tracepb.Span) []Exception {
+	if span == nil {
+		return nil
+	}
+	var exceptions []Exception
+	for _, ev := range span.Events {
+		if ev.Name != ExceptionEventName {
+			continue
+		}
+		exc := Exception{Timestamp: ev.TimeUnixNano}
+		for _, attr := range ev.Attributes {
+			switch attr.Key {
+			case "exception.type":
+				exc.Type = AttributeString(attr.Value)
+			case "exception.message":
+				exc.Message = AttributeString(attr.Value)
+			case "exception.stacktrace":
+				exc.Stacktrace = AttributeString(attr.Value)
+			}
+		}
+		exceptions = append(exceptions, exc)
+	}
+	return exceptions
+}
+
+// TopStackFrame returns the frame where an exception was raised, trimmed of
+// indentation, or "" if the stack trace has no recognizable frame. It
+// understands the common formats: Java/.NET and JavaScript "at ..." lines,
+// Python tracebacks (whose innermost frame comes last) and Go panics.
+func TopStackFrame(stacktrace string) string {
+	lines := strings.Split(strings.ReplaceAll(stacktrace, "\r\n", "\n"), "\n")
+
+	if strings.Contains(stacktrace, "Traceback (most recent call last)") {
+		for i := len(lines) - 1; i >= 0; i-- {
+			if line := strings.TrimSpace(lines[i]); strings.HasPrefix(line, `File "`) {
+				return line
+			}
+		}
+		return ""
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case strings.HasPrefix(line, "at "):
+			return strings.TrimPrefix(line, "at ")
+		case strings.HasPrefix(line, "goroutine "):
+			// Go: the function follows the header, its file:line after that;
+			// skip the frames of panic, debug.Stack and the OTel SDK itself
+			for j := i + 1; j < len(lines); j++ {
+				fn := strings.TrimSpace(lines[j])
+				if fn == "" || strings.HasPrefix(lines[j], "\t") || strings.HasPrefix(fn, "panic(") ||
+					strings.HasPrefix(fn, "runtime.") || strings.HasPrefix(fn, "runtime/") ||
+					strings.HasPrefix(fn, "go.opentelemetry.io/otel") {
+					continue
+				}
+				return fn
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// matchesEventFilter checks a span for the event name and exception type
+// criteria. Exception types match exactly or by their unqualified name, so
+// "IOException" matches "java.io.IOException".
+func matchesEventFilter(span *StoredSpan, filter QueryFilter) bool {
+	if filter.EventName != "" {
+		found := false
+		for _, ev := range span.Span.Events {
+			if ev.Name == filter.EventName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if filter.ExceptionType != "" {
+		for _, exc := range span.Exceptions {
+			if exc.Type == filter.ExceptionType || strings.HasSuffix(exc.Type, "."+filter.ExceptionType) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// ExceptionGroup aggregates exceptions sharing a type and top stack frame.
+type ExceptionGroup struct {
+	Type      string
+	TopFrame  string
+	Message   string // most recent message
+	Count     int
+	Services  []string
+	TraceIDs  []string // most recent first, up to maxGroupTraceIDs
+	FirstSeen uint64   // Unix nano
+	LastSeen  uint64   // Unix nano
+}
+
+// GroupExceptions groups the exceptions recorded on spans by type and top
+// stack frame, most frequent first.
+func GroupExceptions(spans []*StoredSpan) []ExceptionGroup {
+	type key struct{ typ, frame string }
+	groups := make(map[key]*ExceptionGroup)
+	services := make(map[key]map[string]bool)
+	var order []key
+
+	for _, span := range spans {
+		for _, exc := range span.Exceptions {
+			ts := exc.Timestamp
+			if ts == 0 && span.Span != nil {
+				ts = span.Span.StartTimeUnixNano
+			}
+			k := key{exc.Type, exc.TopFrame()}
+			g, ok := groups[k]
+			if !ok {
+				g = &ExceptionGroup{Type: k.typ, TopFrame: k.frame, FirstSeen: ts}
+				groups[k] = g
+				services[k] = make(map[string]bool)
+				order = append(order, k)
+			}
+			g.Count++
+			if ts < g.FirstSeen {
+				g.FirstSeen = ts
+			}
+			if ts >= g.LastSeen {
+				g.LastSeen = ts
+				g.Message = exc.Message
+			}
+			if !services[k][span.ServiceName] {
+				services[k][span.ServiceName] = true
+				g.Services = append(g.Services, span.ServiceName)
+			}
+			if !slices.Contains(g.TraceIDs, span.TraceID) {
+				g.TraceIDs = append([]string{span.TraceID}, g.TraceIDs...)
+				if len(g.TraceIDs) > maxGroupTraceIDs {
+					g.TraceIDs = g.TraceIDs[:maxGroupTraceIDs]
+				}
+			}
+		}
+	}
+
+	result := make([]ExceptionGroup, 0, len(order))
+	for _, k := range order {
+		g := groups[k]
+		sort.Strings(g.Services)
+		result = append(result, *g)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].LastSeen > result[j].LastSeen
+	})
+	return result
+}
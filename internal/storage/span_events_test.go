@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func exceptionEvent(typ, msg, stack string, ts uint64) *tracepb.Span_Event {
+	str := func(k, v string) *commonpb.KeyValue {
+		return &commonpb.KeyValue{Key: k, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}}
+	}
+	return &tracepb.Span_Event{
+		Name:         ExceptionEventName,
+		TimeUnixNano: ts,
+		Attributes: []*commonpb.KeyValue{
+			str("exception.type", typ),
+			str("exception.message", msg),
+			str("exception.stacktrace", stack),
+		},
+	}
+}
+
+func TestExtractExceptions(t *testing.T) {
+	span := &tracepb.Span{Events: []*tracepb.Span_Event{
+		{Name: "cache.miss", TimeUnixNano: 1},
+		exceptionEvent("ValueError", "bad input", "", 2),
+	}}
+
+	got := ExtractExceptions(span)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 exception, got %d", len(got))
+	}
+	if got[0].Type != "ValueError" || got[0].Message != "bad input" || got[0].Timestamp != 2 {
+		t.Errorf("unexpected exception: %+v", got[0])
+	}
+	if ExtractExceptions(nil) != nil {
+		t.Error("expected nil for a nil span")
+	}
+}
+
+func TestTopStackFrame(t *testing.T) {
+	testCases := []struct {
+		name  string
+		stack string
+		want  string
+	}{
+		{
+			name:  "java",
+			stack: "java.io.IOException: disk full\n\tat com.shop.Store.save(Store.java:42)\n\tat com.shop.Api.handle(Api.java:10)",
+			want:  "com.shop.Store.save(Store.java:42)",
+		},
+		{
+			name:  "javascript",
+			stack: "TypeError: x is undefined\n    at render (app.js:3:7)\n    at main (app.js:9:1)",
+			want:  "render (app.js:3:7)",
+		},
+		{
+			name:  "python_innermost_last",
+			stack: "Traceback (most recent call last):\n  File \"app.py\", line 9, in main\n    run()\n  File \"app.py\", line 3, in run\n    raise ValueError()\nValueError",
+			want:  `File "app.py", line 3, in run`,
+		},
+		{
+			name:  "go_skips_runtime_and_sdk",
+			stack: "goroutine 7 [running]:\nruntime/debug.Stack()\n\t/go/src/runtime/debug/stack.go:26 +0x5e\ngo.opentelemetry.io/otel/sdk/trace.recordStackTrace()\n\t/sdk/span.go:1 +0x1\nmain.checkout(0x1)\n\t/app/main.go:12 +0x1d",
+			want:  "main.checkout(0x1)",
+		},
+		{
+			name:  "unrecognized",
+			stack: "something went wrong",
+			want:  "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TopStackFrame(tc.stack); got != tc.want {
+				t.Errorf("TopStackFrame() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilterTracesByEvents(t *testing.T) {
+	st := NewObservabilityStorage(10, 10, 10)
+	st.ReceiveSpans(context.Background(), []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{
+			{Name: "plain", TraceId: []byte{1}, SpanId: []byte{1}},
+			{Name: "failed", TraceId: []byte{1}, SpanId: []byte{2}, Events: []*tracepb.Span_Event{
+				exceptionEvent("java.io.IOException", "disk full", "", 5),
+			}},
+		}}},
+	}})
+	spans := st.Traces().GetAllSpans()
+
+	testCases := []struct {
+		name   string
+		filter QueryFilter
+		want   int
+	}{
+		{"any_exception", QueryFilter{EventName: "exception"}, 1},
+		{"qualified_type", QueryFilter{ExceptionType: "java.io.IOException"}, 1},
+		{"unqualified_type", QueryFilter{ExceptionType: "IOException"}, 1},
+		{"partial_name_does_not_match", QueryFilter{ExceptionType: "Exception"}, 0},
+		{"other_event", QueryFilter{EventName: "cache.miss"}, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := FilterTraces(spans, tc.filter)
+			if len(got) != tc.want {
+				t.Errorf("expected %d spans, got %d", tc.want, len(got))
+			}
+		})
+	}
+}
+
+func TestGroupExceptions(t *testing.T) {
+	javaStack := func(frame string) string {
+		return "java.io.IOException: disk full\n\tat " + frame
+	}
+	span := func(service, traceID string, events ...*tracepb.Span_Event) *StoredSpan {
+		sp := &tracepb.Span{Events: events}
+		return &StoredSpan{Span: sp, TraceID: traceID, ServiceName: service, Exceptions: ExtractExceptions(sp)}
+	}
+	spans := []*StoredSpan{
+		span("api", "t1", exceptionEvent("IOException", "disk full", javaStack("Store.save"), 10)),
+		span("worker", "t2", exceptionEvent("IOException", "disk really full", javaStack("Store.save"), 30)),
+		span("api", "t3",
+			exceptionEvent("IOException", "read failed", javaStack("Store.load"), 20),
+			exceptionEvent("IOException", "disk full", javaStack("Store.save"), 25),
+		),
+	}
+
+	groups := GroupExceptions(spans)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", groups)
+	}
+	g := groups[0]
+	if g.TopFrame != "Store.save" || g.Count != 3 {
+		t.Errorf("expected Store.save group with 3 exceptions first, got %+v", g)
+	}
+	if g.Message != "disk really full" || g.FirstSeen != 10 || g.LastSeen != 30 {
+		t.Errorf("expected latest message and time range, got %+v", g)
+	}
+	if len(g.Services) != 2 || g.Services[0] != "api" || g.Services[1] != "worker" {
+		t.Errorf("expected services api, worker; got %v", g.Services)
+	}
+	if len(g.TraceIDs) != 3 || g.TraceIDs[0] != "t3" {
+		t.Errorf("expected trace IDs most recent first, got %v", g.TraceIDs)
+	}
+	if groups[1].TopFrame != "Store.load" || groups[1].Count != 1 {
+		t.Errorf("unexpected second group: %+v", groups[1])
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// DefaultLinkDepth is how many hops FollowLinks takes when asked for zero.
+const DefaultLinkDepth = 3
+
+// SpanLink is a link from a span to a span in another (or the same) trace,
+// as used by messaging and batch workloads to tie a consumer to its producers.
+type SpanLink struct {
+	TraceID string
+	SpanID  string
+}
+
+// ExtractLinks returns a span's links with hex-encoded IDs.
+func ExtractLinks(span *tracepb.Span) []SpanLink {
+	if span == nil || len(span.Links) == 0 {
+		return nil
+	}
+	links := make([]SpanLink, 0, len(span.Links))
+	for _, l := range span.Links {
+		links = append(links, SpanLink{
+			TraceID: traceIDToString(l.TraceId),
+			SpanID:  spanIDToString(l.SpanId),
+		})
+	}
+	return links
+}
+
+// TraceLink is one span link between two different traces. From is the span
+// that carries the link (typically the consumer), To the span it points at
+// (typically the producer).
+type TraceLink struct {
+	FromTraceID string
+	FromSpanID  string
+	ToTraceID   string
+	ToSpanID    string
+	Depth       int // hops from the starting trace, 1 for direct links
+}
+
+// FollowLinks walks span links outward from traceID in both directions:
+// links its spans carry and links other spans carry to it. Traces reached
+// are followed in turn, up to maxDepth hops (DefaultLinkDepth if <= 0).
+// Links within one trace are ignored. Results are ordered by depth.
+func FollowLinks(spans []*StoredSpan, traceID string, maxDepth int) []TraceLink {
+	if maxDepth <= 0 {
+		maxDepth = DefaultLinkDepth
+	}
+
+	// Every cross-trace link, indexed by both of its ends
+	byTrace := make(map[string][]TraceLink)
+	for _, span := range spans {
+		for _, l := range span.Links {
+			if l.TraceID == span.TraceID {
+				continue
+			}
+			link := TraceLink{FromTraceID: span.TraceID, FromSpanID: span.SpanID, ToTraceID: l.TraceID, ToSpanID: l.SpanID}
+			byTrace[link.FromTraceID] = append(byTrace[link.FromTraceID], link)
+			byTrace[link.ToTraceID] = append(byTrace[link.ToTraceID], link)
+		}
+	}
+
+	type linkKey struct{ from, to string }
+	seenLinks := make(map[linkKey]bool)
+	visited := map[string]bool{traceID: true}
+	frontier := []string{traceID}
+	var result []TraceLink
+
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		var next []string
+		for _, id := range frontier {
+			for _, link := range byTrace[id] {
+				key := linkKey{link.FromTraceID + "/" + link.FromSpanID, link.ToTraceID + "/" + link.ToSpanID}
+				if seenLinks[key] {
+					continue
+				}
+				seenLinks[key] = true
+				link.Depth = depth
+				result = append(result, link)
+
+				other := link.ToTraceID
+				if other == id {
+					other = link.FromTraceID
+				}
+				if !visited[other] {
+					visited[other] = true
+					next = append(next, other)
+				}
+			}
+		}
+		frontier = next
+	}
+	return result
+}
+
+// linkTargets returns the spans that spans of traceID link to.
+func linkTargets(spans []*StoredSpan, traceID string) map[SpanLink]bool {
+	targets := make(map[SpanLink]bool)
+	for _, span := range spans {
+		if span.TraceID != traceID {
+			continue
+		}
+		for _, l := range span.Links {
+			targets[l] = true
+		}
+	}
+	return targets
+}
+
+// matchesLinkFilter checks whether a span is linked to filter.LinkedTraceID in
+// either direction: it carries a link into that trace, or a span of that
+// trace links to it. targets is the result of linkTargets.
+func matchesLinkFilter(span *StoredSpan, filter QueryFilter, targets map[SpanLink]bool) bool {
+	if span.TraceID == filter.LinkedTraceID {
+		return false
+	}
+	for _, l := range span.Links {
+		if l.TraceID == filter.LinkedTraceID {
+			return true
+		}
+	}
+	return targets[SpanLink{TraceID: span.TraceID, SpanID: span.SpanID}]
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"testing"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// linkedSpan builds a stored span in traceID that links to the given
+// trace/span pairs.
+func linkedSpan(traceID, spanID string, links ...SpanLink) *StoredSpan {
+	return &StoredSpan{Span: &tracepb.Span{}, TraceID: traceID, SpanID: spanID, Links: links}
+}
+
+func TestExtractLinks(t *testing.T) {
+	span := &tracepb.Span{Links: []*tracepb.Span_Link{{
+		TraceId: []byte{0xab, 0xcd},
+		SpanId:  []byte{0x01, 0x02},
+	}}}
+	got := ExtractLinks(span)
+	if len(got) != 1 || got[0].TraceID != "abcd" || got[0].SpanID != "0102" {
+		t.Errorf("unexpected links: %+v", got)
+	}
+	if ExtractLinks(&tracepb.Span{}) != nil {
+		t.Error("expected nil for a span without links")
+	}
+}
+
+func TestFollowLinks(t *testing.T) {
+	// publish (p) <- consume (c) <- batch (b); a self-link inside c is ignored
+	spans := []*StoredSpan{
+		linkedSpan("p", "p1"),
+		linkedSpan("c", "c1", SpanLink{TraceID: "p", SpanID: "p1"}, SpanLink{TraceID: "c", SpanID: "c0"}),
+		linkedSpan("b", "b1", SpanLink{TraceID: "c", SpanID: "c1"}),
+		linkedSpan("x", "x1"),
+	}
+
+	// From the producer: the consumer one hop out, the batch two hops out
+	links := FollowLinks(spans, "p", 0)
+	if len(links) != 2 {
+		t.Fatalf("expected 2 links, got %+v", links)
+	}
+	if links[0] != (TraceLink{FromTraceID: "c", FromSpanID: "c1", ToTraceID: "p", ToSpanID: "p1", Depth: 1}) {
+		t.Errorf("unexpected first link: %+v", links[0])
+	}
+	if links[1].FromTraceID != "b" || links[1].Depth != 2 {
+		t.Errorf("expected the batch link at depth 2, got %+v", links[1])
+	}
+
+	// From the middle, both directions are direct
+	links = FollowLinks(spans, "c", 1)
+	if len(links) != 2 || links[0].Depth != 1 || links[1].Depth != 1 {
+		t.Errorf("expected two direct links from the consumer, got %+v", links)
+	}
+
+	if got := FollowLinks(spans, "p", 1); len(got) != 1 {
+		t.Errorf("expected maxDepth to stop after one hop, got %+v", got)
+	}
+	if got := FollowLinks(spans, "x", 0); len(got) != 0 {
+		t.Errorf("expected no links for an unlinked trace, got %+v", got)
+	}
+}
+
+func TestFilterTracesByLinkedTrace(t *testing.T) {
+	spans := []*StoredSpan{
+		linkedSpan("p", "p1"),
+		linkedSpan("p", "p2", SpanLink{TraceID: "u", SpanID: "u1"}),
+		linkedSpan("c", "c1", SpanLink{TraceID: "p", SpanID: "p1"}),
+		linkedSpan("u", "u1"),
+		linkedSpan("x", "x1"),
+	}
+
+	got := FilterTraces(spans, QueryFilter{LinkedTraceID: "p"})
+	if len(got) != 2 {
+		t.Fatalf("expected the consumer and the upstream span, got %d spans", len(got))
+	}
+	if got[0].SpanID != "c1" || got[1].SpanID != "u1" {
+		t.Errorf("unexpected spans: %s, %s", got[0].SpanID, got[1].SpanID)
+	}
+}
+
+func TestFilterTracesLinkedPartialBatch(t *testing.T) {
+	// The linking trace was in an earlier batch; only its target is new
+	linking := []*StoredSpan{linkedSpan("p", "p2", SpanLink{TraceID: "u", SpanID: "u1"})}
+	batch := []*StoredSpan{linkedSpan("u", "u1"), linkedSpan("x", "x1")}
+
+	if got := FilterTraces(batch, QueryFilter{LinkedTraceID: "p"}); len(got) != 0 {
+		t.Errorf("expected no match without the linking spans, got %d", len(got))
+	}
+	got := FilterTracesLinked(batch, QueryFilter{LinkedTraceID: "p"}, linking)
+	if len(got) != 1 || got[0].SpanID != "u1" {
+		t.Errorf("expected the upstream span, got %d spans", len(got))
+	}
+}
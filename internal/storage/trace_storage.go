@@ -20,6 +20,12 @@ type StoredSpan struct {
 	SpanID      string
 	ServiceName string
 	SpanName    string
+
+	// Exceptions recorded as span events, extracted on receipt
+	Exceptions []Exception
+
+	// Links to spans in other traces, extracted on receipt
+	Links []SpanLink
 }
 
 // TraceStorage stores OTLP trace spans without content indexes.
@@ -52,6 +58,8 @@ func (ts *TraceStorage) ReceiveSpans(ctx context.Context, resourceSpans []*trace
 					SpanID:       spanIDToString(span.SpanId),
 					ServiceName:  serviceName,
 					SpanName:     span.Name,
+					Exceptions:   ExtractExceptions(span),
+					Links:        ExtractLinks(span),
 				}
 
 				ts.addSpan(stored)
@@ -135,6 +143,7 @@ func (ts *TraceStorage) Stats() StorageStats {
 		SpanCount:  ts.spans.Size(),
 		Capacity:   ts.spans.Capacity(),
 		TraceCount: len(traceIDs),
+		Evicted:    ts.spans.CurrentPosition() - len(all),
 	}
 }
 
@@ -143,6 +152,12 @@ func (ts *TraceStorage) Clear() {
 	ts.spans.Clear()
 }
 
+// Resize changes how many spans are kept, dropping the oldest when shrinking.
+// Snapshot positions stay valid. It returns the number of spans dropped.
+func (ts *TraceStorage) Resize(capacity int) int {
+	return ts.spans.Resize(capacity)
+}
+
 // GetRange returns spans between start and end positions (inclusive).
 // Positions are absolute and represent the logical sequence of spans added.
 func (ts *TraceStorage) GetRange(start, end int) []*StoredSpan {
@@ -160,6 +175,7 @@ type StorageStats struct {
 	SpanCount  int // Current number of spans stored
 	Capacity   int // Maximum number of spans that can be stored
 	TraceCount int // Number of distinct traces
+	Evicted    int // Spans received but since overwritten or resized away
 }
 
 // extractServiceName extracts the service.name attribute from an OTLP resource.
@@ -55,6 +55,9 @@ func RecentErrors(errors []ActivityError) string {
 		}
 
 		msg := e.ErrorMsg
+		if e.Exception != "" {
+			msg = e.Exception + ": " + msg
+		}
 		if len(msg) > 40 {
 			msg = msg[:39] + "…"
 		}
@@ -65,6 +68,37 @@ func RecentErrors(errors []ActivityError) string {
 	return b.String()
 }
 
+// Exceptions renders exception groups, most frequent first, with the top
+// stack frame under each.
+func Exceptions(groups []ExceptionGroup) string {
+	if len(groups) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Exceptions (%d groups)\n", len(groups))
+
+	for _, g := range groups {
+		typ := g.Type
+		if typ == "" {
+			typ = "(unknown type)"
+		}
+		msg := g.Message
+		if len(msg) > 60 {
+			msg = msg[:59] + "…"
+		}
+		fmt.Fprintf(&b, "  %5d×  %s  [%s]\n", g.Count, typ, strings.Join(g.Services, ", "))
+		if msg != "" {
+			fmt.Fprintf(&b, "         %s\n", msg)
+		}
+		if g.TopFrame != "" {
+			fmt.Fprintf(&b, "         at %s\n", g.TopFrame)
+		}
+	}
+
+	return b.String()
+}
+
 func statusIcon(status string) string {
 	switch status {
 	case "ERROR", "STATUS_CODE_ERROR":
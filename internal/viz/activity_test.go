@@ -61,6 +61,29 @@ func TestRecentErrors(t *testing.T) {
 	}
 }
 
+func TestExceptions(t *testing.T) {
+	if Exceptions(nil) != "" {
+		t.Error("expected empty string for no groups")
+	}
+
+	result := Exceptions([]ExceptionGroup{
+		{Type: "java.io.IOException", TopFrame: "com.shop.Store.save(Store.java:42)", Message: "disk full", Count: 12, Services: []string{"api", "worker"}},
+		{Count: 1, Services: []string{"api"}},
+	})
+	want := []string{
+		"Exceptions (2 groups)",
+		"12×  java.io.IOException  [api, worker]",
+		"disk full",
+		"at com.shop.Store.save(Store.java:42)",
+		"(unknown type)",
+	}
+	for _, w := range want {
+		if !strings.Contains(result, w) {
+			t.Errorf("expected %q in output:\n%s", w, result)
+		}
+	}
+}
+
 func TestRecentTraces_LongLabel(t *testing.T) {
 	traces := []ActivityTrace{
 		{TraceID: "aabbccdd", Service: "very-long-service-name", RootSpan: "GET /api/v1/very/long/path/that/exceeds", Status: "OK", DurationMs: 100},
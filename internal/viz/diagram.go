@@ -0,0 +1,375 @@
+package viz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupByTrace splits spans into one slice per trace, ordered by each
+// trace's earliest start time.
+func GroupByTrace(spans []SpanInfo) [][]SpanInfo {
+	byTrace := make(map[string][]SpanInfo)
+	var order []string
+	for _, s := range spans {
+		if _, seen := byTrace[s.TraceID]; !seen {
+			order = append(order, s.TraceID)
+		}
+		byTrace[s.TraceID] = append(byTrace[s.TraceID], s)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return earliestStart(byTrace[order[i]]) < earliestStart(byTrace[order[j]])
+	})
+
+	groups := make([][]SpanInfo, len(order))
+	for i, tid := range order {
+		groups[i] = byTrace[tid]
+	}
+	return groups
+}
+
+// MermaidSequence renders one trace as a Mermaid sequence diagram. Services
+// are participants; a child span in another service is a call arrow at its
+// start and a return arrow (with its duration) at its end. Same-service
+// children are self-messages and root spans are notes. Spans beyond
+// maxSpansPerTrace are dropped with a note.
+func MermaidSequence(spans []SpanInfo) string {
+	if len(spans) == 0 {
+		return ""
+	}
+	spans, omitted := capSpans(spans)
+	ids, order := participants(spans)
+	byID := indexSpans(spans)
+
+	type event struct {
+		at     uint64
+		isCall bool
+		seq    int
+		line   string
+	}
+	var events []event
+	for i, s := range spans {
+		svc := ids[s.ServiceName]
+		label := mermaidText(s.SpanName)
+		if isError(s.StatusCode) {
+			label += " (ERROR)"
+		}
+		parent, ok := byID[s.ParentID]
+		switch {
+		case !ok || s.ParentID == s.SpanID:
+			events = append(events, event{s.StartNano, true, i,
+				fmt.Sprintf("Note over %s: %s %s", svc, label, formatDuration(spanDuration(s)))})
+		case parent.ServiceName == s.ServiceName:
+			events = append(events, event{s.StartNano, true, i,
+				fmt.Sprintf("%s->>%s: %s", svc, svc, label)})
+		default:
+			caller := ids[parent.ServiceName]
+			events = append(events, event{s.StartNano, true, i,
+				fmt.Sprintf("%s->>+%s: %s", caller, svc, label)})
+			arrow := "-->>-"
+			ret := formatDuration(spanDuration(s))
+			if isError(s.StatusCode) {
+				arrow, ret = "--x-", "ERROR "+ret
+			}
+			events = append(events, event{max(s.EndNano, s.StartNano), false, i,
+				fmt.Sprintf("%s%s%s: %s", svc, arrow, caller, ret)})
+		}
+	}
+	// Calls sort before returns at the same instant so a zero-length span
+	// activates before it deactivates
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.at != b.at {
+			return a.at < b.at
+		}
+		if a.isCall != b.isCall {
+			return a.isCall
+		}
+		return a.seq < b.seq
+	})
+
+	var b strings.Builder
+	b.WriteString("sequenceDiagram\n")
+	fmt.Fprintf(&b, "    %%%% trace %s\n", spans[0].TraceID)
+	for _, name := range order {
+		fmt.Fprintf(&b, "    participant %s as %s\n", ids[name], mermaidText(name))
+	}
+	for _, e := range events {
+		fmt.Fprintf(&b, "    %s\n", e.line)
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "    Note over %s: +%d more spans\n", ids[order[0]], omitted)
+	}
+	return b.String()
+}
+
+// MermaidGantt renders one trace as a Mermaid Gantt chart with a section
+// per service and a task per span, offset from the trace start. Mermaid
+// Gantt charts have millisecond resolution, so every task is at least 1ms
+// wide. Error spans are marked crit.
+func MermaidGantt(spans []SpanInfo) string {
+	if len(spans) == 0 {
+		return ""
+	}
+	spans, omitted := capSpans(spans)
+	sorted := make([]SpanInfo, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartNano < sorted[j].StartNano })
+
+	minStart := sorted[0].StartNano
+	var maxEnd uint64
+	for _, s := range sorted {
+		maxEnd = max(maxEnd, s.EndNano, s.StartNano)
+	}
+	total := maxEnd - minStart
+
+	axis := "%S.%L"
+	if total >= 60_000_000_000 {
+		axis = "%M:%S"
+	}
+
+	_, order := participants(sorted)
+	bySvc := make(map[string][]SpanInfo)
+	for _, s := range sorted {
+		bySvc[s.ServiceName] = append(bySvc[s.ServiceName], s)
+	}
+
+	var b strings.Builder
+	b.WriteString("gantt\n")
+	fmt.Fprintf(&b, "    title Trace %s (%s)\n", ganttText(shortID(sorted[0].TraceID)), formatDuration(total))
+	b.WriteString("    dateFormat x\n")
+	fmt.Fprintf(&b, "    axisFormat %s\n", axis)
+	for _, svc := range order {
+		fmt.Fprintf(&b, "    section %s\n", ganttText(svc))
+		for _, s := range bySvc[svc] {
+			startMs := (s.StartNano - minStart) / 1_000_000
+			endMs := max((max(s.EndNano, s.StartNano)-minStart+999_999)/1_000_000, startMs+1)
+			tag := ""
+			if isError(s.StatusCode) {
+				tag = "crit, "
+			}
+			fmt.Fprintf(&b, "    %s :%s%d, %d\n", ganttText(s.SpanName), tag, startMs, endMs)
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "    %%%% +%d more spans omitted\n", omitted)
+	}
+	return b.String()
+}
+
+// ServiceEdge is one caller -> callee service dependency.
+type ServiceEdge struct {
+	From   string
+	To     string
+	Calls  int // child spans in To whose parent is in From
+	Errors int // of those, how many had error status
+}
+
+// ServiceGraph is the service dependency graph derived from parent/child
+// spans that cross a service boundary.
+type ServiceGraph struct {
+	Services []ServiceStats
+	Edges    []ServiceEdge
+}
+
+// BuildServiceGraph derives service dependencies from spans. Spans are
+// linked to parents within the same trace. Services and edges are sorted
+// by name.
+func BuildServiceGraph(spans []SpanInfo) ServiceGraph {
+	stats := make(map[string]*ServiceStats)
+	edges := make(map[[2]string]*ServiceEdge)
+
+	for _, trace := range GroupByTrace(spans) {
+		byID := indexSpans(trace)
+		for _, s := range trace {
+			st, ok := stats[s.ServiceName]
+			if !ok {
+				st = &ServiceStats{Name: s.ServiceName}
+				stats[s.ServiceName] = st
+			}
+			st.SpanCount++
+			if isError(s.StatusCode) {
+				st.ErrorCount++
+			}
+
+			parent, ok := byID[s.ParentID]
+			if !ok || parent.ServiceName == s.ServiceName {
+				continue
+			}
+			key := [2]string{parent.ServiceName, s.ServiceName}
+			e, ok := edges[key]
+			if !ok {
+				e = &ServiceEdge{From: key[0], To: key[1]}
+				edges[key] = e
+			}
+			e.Calls++
+			if isError(s.StatusCode) {
+				e.Errors++
+			}
+		}
+	}
+
+	var g ServiceGraph
+	for _, st := range stats {
+		g.Services = append(g.Services, *st)
+	}
+	sort.Slice(g.Services, func(i, j int) bool { return g.Services[i].Name < g.Services[j].Name })
+	for _, e := range edges {
+		g.Edges = append(g.Edges, *e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// MermaidFlowchart renders the service graph as a left-to-right Mermaid
+// flowchart. Services and edges with errors are drawn in red.
+func MermaidFlowchart(g ServiceGraph) string {
+	if len(g.Services) == 0 {
+		return ""
+	}
+	ids := make(map[string]string, len(g.Services))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, svc := range g.Services {
+		id := fmt.Sprintf("s%d", i)
+		ids[svc.Name] = id
+		fmt.Fprintf(&b, "    %s[\"%s<br/>%s\"]\n", id, mermaidText(svc.Name), countLabel(svc.SpanCount, svc.ErrorCount, "span"))
+	}
+	var errEdges []int
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "    %s -->|\"%s\"| %s\n", ids[e.From], countLabel(e.Calls, e.Errors, "call"), ids[e.To])
+		if e.Errors > 0 {
+			errEdges = append(errEdges, i)
+		}
+	}
+	for _, svc := range g.Services {
+		if svc.ErrorCount > 0 {
+			fmt.Fprintf(&b, "    style %s stroke:#d33,stroke-width:2px\n", ids[svc.Name])
+		}
+	}
+	for _, i := range errEdges {
+		fmt.Fprintf(&b, "    linkStyle %d stroke:#d33\n", i)
+	}
+	return b.String()
+}
+
+// DOT renders the service graph in Graphviz DOT. Services and edges with
+// errors are drawn in red.
+func DOT(g ServiceGraph) string {
+	if len(g.Services) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("digraph services {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, svc := range g.Services {
+		color := ""
+		if svc.ErrorCount > 0 {
+			color = ", color=red"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s%s];\n", dotQuote(svc.Name),
+			dotQuote(svc.Name+"\n"+countLabel(svc.SpanCount, svc.ErrorCount, "span")), color)
+	}
+	for _, e := range g.Edges {
+		color := ""
+		if e.Errors > 0 {
+			color = ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s%s];\n", dotQuote(e.From), dotQuote(e.To),
+			dotQuote(countLabel(e.Calls, e.Errors, "call")), color)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// capSpans limits a trace to maxSpansPerTrace spans, keeping the earliest.
+func capSpans(spans []SpanInfo) ([]SpanInfo, int) {
+	if len(spans) <= maxSpansPerTrace {
+		return spans, 0
+	}
+	sorted := make([]SpanInfo, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartNano < sorted[j].StartNano })
+	return sorted[:maxSpansPerTrace], len(spans) - maxSpansPerTrace
+}
+
+// participants assigns short Mermaid IDs to services in order of first
+// appearance by start time.
+func participants(spans []SpanInfo) (map[string]string, []string) {
+	sorted := make([]SpanInfo, len(spans))
+	copy(sorted, spans)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartNano < sorted[j].StartNano })
+
+	ids := make(map[string]string)
+	var order []string
+	for _, s := range sorted {
+		if _, ok := ids[s.ServiceName]; !ok {
+			ids[s.ServiceName] = fmt.Sprintf("s%d", len(order))
+			order = append(order, s.ServiceName)
+		}
+	}
+	return ids, order
+}
+
+func indexSpans(spans []SpanInfo) map[string]SpanInfo {
+	byID := make(map[string]SpanInfo, len(spans))
+	for _, s := range spans {
+		byID[s.SpanID] = s
+	}
+	return byID
+}
+
+func spanDuration(s SpanInfo) uint64 {
+	return max(s.EndNano, s.StartNano) - s.StartNano
+}
+
+func isError(status string) bool {
+	return status == "ERROR" || status == "STATUS_CODE_ERROR"
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// countLabel formats "3 spans" or "3 spans, 1 error".
+func countLabel(n, errors int, noun string) string {
+	s := fmt.Sprintf("%d %s", n, plural(n, noun))
+	if errors > 0 {
+		s += fmt.Sprintf(", %d %s", errors, plural(errors, "error"))
+	}
+	return s
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// mermaidText makes a label safe inside Mermaid statements: quotes, line
+// breaks and the ';' and '#' statement/entity characters are replaced.
+var mermaidText = strings.NewReplacer(
+	`"`, "'", ";", ",", "#", "", "\n", " ", "\r", " ",
+).Replace
+
+// ganttText additionally drops ':', which separates a Gantt task's name
+// from its data.
+func ganttText(s string) string {
+	return strings.ReplaceAll(mermaidText(s), ":", " ")
+}
+
+// dotQuote returns s as a quoted DOT ID, with newlines as centered breaks.
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
@@ -0,0 +1,139 @@
+package viz
+
+import (
+	"strings"
+	"testing"
+)
+
+// diagramSpans is one trace: frontend calls api, api queries db (failing)
+// and does local work.
+func diagramSpans() []SpanInfo {
+	return []SpanInfo{
+		{TraceID: "abcdef0123456789", SpanID: "r", ServiceName: "frontend", SpanName: "GET /checkout", StartNano: 0, EndNano: 100_000_000},
+		{TraceID: "abcdef0123456789", SpanID: "a", ParentID: "r", ServiceName: "api", SpanName: "checkout", StartNano: 5_000_000, EndNano: 90_000_000},
+		{TraceID: "abcdef0123456789", SpanID: "d", ParentID: "a", ServiceName: "db", SpanName: "SELECT; orders", StartNano: 10_000_000, EndNano: 40_000_000, StatusCode: "ERROR"},
+		{TraceID: "abcdef0123456789", SpanID: "l", ParentID: "a", ServiceName: "api", SpanName: "render", StartNano: 50_000_000, EndNano: 50_000_000},
+	}
+}
+
+func TestGroupByTrace(t *testing.T) {
+	groups := GroupByTrace([]SpanInfo{
+		{TraceID: "late", SpanID: "1", StartNano: 20},
+		{TraceID: "early", SpanID: "2", StartNano: 10},
+		{TraceID: "late", SpanID: "3", StartNano: 5},
+	})
+	if len(groups) != 2 || groups[0][0].TraceID != "late" || len(groups[0]) != 2 || groups[1][0].TraceID != "early" {
+		t.Errorf("expected traces ordered by earliest start, got %+v", groups)
+	}
+}
+
+func TestMermaidSequence(t *testing.T) {
+	if MermaidSequence(nil) != "" {
+		t.Error("expected empty output for no spans")
+	}
+
+	out := MermaidSequence(diagramSpans())
+	want := []string{
+		"sequenceDiagram\n",
+		"participant s0 as frontend",
+		"participant s1 as api",
+		"participant s2 as db",
+		"Note over s0: GET /checkout 100ms",
+		"s0->>+s1: checkout",
+		"s1->>+s2: SELECT, orders (ERROR)",
+		"s2--x-s1: ERROR 30ms",
+		"s1->>s1: render",
+		"s1-->>-s0: 85ms",
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("expected %q in output:\n%s", w, out)
+		}
+	}
+	// The db return happens before the local render call
+	if strings.Index(out, "s2--x-s1") > strings.Index(out, "s1->>s1: render") {
+		t.Errorf("expected events in time order:\n%s", out)
+	}
+}
+
+func TestMermaidGantt(t *testing.T) {
+	out := MermaidGantt(diagramSpans())
+	want := []string{
+		"gantt\n",
+		"title Trace abcdef01 (100ms)",
+		"dateFormat x",
+		"axisFormat %S.%L",
+		"section frontend\n    GET /checkout :0, 100\n",
+		"section api\n    checkout :5, 90\n    render :50, 51\n",
+		"section db\n    SELECT, orders :crit, 10, 40\n",
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("expected %q in output:\n%s", w, out)
+		}
+	}
+}
+
+func TestBuildServiceGraph(t *testing.T) {
+	spans := append(diagramSpans(), SpanInfo{
+		TraceID: "other", SpanID: "x", ServiceName: "frontend", SpanName: "GET /", StartNano: 0, EndNano: 10,
+	}, SpanInfo{
+		TraceID: "other", SpanID: "y", ParentID: "x", ServiceName: "api", SpanName: "list", StartNano: 1, EndNano: 9,
+	})
+	g := BuildServiceGraph(spans)
+
+	if len(g.Services) != 3 || g.Services[0].Name != "api" || g.Services[0].SpanCount != 3 {
+		t.Errorf("unexpected services: %+v", g.Services)
+	}
+	want := []ServiceEdge{
+		{From: "api", To: "db", Calls: 1, Errors: 1},
+		{From: "frontend", To: "api", Calls: 2},
+	}
+	if len(g.Edges) != len(want) {
+		t.Fatalf("expected %d edges, got %+v", len(want), g.Edges)
+	}
+	for i, e := range want {
+		if g.Edges[i] != e {
+			t.Errorf("edge %d: got %+v, want %+v", i, g.Edges[i], e)
+		}
+	}
+}
+
+func TestMermaidFlowchart(t *testing.T) {
+	if MermaidFlowchart(ServiceGraph{}) != "" {
+		t.Error("expected empty output for empty graph")
+	}
+	out := MermaidFlowchart(BuildServiceGraph(diagramSpans()))
+	want := []string{
+		"flowchart LR\n",
+		`s0["api<br/>2 spans"]`,
+		`s2["frontend<br/>1 span"]`,
+		`s0 -->|"1 call, 1 error"| s1`,
+		`s2 -->|"1 call"| s0`,
+		"style s1 stroke:#d33",
+		"linkStyle 0 stroke:#d33",
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("expected %q in output:\n%s", w, out)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	out := DOT(BuildServiceGraph(diagramSpans()))
+	want := []string{
+		"digraph services {\n",
+		`"db" [label="db\n1 span, 1 error", color=red];`,
+		`"api" -> "db" [label="1 call, 1 error", color=red, fontcolor=red];`,
+		`"frontend" -> "api" [label="1 call"];`,
+	}
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("expected %q in output:\n%s", w, out)
+		}
+	}
+	if got := dotQuote(`a "b"\c`); got != `"a \"b\"\\c"` {
+		t.Errorf("dotQuote escaped badly: %s", got)
+	}
+}
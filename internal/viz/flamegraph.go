@@ -0,0 +1,358 @@
+package viz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FlameWeight selects which time a flame graph ranks and draws frames by.
+type FlameWeight string
+
+const (
+	// WeightTotal uses inclusive time: the frame plus everything below it.
+	WeightTotal FlameWeight = "total"
+	// WeightSelf uses exclusive time: the frame minus time covered by children.
+	WeightSelf FlameWeight = "self"
+)
+
+const (
+	maxFlameRows    = 60
+	maxFlameDepth   = 64   // guards against parent cycles in bad data
+	minFlameShare   = 0.01 // frames under 1% of the root are folded into "more"
+	maxTopSelfLines = 10
+)
+
+// FlameNode is one frame of an aggregated flame graph: every span sharing
+// the same service.span path from the root, merged across traces.
+type FlameNode struct {
+	Name     string       `json:"name"` // service.span
+	Service  string       `json:"service,omitempty"`
+	SpanName string       `json:"span_name,omitempty"`
+	TotalNs  uint64       `json:"total_ns"`
+	SelfNs   uint64       `json:"self_ns"`
+	Count    int          `json:"count"`
+	Errors   int          `json:"errors,omitempty"`
+	Children []*FlameNode `json:"children,omitempty"`
+}
+
+// FlameGraph is the aggregate of many traces under a synthetic "all" root.
+type FlameGraph struct {
+	Root   *FlameNode `json:"root"`
+	Traces int        `json:"traces"`
+	Spans  int        `json:"spans"`
+}
+
+// BuildFlameGraph aggregates spans into a flame graph. Spans are grouped by
+// trace and linked by ParentID; spans whose parent is missing become roots.
+// Self time is the span's duration minus the union of its children's
+// intervals, so concurrent children are not double-counted.
+func BuildFlameGraph(spans []SpanInfo) *FlameGraph {
+	g := &FlameGraph{Root: &FlameNode{Name: "all"}}
+
+	byTrace := make(map[string][]SpanInfo)
+	for _, s := range spans {
+		byTrace[s.TraceID] = append(byTrace[s.TraceID], s)
+	}
+	g.Traces = len(byTrace)
+
+	for _, traceSpans := range byTrace {
+		byID := make(map[string]SpanInfo, len(traceSpans))
+		for _, s := range traceSpans {
+			byID[s.SpanID] = s
+		}
+		children := make(map[string][]SpanInfo)
+		var roots []SpanInfo
+		for _, s := range traceSpans {
+			if _, ok := byID[s.ParentID]; ok && s.ParentID != s.SpanID {
+				children[s.ParentID] = append(children[s.ParentID], s)
+			} else {
+				roots = append(roots, s)
+			}
+		}
+		for _, r := range roots {
+			g.Spans += addFlameSpan(g.Root, r, children, 0)
+		}
+	}
+
+	for _, child := range g.Root.Children {
+		g.Root.TotalNs += child.TotalNs
+	}
+	g.Root.Count = g.Traces
+	sortFlame(g.Root, WeightTotal)
+	return g
+}
+
+// addFlameSpan merges one span (and its subtree) into parent and returns the
+// number of spans added.
+func addFlameSpan(parent *FlameNode, s SpanInfo, children map[string][]SpanInfo, depth int) int {
+	if depth >= maxFlameDepth {
+		return 0
+	}
+	name := s.ServiceName + "." + s.SpanName
+	var node *FlameNode
+	for _, c := range parent.Children {
+		if c.Name == name {
+			node = c
+			break
+		}
+	}
+	if node == nil {
+		node = &FlameNode{Name: name, Service: s.ServiceName, SpanName: s.SpanName}
+		parent.Children = append(parent.Children, node)
+	}
+
+	start := s.StartNano
+	end := max(s.EndNano, start)
+	kids := children[s.SpanID]
+	node.TotalNs += end - start
+	node.SelfNs += end - start - coveredNanos(start, end, kids)
+	node.Count++
+	if isError(s.StatusCode) {
+		node.Errors++
+	}
+
+	added := 1
+	for _, k := range kids {
+		added += addFlameSpan(node, k, children, depth+1)
+	}
+	return added
+}
+
+// coveredNanos returns how much of [start, end) the spans' intervals cover.
+func coveredNanos(start, end uint64, spans []SpanInfo) uint64 {
+	if len(spans) == 0 {
+		return 0
+	}
+	type interval struct{ s, e uint64 }
+	ivs := make([]interval, 0, len(spans))
+	for _, sp := range spans {
+		s, e := max(sp.StartNano, start), min(max(sp.EndNano, sp.StartNano), end)
+		if e > s {
+			ivs = append(ivs, interval{s, e})
+		}
+	}
+	sort.Slice(ivs, func(i, j int) bool { return ivs[i].s < ivs[j].s })
+
+	var covered, curS, curE uint64
+	for i, iv := range ivs {
+		if i == 0 || iv.s > curE {
+			covered += curE - curS
+			curS, curE = iv.s, iv.e
+			continue
+		}
+		curE = max(curE, iv.e)
+	}
+	return covered + curE - curS
+}
+
+// weightOf returns the node's value under the given weight.
+func (n *FlameNode) weightOf(w FlameWeight) uint64 {
+	if w == WeightSelf {
+		return n.SelfNs
+	}
+	return n.TotalNs
+}
+
+// sortFlame orders children by weight, heaviest first, ties by name.
+func sortFlame(n *FlameNode, w FlameWeight) {
+	sort.Slice(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.weightOf(w) != b.weightOf(w) {
+			return a.weightOf(w) > b.weightOf(w)
+		}
+		return a.Name < b.Name
+	})
+	for _, c := range n.Children {
+		sortFlame(c, w)
+	}
+}
+
+// FlameGraphText renders an aggregated flame graph as a top-down (icicle)
+// tree with bars scaled to the root. Frames under 1% of the root are folded
+// into a "more" line. With WeightSelf, bars show self time and a list of the
+// frames with the most self time (merged across paths) is appended.
+// Width controls the total line width; 0 uses a sensible default (80).
+func FlameGraphText(g *FlameGraph, weight FlameWeight, width int) string {
+	if g == nil || g.Root == nil || len(g.Root.Children) == 0 {
+		return ""
+	}
+	if width <= 0 {
+		width = 80
+	}
+	if weight != WeightSelf {
+		weight = WeightTotal
+	}
+	sortFlame(g.Root, weight)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Flame graph (%d traces, %d spans, %s total, by %s time)\n",
+		g.Traces, g.Spans, formatDuration(g.Root.TotalNs), weight)
+
+	rows := 0
+	var walk func(n *FlameNode, prefix string)
+	walk = func(n *FlameNode, prefix string) {
+		shown, hidden := splitVisible(n.Children, g.Root.TotalNs)
+		for i, c := range shown {
+			if rows >= maxFlameRows {
+				hidden = append(hidden, shown[i:]...)
+				break
+			}
+			last := i == len(shown)-1 && len(hidden) == 0
+			connector, childPrefix := "├─ ", prefix+"│  "
+			if last {
+				connector, childPrefix = "└─ ", prefix+"   "
+			}
+			renderFlameRow(&b, prefix+connector, c, g.Root.TotalNs, weight, width)
+			rows++
+			walk(c, childPrefix)
+		}
+		if len(hidden) > 0 {
+			var total uint64
+			for _, h := range hidden {
+				total += h.TotalNs
+			}
+			fmt.Fprintf(&b, " %s└─ … +%d more frames (%s)\n", prefix, len(hidden), formatDuration(total))
+			rows++
+		}
+	}
+	walk(g.Root, "")
+
+	if weight == WeightSelf {
+		b.WriteString("\nTop self time\n")
+		for _, f := range topSelfFrames(g.Root, maxTopSelfLines) {
+			nameWidth := flameLabelWidth(width) + 4
+			fmt.Fprintf(&b, "  %s %8s %5.1f%%  x%d\n",
+				padRight(truncate(f.Name, nameWidth), nameWidth), formatDuration(f.SelfNs),
+				share(f.SelfNs, g.Root.TotalNs)*100, f.Count)
+		}
+	}
+	return b.String()
+}
+
+// splitVisible separates frames big enough to draw from those folded away.
+func splitVisible(nodes []*FlameNode, rootTotal uint64) (shown, hidden []*FlameNode) {
+	for _, n := range nodes {
+		if share(n.TotalNs, rootTotal) >= minFlameShare {
+			shown = append(shown, n)
+		} else {
+			hidden = append(hidden, n)
+		}
+	}
+	return shown, hidden
+}
+
+func renderFlameRow(b *strings.Builder, prefix string, n *FlameNode, rootTotal uint64, weight FlameWeight, width int) {
+	barWidth := defaultBarWidth
+	filled := int(share(n.weightOf(weight), rootTotal)*float64(barWidth) + 0.5)
+	filled = min(max(filled, 0), barWidth)
+	bar := strings.Repeat("#", filled) + strings.Repeat(".", barWidth-filled)
+
+	// Prefix tree characters are one display column each
+	prefixCols := len([]rune(prefix))
+	labelBudget := max(flameLabelWidth(width)-prefixCols, 8)
+	label := padRight(truncate(n.Name, labelBudget), labelBudget)
+
+	errStr := ""
+	if n.Errors > 0 {
+		errStr = fmt.Sprintf(" !! %d ERR", n.Errors)
+	}
+	fmt.Fprintf(b, " %s%s [%s] %6s %5.1f%% self %-6s x%d%s\n",
+		prefix, label, bar,
+		formatDuration(n.TotalNs), share(n.TotalNs, rootTotal)*100,
+		formatDuration(n.SelfNs), n.Count, errStr)
+}
+
+// flameLabelWidth is the column budget for prefix plus label: the line
+// width minus the bar and the numbers after it.
+func flameLabelWidth(width int) int {
+	return max(width-(defaultBarWidth+3)-29, 16)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// padRight pads s with spaces to n display columns (runes).
+func padRight(s string, n int) string {
+	return s + strings.Repeat(" ", max(0, n-len([]rune(s))))
+}
+
+func share(v, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(v) / float64(total)
+}
+
+// topSelfFrames merges frames by name across all paths and returns the n
+// with the most self time.
+func topSelfFrames(root *FlameNode, n int) []FlameNode {
+	merged := make(map[string]*FlameNode)
+	var walk func(*FlameNode)
+	walk = func(node *FlameNode) {
+		for _, c := range node.Children {
+			m, ok := merged[c.Name]
+			if !ok {
+				m = &FlameNode{Name: c.Name}
+				merged[c.Name] = m
+			}
+			m.SelfNs += c.SelfNs
+			m.Count += c.Count
+			walk(c)
+		}
+	}
+	walk(root)
+
+	frames := make([]FlameNode, 0, len(merged))
+	for _, m := range merged {
+		frames = append(frames, *m)
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		if frames[i].SelfNs != frames[j].SelfNs {
+			return frames[i].SelfNs > frames[j].SelfNs
+		}
+		return frames[i].Name < frames[j].Name
+	})
+	if len(frames) > n {
+		frames = frames[:n]
+	}
+	return frames
+}
+
+// FoldedStacks renders the flame graph in the folded-stack format used by
+// flamegraph.pl, inferno and speedscope: one "frame;frame;frame value" line
+// per path with its self time in microseconds. Lines with no self time are
+// omitted; the tools rebuild inclusive time from the stacks.
+func FoldedStacks(g *FlameGraph) string {
+	if g == nil || g.Root == nil {
+		return ""
+	}
+	var lines []string
+	var walk func(n *FlameNode, path []string)
+	walk = func(n *FlameNode, path []string) {
+		for _, c := range n.Children {
+			p := append(path[:len(path):len(path)], foldedFrame(c.Name))
+			if us := (c.SelfNs + 500) / 1000; us > 0 {
+				lines = append(lines, fmt.Sprintf("%s %d", strings.Join(p, ";"), us))
+			}
+			walk(c, p)
+		}
+	}
+	walk(g.Root, nil)
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// foldedFrame makes a frame name safe for the folded format, where ';'
+// separates frames and the last space separates the value.
+func foldedFrame(name string) string {
+	return strings.NewReplacer(";", ",", "\n", " ", "\r", " ").Replace(name)
+}
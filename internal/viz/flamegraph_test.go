@@ -0,0 +1,102 @@
+package viz
+
+import (
+	"strings"
+	"testing"
+)
+
+// flameSpans returns two traces with the same shape: api.GET / calls db.query
+// twice concurrently (overlapping) and cache.get once.
+func flameSpans() []SpanInfo {
+	var spans []SpanInfo
+	for _, tid := range []string{"t1", "t2"} {
+		spans = append(spans,
+			SpanInfo{TraceID: tid, SpanID: "root", ServiceName: "api", SpanName: "GET /", StartNano: 0, EndNano: 100_000_000},
+			SpanInfo{TraceID: tid, SpanID: "q1", ParentID: "root", ServiceName: "db", SpanName: "query", StartNano: 10_000_000, EndNano: 50_000_000},
+			SpanInfo{TraceID: tid, SpanID: "q2", ParentID: "root", ServiceName: "db", SpanName: "query", StartNano: 30_000_000, EndNano: 60_000_000, StatusCode: "ERROR"},
+			SpanInfo{TraceID: tid, SpanID: "c1", ParentID: "root", ServiceName: "cache", SpanName: "get", StartNano: 70_000_000, EndNano: 80_000_000},
+		)
+	}
+	return spans
+}
+
+func TestBuildFlameGraph_Aggregates(t *testing.T) {
+	g := BuildFlameGraph(flameSpans())
+
+	if g.Traces != 2 || g.Spans != 8 {
+		t.Fatalf("expected 2 traces / 8 spans, got %d / %d", g.Traces, g.Spans)
+	}
+	if len(g.Root.Children) != 1 {
+		t.Fatalf("expected one root frame, got %d", len(g.Root.Children))
+	}
+	api := g.Root.Children[0]
+	if api.Name != "api.GET /" || api.Count != 2 || api.TotalNs != 200_000_000 {
+		t.Errorf("unexpected root frame: %+v", api)
+	}
+	// Children cover 10-60ms (overlapping queries) and 70-80ms: 60ms of 100ms
+	if api.SelfNs != 2*40_000_000 {
+		t.Errorf("expected self 80ms, got %d", api.SelfNs)
+	}
+	if g.Root.TotalNs != api.TotalNs {
+		t.Errorf("root total %d should equal its only child %d", g.Root.TotalNs, api.TotalNs)
+	}
+
+	db := api.Children[0]
+	if db.Name != "db.query" || db.Count != 4 || db.TotalNs != 140_000_000 || db.Errors != 2 {
+		t.Errorf("unexpected db frame: %+v", db)
+	}
+}
+
+func TestBuildFlameGraph_OrphansAndCycles(t *testing.T) {
+	spans := []SpanInfo{
+		{TraceID: "t", SpanID: "a", ParentID: "missing", ServiceName: "svc", SpanName: "orphan", StartNano: 0, EndNano: 10},
+		{TraceID: "t", SpanID: "self", ParentID: "self", ServiceName: "svc", SpanName: "loop", StartNano: 0, EndNano: 10},
+	}
+	g := BuildFlameGraph(spans)
+	if len(g.Root.Children) != 2 || g.Spans != 2 {
+		t.Errorf("expected orphan and self-parented spans as roots, got %+v", g.Root.Children)
+	}
+}
+
+func TestFlameGraphText(t *testing.T) {
+	if FlameGraphText(BuildFlameGraph(nil), WeightTotal, 80) != "" {
+		t.Error("expected empty output for no spans")
+	}
+
+	out := FlameGraphText(BuildFlameGraph(flameSpans()), WeightTotal, 80)
+	for _, want := range []string{"Flame graph (2 traces, 8 spans", "api.GET /", "└─ ", "db.query", "!! 2 ERR", "x4"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Top self time") {
+		t.Error("total weighting should not list top self time")
+	}
+
+	self := FlameGraphText(BuildFlameGraph(flameSpans()), WeightSelf, 80)
+	if !strings.Contains(self, "by self time") || !strings.Contains(self, "Top self time") {
+		t.Errorf("expected self-time rendering, got:\n%s", self)
+	}
+	// db.query has the most self time (140ms vs api's 80ms)
+	top := self[strings.Index(self, "Top self time"):]
+	if strings.Index(top, "db.query") > strings.Index(top, "api.GET /") {
+		t.Errorf("expected db.query first in top self time:\n%s", top)
+	}
+}
+
+func TestFoldedStacks(t *testing.T) {
+	out := FoldedStacks(BuildFlameGraph(flameSpans()))
+	want := "api.GET / 80000\n" +
+		"api.GET /;cache.get 20000\n" +
+		"api.GET /;db.query 140000\n"
+	if out != want {
+		t.Errorf("unexpected folded output:\n got: %q\nwant: %q", out, want)
+	}
+
+	semi := FoldedStacks(BuildFlameGraph([]SpanInfo{
+		{TraceID: "t", SpanID: "a", ServiceName: "svc", SpanName: "a;b", StartNano: 0, EndNano: 5000},
+	}))
+	if semi != "svc.a,b 5\n" {
+		t.Errorf("expected ';' in frame names to be escaped, got %q", semi)
+	}
+}
@@ -11,6 +11,8 @@ type SpanInfo struct {
 	StartNano   uint64
 	EndNano     uint64
 	StatusCode  string // "OK", "ERROR", "UNSET"
+
+	LinkedTraces []string // trace IDs this span links to (span links)
 }
 
 // ServiceStats describes one service for the service summary bar chart.
@@ -48,4 +50,14 @@ type ActivityError struct {
 	SpanName  string
 	ErrorMsg  string
 	Timestamp uint64
+	Exception string // exception type, if the span recorded one
+}
+
+// ExceptionGroup describes exceptions sharing a type and top stack frame.
+type ExceptionGroup struct {
+	Type     string
+	TopFrame string
+	Message  string
+	Count    int
+	Services []string
 }
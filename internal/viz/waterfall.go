@@ -28,34 +28,21 @@ func Waterfall(spans []SpanInfo, width int) string {
 		spans = spans[:maxInputSpans]
 	}
 
-	// Group spans by TraceID
-	byTrace := make(map[string][]SpanInfo)
-	var traceOrder []string
-	for _, s := range spans {
-		if _, seen := byTrace[s.TraceID]; !seen {
-			traceOrder = append(traceOrder, s.TraceID)
-		}
-		byTrace[s.TraceID] = append(byTrace[s.TraceID], s)
-	}
-
-	// Sort traces by earliest start time
-	sort.Slice(traceOrder, func(i, j int) bool {
-		return earliestStart(byTrace[traceOrder[i]]) < earliestStart(byTrace[traceOrder[j]])
-	})
+	traces := GroupByTrace(spans)
 
 	// Cap number of traces
 	overflow := 0
-	if len(traceOrder) > maxTraces {
-		overflow = len(traceOrder) - maxTraces
-		traceOrder = traceOrder[:maxTraces]
+	if len(traces) > maxTraces {
+		overflow = len(traces) - maxTraces
+		traces = traces[:maxTraces]
 	}
 
 	var b strings.Builder
-	for i, tid := range traceOrder {
+	for i, trace := range traces {
 		if i > 0 {
 			b.WriteByte('\n')
 		}
-		renderTrace(&b, tid, byTrace[tid], width)
+		renderTrace(&b, trace[0].TraceID, trace, width)
 	}
 
 	if overflow > 0 {
@@ -121,6 +108,19 @@ func renderTrace(b *strings.Builder, traceID string, spans []SpanInfo, width int
 	if spanOverflow > 0 {
 		fmt.Fprintf(b, "  ... +%d more spans\n", spanOverflow)
 	}
+
+	// Span links to other traces, e.g. a consumer pointing at its producer
+	for _, s := range spans {
+		for _, id := range s.LinkedTraces {
+			if id == traceID {
+				continue
+			}
+			if len(id) > 6 {
+				id = id[:6]
+			}
+			fmt.Fprintf(b, "  ⇢ %s.%s links to trace %s\n", s.ServiceName, s.SpanName, id)
+		}
+	}
 }
 
 type treeEntry struct {
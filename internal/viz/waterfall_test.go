@@ -57,6 +57,19 @@ func TestWaterfall_ErrorSpan(t *testing.T) {
 	}
 }
 
+func TestWaterfall_Links(t *testing.T) {
+	spans := []SpanInfo{
+		{TraceID: "c0ffee01", SpanID: "s1", ServiceName: "worker", SpanName: "process", StartNano: 0, EndNano: 1000, LinkedTraces: []string{"abcdef0123", "c0ffee01"}},
+	}
+	result := Waterfall(spans, 80)
+	if !strings.Contains(result, "⇢ worker.process links to trace abcdef") {
+		t.Errorf("expected link line, got:\n%s", result)
+	}
+	if strings.Count(result, "⇢") != 1 {
+		t.Errorf("expected links within the trace to be skipped, got:\n%s", result)
+	}
+}
+
 func TestWaterfall_DeepNesting(t *testing.T) {
 	spans := make([]SpanInfo, 6)
 	for i := range spans {
@@ -0,0 +1,38 @@
+package webui
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/tobert/otlp-mcp/internal/chrometrace"
+)
+
+// handleChromeTrace downloads the traces matching the filter (same
+// parameters as /api/flamegraph) as Chrome Trace Event JSON, with their
+// correlated logs, for Perfetto or chrome://tracing.
+func (s *Server) handleChromeTrace(w http.ResponseWriter, r *http.Request) {
+	filter, err := traceFilterFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trace, err := chrometrace.Export(s.storage, filter)
+	if err != nil {
+		if errors.Is(err, chrometrace.ErrNoTraces) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := "otlp-mcp-traces.json"
+	if _, err := hex.DecodeString(filter.TraceID); err == nil && filter.TraceID != "" {
+		name = "otlp-mcp-" + filter.TraceID + ".json"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	trace.WriteTo(w)
+}
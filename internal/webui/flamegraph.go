@@ -0,0 +1,103 @@
+package webui
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/tobert/otlp-mcp/internal/viz"
+)
+
+// handleFlameGraph aggregates every trace with a span matching the filter
+// into a flame graph. Query parameters:
+//
+//	q        query language filter (see storage.ParseQuery)
+//	trace_id only this trace
+//	service  only traces touching this service
+//	start_snapshot, end_snapshot  limit to a snapshot range
+//	limit    max traces, most recent first
+//	format   "json" (default, the frame tree) or "folded" (folded stacks download)
+func (s *Server) handleFlameGraph(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := traceFilterFromQuery(q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	spans, err := s.storage.QueryTraces(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	graph := viz.BuildFlameGraph(spanInfos(spans))
+
+	switch q.Get("format") {
+	case "", "json":
+		writeJSON(w, graph)
+	case "folded":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="otlp-mcp.folded"`)
+		w.Write([]byte(viz.FoldedStacks(graph)))
+	default:
+		http.Error(w, "format must be json or folded", http.StatusBadRequest)
+	}
+}
+
+// traceFilterFromQuery builds a trace filter from the q, trace_id, service,
+// start_snapshot, end_snapshot and limit query parameters. Explicit
+// parameters override terms in q.
+func traceFilterFromQuery(q url.Values) (storage.QueryFilter, error) {
+	filter, err := storage.ParseQuery(q.Get("q"))
+	if err != nil {
+		return filter, err
+	}
+	if v := q.Get("trace_id"); v != "" {
+		filter.TraceID = strings.ToLower(v)
+	}
+	if v := q.Get("service"); v != "" {
+		filter.ServiceName = v
+	}
+	if sc := scopeFromQuery(q); sc.active() {
+		if sc.Start == "" {
+			return filter, fmt.Errorf("end_snapshot requires start_snapshot")
+		}
+		filter.StartSnapshot, filter.EndSnapshot = sc.Start, sc.End
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("limit must be a non-negative integer")
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
+
+// spanInfos converts stored spans to viz input.
+func spanInfos(spans []*storage.StoredSpan) []viz.SpanInfo {
+	infos := make([]viz.SpanInfo, 0, len(spans))
+	for _, ss := range spans {
+		if ss.Span == nil {
+			continue
+		}
+		info := viz.SpanInfo{
+			TraceID:     ss.TraceID,
+			SpanID:      ss.SpanID,
+			ServiceName: ss.ServiceName,
+			SpanName:    ss.SpanName,
+			StartNano:   ss.Span.StartTimeUnixNano,
+			EndNano:     ss.Span.EndTimeUnixNano,
+			StatusCode:  spanStatus(ss.Span.Status),
+		}
+		if len(ss.Span.ParentSpanId) > 0 {
+			info.ParentID = hex.EncodeToString(ss.Span.ParentSpanId)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
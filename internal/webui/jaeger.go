@@ -0,0 +1,490 @@
+package webui
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	"github.com/tobert/otlp-mcp/internal/viz"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// JaegerPrefix is where the Jaeger query API is mounted when enabled. The
+// web UI already owns /api, so Jaeger UI should be run with this base path
+// (QUERY_BASE_PATH=/jaeger) or behind a proxy that maps /api to /jaeger/api.
+const JaegerPrefix = "/jaeger"
+
+// defaultJaegerLimit matches Jaeger UI's default search limit.
+const defaultJaegerLimit = 20
+
+// registerJaegerRoutes attaches the Jaeger HTTP query API subset that
+// Jaeger UI uses for search, trace view and the dependency graph.
+func (s *Server) registerJaegerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/services", securityHeaders(s.handleJaegerServices))
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/services/{service}/operations", securityHeaders(s.handleJaegerServiceOperations))
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/operations", securityHeaders(s.handleJaegerOperations))
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/traces", securityHeaders(s.handleJaegerSearch))
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/traces/{id}", securityHeaders(s.handleJaegerTrace))
+	mux.HandleFunc("GET "+JaegerPrefix+"/api/dependencies", securityHeaders(s.handleJaegerDependencies))
+}
+
+// jaegerResponse is the envelope every Jaeger query API response uses.
+type jaegerResponse struct {
+	Data   any           `json:"data"`
+	Total  int           `json:"total"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+	Errors []jaegerError `json:"errors"`
+}
+
+type jaegerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"msg"`
+}
+
+type jaegerTrace struct {
+	TraceID   string                   `json:"traceID"`
+	Spans     []jaegerSpan             `json:"spans"`
+	Processes map[string]jaegerProcess `json:"processes"`
+	Warnings  []string                 `json:"warnings"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	Flags         int               `json:"flags"`
+	StartTime     uint64            `json:"startTime"` // µs since epoch
+	Duration      uint64            `json:"duration"`  // µs
+	Tags          []jaegerKV        `json:"tags"`
+	Logs          []jaegerLog       `json:"logs"`
+	ProcessID     string            `json:"processID"`
+	Warnings      []string          `json:"warnings"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"` // CHILD_OF or FOLLOWS_FROM
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerKV struct {
+	Key   string `json:"key"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type jaegerLog struct {
+	Timestamp uint64     `json:"timestamp"`
+	Fields    []jaegerKV `json:"fields"`
+}
+
+type jaegerProcess struct {
+	ServiceName string     `json:"serviceName"`
+	Tags        []jaegerKV `json:"tags"`
+}
+
+type jaegerOperation struct {
+	Name     string `json:"name"`
+	SpanKind string `json:"spanKind"`
+}
+
+type jaegerDependency struct {
+	Parent    string `json:"parent"`
+	Child     string `json:"child"`
+	CallCount int    `json:"callCount"`
+}
+
+func writeJaeger(w http.ResponseWriter, data any, total int) {
+	writeJSON(w, jaegerResponse{Data: data, Total: total})
+}
+
+func writeJaegerError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(jaegerResponse{Errors: []jaegerError{{Code: code, Message: msg}}})
+}
+
+func (s *Server) handleJaegerServices(w http.ResponseWriter, r *http.Request) {
+	services := s.storage.Services()
+	writeJaeger(w, services, len(services))
+}
+
+func (s *Server) handleJaegerServiceOperations(w http.ResponseWriter, r *http.Request) {
+	ops := s.jaegerOperations(r.PathValue("service"), "")
+	names := make([]string, 0, len(ops))
+	for _, op := range ops {
+		if len(names) == 0 || names[len(names)-1] != op.Name { // ops are sorted by name
+			names = append(names, op.Name)
+		}
+	}
+	writeJaeger(w, names, len(names))
+}
+
+// handleJaegerOperations is the newer form Jaeger UI uses, with span kinds.
+func (s *Server) handleJaegerOperations(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ops := s.jaegerOperations(q.Get("service"), q.Get("spanKind"))
+	writeJaeger(w, ops, len(ops))
+}
+
+// jaegerOperations lists the distinct span name/kind pairs of a service,
+// sorted by name.
+func (s *Server) jaegerOperations(service, kind string) []jaegerOperation {
+	seen := make(map[jaegerOperation]bool)
+	ops := make([]jaegerOperation, 0)
+	for _, span := range s.storage.Traces().GetSpansByService(service) {
+		if span.Span == nil {
+			continue
+		}
+		op := jaegerOperation{Name: span.SpanName, SpanKind: jaegerSpanKind(span.Span.Kind)}
+		if (kind != "" && op.SpanKind != kind) || seen[op] {
+			continue
+		}
+		seen[op] = true
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].Name != ops[j].Name {
+			return ops[i].Name < ops[j].Name
+		}
+		return ops[i].SpanKind < ops[j].SpanKind
+	})
+	return ops
+}
+
+// handleJaegerSearch finds traces with a span matching service, operation,
+// tags, duration and time window, most recent first. Query parameters
+// follow Jaeger: start/end in microseconds (or a lookback like 1h or 2d
+// when start is absent), minDuration/maxDuration as Go durations, tags as a
+// JSON object (or repeated tag=key:value), limit.
+func (s *Server) handleJaegerSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, start, end, limit, err := jaegerSearchParams(q, time.Now())
+	if err != nil {
+		writeJaegerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	spans := s.storage.Traces().GetAllSpans()
+	byTrace := make(map[string][]*storage.StoredSpan)
+	for _, span := range spans {
+		byTrace[span.TraceID] = append(byTrace[span.TraceID], span)
+	}
+
+	// Trace IDs with a match, newest match first
+	var ids []string
+	seen := make(map[string]bool)
+	matches := storage.FilterTraces(spans, filter)
+	for i := len(matches) - 1; i >= 0 && len(ids) < limit; i-- {
+		span := matches[i]
+		if span.Span == nil || seen[span.TraceID] {
+			continue
+		}
+		if t := span.Span.StartTimeUnixNano / 1000; (start > 0 && t < start) || (end > 0 && t > end) {
+			continue
+		}
+		seen[span.TraceID] = true
+		ids = append(ids, span.TraceID)
+	}
+
+	traces := make([]jaegerTrace, 0, len(ids))
+	for _, id := range ids {
+		traces = append(traces, s.buildJaegerTrace(id, byTrace[id]))
+	}
+	writeJaeger(w, traces, len(traces))
+}
+
+// jaegerSearchParams converts Jaeger search parameters to a span filter,
+// a start/end window in microseconds (0 = open) and a trace limit. A
+// lookback counts back from end, or from now.
+func jaegerSearchParams(q url.Values, now time.Time) (storage.QueryFilter, uint64, uint64, int, error) {
+	filter := storage.QueryFilter{ServiceName: q.Get("service"), SpanName: q.Get("operation")}
+	if filter.ServiceName == "" {
+		return filter, 0, 0, 0, fmt.Errorf("parameter 'service' is required")
+	}
+
+	tags := make(map[string]string)
+	if v := q.Get("tags"); v != "" {
+		if err := json.Unmarshal([]byte(v), &tags); err != nil {
+			return filter, 0, 0, 0, fmt.Errorf("malformed 'tags' parameter: %v", err)
+		}
+	}
+	for _, tag := range q["tag"] {
+		k, v, ok := strings.Cut(tag, ":")
+		if !ok {
+			return filter, 0, 0, 0, fmt.Errorf("malformed 'tag' parameter %q, expected key:value", tag)
+		}
+		tags[k] = v
+	}
+	// error=true is how Jaeger users search for failed spans
+	if tags["error"] == "true" {
+		filter.ErrorsOnly = true
+		delete(tags, "error")
+	}
+	if len(tags) > 0 {
+		filter.AttributeEquals = tags
+	}
+
+	for _, d := range []struct {
+		param string
+		dst   **uint64
+	}{{"minDuration", &filter.MinDurationNs}, {"maxDuration", &filter.MaxDurationNs}} {
+		if v := q.Get(d.param); v != "" {
+			dur, err := time.ParseDuration(v)
+			if err != nil || dur < 0 {
+				return filter, 0, 0, 0, fmt.Errorf("malformed '%s' parameter %q", d.param, v)
+			}
+			ns := uint64(dur)
+			*d.dst = &ns
+		}
+	}
+
+	var start, end uint64
+	for _, t := range []struct {
+		param string
+		dst   *uint64
+	}{{"start", &start}, {"end", &end}} {
+		if v := q.Get(t.param); v != "" {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return filter, 0, 0, 0, fmt.Errorf("malformed '%s' parameter %q, expected microseconds", t.param, v)
+			}
+			*t.dst = n
+		}
+	}
+	if v := q.Get("lookback"); v != "" && v != "custom" && start == 0 {
+		lookback, err := parseJaegerLookback(v)
+		if err != nil {
+			return filter, 0, 0, 0, err
+		}
+		from := uint64(now.UnixMicro())
+		if end > 0 {
+			from = end
+		}
+		start = from - min(from, uint64(lookback.Microseconds()))
+	}
+
+	limit := defaultJaegerLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, 0, 0, 0, fmt.Errorf("malformed 'limit' parameter %q", v)
+		}
+		if n > 0 {
+			limit = n
+		}
+	}
+	return filter, start, end, limit, nil
+}
+
+// parseJaegerLookback parses Jaeger UI's lookback values: Go durations plus
+// whole days and weeks, such as 2d or 1w.
+func parseJaegerLookback(v string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(v, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(v, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(v[:len(v)-1])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("malformed 'lookback' parameter %q", v)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("malformed 'lookback' parameter %q", v)
+	}
+	return d, nil
+}
+
+func (s *Server) handleJaegerTrace(w http.ResponseWriter, r *http.Request) {
+	// Jaeger trims leading zeros from trace IDs
+	traceID := strings.ToLower(r.PathValue("id"))
+	if len(traceID) < 32 {
+		traceID = strings.Repeat("0", 32-len(traceID)) + traceID
+	}
+	if _, err := hex.DecodeString(traceID); err != nil || len(traceID) != 32 {
+		writeJaegerError(w, http.StatusBadRequest, "trace ID must be up to 32 hex characters")
+		return
+	}
+
+	spans := s.storage.Traces().GetSpansByTraceID(traceID)
+	if len(spans) == 0 {
+		writeJaegerError(w, http.StatusNotFound, "trace not found")
+		return
+	}
+	writeJaeger(w, []jaegerTrace{s.buildJaegerTrace(traceID, spans)}, 1)
+}
+
+// handleJaegerDependencies serves the service dependency graph for Jaeger
+// UI's System Architecture view, over everything in the buffer.
+func (s *Server) handleJaegerDependencies(w http.ResponseWriter, r *http.Request) {
+	graph := viz.BuildServiceGraph(spanInfos(s.storage.Traces().GetAllSpans()))
+	deps := make([]jaegerDependency, 0, len(graph.Edges))
+	for _, e := range graph.Edges {
+		deps = append(deps, jaegerDependency{Parent: e.From, Child: e.To, CallCount: e.Calls})
+	}
+	writeJaeger(w, deps, len(deps))
+}
+
+// buildJaegerTrace converts one trace's spans, plus the logs correlated
+// with each span, to Jaeger's JSON model. Each distinct resource becomes a
+// process.
+func (s *Server) buildJaegerTrace(traceID string, spans []*storage.StoredSpan) jaegerTrace {
+	trace := jaegerTrace{
+		TraceID:   traceID,
+		Spans:     make([]jaegerSpan, 0, len(spans)),
+		Processes: make(map[string]jaegerProcess),
+	}
+
+	logsBySpan := make(map[string][]*storage.StoredLog)
+	for _, l := range s.storage.Logs().GetLogsByTraceID(traceID) {
+		if l.SpanID != "" {
+			logsBySpan[l.SpanID] = append(logsBySpan[l.SpanID], l)
+		}
+	}
+
+	processIDs := make(map[any]string)
+	for _, ss := range spans {
+		if ss.Span == nil {
+			continue
+		}
+		key := any(ss.ServiceName)
+		if ss.ResourceSpan != nil {
+			key = ss.ResourceSpan
+		}
+		pid, ok := processIDs[key]
+		if !ok {
+			pid = fmt.Sprintf("p%d", len(processIDs)+1)
+			processIDs[key] = pid
+			proc := jaegerProcess{ServiceName: ss.ServiceName, Tags: []jaegerKV{}}
+			if ss.ResourceSpan != nil && ss.ResourceSpan.Resource != nil {
+				for _, kv := range ss.ResourceSpan.Resource.Attributes {
+					if kv.Key != "service.name" {
+						proc.Tags = append(proc.Tags, jaegerTag(kv.Key, kv.Value))
+					}
+				}
+			}
+			trace.Processes[pid] = proc
+		}
+		trace.Spans = append(trace.Spans, jaegerSpanFrom(ss, pid, logsBySpan[ss.SpanID]))
+	}
+	return trace
+}
+
+func jaegerSpanFrom(ss *storage.StoredSpan, processID string, logs []*storage.StoredLog) jaegerSpan {
+	sp := ss.Span
+	span := jaegerSpan{
+		TraceID:       ss.TraceID,
+		SpanID:        ss.SpanID,
+		OperationName: ss.SpanName,
+		References:    []jaegerReference{},
+		Flags:         1,
+		StartTime:     sp.StartTimeUnixNano / 1000,
+		ProcessID:     processID,
+		Tags:          make([]jaegerKV, 0, len(sp.Attributes)+3),
+		Logs:          make([]jaegerLog, 0, len(sp.Events)+len(logs)),
+	}
+	if sp.EndTimeUnixNano > sp.StartTimeUnixNano {
+		span.Duration = (sp.EndTimeUnixNano - sp.StartTimeUnixNano) / 1000
+	}
+	if len(sp.ParentSpanId) > 0 {
+		span.References = append(span.References, jaegerReference{
+			RefType: "CHILD_OF", TraceID: ss.TraceID, SpanID: hex.EncodeToString(sp.ParentSpanId),
+		})
+	}
+	for _, link := range sp.Links {
+		span.References = append(span.References, jaegerReference{
+			RefType: "FOLLOWS_FROM", TraceID: hex.EncodeToString(link.TraceId), SpanID: hex.EncodeToString(link.SpanId),
+		})
+	}
+
+	for _, kv := range sp.Attributes {
+		span.Tags = append(span.Tags, jaegerTag(kv.Key, kv.Value))
+	}
+	if kind := jaegerSpanKind(sp.Kind); kind != "" {
+		span.Tags = append(span.Tags, jaegerKV{Key: "span.kind", Type: "string", Value: kind})
+	}
+	if sp.Status != nil && sp.Status.Code != tracepb.Status_STATUS_CODE_UNSET {
+		span.Tags = append(span.Tags, jaegerKV{Key: "otel.status_code", Type: "string", Value: strings.TrimPrefix(sp.Status.Code.String(), "STATUS_CODE_")})
+		if sp.Status.Code == tracepb.Status_STATUS_CODE_ERROR {
+			span.Tags = append(span.Tags, jaegerKV{Key: "error", Type: "bool", Value: true})
+		}
+		if sp.Status.Message != "" {
+			span.Tags = append(span.Tags, jaegerKV{Key: "otel.status_description", Type: "string", Value: sp.Status.Message})
+		}
+	}
+
+	for _, ev := range sp.Events {
+		fields := []jaegerKV{{Key: "event", Type: "string", Value: ev.Name}}
+		for _, kv := range ev.Attributes {
+			fields = append(fields, jaegerTag(kv.Key, kv.Value))
+		}
+		span.Logs = append(span.Logs, jaegerLog{Timestamp: ev.TimeUnixNano / 1000, Fields: fields})
+	}
+	for _, l := range logs {
+		fields := []jaegerKV{
+			{Key: "event", Type: "string", Value: l.Body},
+			{Key: "level", Type: "string", Value: l.Severity},
+		}
+		if l.LogRecord != nil {
+			for _, kv := range l.LogRecord.Attributes {
+				fields = append(fields, jaegerTag(kv.Key, kv.Value))
+			}
+		}
+		span.Logs = append(span.Logs, jaegerLog{Timestamp: l.Timestamp / 1000, Fields: fields})
+	}
+	sort.SliceStable(span.Logs, func(i, j int) bool { return span.Logs[i].Timestamp < span.Logs[j].Timestamp })
+	return span
+}
+
+// jaegerTag converts an OTLP attribute to a typed Jaeger tag. Arrays and
+// maps are JSON-encoded strings.
+func jaegerTag(key string, value *commonpb.AnyValue) jaegerKV {
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_BoolValue:
+		return jaegerKV{Key: key, Type: "bool", Value: v.BoolValue}
+	case *commonpb.AnyValue_IntValue:
+		return jaegerKV{Key: key, Type: "int64", Value: v.IntValue}
+	case *commonpb.AnyValue_DoubleValue:
+		return jaegerKV{Key: key, Type: "float64", Value: v.DoubleValue}
+	case *commonpb.AnyValue_StringValue:
+		return jaegerKV{Key: key, Type: "string", Value: v.StringValue}
+	default:
+		data, _ := json.Marshal(storage.AttributeValue(value))
+		return jaegerKV{Key: key, Type: "string", Value: string(data)}
+	}
+}
+
+// jaegerSpanKind maps OTLP span kinds to Jaeger's lowercase names;
+// unspecified spans have no kind.
+func jaegerSpanKind(kind tracepb.Span_SpanKind) string {
+	switch kind {
+	case tracepb.Span_SPAN_KIND_SERVER:
+		return "server"
+	case tracepb.Span_SPAN_KIND_CLIENT:
+		return "client"
+	case tracepb.Span_SPAN_KIND_PRODUCER:
+		return "producer"
+	case tracepb.Span_SPAN_KIND_CONSUMER:
+		return "consumer"
+	case tracepb.Span_SPAN_KIND_INTERNAL:
+		return "internal"
+	default:
+		return ""
+	}
+}
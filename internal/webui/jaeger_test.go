@@ -0,0 +1,250 @@
+package webui
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+// jaegerEnvelope is the response envelope as Jaeger UI reads it.
+type jaegerEnvelope struct {
+	Data   json.RawMessage `json:"data"`
+	Total  int             `json:"total"`
+	Errors []jaegerError   `json:"errors"`
+}
+
+// getJaeger GETs a Jaeger API path and decodes the envelope, whatever the
+// status code, and its data into v when given.
+func getJaeger(t *testing.T, h http.Handler, target string, v any) (int, jaegerEnvelope) {
+	t.Helper()
+	rec := serve(h, httptest.NewRequest(http.MethodGet, JaegerPrefix+target, nil))
+	var env jaegerEnvelope
+	if err := json.NewDecoder(rec.Body).Decode(&env); err != nil {
+		t.Fatalf("GET %s: decode: %v", target, err)
+	}
+	if v != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(env.Data, v); err != nil {
+			t.Fatalf("GET %s: decode data: %v", target, err)
+		}
+	}
+	return rec.Code, env
+}
+
+func TestJaegerServicesAndOperations(t *testing.T) {
+	st, h := newTestServer(t)
+
+	// Jaeger UI expects arrays, never null, even with nothing stored
+	var services []string
+	if code, env := getJaeger(t, h, "/api/services", &services); code != http.StatusOK || string(env.Data) != "[]" {
+		t.Fatalf("expected 200 with [], got %d and %s", code, env.Data)
+	}
+	var names []string
+	if code, env := getJaeger(t, h, "/api/services/nope/operations", &names); code != http.StatusOK || string(env.Data) != "[]" {
+		t.Fatalf("expected 200 with [] for an unknown service, got %d and %s", code, env.Data)
+	}
+
+	addSpans(t, st,
+		testSpan{service: "frontend", name: "GET /", traceID: traceOne, spanID: "0000000000000001", startNs: 1000, durNs: 10},
+		testSpan{service: "frontend", name: "GET /", traceID: traceTwo, spanID: "0000000000000002", startNs: 2000, durNs: 10},
+		testSpan{service: "frontend", name: "DELETE /", traceID: traceTwo, spanID: "0000000000000003", startNs: 3000, durNs: 10},
+		testSpan{service: "cart", name: "db", traceID: traceOne, spanID: "0000000000000004", startNs: 1500, durNs: 10},
+	)
+
+	code, env := getJaeger(t, h, "/api/services", &services)
+	if code != http.StatusOK || !slices.Equal(services, []string{"cart", "frontend"}) || env.Total != 2 {
+		t.Errorf("expected sorted services and total 2, got %d: %v (total %d)", code, services, env.Total)
+	}
+
+	// Distinct operation names, sorted
+	getJaeger(t, h, "/api/services/frontend/operations", &names)
+	if !slices.Equal(names, []string{"DELETE /", "GET /"}) {
+		t.Errorf("expected distinct sorted operations, got %v", names)
+	}
+
+	var ops []jaegerOperation
+	getJaeger(t, h, "/api/operations?service=frontend&spanKind=server", &ops)
+	if len(ops) != 2 || ops[0] != (jaegerOperation{Name: "DELETE /", SpanKind: "server"}) {
+		t.Errorf("unexpected operations with kinds: %+v", ops)
+	}
+	getJaeger(t, h, "/api/operations?service=frontend&spanKind=client", &ops)
+	if len(ops) != 0 {
+		t.Errorf("expected no client operations, got %+v", ops)
+	}
+}
+
+func TestJaegerSearch(t *testing.T) {
+	st, h := newTestServer(t)
+	addSpans(t, st,
+		testSpan{service: "frontend", name: "GET /", traceID: traceOne, spanID: "0000000000000001", startNs: 1_000_000, durNs: 5_000_000,
+			attrs: []*commonpb.KeyValue{stringKV("http.route", "/")}},
+		testSpan{service: "cart", name: "db", traceID: traceOne, spanID: "0000000000000002", parentID: "0000000000000001", startNs: 2_000_000, durNs: 1_000_000, errored: true},
+		testSpan{service: "frontend", name: "GET /cart", traceID: traceTwo, spanID: "0000000000000003", startNs: 9_000_000, durNs: 1_000_000,
+			attrs: []*commonpb.KeyValue{stringKV("http.route", "/cart")}},
+	)
+
+	search := func(query string) []jaegerTrace {
+		t.Helper()
+		var traces []jaegerTrace
+		if code, env := getJaeger(t, h, "/api/traces?"+query, &traces); code != http.StatusOK {
+			t.Fatalf("search %q: expected 200, got %d: %+v", query, code, env.Errors)
+		}
+		return traces
+	}
+	traceIDs := func(traces []jaegerTrace) []string {
+		var ids []string
+		for _, tr := range traces {
+			ids = append(ids, tr.TraceID)
+		}
+		return ids
+	}
+
+	// Newest match first, each trace with all its spans
+	traces := search("service=frontend")
+	if got := traceIDs(traces); !slices.Equal(got, []string{traceTwo, traceOne}) {
+		t.Fatalf("expected both traces newest first, got %v", got)
+	}
+	if len(traces[1].Spans) != 2 || len(traces[1].Processes) != 2 {
+		t.Errorf("expected the whole first trace with two processes, got %d spans, %d processes",
+			len(traces[1].Spans), len(traces[1].Processes))
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"service=frontend&limit=1", []string{traceTwo}},
+		{"service=frontend&limit=0", []string{traceTwo, traceOne}}, // 0 is the default limit
+		{"service=frontend&operation=GET+/cart", []string{traceTwo}},
+		{"service=frontend&tags=" + url.QueryEscape(`{"http.route":"/"}`), []string{traceOne}},
+		{"service=frontend&tag=http.route:/cart", []string{traceTwo}},
+		{"service=cart&tags=" + url.QueryEscape(`{"error":"true"}`), []string{traceOne}},
+		{"service=frontend&minDuration=2ms", []string{traceOne}},
+		{"service=frontend&maxDuration=2ms", []string{traceTwo}},
+		// start/end are microseconds
+		{"service=frontend&start=0&end=5000", []string{traceOne}},
+		{"service=frontend&start=5000", []string{traceTwo}},
+		// A lookback counts back from end
+		{"service=frontend&end=10000&lookback=2ms", []string{traceTwo}},
+		{"service=frontend&end=10000&lookback=custom", []string{traceTwo, traceOne}},
+	}
+	for _, tt := range tests {
+		if got := traceIDs(search(tt.query)); !slices.Equal(got, tt.want) {
+			t.Errorf("search %q: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	// No match is an empty array, not null
+	code, env := getJaeger(t, h, "/api/traces?service=nope", nil)
+	if code != http.StatusOK || string(env.Data) != "[]" || env.Total != 0 {
+		t.Errorf("expected 200 with [], got %d and %s", code, env.Data)
+	}
+
+	for _, query := range []string{
+		"",                               // service is required
+		"service=frontend&limit=-1",      // negative limit
+		"service=frontend&limit=many",    // not a number
+		"service=frontend&tags={broken",  // bad JSON
+		"service=frontend&tag=nocolon",   // tag without a value
+		"service=frontend&minDuration=1", // no unit
+		"service=frontend&start=yesterday",
+		"service=frontend&lookback=soon",
+	} {
+		code, env := getJaeger(t, h, "/api/traces?"+query, nil)
+		if code != http.StatusBadRequest || len(env.Errors) != 1 || env.Errors[0].Code != http.StatusBadRequest {
+			t.Errorf("search %q: expected a 400 error envelope, got %d: %+v", query, code, env.Errors)
+		}
+	}
+}
+
+func TestJaegerSearchParamsLookback(t *testing.T) {
+	now := time.Date(2025, 12, 9, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lookback string
+		want     time.Time
+	}{
+		{"1h", now.Add(-time.Hour)},
+		{"2d", now.Add(-48 * time.Hour)},
+		{"1w", now.Add(-7 * 24 * time.Hour)},
+	}
+	for _, tt := range tests {
+		q := url.Values{"service": {"svc"}, "lookback": {tt.lookback}}
+		_, start, end, _, err := jaegerSearchParams(q, now)
+		if err != nil {
+			t.Fatalf("lookback %q: %v", tt.lookback, err)
+		}
+		if start != uint64(tt.want.UnixMicro()) || end != 0 {
+			t.Errorf("lookback %q: expected start %d and open end, got %d..%d", tt.lookback, tt.want.UnixMicro(), start, end)
+		}
+	}
+
+	// An explicit start wins
+	q := url.Values{"service": {"svc"}, "lookback": {"1h"}, "start": {"42"}}
+	if _, start, _, _, _ := jaegerSearchParams(q, now); start != 42 {
+		t.Errorf("expected the explicit start, got %d", start)
+	}
+}
+
+func TestJaegerTrace(t *testing.T) {
+	st, h := newTestServer(t)
+	// Jaeger trims leading zeros from trace IDs
+	const paddedID = "000000000000000000000000000000ab"
+	addSpans(t, st,
+		testSpan{service: "frontend", name: "GET /", traceID: paddedID, spanID: "0000000000000001", startNs: 1_000_000, durNs: 3_000_000},
+		testSpan{service: "cart", name: "db", traceID: paddedID, spanID: "0000000000000002", parentID: "0000000000000001",
+			startNs: 2_000_000, durNs: 1_000_000, errored: true, attrs: []*commonpb.KeyValue{stringKV("db.system", "redis")},
+			links: [][2]string{{traceOne, "0000000000000009"}}},
+	)
+
+	var traces []jaegerTrace
+	code, env := getJaeger(t, h, "/api/traces/AB", &traces)
+	if code != http.StatusOK || env.Total != 1 || len(traces) != 1 {
+		t.Fatalf("expected one trace, got %d: %s", code, env.Data)
+	}
+	tr := traces[0]
+	if tr.TraceID != paddedID || len(tr.Spans) != 2 || len(tr.Processes) != 2 {
+		t.Fatalf("unexpected trace: %+v", tr)
+	}
+
+	root, child := tr.Spans[0], tr.Spans[1]
+	if root.StartTime != 1000 || root.Duration != 3000 || len(root.References) != 0 {
+		t.Errorf("expected µs times and no references on the root, got %+v", root)
+	}
+	if tr.Processes[root.ProcessID].ServiceName != "frontend" || tr.Processes[child.ProcessID].ServiceName != "cart" {
+		t.Errorf("unexpected processes: %+v", tr.Processes)
+	}
+	wantRefs := []jaegerReference{
+		{RefType: "CHILD_OF", TraceID: paddedID, SpanID: "0000000000000001"},
+		{RefType: "FOLLOWS_FROM", TraceID: traceOne, SpanID: "0000000000000009"},
+	}
+	if !slices.Equal(child.References, wantRefs) {
+		t.Errorf("expected parent and link references, got %+v", child.References)
+	}
+	tags := make(map[string]any)
+	for _, kv := range child.Tags {
+		tags[kv.Key] = kv.Value
+	}
+	if tags["db.system"] != "redis" || tags["span.kind"] != "server" || tags["error"] != true || tags["otel.status_code"] != "ERROR" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if root.Logs == nil {
+		t.Errorf("expected empty logs as [], got %+v", root.Logs)
+	}
+
+	// Unknown and malformed IDs use the error envelope
+	code, env = getJaeger(t, h, "/api/traces/"+traceTwo, nil)
+	if code != http.StatusNotFound || len(env.Errors) != 1 || env.Errors[0].Code != http.StatusNotFound {
+		t.Errorf("expected a 404 error envelope, got %d: %+v", code, env.Errors)
+	}
+	for _, id := range []string{"xyz", strings.Repeat("a", 33)} {
+		if code, _ := getJaeger(t, h, "/api/traces/"+id, nil); code != http.StatusBadRequest {
+			t.Errorf("trace %q: expected 400, got %d", id, code)
+		}
+	}
+}
@@ -0,0 +1,459 @@
+package webui
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+)
+
+// maxSeriesPoints caps points per series so a long buffered window stays chartable.
+const maxSeriesPoints = 2000
+
+// splitAll splits series by the full attribute set instead of one key.
+const splitAll = "*"
+
+// metricServiceGroup is one service's entry in /api/metrics.
+type metricServiceGroup struct {
+	Service string       `json:"service"`
+	Metrics []metricInfo `json:"metrics"`
+}
+
+type metricInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Unit        string `json:"unit,omitempty"`
+	Description string `json:"description,omitempty"`
+	Points      int    `json:"points"`
+	Updated     string `json:"updated"`
+}
+
+// handleMetrics lists buffered metric names grouped by service.
+// An optional ?service= narrows the list to one service, and
+// start_snapshot/end_snapshot to a snapshot range.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	service := r.URL.Query().Get("service")
+
+	type key struct{ service, name string }
+	infos := make(map[key]*metricInfo)
+	latest := make(map[key]uint64)
+	stored, ok := s.scopedMetrics(w, r)
+	if !ok {
+		return
+	}
+	for _, m := range stored {
+		if service != "" && m.ServiceName != service {
+			continue
+		}
+		k := key{m.ServiceName, m.MetricName}
+		info, ok := infos[k]
+		if !ok {
+			info = &metricInfo{Name: m.MetricName}
+			infos[k] = info
+		}
+		// Later entries win so type/unit reflect the newest export
+		info.Type = m.MetricType.String()
+		if m.Metric != nil {
+			info.Unit = m.Metric.Unit
+			info.Description = m.Metric.Description
+		}
+		info.Points += m.DataPointCount
+		if m.Timestamp >= latest[k] {
+			latest[k] = m.Timestamp
+			info.Updated = formatNanoTime(m.Timestamp)
+		}
+	}
+
+	byService := make(map[string][]metricInfo)
+	for k, info := range infos {
+		byService[k.service] = append(byService[k.service], *info)
+	}
+	groups := make([]metricServiceGroup, 0, len(byService))
+	for svc, metrics := range byService {
+		sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+		groups = append(groups, metricServiceGroup{Service: svc, Metrics: metrics})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Service < groups[j].Service })
+
+	writeJSON(w, groups)
+}
+
+// metricSeriesResponse is the JSON shape for /api/metrics/series.
+type metricSeriesResponse struct {
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`
+	Unit          string         `json:"unit,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	Temporality   string         `json:"temporality,omitempty"`
+	Monotonic     bool           `json:"monotonic,omitempty"`
+	SplitBy       string         `json:"split_by,omitempty"`
+	AttributeKeys []string       `json:"attribute_keys"`
+	Bounds        []float64      `json:"bounds,omitempty"` // histogram explicit bounds
+	Series        []metricSeries `json:"series"`
+}
+
+type metricSeries struct {
+	Service string        `json:"service"`
+	Label   string        `json:"label,omitempty"` // split attribute value(s)
+	Points  []metricPoint `json:"points"`
+}
+
+// metricPoint is one timestamp of a series. V is the gauge/sum value, or the
+// mean for histograms and summaries. Buckets are histogram counts for
+// heatmaps, and Percentiles are computed from those buckets or taken from
+// summary quantiles. Cumulative explicit histograms are differenced per
+// attribute set before merging, so Count, Sum, V and Buckets all cover the
+// interval since the previous report.
+type metricPoint struct {
+	T           uint64             `json:"t"`
+	V           *float64           `json:"v,omitempty"`
+	Count       *uint64            `json:"count,omitempty"`
+	Sum         *float64           `json:"sum,omitempty"`
+	Buckets     []uint64           `json:"buckets,omitempty"`
+	Percentiles map[string]float64 `json:"percentiles,omitempty"`
+}
+
+// handleMetricSeries returns time series for one metric over the buffered
+// window. Query parameters:
+//
+//	name     metric name (required)
+//	service  only this service (default: all, one series group per service)
+//	split    attribute key to split series by, or "*" for every attribute set;
+//	         points sharing a timestamp are otherwise merged (gauges averaged,
+//	         sums and histogram buckets added)
+//	start_snapshot, end_snapshot  limit to a snapshot range
+func (s *Server) handleMetricSeries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name := q.Get("name")
+	if name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	all, ok := s.scopedMetrics(w, r)
+	if !ok {
+		return
+	}
+	service := q.Get("service")
+	var stored []*storage.StoredMetric
+	for _, m := range all {
+		if m.MetricName == name && (service == "" || m.ServiceName == service) {
+			stored = append(stored, m)
+		}
+	}
+	if len(stored) == 0 {
+		http.Error(w, fmt.Sprintf("metric %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, buildMetricSeries(name, stored, q.Get("split")))
+}
+
+// seriesBuilder accumulates merged points for one series, keyed by timestamp.
+type seriesBuilder struct {
+	service string
+	label   string
+	points  map[uint64]*pointAcc
+}
+
+// pointAcc merges every data point that lands on one timestamp.
+type pointAcc struct {
+	n       int
+	value   float64
+	count   uint64
+	sum     float64
+	hasSum  bool
+	buckets []uint64
+	quants  map[string]float64
+	expHist *metricspb.ExponentialHistogramDataPoint
+}
+
+func buildMetricSeries(name string, stored []*storage.StoredMetric, split string) metricSeriesResponse {
+	resp := metricSeriesResponse{Name: name, SplitBy: split, AttributeKeys: []string{}}
+	builders := make(map[string]*seriesBuilder)
+	attrKeys := make(map[string]bool)
+	deltas := histogramDeltas(stored)
+
+	add := func(svc string, attrs []*commonpb.KeyValue, t uint64, fn func(*pointAcc)) {
+		for _, kv := range attrs {
+			attrKeys[kv.Key] = true
+		}
+		label := splitLabel(attrs, split)
+		key := svc + "\x00" + label
+		b, ok := builders[key]
+		if !ok {
+			b = &seriesBuilder{service: svc, label: label, points: make(map[uint64]*pointAcc)}
+			builders[key] = b
+		}
+		acc, ok := b.points[t]
+		if !ok {
+			acc = &pointAcc{}
+			b.points[t] = acc
+		}
+		acc.n++
+		fn(acc)
+	}
+
+	for _, sm := range stored {
+		m := sm.Metric
+		if m == nil {
+			continue
+		}
+		resp.Type = sm.MetricType.String()
+		resp.Unit = m.Unit
+		resp.Description = m.Description
+		svc := sm.ServiceName
+
+		switch data := m.Data.(type) {
+		case *metricspb.Metric_Gauge:
+			for _, dp := range data.Gauge.DataPoints {
+				v := numberValue(dp)
+				add(svc, dp.Attributes, dp.TimeUnixNano, func(a *pointAcc) { a.value += v })
+			}
+		case *metricspb.Metric_Sum:
+			resp.Temporality = temporalityName(data.Sum.AggregationTemporality)
+			resp.Monotonic = data.Sum.IsMonotonic
+			for _, dp := range data.Sum.DataPoints {
+				v := numberValue(dp)
+				add(svc, dp.Attributes, dp.TimeUnixNano, func(a *pointAcc) { a.value += v })
+			}
+		case *metricspb.Metric_Histogram:
+			resp.Temporality = temporalityName(data.Histogram.AggregationTemporality)
+			for _, dp := range data.Histogram.DataPoints {
+				if len(dp.ExplicitBounds) > 0 {
+					resp.Bounds = dp.ExplicitBounds
+				}
+				if delta, ok := deltas[dp]; ok {
+					dp = delta
+				}
+				add(svc, dp.Attributes, dp.TimeUnixNano, func(a *pointAcc) {
+					a.count += dp.Count
+					a.sum += dp.GetSum()
+					a.hasSum = a.hasSum || dp.Sum != nil
+					if a.buckets == nil {
+						a.buckets = make([]uint64, len(dp.BucketCounts))
+					}
+					if len(a.buckets) == len(dp.BucketCounts) {
+						for i, c := range dp.BucketCounts {
+							a.buckets[i] += c
+						}
+					}
+				})
+			}
+		case *metricspb.Metric_ExponentialHistogram:
+			resp.Temporality = temporalityName(data.ExponentialHistogram.AggregationTemporality)
+			for _, dp := range data.ExponentialHistogram.DataPoints {
+				add(svc, dp.Attributes, dp.TimeUnixNano, func(a *pointAcc) {
+					a.count += dp.Count
+					a.sum += dp.GetSum()
+					a.hasSum = a.hasSum || dp.Sum != nil
+					// Differing scales can't be merged; keep the largest point for percentiles
+					if a.expHist == nil || dp.Count > a.expHist.Count {
+						a.expHist = dp
+					}
+				})
+			}
+		case *metricspb.Metric_Summary:
+			for _, dp := range data.Summary.DataPoints {
+				add(svc, dp.Attributes, dp.TimeUnixNano, func(a *pointAcc) {
+					a.count += dp.Count
+					a.sum += dp.Sum
+					a.hasSum = true
+					if a.quants == nil {
+						a.quants = make(map[string]float64)
+					}
+					// Quantiles can't be added; average them across merged points
+					for _, q := range dp.QuantileValues {
+						k := quantileName(q.Quantile)
+						a.quants[k] += (q.Value - a.quants[k]) / float64(a.n)
+					}
+				})
+			}
+		}
+	}
+
+	for k := range attrKeys {
+		resp.AttributeKeys = append(resp.AttributeKeys, k)
+	}
+	sort.Strings(resp.AttributeKeys)
+
+	resp.Series = make([]metricSeries, 0, len(builders))
+	for _, b := range builders {
+		resp.Series = append(resp.Series, b.finish(resp.Type, resp.Bounds))
+	}
+	sort.Slice(resp.Series, func(i, j int) bool {
+		if resp.Series[i].Service != resp.Series[j].Service {
+			return resp.Series[i].Service < resp.Series[j].Service
+		}
+		return resp.Series[i].Label < resp.Series[j].Label
+	})
+	return resp
+}
+
+// finish orders points by time and derives means and percentiles.
+func (b *seriesBuilder) finish(metricType string, bounds []float64) metricSeries {
+	times := make([]uint64, 0, len(b.points))
+	for t := range b.points {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	if len(times) > maxSeriesPoints {
+		times = times[len(times)-maxSeriesPoints:]
+	}
+
+	series := metricSeries{Service: b.service, Label: b.label, Points: make([]metricPoint, 0, len(times))}
+	for _, t := range times {
+		a := b.points[t]
+		p := metricPoint{T: t}
+
+		switch metricType {
+		case "Gauge":
+			v := a.value / float64(a.n)
+			p.V = &v
+		case "Sum":
+			v := a.value
+			p.V = &v
+		default:
+			count := a.count
+			p.Count = &count
+			if a.hasSum {
+				sum := a.sum
+				p.Sum = &sum
+				if count > 0 {
+					mean := sum / float64(count)
+					p.V = &mean
+				}
+			}
+		}
+
+		switch {
+		case a.buckets != nil:
+			p.Buckets = a.buckets
+			var total uint64
+			for _, c := range p.Buckets {
+				total += c
+			}
+			p.Percentiles = storage.ComputeHistogramPercentiles(&metricspb.HistogramDataPoint{
+				Count:          total,
+				BucketCounts:   p.Buckets,
+				ExplicitBounds: bounds,
+			})
+		case a.expHist != nil:
+			p.Percentiles = storage.ComputeExponentialHistogramPercentiles(a.expHist)
+		case a.quants != nil:
+			p.Percentiles = a.quants
+		}
+
+		series.Points = append(series.Points, p)
+	}
+	return series
+}
+
+// histogramDeltas differences the points of cumulative explicit histograms
+// against the previous point with the same service and attribute set, so
+// they can be merged like delta points. It returns the replacement for
+// each cumulative point. The first point of each stream, and the first
+// after a reset, covers everything since its start time and is kept as is.
+func histogramDeltas(stored []*storage.StoredMetric) map[*metricspb.HistogramDataPoint]*metricspb.HistogramDataPoint {
+	streams := make(map[string][]*metricspb.HistogramDataPoint)
+	for _, sm := range stored {
+		h := sm.Metric.GetHistogram()
+		if h == nil || h.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+			continue
+		}
+		for _, dp := range h.DataPoints {
+			key := sm.ServiceName + "\x00" + splitLabel(dp.Attributes, splitAll)
+			streams[key] = append(streams[key], dp)
+		}
+	}
+
+	deltas := make(map[*metricspb.HistogramDataPoint]*metricspb.HistogramDataPoint)
+	for _, points := range streams {
+		sort.SliceStable(points, func(i, j int) bool { return points[i].TimeUnixNano < points[j].TimeUnixNano })
+		var prev *metricspb.HistogramDataPoint
+		for _, dp := range points {
+			deltas[dp] = histogramDelta(dp, prev)
+			prev = dp
+		}
+	}
+	return deltas
+}
+
+// histogramDelta returns cur-prev for the count, sum and every bucket, or
+// cur unchanged when there is no previous point, the bucket layout or
+// start time differs, or any count went down (a reset).
+func histogramDelta(cur, prev *metricspb.HistogramDataPoint) *metricspb.HistogramDataPoint {
+	if prev == nil || len(cur.BucketCounts) != len(prev.BucketCounts) ||
+		cur.StartTimeUnixNano != prev.StartTimeUnixNano || cur.Count < prev.Count {
+		return cur
+	}
+	buckets := make([]uint64, len(cur.BucketCounts))
+	for i := range cur.BucketCounts {
+		if cur.BucketCounts[i] < prev.BucketCounts[i] {
+			return cur
+		}
+		buckets[i] = cur.BucketCounts[i] - prev.BucketCounts[i]
+	}
+	delta := &metricspb.HistogramDataPoint{
+		Attributes:        cur.Attributes,
+		StartTimeUnixNano: prev.TimeUnixNano,
+		TimeUnixNano:      cur.TimeUnixNano,
+		Count:             cur.Count - prev.Count,
+		BucketCounts:      buckets,
+		ExplicitBounds:    cur.ExplicitBounds,
+	}
+	if cur.Sum != nil && prev.Sum != nil {
+		sum := cur.GetSum() - prev.GetSum()
+		delta.Sum = &sum
+	}
+	return delta
+}
+
+// splitLabel renders the series label for a data point's attributes.
+func splitLabel(attrs []*commonpb.KeyValue, split string) string {
+	switch split {
+	case "":
+		return ""
+	case splitAll:
+		parts := make([]string, 0, len(attrs))
+		for _, kv := range attrs {
+			parts = append(parts, kv.Key+"="+fmt.Sprint(storage.AttributeValue(kv.Value)))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	default:
+		for _, kv := range attrs {
+			if kv.Key == split {
+				return fmt.Sprint(storage.AttributeValue(kv.Value))
+			}
+		}
+		return "(none)"
+	}
+}
+
+func numberValue(dp *metricspb.NumberDataPoint) float64 {
+	if v, ok := dp.Value.(*metricspb.NumberDataPoint_AsInt); ok {
+		return float64(v.AsInt)
+	}
+	return dp.GetAsDouble()
+}
+
+func temporalityName(t metricspb.AggregationTemporality) string {
+	switch t {
+	case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE:
+		return "cumulative"
+	case metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA:
+		return "delta"
+	default:
+		return ""
+	}
+}
+
+// quantileName formats 0.99 as "p99" and 0.999 as "p99.9".
+func quantileName(q float64) string {
+	return "p" + strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", q*100), "0"), ".")
+}
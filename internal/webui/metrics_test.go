@@ -0,0 +1,192 @@
+package webui
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+var testBounds = []float64{10, 100}
+
+// histPoint is a histogram data point for route=route with buckets
+// counting values of 1, 50 and 500, and a sum of sum.
+func histPoint(route string, t uint64, buckets [3]uint64, sum float64) *metricspb.HistogramDataPoint {
+	return &metricspb.HistogramDataPoint{
+		Attributes:        []*commonpb.KeyValue{stringKV("route", route)},
+		StartTimeUnixNano: 1,
+		TimeUnixNano:      t,
+		Count:             buckets[0] + buckets[1] + buckets[2],
+		Sum:               &sum,
+		BucketCounts:      buckets[:],
+		ExplicitBounds:    testBounds,
+	}
+}
+
+// histMetric wraps points in one stored histogram export from svc.
+func histMetric(svc string, temporality metricspb.AggregationTemporality, points ...*metricspb.HistogramDataPoint) *storage.StoredMetric {
+	return &storage.StoredMetric{
+		ServiceName: svc,
+		MetricName:  "latency",
+		MetricType:  storage.MetricTypeHistogram,
+		Metric: &metricspb.Metric{Name: "latency", Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+			AggregationTemporality: temporality,
+			DataPoints:             points,
+		}}},
+	}
+}
+
+const cumulative = metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+
+// pointAt returns the point of series at time t.
+func pointAt(t *testing.T, series metricSeries, ts uint64) metricPoint {
+	t.Helper()
+	for _, p := range series.Points {
+		if p.T == ts {
+			return p
+		}
+	}
+	t.Fatalf("no point at %d in %+v", ts, series.Points)
+	return metricPoint{}
+}
+
+func TestBuildMetricSeriesCumulativeHistogram(t *testing.T) {
+	// Two routes report at interleaved times, so a merged point never has
+	// both; each must be differenced against its own previous report
+	stored := []*storage.StoredMetric{
+		histMetric("api", cumulative, histPoint("a", 10, [3]uint64{1, 0, 0}, 1)),
+		histMetric("api", cumulative, histPoint("b", 20, [3]uint64{0, 2, 0}, 100)),
+		histMetric("api", cumulative, histPoint("a", 30, [3]uint64{3, 0, 1}, 503)),
+		histMetric("api", cumulative, histPoint("b", 40, [3]uint64{0, 2, 2}, 1100)),
+	}
+
+	resp := buildMetricSeries("latency", stored, "")
+	if len(resp.Series) != 1 || resp.Type != "Histogram" || resp.Temporality != "cumulative" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if !slices.Equal(resp.Bounds, testBounds) || !slices.Equal(resp.AttributeKeys, []string{"route"}) {
+		t.Errorf("unexpected bounds %v or keys %v", resp.Bounds, resp.AttributeKeys)
+	}
+	series := resp.Series[0]
+
+	// a: 2 more in the first bucket and 1 in the last since t=10
+	p := pointAt(t, series, 30)
+	if !slices.Equal(p.Buckets, []uint64{2, 0, 1}) || *p.Count != 3 || *p.Sum != 502 {
+		t.Errorf("t=30: expected buckets [2 0 1], count 3, sum 502; got %v %d %v", p.Buckets, *p.Count, *p.Sum)
+	}
+	if want := 502.0 / 3; *p.V != want {
+		t.Errorf("t=30: expected mean %v, got %v", want, *p.V)
+	}
+
+	// b: 2 more in the last bucket since t=20
+	p = pointAt(t, series, 40)
+	if !slices.Equal(p.Buckets, []uint64{0, 0, 2}) || *p.Count != 2 || *p.Sum != 1000 || *p.V != 500 {
+		t.Errorf("t=40: expected buckets [0 0 2], count 2, sum 1000, mean 500; got %v %d %v %v", p.Buckets, *p.Count, *p.Sum, *p.V)
+	}
+	if p.Percentiles["p50"] < 100 {
+		t.Errorf("t=40: expected p50 in the overflow bucket, got %v", p.Percentiles)
+	}
+
+	// The first report of each route covers everything since its start
+	if p := pointAt(t, series, 20); !slices.Equal(p.Buckets, []uint64{0, 2, 0}) || *p.Count != 2 {
+		t.Errorf("t=20: expected the first report as is, got %v %d", p.Buckets, *p.Count)
+	}
+}
+
+func TestBuildMetricSeriesMergesDeltas(t *testing.T) {
+	// Both routes at each timestamp: deltas per route, then added
+	stored := []*storage.StoredMetric{
+		histMetric("api", cumulative, histPoint("a", 10, [3]uint64{1, 0, 0}, 1), histPoint("b", 10, [3]uint64{0, 1, 0}, 50)),
+		histMetric("api", cumulative, histPoint("a", 20, [3]uint64{2, 0, 0}, 2), histPoint("b", 20, [3]uint64{0, 1, 1}, 550)),
+		// a restarted: its counts went down, so it is taken as is
+		histMetric("api", cumulative, histPoint("a", 30, [3]uint64{1, 0, 0}, 1), histPoint("b", 30, [3]uint64{0, 1, 1}, 550)),
+	}
+
+	merged := buildMetricSeries("latency", stored, "").Series[0]
+	if p := pointAt(t, merged, 20); !slices.Equal(p.Buckets, []uint64{1, 0, 1}) || *p.Count != 2 || *p.Sum != 501 {
+		t.Errorf("t=20: expected buckets [1 0 1], count 2, sum 501; got %v %d %v", p.Buckets, *p.Count, *p.Sum)
+	}
+	if p := pointAt(t, merged, 30); !slices.Equal(p.Buckets, []uint64{1, 0, 0}) || *p.Count != 1 {
+		t.Errorf("t=30: expected a's reset report only, got %v %d", p.Buckets, *p.Count)
+	}
+
+	split := buildMetricSeries("latency", stored, "route")
+	if len(split.Series) != 2 || split.Series[0].Label != "a" || split.Series[1].Label != "b" {
+		t.Fatalf("expected series a and b, got %+v", split.Series)
+	}
+	if p := pointAt(t, split.Series[1], 20); !slices.Equal(p.Buckets, []uint64{0, 0, 1}) || *p.Sum != 500 {
+		t.Errorf("b at t=20: expected buckets [0 0 1] and sum 500, got %v %v", p.Buckets, *p.Sum)
+	}
+
+	// Delta histograms are already per interval
+	delta := metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA
+	deltas := buildMetricSeries("latency", []*storage.StoredMetric{
+		histMetric("api", delta, histPoint("a", 10, [3]uint64{1, 0, 0}, 1)),
+		histMetric("api", delta, histPoint("a", 20, [3]uint64{1, 0, 0}, 1)),
+	}, "").Series[0]
+	if p := pointAt(t, deltas, 20); !slices.Equal(p.Buckets, []uint64{1, 0, 0}) || *p.Count != 1 {
+		t.Errorf("delta: expected the point unchanged, got %v %d", p.Buckets, *p.Count)
+	}
+}
+
+func TestBuildMetricSeriesNumbers(t *testing.T) {
+	point := func(route string, v float64) *metricspb.NumberDataPoint {
+		return &metricspb.NumberDataPoint{
+			Attributes:   []*commonpb.KeyValue{stringKV("route", route)},
+			TimeUnixNano: 10,
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: v},
+		}
+	}
+	gauge := &storage.StoredMetric{ServiceName: "api", MetricName: "mem", MetricType: storage.MetricTypeGauge, Metric: &metricspb.Metric{
+		Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{point("a", 2), point("b", 4)}}},
+	}}
+	if p := buildMetricSeries("mem", []*storage.StoredMetric{gauge}, "").Series[0].Points[0]; *p.V != 3 {
+		t.Errorf("expected gauges averaged to 3, got %v", *p.V)
+	}
+
+	sum := &storage.StoredMetric{ServiceName: "api", MetricName: "requests", MetricType: storage.MetricTypeSum, Metric: &metricspb.Metric{
+		Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			AggregationTemporality: cumulative,
+			IsMonotonic:            true,
+			DataPoints:             []*metricspb.NumberDataPoint{point("a", 2), point("b", 4)},
+		}},
+	}}
+	resp := buildMetricSeries("requests", []*storage.StoredMetric{sum}, splitAll)
+	if len(resp.Series) != 2 || resp.Series[0].Label != "route=a" || !resp.Monotonic {
+		t.Fatalf("expected one series per attribute set, got %+v", resp)
+	}
+	if p := buildMetricSeries("requests", []*storage.StoredMetric{sum}, "").Series[0].Points[0]; *p.V != 6 {
+		t.Errorf("expected sums added to 6, got %v", *p.V)
+	}
+}
+
+func TestHandleMetricSeries(t *testing.T) {
+	st, h := newTestServer(t)
+	if err := st.ReceiveMetrics(context.Background(), []*metricspb.ResourceMetrics{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringKV("service.name", "api")}},
+		ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: []*metricspb.Metric{
+			histMetric("api", cumulative, histPoint("a", 10, [3]uint64{1, 0, 0}, 1)).Metric,
+		}}},
+	}}); err != nil {
+		t.Fatalf("ReceiveMetrics failed: %v", err)
+	}
+
+	var resp metricSeriesResponse
+	if code := getJSON(t, h, "/api/metrics/series?name=latency&service=api", &resp); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(resp.Series) != 1 || resp.Series[0].Service != "api" || len(resp.Series[0].Points) != 1 {
+		t.Errorf("unexpected series: %+v", resp)
+	}
+	if code := getJSON(t, h, "/api/metrics/series", &resp); code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a name, got %d", code)
+	}
+	if code := getJSON(t, h, "/api/metrics/series?name=missing", &resp); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown metric, got %d", code)
+	}
+}
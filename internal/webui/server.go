@@ -8,8 +8,11 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf8"
 
@@ -22,7 +25,10 @@ var staticFiles embed.FS
 
 // Server serves the embedded web UI and WebSocket updates.
 type Server struct {
-	storage        *storage.ObservabilityStorage
+	storage   *storage.ObservabilityStorage
+	jaegerAPI bool // serve the Jaeger query API under JaegerPrefix
+
+	originsMu      sync.RWMutex
 	originPatterns []string // host patterns for websocket.AcceptOptions.OriginPatterns
 }
 
@@ -34,6 +40,29 @@ func New(s *storage.ObservabilityStorage, allowedOrigins []string) *Server {
 	return &Server{storage: s, originPatterns: patterns}
 }
 
+// SetAllowedOrigins replaces the allowed origin patterns, e.g. after a
+// config reload. It takes the same URI patterns as New.
+func (s *Server) SetAllowedOrigins(allowedOrigins []string) {
+	patterns := buildOriginPatterns(allowedOrigins)
+	s.originsMu.Lock()
+	s.originPatterns = patterns
+	s.originsMu.Unlock()
+}
+
+// allowedPatterns returns the current host-only origin patterns.
+func (s *Server) allowedPatterns() []string {
+	s.originsMu.RLock()
+	defer s.originsMu.RUnlock()
+	return s.originPatterns
+}
+
+// EnableJaegerAPI makes RegisterRoutes also serve the Jaeger HTTP query API
+// under JaegerPrefix, so a Jaeger UI can read the ring buffers. Call it
+// before registering routes.
+func (s *Server) EnableJaegerAPI() {
+	s.jaegerAPI = true
+}
+
 // buildOriginPatterns converts config-style origins (e.g. "http://localhost:*")
 // to host-only patterns (e.g. "localhost:*") for coder/websocket.
 // If no origins are provided, defaults to localhost-only.
@@ -70,7 +99,18 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/services", securityHeaders(s.handleServices))
 	mux.HandleFunc("GET /api/status", securityHeaders(s.handleStatus))
 	mux.HandleFunc("GET /api/query", securityHeaders(s.handleQuery))
+	mux.HandleFunc("GET /api/trace/{id}", securityHeaders(s.handleTrace))
+	mux.HandleFunc("GET /api/snapshots", securityHeaders(s.handleSnapshots))
+	mux.HandleFunc("POST /api/snapshots", securityHeaders(s.handleCreateSnapshot))
+	mux.HandleFunc("DELETE /api/snapshots/{name}", securityHeaders(s.handleDeleteSnapshot))
+	mux.HandleFunc("GET /api/flamegraph", securityHeaders(s.handleFlameGraph))
+	mux.HandleFunc("GET /api/export/chrome", securityHeaders(s.handleChromeTrace))
+	mux.HandleFunc("GET /api/metrics", securityHeaders(s.handleMetrics))
+	mux.HandleFunc("GET /api/metrics/series", securityHeaders(s.handleMetricSeries))
 	mux.HandleFunc("GET /ws", s.handleWebSocket)
+	if s.jaegerAPI {
+		s.registerJaegerRoutes(mux)
+	}
 }
 
 // ListenAndServe starts a standalone HTTP server for the web UI.
@@ -119,9 +159,20 @@ func (s *Server) handleUI(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-// handleServices returns the list of known service names.
+// handleServices returns the list of known service names, optionally only
+// those seen between start_snapshot and end_snapshot.
 func (s *Server) handleServices(w http.ResponseWriter, r *http.Request) {
-	services := s.storage.Services()
+	data, err := s.scopedData(scopeFromQuery(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if data == nil {
+		writeJSON(w, s.storage.Services())
+		return
+	}
+	services := append([]string{}, data.Summary.Services...)
+	sort.Strings(services)
 	writeJSON(w, services)
 }
 
@@ -150,12 +201,24 @@ func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
-	filter := storage.QueryFilter{
-		ServiceName: q.Get("service"),
-		LogSeverity: q.Get("severity"),
-		SpanName:    q.Get("span_name"),
-		TraceID:     q.Get("trace_id"),
-		SpanStatus:  q.Get("span_status"),
+	// ?q= takes the query language; explicit parameters override its terms
+	filter, err := storage.ParseQuery(q.Get("q"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for param, field := range map[string]*string{
+		"service":        &filter.ServiceName,
+		"severity":       &filter.LogSeverity,
+		"span_name":      &filter.SpanName,
+		"trace_id":       &filter.TraceID,
+		"span_status":    &filter.SpanStatus,
+		"start_snapshot": &filter.StartSnapshot,
+		"end_snapshot":   &filter.EndSnapshot,
+	} {
+		if v := q.Get(param); v != "" {
+			*field = v
+		}
 	}
 
 	if q.Get("errors_only") == "true" {
@@ -179,16 +242,38 @@ func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
 }
 
 // wsFilter is the client-sent filter message on the WebSocket.
+// StartSnapshot/EndSnapshot scope the stream to a snapshot range. Query
+// (see storage.ParseQuery) and Filter are evaluated server-side, so only
+// matching telemetry is sent. Changing the scope or the filter restarts the
+// stream from recent history.
 type wsFilter struct {
-	Service  string `json:"service"`
-	Severity string `json:"severity"`
-	Paused   bool   `json:"paused"`
+	Service       string               `json:"service"`
+	Severity      string               `json:"severity"`
+	Paused        bool                 `json:"paused"`
+	StartSnapshot string               `json:"start_snapshot,omitempty"`
+	EndSnapshot   string               `json:"end_snapshot,omitempty"`
+	Query         string               `json:"query,omitempty"`
+	Filter        *storage.QueryFilter `json:"filter,omitempty"`
+	Sample        int                  `json:"sample,omitempty"`   // max new traces, logs and metrics per second; 0 = all
+	MaxRate       int                  `json:"max_rate,omitempty"` // max updates per second; 0 = default
+}
+
+// wsFilterMessage is a filter read from the client, or why it couldn't be.
+type wsFilterMessage struct {
+	filter wsFilter
+	err    error
 }
 
 // wsUpdate is the server-sent update message on the WebSocket.
+// Reset tells the client to drop what it has before applying this update.
+// Dropped counts items that matched but were sampled out or over the
+// per-update cap.
 type wsUpdate struct {
+	Reset      bool              `json:"reset,omitempty"`
+	Error      string            `json:"error,omitempty"`
 	Generation uint64            `json:"generation"`
 	Counters   wsCounters        `json:"counters"`
+	Dropped    *wsCounters       `json:"dropped,omitempty"`
 	Traces     []wsSpanSummary   `json:"traces,omitempty"`
 	Logs       []wsLogSummary    `json:"logs,omitempty"`
 	Metrics    []wsMetricSummary `json:"metrics,omitempty"`
@@ -216,6 +301,7 @@ type wsSpanSummary struct {
 
 type wsLogSummary struct {
 	Time     string `json:"time"`
+	TraceID  string `json:"trace_id,omitempty"`
 	Service  string `json:"service"`
 	Severity string `json:"severity"`
 	Body     string `json:"body"`
@@ -232,13 +318,13 @@ type wsMetricSummary struct {
 // handleWebSocket upgrades to WebSocket and streams real-time updates.
 func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
-		OriginPatterns: s.originPatterns,
+		OriginPatterns: s.allowedPatterns(),
 	})
 	if err != nil {
 		return
 	}
 	defer conn.CloseNow()
-	conn.SetReadLimit(4096)
+	conn.SetReadLimit(16384)
 
 	ctx := r.Context()
 
@@ -246,17 +332,13 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 	notifyCh, unsubscribe := s.storage.ActivityCache().Subscribe()
 	defer unsubscribe()
 
-	// Track positions for delta reads — back up to include recent history on connect
-	const backfillTraces, backfillLogs, backfillMetrics = 50, 100, 50
-	lastTracePos := max(0, s.storage.Traces().CurrentPosition()-backfillTraces)
-	lastLogPos := max(0, s.storage.Logs().CurrentPosition()-backfillLogs)
-	lastMetricPos := max(0, s.storage.Metrics().CurrentPosition()-backfillMetrics)
+	// Initially unfiltered, starting from recent history
+	st := &wsStream{}
+	s.rewindStream(st)
 
-	// Current filter (initially empty = show all)
-	var filter wsFilter
-
-	// Read filter messages from client in a goroutine
-	filterCh := make(chan wsFilter, 4)
+	// Read filter messages from client in a goroutine. Each message is the
+	// whole filter, so only the latest one not yet applied is kept.
+	filterCh := make(chan wsFilterMessage, 1)
 	go func() {
 		defer close(filterCh)
 		for {
@@ -264,18 +346,29 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				return
 			}
-			var f wsFilter
-			if json.Unmarshal(data, &f) == nil {
-				select {
-				case filterCh <- f:
-				default:
-				}
+			var msg wsFilterMessage
+			if err := json.Unmarshal(data, &msg.filter); err != nil {
+				msg.err = fmt.Errorf("invalid filter message: %w", err)
 			}
+			select {
+			case <-filterCh:
+			default:
+			}
+			filterCh <- msg
 		}
 	}()
 
+	// Updates are throttled per connection: notifications arriving within
+	// the minimum interval are coalesced into one delayed flush.
+	var lastSend time.Time
+	var flushC <-chan time.Time
+	send := func(reset bool) {
+		s.sendWSUpdate(ctx, conn, st, reset)
+		lastSend = time.Now()
+	}
+
 	// Send initial status immediately
-	s.sendWSUpdate(ctx, conn, &lastTracePos, &lastLogPos, &lastMetricPos, filter)
+	send(false)
 
 	// Keepalive ticker (send status even with no data changes, so client knows we're alive)
 	keepalive := time.NewTicker(15 * time.Second)
@@ -287,39 +380,68 @@ func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
 			conn.Close(websocket.StatusNormalClosure, "server shutting down")
 			return
 
-		case f, ok := <-filterCh:
+		case msg, ok := <-filterCh:
 			if !ok {
 				// Client disconnected
 				return
 			}
-			filter = f
+			if msg.err != nil {
+				s.writeWSMessage(ctx, conn, wsUpdate{Error: msg.err.Error()})
+				continue
+			}
+			f := msg.filter
+			wasPaused := st.filter.Paused
+			restart, err := s.applyWSFilter(st, f)
+			if err != nil {
+				// A bad filter or range leaves the current stream untouched
+				s.writeWSMessage(ctx, conn, wsUpdate{Error: err.Error()})
+				continue
+			}
+			if restart {
+				s.rewindStream(st)
+				send(true)
+			} else if wasPaused && !f.Paused {
+				send(false)
+			}
 
 		case <-notifyCh:
-			if filter.Paused {
+			if st.filter.Paused || flushC != nil {
 				continue
 			}
-			s.sendWSUpdate(ctx, conn, &lastTracePos, &lastLogPos, &lastMetricPos, filter)
+			if wait := st.minInterval() - time.Since(lastSend); wait > 0 {
+				flushC = time.After(wait)
+				continue
+			}
+			send(false)
+
+		case <-flushC:
+			flushC = nil
+			if !st.filter.Paused {
+				send(false)
+			}
 
 		case <-keepalive.C:
-			if filter.Paused {
+			if st.filter.Paused {
 				continue
 			}
-			s.sendWSUpdate(ctx, conn, &lastTracePos, &lastLogPos, &lastMetricPos, filter)
+			send(false)
 		}
 	}
 }
 
 // sendWSUpdate reads deltas from ring buffers and sends a JSON update over WebSocket.
-func (s *Server) sendWSUpdate(ctx context.Context, conn *websocket.Conn,
-	lastTracePos, lastLogPos, lastMetricPos *int, filter wsFilter) {
-
+// A bounded scope stops reading at the end snapshot's positions.
+func (s *Server) sendWSUpdate(ctx context.Context, conn *websocket.Conn, st *wsStream, reset bool) {
 	ac := s.storage.ActivityCache()
 
-	curTracePos := s.storage.Traces().CurrentPosition()
-	curLogPos := s.storage.Logs().CurrentPosition()
-	curMetricPos := s.storage.Metrics().CurrentPosition()
+	curTracePos, curLogPos, curMetricPos := st.scope.clamp(
+		s.storage.Traces().CurrentPosition(),
+		s.storage.Logs().CurrentPosition(),
+		s.storage.Metrics().CurrentPosition(),
+	)
 
 	update := wsUpdate{
+		Reset:      reset,
 		Generation: ac.Generation(),
 		Counters: wsCounters{
 			Spans:   ac.SpansReceived(),
@@ -327,27 +449,31 @@ func (s *Server) sendWSUpdate(ctx context.Context, conn *websocket.Conn,
 			Metrics: ac.MetricsReceived(),
 		},
 	}
+	var dropped wsCounters
+	now := time.Now()
+	st.sampler.roll(now)
 
 	// Get trace deltas
-	if curTracePos > *lastTracePos {
-		spans := s.storage.Traces().GetRange(*lastTracePos, curTracePos-1)
-		for _, span := range spans {
-			if span.Span == nil {
-				continue
-			}
-			if filter.Service != "" && span.ServiceName != filter.Service {
-				continue
+	if curTracePos > st.tracePos {
+		spans := s.storage.Traces().GetRange(st.tracePos, curTracePos-1)
+		spans = slices.DeleteFunc(spans, func(span *storage.StoredSpan) bool { return span.Span == nil })
+		// linked: must see links from spans of the linked trace that
+		// arrived in earlier updates, not only in this one
+		var linked []*storage.StoredSpan
+		if st.match.LinkedTraceID != "" {
+			linked = s.storage.Traces().GetSpansByTraceID(st.match.LinkedTraceID)
+		}
+		spans = storage.FilterTracesLinked(spans, st.match, linked)
+		spans = slices.DeleteFunc(spans, func(span *storage.StoredSpan) bool {
+			if st.sampler.admitSpan(span.TraceID, now) {
+				return false
 			}
+			dropped.Spans++
+			return true
+		})
+		spans, dropped.Spans = keepNewest(spans, dropped.Spans)
+		for _, span := range spans {
 			durationNs := span.Span.EndTimeUnixNano - span.Span.StartTimeUnixNano
-			status := "UNSET"
-			if span.Span.Status != nil {
-				switch span.Span.Status.Code {
-				case 1:
-					status = "OK"
-				case 2:
-					status = "ERROR"
-				}
-			}
 			parentSpanID := ""
 			if len(span.Span.ParentSpanId) > 0 {
 				parentSpanID = fmt.Sprintf("%x", span.Span.ParentSpanId)
@@ -363,45 +489,53 @@ func (s *Server) sendWSUpdate(ctx context.Context, conn *websocket.Conn,
 				SpanName:     span.SpanName,
 				Kind:         kind,
 				DurationMs:   float64(durationNs) / 1e6,
-				Status:       status,
+				Status:       spanStatus(span.Span.Status),
 				StartNs:      span.Span.StartTimeUnixNano,
 				EndNs:        span.Span.EndTimeUnixNano,
 			})
 		}
-		*lastTracePos = curTracePos
+		st.tracePos = curTracePos
 	}
 
 	// Get log deltas
-	if curLogPos > *lastLogPos {
-		logs := s.storage.Logs().GetRange(*lastLogPos, curLogPos-1)
-		for _, l := range logs {
-			if filter.Service != "" && l.ServiceName != filter.Service {
-				continue
-			}
-			if filter.Severity != "" && l.Severity != filter.Severity {
-				continue
+	if curLogPos > st.logPos {
+		logs := storage.FilterLogs(s.storage.Logs().GetRange(st.logPos, curLogPos-1), st.match)
+		logs = slices.DeleteFunc(logs, func(*storage.StoredLog) bool {
+			if st.sampler.admitLog() {
+				return false
 			}
+			dropped.Logs++
+			return true
+		})
+		logs, dropped.Logs = keepNewest(logs, dropped.Logs)
+		for _, l := range logs {
 			body := l.Body
 			if utf8.RuneCountInString(body) > 500 {
 				body = string([]rune(body)[:500]) + "..."
 			}
 			update.Logs = append(update.Logs, wsLogSummary{
 				Time:     formatNanoTime(l.Timestamp),
+				TraceID:  l.TraceID,
 				Service:  l.ServiceName,
 				Severity: l.Severity,
 				Body:     body,
 			})
 		}
-		*lastLogPos = curLogPos
+		st.logPos = curLogPos
 	}
 
 	// Get metric deltas
-	if curMetricPos > *lastMetricPos {
-		metrics := s.storage.Metrics().GetRange(*lastMetricPos, curMetricPos-1)
-		for _, m := range metrics {
-			if filter.Service != "" && m.ServiceName != filter.Service {
-				continue
+	if curMetricPos > st.metricPos {
+		metrics := storage.FilterMetrics(s.storage.Metrics().GetRange(st.metricPos, curMetricPos-1), st.match)
+		metrics = slices.DeleteFunc(metrics, func(*storage.StoredMetric) bool {
+			if st.sampler.admitMetric() {
+				return false
 			}
+			dropped.Metrics++
+			return true
+		})
+		metrics, dropped.Metrics = keepNewest(metrics, dropped.Metrics)
+		for _, m := range metrics {
 			valStr := ""
 			if m.NumericValue != nil {
 				valStr = fmt.Sprintf("%.4g", *m.NumericValue)
@@ -419,9 +553,17 @@ func (s *Server) sendWSUpdate(ctx context.Context, conn *websocket.Conn,
 				Updated: formatNanoTime(m.Timestamp),
 			})
 		}
-		*lastMetricPos = curMetricPos
+		st.metricPos = curMetricPos
 	}
 
+	if dropped != (wsCounters{}) {
+		update.Dropped = &dropped
+	}
+	s.writeWSMessage(ctx, conn, update)
+}
+
+// writeWSMessage marshals and sends one update, giving up after 5s.
+func (s *Server) writeWSMessage(ctx context.Context, conn *websocket.Conn, update wsUpdate) {
 	data, err := json.Marshal(update)
 	if err != nil {
 		log.Printf("webui: failed to marshal update: %v", err)
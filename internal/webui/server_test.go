@@ -0,0 +1,105 @@
+package webui
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// newTestServer returns storage and the web UI routes over it, including
+// the Jaeger API.
+func newTestServer(t *testing.T) (*storage.ObservabilityStorage, http.Handler) {
+	t.Helper()
+	st := storage.NewObservabilityStorage(100, 100, 100)
+	srv := New(st, nil)
+	srv.EnableJaegerAPI()
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+	return st, mux
+}
+
+// serve sends req to h and returns the recorded response.
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// getJSON GETs target and decodes a 200 response into v. It returns the
+// status code.
+func getJSON(t *testing.T, h http.Handler, target string, v any) int {
+	t.Helper()
+	rec := serve(h, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+			t.Fatalf("GET %s: decode: %v", target, err)
+		}
+	} else {
+		io.Copy(io.Discard, rec.Body)
+	}
+	return rec.Code
+}
+
+// testSpan describes one span for addSpans.
+type testSpan struct {
+	service, name   string
+	traceID, spanID string // hex
+	parentID        string // hex, empty for a root span
+	startNs, durNs  uint64
+	errored         bool
+	attrs           []*commonpb.KeyValue
+	links           [][2]string // hex trace and span IDs this span links to
+}
+
+// addSpans stores each span in its own export, as its service's resource.
+func addSpans(t *testing.T, st *storage.ObservabilityStorage, spans ...testSpan) {
+	t.Helper()
+	for _, s := range spans {
+		span := &tracepb.Span{
+			TraceId:           mustHex(t, s.traceID),
+			SpanId:            mustHex(t, s.spanID),
+			Name:              s.name,
+			Kind:              tracepb.Span_SPAN_KIND_SERVER,
+			StartTimeUnixNano: s.startNs,
+			EndTimeUnixNano:   s.startNs + s.durNs,
+			Attributes:        s.attrs,
+		}
+		if s.parentID != "" {
+			span.ParentSpanId = mustHex(t, s.parentID)
+		}
+		for _, l := range s.links {
+			span.Links = append(span.Links, &tracepb.Span_Link{TraceId: mustHex(t, l[0]), SpanId: mustHex(t, l[1])})
+		}
+		if s.errored {
+			span.Status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR}
+		}
+		if err := st.ReceiveSpans(context.Background(), []*tracepb.ResourceSpans{{
+			Resource:   &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringKV("service.name", s.service)}},
+			ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{span}}},
+		}}); err != nil {
+			t.Fatalf("ReceiveSpans failed: %v", err)
+		}
+	}
+}
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+func stringKV(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
@@ -0,0 +1,235 @@
+package webui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+)
+
+// snapshotInfo is the JSON shape of one snapshot in /api/snapshots.
+type snapshotInfo struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	CreatedNs   int64     `json:"created_ns"`
+	Time        string    `json:"time"`
+	TracePos    int       `json:"trace_pos"`
+	LogPos      int       `json:"log_pos"`
+	MetricPos   int       `json:"metric_pos"`
+}
+
+// snapshotsResponse lists snapshots oldest first, plus the current buffer
+// positions and time so the UI can place "now" on its timeline.
+type snapshotsResponse struct {
+	Snapshots []snapshotInfo `json:"snapshots"`
+	NowNs     int64          `json:"now_ns"`
+	TracePos  int            `json:"trace_pos"`
+	LogPos    int            `json:"log_pos"`
+	MetricPos int            `json:"metric_pos"`
+}
+
+// handleSnapshots lists all snapshots ordered by creation time.
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	sm := s.storage.Snapshots()
+	resp := snapshotsResponse{
+		Snapshots: []snapshotInfo{},
+		NowNs:     time.Now().UnixNano(),
+		TracePos:  s.storage.Traces().CurrentPosition(),
+		LogPos:    s.storage.Logs().CurrentPosition(),
+		MetricPos: s.storage.Metrics().CurrentPosition(),
+	}
+	for _, name := range sm.List() {
+		snap, err := sm.Get(name)
+		if err != nil {
+			continue // deleted between List and Get
+		}
+		resp.Snapshots = append(resp.Snapshots, snapshotInfo{
+			Name:        snap.Name,
+			Description: snap.Description,
+			Tags:        snap.Tags,
+			CreatedAt:   snap.CreatedAt,
+			CreatedNs:   snap.CreatedAt.UnixNano(),
+			Time:        snap.CreatedAt.Format("15:04:05"),
+			TracePos:    snap.TracePos,
+			LogPos:      snap.LogPos,
+			MetricPos:   snap.MetricPos,
+		})
+	}
+	sort.Slice(resp.Snapshots, func(i, j int) bool {
+		return resp.Snapshots[i].CreatedAt.Before(resp.Snapshots[j].CreatedAt)
+	})
+	writeJSON(w, resp)
+}
+
+// handleCreateSnapshot bookmarks the current buffer positions, exactly like
+// the create_snapshot MCP tool. Body: {"name": "..."}.
+func (s *Server) handleCreateSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !s.sameOriginWrite(w, r) {
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 4096)).Decode(&req); err != nil {
+		http.Error(w, "body must be JSON like {\"name\":\"before-deploy\"}", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "snapshot name cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if err := s.storage.CreateSnapshot(req.Name); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, map[string]string{"name": req.Name})
+}
+
+// handleDeleteSnapshot removes one snapshot by name.
+func (s *Server) handleDeleteSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !s.sameOriginWrite(w, r) {
+		return
+	}
+	if err := s.storage.DeleteSnapshot(r.PathValue("name")); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sameOriginWrite rejects state-changing requests from other sites. Browsers
+// always send Origin on cross-site POST/DELETE, so a missing Origin (curl) is
+// allowed and a present one must match the host or the allowed patterns.
+func (s *Server) sameOriginWrite(w http.ResponseWriter, r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err == nil && u.Host != "" {
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, pattern := range s.allowedPatterns() {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(u.Host)); ok {
+				return true
+			}
+		}
+	}
+	http.Error(w, "origin not allowed", http.StatusForbidden)
+	return false
+}
+
+// snapshotScope is an optional [start, end) snapshot range taken from the
+// start_snapshot and end_snapshot query parameters. An empty end means now.
+type snapshotScope struct {
+	Start string
+	End   string
+}
+
+func scopeFromQuery(q url.Values) snapshotScope {
+	return snapshotScope{Start: q.Get("start_snapshot"), End: q.Get("end_snapshot")}
+}
+
+func (sc snapshotScope) active() bool { return sc.Start != "" || sc.End != "" }
+
+// errBadScope marks scope errors that should be reported as 400s.
+var errBadScope = errors.New("invalid snapshot range")
+
+// scopedData returns the telemetry inside the scope, or nil when no scope is set.
+func (s *Server) scopedData(sc snapshotScope) (*storage.SnapshotData, error) {
+	if !sc.active() {
+		return nil, nil
+	}
+	if sc.Start == "" {
+		return nil, fmt.Errorf("%w: end_snapshot requires start_snapshot", errBadScope)
+	}
+	data, err := s.storage.GetSnapshotData(sc.Start, sc.End)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errBadScope, err)
+	}
+	return data, nil
+}
+
+// scopedMetrics returns the stored metrics inside the request's snapshot
+// scope, or the whole buffer when none is given.
+func (s *Server) scopedMetrics(w http.ResponseWriter, r *http.Request) ([]*storage.StoredMetric, bool) {
+	data, err := s.scopedData(scopeFromQuery(r.URL.Query()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if data == nil {
+		return s.storage.Metrics().GetAllMetrics(), true
+	}
+	return data.Metrics, true
+}
+
+// wsScope holds the buffer positions bounding a WebSocket stream. Without
+// an end snapshot the stream is unbounded and keeps delivering new data.
+type wsScope struct {
+	traceStart, logStart, metricStart int
+	traceEnd, logEnd, metricEnd       int
+	bounded                           bool
+}
+
+// Scoped streams start with at most this much of the range, newest last.
+const scopeBackfillTraces, scopeBackfillLogs, scopeBackfillMetrics = 2000, 2000, 1000
+
+// startPositions returns where a freshly scoped stream should begin reading.
+func (sc *wsScope) startPositions(curTrace, curLog, curMetric int) (int, int, int) {
+	if sc.bounded {
+		curTrace, curLog, curMetric = sc.traceEnd, sc.logEnd, sc.metricEnd
+	}
+	return max(sc.traceStart, curTrace-scopeBackfillTraces),
+		max(sc.logStart, curLog-scopeBackfillLogs),
+		max(sc.metricStart, curMetric-scopeBackfillMetrics)
+}
+
+// clamp limits current buffer positions to the scope's end.
+func (sc *wsScope) clamp(curTrace, curLog, curMetric int) (int, int, int) {
+	if sc == nil || !sc.bounded {
+		return curTrace, curLog, curMetric
+	}
+	return min(curTrace, sc.traceEnd), min(curLog, sc.logEnd), min(curMetric, sc.metricEnd)
+}
+
+// resolveWSScope turns snapshot names into buffer positions for the stream.
+func (s *Server) resolveWSScope(sc snapshotScope) (*wsScope, error) {
+	if !sc.active() {
+		return nil, nil
+	}
+	if sc.Start == "" {
+		return nil, fmt.Errorf("%w: end_snapshot requires start_snapshot", errBadScope)
+	}
+	sm := s.storage.Snapshots()
+	start, err := sm.Get(sc.Start)
+	if err != nil {
+		return nil, err
+	}
+	scope := &wsScope{traceStart: start.TracePos, logStart: start.LogPos, metricStart: start.MetricPos}
+	if sc.End != "" {
+		end, err := sm.Get(sc.End)
+		if err != nil {
+			return nil, err
+		}
+		if end.CreatedAt.Before(start.CreatedAt) {
+			return nil, fmt.Errorf("%w: end snapshot is before start snapshot", errBadScope)
+		}
+		scope.traceEnd, scope.logEnd, scope.metricEnd = end.TracePos, end.LogPos, end.MetricPos
+		scope.bounded = true
+	}
+	return scope, nil
+}
@@ -0,0 +1,113 @@
+package webui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// snapshotRequest builds a POST creating name, or a DELETE of it, with an
+// optional Origin header. httptest requests have Host example.com.
+func snapshotRequest(method, name, origin string) *http.Request {
+	var req *http.Request
+	if method == http.MethodPost {
+		req = httptest.NewRequest(method, "/api/snapshots", strings.NewReader(`{"name":"`+name+`"}`))
+	} else {
+		req = httptest.NewRequest(method, "/api/snapshots/"+name, nil)
+	}
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	return req
+}
+
+func TestSnapshotWritesCheckOrigin(t *testing.T) {
+	st, h := newTestServer(t)
+
+	for _, origin := range []string{"http://evil.example", "null", "http://example.com.evil.example"} {
+		if rec := serve(h, snapshotRequest(http.MethodPost, "cross", origin)); rec.Code != http.StatusForbidden {
+			t.Errorf("POST from %q: expected 403, got %d", origin, rec.Code)
+		}
+	}
+	if _, err := st.Snapshots().Get("cross"); err == nil {
+		t.Error("cross-origin POST should not create a snapshot")
+	}
+
+	// Same origin, an allowed origin (localhost by default) and no Origin (curl)
+	for name, origin := range map[string]string{
+		"same":      "http://example.com",
+		"localhost": "http://localhost:5173",
+		"curl":      "",
+	} {
+		if rec := serve(h, snapshotRequest(http.MethodPost, name, origin)); rec.Code != http.StatusCreated {
+			t.Errorf("POST %s: expected 201, got %d: %s", name, rec.Code, rec.Body)
+		}
+		if _, err := st.Snapshots().Get(name); err != nil {
+			t.Errorf("POST %s: snapshot not created: %v", name, err)
+		}
+	}
+
+	if rec := serve(h, snapshotRequest(http.MethodDelete, "same", "http://evil.example")); rec.Code != http.StatusForbidden {
+		t.Errorf("cross-origin DELETE: expected 403, got %d", rec.Code)
+	}
+	if _, err := st.Snapshots().Get("same"); err != nil {
+		t.Error("cross-origin DELETE should not delete the snapshot")
+	}
+	if rec := serve(h, snapshotRequest(http.MethodDelete, "same", "http://example.com")); rec.Code != http.StatusNoContent {
+		t.Errorf("same-origin DELETE: expected 204, got %d", rec.Code)
+	}
+	if rec := serve(h, snapshotRequest(http.MethodDelete, "curl", "")); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE without Origin: expected 204, got %d", rec.Code)
+	}
+	if rec := serve(h, snapshotRequest(http.MethodDelete, "curl", "")); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE of a missing snapshot: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateSnapshotErrors(t *testing.T) {
+	_, h := newTestServer(t)
+
+	if rec := serve(h, snapshotRequest(http.MethodPost, "deploy", "")); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	if rec := serve(h, snapshotRequest(http.MethodPost, "deploy", "")); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate name: expected 409, got %d", rec.Code)
+	}
+	if rec := serve(h, snapshotRequest(http.MethodPost, "  ", "")); rec.Code != http.StatusBadRequest {
+		t.Errorf("blank name: expected 400, got %d", rec.Code)
+	}
+	bad := httptest.NewRequest(http.MethodPost, "/api/snapshots", strings.NewReader("deploy"))
+	if rec := serve(h, bad); rec.Code != http.StatusBadRequest {
+		t.Errorf("non-JSON body: expected 400, got %d", rec.Code)
+	}
+
+	var resp snapshotsResponse
+	if code := getJSON(t, h, "/api/snapshots", &resp); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(resp.Snapshots) != 1 || resp.Snapshots[0].Name != "deploy" {
+		t.Errorf("expected only the deploy snapshot, got %+v", resp.Snapshots)
+	}
+}
+
+func TestSnapshotScopeErrors(t *testing.T) {
+	st, h := newTestServer(t)
+	if err := st.CreateSnapshot("start"); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	var out any
+	for _, target := range []string{
+		"/api/services?end_snapshot=start",
+		"/api/metrics?end_snapshot=start",
+		"/api/services?start_snapshot=missing",
+	} {
+		if code := getJSON(t, h, target, &out); code != http.StatusBadRequest {
+			t.Errorf("GET %s: expected 400, got %d", target, code)
+		}
+	}
+	if code := getJSON(t, h, "/api/services?start_snapshot=start", &out); code != http.StatusOK {
+		t.Errorf("expected 200 for a valid range, got %d", code)
+	}
+}
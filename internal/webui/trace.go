@@ -0,0 +1,255 @@
+package webui
+
+import (
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// traceDetail is the JSON shape for /api/trace/{id}.
+type traceDetail struct {
+	TraceID    string        `json:"trace_id"`
+	StartNs    uint64        `json:"start_ns"`
+	EndNs      uint64        `json:"end_ns"`
+	DurationMs float64       `json:"duration_ms"`
+	Services   []string      `json:"services"`
+	ErrorCount int           `json:"error_count"`
+	Spans      []spanDetail  `json:"spans"`
+	Logs       []traceLogRow `json:"logs"`
+
+	// Span links to and from other traces, followed in both directions
+	LinkedTraces []traceLink `json:"linked_traces"`
+}
+
+// traceLink is one cross-trace span link. From carries the link (usually a
+// consumer), To is the span it points at (usually the producer). Names are
+// empty when that span is not in the buffer.
+type traceLink struct {
+	FromTraceID string `json:"from_trace_id"`
+	FromSpanID  string `json:"from_span_id"`
+	FromService string `json:"from_service,omitempty"`
+	FromSpan    string `json:"from_span,omitempty"`
+	ToTraceID   string `json:"to_trace_id"`
+	ToSpanID    string `json:"to_span_id"`
+	ToService   string `json:"to_service,omitempty"`
+	ToSpan      string `json:"to_span,omitempty"`
+	Depth       int    `json:"depth"`
+}
+
+type spanDetail struct {
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Service       string         `json:"service"`
+	SpanName      string         `json:"span_name"`
+	Kind          string         `json:"kind"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	StartNs       uint64         `json:"start_ns"`
+	EndNs         uint64         `json:"end_ns"`
+	DurationMs    float64        `json:"duration_ms"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Resource      map[string]any `json:"resource,omitempty"`
+	Scope         string         `json:"scope,omitempty"`
+	ScopeVersion  string         `json:"scope_version,omitempty"`
+	Events        []spanEvent    `json:"events,omitempty"`
+	Links         []spanLink     `json:"links,omitempty"`
+}
+
+type spanEvent struct {
+	Name       string         `json:"name"`
+	TimeNs     uint64         `json:"time_ns"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+type spanLink struct {
+	TraceID    string         `json:"trace_id"`
+	SpanID     string         `json:"span_id"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+type traceLogRow struct {
+	Time       string         `json:"time"`
+	TimeNs     uint64         `json:"time_ns"`
+	SpanID     string         `json:"span_id,omitempty"`
+	Service    string         `json:"service"`
+	Severity   string         `json:"severity"`
+	Body       string         `json:"body"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// handleTrace returns every stored span of one trace with full attributes,
+// events and links, plus the log records carrying the same trace ID.
+func (s *Server) handleTrace(w http.ResponseWriter, r *http.Request) {
+	traceID := strings.ToLower(r.PathValue("id"))
+	if len(traceID) != 32 {
+		http.Error(w, "trace ID must be 32 hex characters", http.StatusBadRequest)
+		return
+	}
+	if _, err := hex.DecodeString(traceID); err != nil {
+		http.Error(w, "trace ID must be 32 hex characters", http.StatusBadRequest)
+		return
+	}
+
+	spans := s.storage.Traces().GetSpansByTraceID(traceID)
+	logs := s.storage.Logs().GetLogsByTraceID(traceID)
+	if len(spans) == 0 && len(logs) == 0 {
+		http.Error(w, "trace not found (it may have been evicted from the buffer)", http.StatusNotFound)
+		return
+	}
+
+	detail := buildTraceDetail(traceID, spans, logs)
+	detail.LinkedTraces = followTraceLinks(s.storage.Traces().GetAllSpans(), traceID)
+	writeJSON(w, detail)
+}
+
+// followTraceLinks returns the span links reachable from traceID, with the
+// service and span names of both ends where they are stored.
+func followTraceLinks(all []*storage.StoredSpan, traceID string) []traceLink {
+	links := storage.FollowLinks(all, traceID, 0)
+	result := make([]traceLink, 0, len(links))
+	if len(links) == 0 {
+		return result
+	}
+
+	byID := make(map[storage.SpanLink]*storage.StoredSpan, len(all))
+	for _, ss := range all {
+		byID[storage.SpanLink{TraceID: ss.TraceID, SpanID: ss.SpanID}] = ss
+	}
+	for _, l := range links {
+		tl := traceLink{
+			FromTraceID: l.FromTraceID,
+			FromSpanID:  l.FromSpanID,
+			ToTraceID:   l.ToTraceID,
+			ToSpanID:    l.ToSpanID,
+			Depth:       l.Depth,
+		}
+		if ss := byID[storage.SpanLink{TraceID: l.FromTraceID, SpanID: l.FromSpanID}]; ss != nil {
+			tl.FromService, tl.FromSpan = ss.ServiceName, ss.SpanName
+		}
+		if ss := byID[storage.SpanLink{TraceID: l.ToTraceID, SpanID: l.ToSpanID}]; ss != nil {
+			tl.ToService, tl.ToSpan = ss.ServiceName, ss.SpanName
+		}
+		result = append(result, tl)
+	}
+	return result
+}
+
+// buildTraceDetail converts stored spans and logs into the detail response,
+// with spans ordered by start time and logs by timestamp.
+func buildTraceDetail(traceID string, spans []*storage.StoredSpan, logs []*storage.StoredLog) traceDetail {
+	detail := traceDetail{
+		TraceID:  traceID,
+		Spans:    make([]spanDetail, 0, len(spans)),
+		Logs:     make([]traceLogRow, 0, len(logs)),
+		Services: []string{},
+
+		LinkedTraces: []traceLink{},
+	}
+
+	for _, ss := range spans {
+		if ss.Span == nil {
+			continue
+		}
+		sp := ss.Span
+		d := spanDetail{
+			SpanID:     ss.SpanID,
+			Service:    ss.ServiceName,
+			SpanName:   ss.SpanName,
+			Kind:       strings.TrimPrefix(sp.Kind.String(), "SPAN_KIND_"),
+			Status:     spanStatus(sp.Status),
+			StartNs:    sp.StartTimeUnixNano,
+			EndNs:      sp.EndTimeUnixNano,
+			Attributes: storage.AttributeMap(sp.Attributes),
+		}
+		if sp.EndTimeUnixNano > sp.StartTimeUnixNano {
+			d.DurationMs = float64(sp.EndTimeUnixNano-sp.StartTimeUnixNano) / 1e6
+		}
+		if len(sp.ParentSpanId) > 0 {
+			d.ParentSpanID = hex.EncodeToString(sp.ParentSpanId)
+		}
+		if sp.Status != nil {
+			d.StatusMessage = sp.Status.Message
+		}
+		if ss.ResourceSpan != nil && ss.ResourceSpan.Resource != nil {
+			d.Resource = storage.AttributeMap(ss.ResourceSpan.Resource.Attributes)
+		}
+		if ss.ScopeSpan != nil && ss.ScopeSpan.Scope != nil {
+			d.Scope = ss.ScopeSpan.Scope.Name
+			d.ScopeVersion = ss.ScopeSpan.Scope.Version
+		}
+		for _, ev := range sp.Events {
+			d.Events = append(d.Events, spanEvent{
+				Name:       ev.Name,
+				TimeNs:     ev.TimeUnixNano,
+				Attributes: storage.AttributeMap(ev.Attributes),
+			})
+		}
+		for _, l := range sp.Links {
+			d.Links = append(d.Links, spanLink{
+				TraceID:    hex.EncodeToString(l.TraceId),
+				SpanID:     hex.EncodeToString(l.SpanId),
+				Attributes: storage.AttributeMap(l.Attributes),
+			})
+		}
+
+		if d.Status == "ERROR" {
+			detail.ErrorCount++
+		}
+		if d.StartNs > 0 && (detail.StartNs == 0 || d.StartNs < detail.StartNs) {
+			detail.StartNs = d.StartNs
+		}
+		detail.EndNs = max(detail.EndNs, d.EndNs)
+		detail.Spans = append(detail.Spans, d)
+	}
+	sort.SliceStable(detail.Spans, func(i, j int) bool {
+		return detail.Spans[i].StartNs < detail.Spans[j].StartNs
+	})
+
+	// Services in order of first appearance, so the entry service comes first
+	seen := make(map[string]bool)
+	for _, d := range detail.Spans {
+		if !seen[d.Service] {
+			seen[d.Service] = true
+			detail.Services = append(detail.Services, d.Service)
+		}
+	}
+	if detail.EndNs > detail.StartNs {
+		detail.DurationMs = float64(detail.EndNs-detail.StartNs) / 1e6
+	}
+
+	for _, l := range logs {
+		row := traceLogRow{
+			Time:     formatNanoTime(l.Timestamp),
+			TimeNs:   l.Timestamp,
+			SpanID:   l.SpanID,
+			Service:  l.ServiceName,
+			Severity: l.Severity,
+			Body:     l.Body,
+		}
+		if l.LogRecord != nil {
+			row.Attributes = storage.AttributeMap(l.LogRecord.Attributes)
+		}
+		detail.Logs = append(detail.Logs, row)
+	}
+	sort.SliceStable(detail.Logs, func(i, j int) bool {
+		return detail.Logs[i].TimeNs < detail.Logs[j].TimeNs
+	})
+
+	return detail
+}
+
+// spanStatus maps an OTLP status to the UNSET/OK/ERROR strings the UI uses.
+func spanStatus(st *tracepb.Status) string {
+	switch st.GetCode() {
+	case tracepb.Status_STATUS_CODE_OK:
+		return "OK"
+	case tracepb.Status_STATUS_CODE_ERROR:
+		return "ERROR"
+	default:
+		return "UNSET"
+	}
+}
@@ -0,0 +1,63 @@
+package webui
+
+import (
+	"net/http"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+)
+
+const (
+	traceOne = "0102030405060708090a0b0c0d0e0f10"
+	traceTwo = "1112131415161718191a1b1c1d1e1f20"
+)
+
+func TestHandleTrace(t *testing.T) {
+	st, h := newTestServer(t)
+	nested := &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+		Values: []*commonpb.KeyValue{stringKV("region", "eu")},
+	}}}
+	addSpans(t, st,
+		testSpan{service: "cart", name: "db", traceID: traceOne, spanID: "0000000000000002", parentID: "0000000000000001", startNs: 2000, durNs: 500, errored: true},
+		testSpan{service: "frontend", name: "GET /", traceID: traceOne, spanID: "0000000000000001", startNs: 1000, durNs: 2000_000,
+			attrs: []*commonpb.KeyValue{
+				stringKV("http.method", "GET"),
+				{Key: "id", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte{0xab, 0xcd}}}},
+				{Key: "placement", Value: nested},
+			}},
+		testSpan{service: "other", name: "unrelated", traceID: traceTwo, spanID: "0000000000000003", startNs: 1000, durNs: 1},
+	)
+
+	var detail traceDetail
+	if code := getJSON(t, h, "/api/trace/"+traceOne, &detail); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if detail.TraceID != traceOne || len(detail.Spans) != 2 || detail.ErrorCount != 1 {
+		t.Fatalf("unexpected trace: %+v", detail)
+	}
+	// Spans by start time, so the entry service comes first
+	if detail.Spans[0].SpanName != "GET /" || detail.Services[0] != "frontend" {
+		t.Errorf("expected the root span first, got %q and services %v", detail.Spans[0].SpanName, detail.Services)
+	}
+	if detail.Spans[1].ParentSpanID != "0000000000000001" || detail.Spans[1].Status != "ERROR" {
+		t.Errorf("unexpected child span: %+v", detail.Spans[1])
+	}
+	attrs := detail.Spans[0].Attributes
+	if attrs["http.method"] != "GET" || attrs["id"] != "abcd" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+	if placement, ok := attrs["placement"].(map[string]any); !ok || placement["region"] != "eu" {
+		t.Errorf("expected a nested attribute map, got %v", attrs["placement"])
+	}
+	if detail.Logs == nil || detail.LinkedTraces == nil {
+		t.Error("expected empty logs and linked traces as [], not null")
+	}
+
+	// Unknown, evicted and malformed IDs
+	if code := getJSON(t, h, "/api/trace/ffffffffffffffffffffffffffffffff", &detail); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown trace, got %d", code)
+	}
+	if code := getJSON(t, h, "/api/trace/xyz", &detail); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a malformed ID, got %d", code)
+	}
+}
@@ -0,0 +1,247 @@
+package webui
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/tobert/otlp-mcp/internal/storage"
+)
+
+// Unscoped streams start this far back so a new page isn't empty.
+const backfillTraces, backfillLogs, backfillMetrics = 50, 100, 50
+
+// maxWSItems caps each signal per update; older matches beyond it are dropped
+// so a burst (or a large snapshot range) can't flood the browser.
+const maxWSItems = 2000
+
+// Update rate limits, in updates per second.
+const (
+	defaultWSRate = 4
+	maxWSRate     = 20
+)
+
+// wsStream is the per-connection stream state: read positions plus the
+// compiled filter, snapshot scope and sampler.
+type wsStream struct {
+	tracePos, logPos, metricPos int
+
+	filter  wsFilter
+	match   storage.QueryFilter
+	scope   *wsScope
+	sampler *wsSampler
+}
+
+// minInterval is the shortest gap between two updates on this stream.
+func (st *wsStream) minInterval() time.Duration {
+	rate := st.filter.MaxRate
+	if rate <= 0 {
+		rate = defaultWSRate
+	}
+	return time.Second / time.Duration(min(rate, maxWSRate))
+}
+
+// compileWSFilter merges the query string, structured filter and the legacy
+// service/severity fields into one QueryFilter. Snapshot terms in the query
+// are lifted onto the filter's scope fields; the limit is ignored.
+func compileWSFilter(f *wsFilter) (storage.QueryFilter, error) {
+	var match storage.QueryFilter
+	if f.Filter != nil {
+		match = *f.Filter
+	}
+	if f.Query != "" {
+		parsed, err := storage.ParseQuery(f.Query)
+		if err != nil {
+			return match, err
+		}
+		mergeQueryFilter(&match, parsed)
+	}
+	if match.ServiceName == "" {
+		match.ServiceName = f.Service
+	}
+	if match.LogSeverity == "" {
+		match.LogSeverity = f.Severity
+	}
+
+	if f.StartSnapshot == "" && f.EndSnapshot == "" {
+		f.StartSnapshot, f.EndSnapshot = match.StartSnapshot, match.EndSnapshot
+	}
+	match.StartSnapshot, match.EndSnapshot, match.Limit = "", "", 0
+	return match, nil
+}
+
+// mergeQueryFilter copies every field set in src onto dst.
+func mergeQueryFilter(dst *storage.QueryFilter, src storage.QueryFilter) {
+	if src.ServiceName != "" {
+		dst.ServiceName = src.ServiceName
+	}
+	if src.TraceID != "" {
+		dst.TraceID = src.TraceID
+	}
+	if src.SpanName != "" {
+		dst.SpanName = src.SpanName
+	}
+	if src.LogSeverity != "" {
+		dst.LogSeverity = src.LogSeverity
+	}
+	if len(src.MetricNames) > 0 {
+		dst.MetricNames = src.MetricNames
+	}
+	if src.StartSnapshot != "" {
+		dst.StartSnapshot = src.StartSnapshot
+	}
+	if src.EndSnapshot != "" {
+		dst.EndSnapshot = src.EndSnapshot
+	}
+	if src.Limit != 0 {
+		dst.Limit = src.Limit
+	}
+	if src.ErrorsOnly {
+		dst.ErrorsOnly = true
+	}
+	if src.SpanStatus != "" {
+		dst.SpanStatus = src.SpanStatus
+	}
+	if src.MinDurationNs != nil {
+		dst.MinDurationNs = src.MinDurationNs
+	}
+	if src.MaxDurationNs != nil {
+		dst.MaxDurationNs = src.MaxDurationNs
+	}
+	if src.HasAttribute != "" {
+		dst.HasAttribute = src.HasAttribute
+	}
+	if len(src.AttributeEquals) > 0 {
+		dst.AttributeEquals = src.AttributeEquals
+	}
+	if src.EventName != "" {
+		dst.EventName = src.EventName
+	}
+	if src.ExceptionType != "" {
+		dst.ExceptionType = src.ExceptionType
+	}
+	if src.LinkedTraceID != "" {
+		dst.LinkedTraceID = src.LinkedTraceID
+	}
+}
+
+// applyWSFilter installs a new client filter. It reports whether the stream
+// must restart because the scope, match criteria or sampling changed; on
+// error the stream is left as it was.
+func (s *Server) applyWSFilter(st *wsStream, f wsFilter) (bool, error) {
+	match, err := compileWSFilter(&f)
+	if err != nil {
+		return false, err
+	}
+
+	scopeChanged := f.StartSnapshot != st.filter.StartSnapshot || f.EndSnapshot != st.filter.EndSnapshot
+	scope := st.scope
+	if scopeChanged {
+		if scope, err = s.resolveWSScope(snapshotScope{Start: f.StartSnapshot, End: f.EndSnapshot}); err != nil {
+			return false, err
+		}
+	}
+
+	restart := scopeChanged || f.Sample != st.filter.Sample || !reflect.DeepEqual(match, st.match)
+	st.filter, st.match, st.scope = f, match, scope
+	if restart {
+		st.sampler = newWSSampler(f.Sample)
+	}
+	return restart, nil
+}
+
+// rewindStream moves the read positions back to the start of the scope, or
+// to recent history when unscoped.
+func (s *Server) rewindStream(st *wsStream) {
+	curTrace := s.storage.Traces().CurrentPosition()
+	curLog := s.storage.Logs().CurrentPosition()
+	curMetric := s.storage.Metrics().CurrentPosition()
+	if st.scope != nil {
+		st.tracePos, st.logPos, st.metricPos = st.scope.startPositions(curTrace, curLog, curMetric)
+		return
+	}
+	st.tracePos = max(0, curTrace-backfillTraces)
+	st.logPos = max(0, curLog-backfillLogs)
+	st.metricPos = max(0, curMetric-backfillMetrics)
+}
+
+// wsSampler admits at most perSecond new traces, logs and metrics per
+// one-second window. Sampling is per trace: once a trace is admitted its
+// later spans always pass, so sampled traces stay complete. A nil sampler
+// admits everything.
+type wsSampler struct {
+	perSecond             int
+	window                time.Time
+	traces, logs, metrics int
+	admitted              map[string]time.Time // trace ID -> last span seen
+}
+
+// admittedTraceTTL is how long an admitted trace keeps passing without new spans.
+const admittedTraceTTL = time.Minute
+
+func newWSSampler(perSecond int) *wsSampler {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &wsSampler{perSecond: perSecond, admitted: make(map[string]time.Time)}
+}
+
+// roll starts a new window once a second has passed since the last one.
+func (sm *wsSampler) roll(now time.Time) {
+	if sm == nil || now.Sub(sm.window) < time.Second {
+		return
+	}
+	sm.window = now
+	sm.traces, sm.logs, sm.metrics = 0, 0, 0
+	for id, seen := range sm.admitted {
+		if now.Sub(seen) > admittedTraceTTL {
+			delete(sm.admitted, id)
+		}
+	}
+}
+
+func (sm *wsSampler) admitSpan(traceID string, now time.Time) bool {
+	if sm == nil {
+		return true
+	}
+	if _, ok := sm.admitted[traceID]; ok {
+		sm.admitted[traceID] = now
+		return true
+	}
+	if sm.traces >= sm.perSecond {
+		return false
+	}
+	sm.traces++
+	sm.admitted[traceID] = now
+	return true
+}
+
+func (sm *wsSampler) admitLog() bool {
+	if sm == nil {
+		return true
+	}
+	if sm.logs >= sm.perSecond {
+		return false
+	}
+	sm.logs++
+	return true
+}
+
+func (sm *wsSampler) admitMetric() bool {
+	if sm == nil {
+		return true
+	}
+	if sm.metrics >= sm.perSecond {
+		return false
+	}
+	sm.metrics++
+	return true
+}
+
+// keepNewest trims items to the last maxWSItems, adding the overflow to dropped.
+func keepNewest[T any](items []T, dropped uint64) ([]T, uint64) {
+	if len(items) <= maxWSItems {
+		return items, dropped
+	}
+	over := len(items) - maxWSItems
+	return items[over:], dropped + uint64(over)
+}
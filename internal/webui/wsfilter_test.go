@@ -0,0 +1,235 @@
+package webui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/tobert/otlp-mcp/internal/storage"
+)
+
+func TestMergeQueryFilter(t *testing.T) {
+	minDur := uint64(5)
+	dst := storage.QueryFilter{
+		ServiceName:     "api",
+		LogSeverity:     "WARN",
+		MetricNames:     []string{"requests"},
+		AttributeEquals: map[string]string{"env": "prod"},
+	}
+	mergeQueryFilter(&dst, storage.QueryFilter{
+		ServiceName:   "worker",
+		ErrorsOnly:    true,
+		MinDurationNs: &minDur,
+		EventName:     "retry",
+	})
+
+	if dst.ServiceName != "worker" || !dst.ErrorsOnly || dst.EventName != "retry" {
+		t.Errorf("expected set fields to override, got %+v", dst)
+	}
+	if dst.MinDurationNs == nil || *dst.MinDurationNs != 5 {
+		t.Errorf("expected the min duration pointer to be copied, got %v", dst.MinDurationNs)
+	}
+	// Zero fields in src keep dst's values, including slices and maps
+	if dst.LogSeverity != "WARN" || len(dst.MetricNames) != 1 || dst.AttributeEquals["env"] != "prod" {
+		t.Errorf("expected unset fields to be kept, got %+v", dst)
+	}
+}
+
+func TestCompileWSFilter(t *testing.T) {
+	f := wsFilter{
+		Service:  "legacy",
+		Severity: "ERROR",
+		Query:    "svc:api from:before to:after limit:5",
+		Filter:   &storage.QueryFilter{ServiceName: "structured", SpanName: "GET /"},
+	}
+	match, err := compileWSFilter(&f)
+	if err != nil {
+		t.Fatalf("compileWSFilter failed: %v", err)
+	}
+	if match.ServiceName != "api" || match.SpanName != "GET /" {
+		t.Errorf("expected the query to override the structured filter, got %+v", match)
+	}
+	if match.LogSeverity != "ERROR" {
+		t.Errorf("expected the legacy severity as a fallback, got %q", match.LogSeverity)
+	}
+	if f.StartSnapshot != "before" || f.EndSnapshot != "after" {
+		t.Errorf("expected snapshot terms on the scope, got %q..%q", f.StartSnapshot, f.EndSnapshot)
+	}
+	if match.StartSnapshot != "" || match.EndSnapshot != "" || match.Limit != 0 {
+		t.Errorf("expected snapshots and limit cleared from the match, got %+v", match)
+	}
+
+	// An explicit scope wins over the query's snapshot terms
+	f = wsFilter{Query: "from:before", StartSnapshot: "explicit"}
+	if _, err := compileWSFilter(&f); err != nil {
+		t.Fatalf("compileWSFilter failed: %v", err)
+	}
+	if f.StartSnapshot != "explicit" {
+		t.Errorf("expected the explicit scope to be kept, got %q", f.StartSnapshot)
+	}
+
+	if _, err := compileWSFilter(&wsFilter{Query: "bogus:1"}); err == nil {
+		t.Error("expected an error for an unknown query key")
+	}
+}
+
+func TestWSStreamMinInterval(t *testing.T) {
+	tests := []struct {
+		rate int
+		want time.Duration
+	}{
+		{0, time.Second / defaultWSRate},
+		{10, 100 * time.Millisecond},
+		{1000, time.Second / maxWSRate},
+	}
+	for _, tt := range tests {
+		st := &wsStream{filter: wsFilter{MaxRate: tt.rate}}
+		if got := st.minInterval(); got != tt.want {
+			t.Errorf("max_rate %d: expected %v, got %v", tt.rate, tt.want, got)
+		}
+	}
+}
+
+func TestWSSampler(t *testing.T) {
+	var none *wsSampler
+	if newWSSampler(0) != nil {
+		t.Fatal("expected no sampler when sampling is off")
+	}
+	if !none.admitSpan("a", time.Now()) || !none.admitLog() || !none.admitMetric() {
+		t.Error("expected a nil sampler to admit everything")
+	}
+
+	sm := newWSSampler(2)
+	now := time.Now()
+	sm.roll(now)
+	if !sm.admitSpan("a", now) || !sm.admitSpan("b", now) {
+		t.Fatal("expected the first two traces to be admitted")
+	}
+	if sm.admitSpan("c", now) {
+		t.Error("expected a third trace in the same second to be dropped")
+	}
+	if !sm.admitSpan("a", now) {
+		t.Error("expected later spans of an admitted trace to pass")
+	}
+	if !sm.admitLog() || !sm.admitLog() || sm.admitLog() {
+		t.Error("expected two logs per second")
+	}
+	if !sm.admitMetric() || !sm.admitMetric() || sm.admitMetric() {
+		t.Error("expected two metrics per second")
+	}
+
+	// A new window resets the counters
+	now = now.Add(time.Second)
+	sm.roll(now)
+	if !sm.admitSpan("c", now) || !sm.admitLog() || !sm.admitMetric() {
+		t.Error("expected new admissions after the window rolled")
+	}
+
+	// Traces without spans for longer than the TTL are forgotten
+	now = now.Add(admittedTraceTTL / 2)
+	sm.admitSpan("a", now)
+	now = now.Add(admittedTraceTTL/2 + time.Second)
+	sm.roll(now)
+	if _, ok := sm.admitted["b"]; ok {
+		t.Error("expected an idle trace to expire")
+	}
+	if _, ok := sm.admitted["a"]; !ok {
+		t.Error("expected a recently seen trace to stay admitted")
+	}
+}
+
+func TestKeepNewest(t *testing.T) {
+	items := make([]int, maxWSItems+3)
+	for i := range items {
+		items[i] = i
+	}
+	kept, dropped := keepNewest(items, 2)
+	if len(kept) != maxWSItems || kept[0] != 3 || dropped != 5 {
+		t.Errorf("expected the newest %d items and 5 dropped, got %d from %d, %d dropped",
+			maxWSItems, len(kept), kept[0], dropped)
+	}
+	if kept, dropped := keepNewest(items[:10], 0); len(kept) != 10 || dropped != 0 {
+		t.Errorf("expected short input unchanged, got %d, %d dropped", len(kept), dropped)
+	}
+}
+
+// readWSUpdate reads the next update from conn.
+func readWSUpdate(t *testing.T, ctx context.Context, conn *websocket.Conn) wsUpdate {
+	t.Helper()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var update wsUpdate
+	if err := json.Unmarshal(data, &update); err != nil {
+		t.Fatalf("decode update: %v", err)
+	}
+	return update
+}
+
+func TestWebSocketLinkedStream(t *testing.T) {
+	st, h := newTestServer(t)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	// The linking span is in the buffer before its target arrives
+	const targetSpan = "2222222222222222"
+	addSpans(t, st, testSpan{service: "consumer", name: "consume", traceID: traceOne, spanID: "1111111111111111",
+		startNs: 1_000, durNs: 10, links: [][2]string{{traceTwo, targetSpan}}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	readWSUpdate(t, ctx, conn) // initial status
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte(`{"query":"linked:`+traceOne+`"}`)); err != nil {
+		t.Fatalf("write filter: %v", err)
+	}
+	if update := readWSUpdate(t, ctx, conn); !update.Reset || len(update.Traces) != 0 {
+		t.Fatalf("expected an empty reset, got reset=%v with %d spans", update.Reset, len(update.Traces))
+	}
+
+	addSpans(t, st,
+		testSpan{service: "producer", name: "publish", traceID: traceTwo, spanID: targetSpan, startNs: 500, durNs: 10},
+		testSpan{service: "producer", name: "unrelated", traceID: traceTwo, spanID: "3333333333333333", startNs: 600, durNs: 10})
+	for {
+		update := readWSUpdate(t, ctx, conn)
+		if len(update.Traces) == 0 {
+			continue
+		}
+		if len(update.Traces) != 1 || update.Traces[0].SpanID != targetSpan {
+			t.Fatalf("expected only the link target, got %+v", update.Traces)
+		}
+		return
+	}
+}
+
+func TestWebSocketInvalidFilter(t *testing.T) {
+	_, h := newTestServer(t)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	readWSUpdate(t, ctx, conn) // initial status
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte(`{"query":`)); err != nil {
+		t.Fatalf("write filter: %v", err)
+	}
+	if update := readWSUpdate(t, ctx, conn); update.Error == "" || update.Reset {
+		t.Errorf("expected an error frame for invalid JSON, got %+v", update)
+	}
+}
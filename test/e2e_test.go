@@ -2,6 +2,8 @@ package test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,7 +14,11 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/tobert/otlp-mcp/internal/otlpclient"
 	"github.com/tobert/otlp-mcp/internal/otlpreceiver"
+	"github.com/tobert/otlp-mcp/internal/replay"
 	"github.com/tobert/otlp-mcp/internal/storage"
 )
 
@@ -261,3 +267,152 @@ func TestMultipleSpans(t *testing.T) {
 
 	t.Log("Multiple spans test passed")
 }
+
+// TestUnixSocket verifies OTLP export over a Unix domain socket listener.
+func TestUnixSocket(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(100, 100, 100)
+
+	// Keep the path short; Unix socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "otlp-mcp")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	otlpServer, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Socket: filepath.Join(dir, "otlp.sock")},
+		obsStorage,
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go otlpServer.Start(ctx)
+	defer otlpServer.Stop()
+
+	endpoint := otlpServer.Endpoint()
+	if !otlpreceiver.IsSocketEndpoint(endpoint) {
+		t.Fatalf("expected unix:// endpoint, got %s", endpoint)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	// grpc-go resolves unix:///path targets natively
+	conn, err := grpc.NewClient(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to create grpc client: %v", err)
+	}
+	defer conn.Close()
+
+	client := collectortrace.NewTraceServiceClient(conn)
+	_, err = client.Export(context.Background(), &collectortrace.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				ScopeSpans: []*tracepb.ScopeSpans{
+					{
+						Spans: []*tracepb.Span{
+							{
+								TraceId:           []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+								SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+								Name:              "socket-span",
+								StartTimeUnixNano: uint64(time.Now().UnixNano()),
+								EndTimeUnixNano:   uint64(time.Now().UnixNano()),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to export span over socket: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if stats := obsStorage.Traces().Stats(); stats.SpanCount != 1 {
+		t.Errorf("expected 1 span, got %d", stats.SpanCount)
+	}
+}
+
+// TestReplayToReceiver replays a captured JSONL file into a live receiver
+// through the OTLP gRPC client used by the replay command.
+func TestReplayToReceiver(t *testing.T) {
+	obsStorage := storage.NewObservabilityStorage(100, 100, 100)
+
+	otlpServer, err := otlpreceiver.NewUnifiedServer(
+		otlpreceiver.Config{Host: "127.0.0.1", Port: 0},
+		obsStorage,
+	)
+	if err != nil {
+		t.Fatalf("failed to create OTLP server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go otlpServer.Start(ctx)
+	defer otlpServer.Stop()
+
+	// Capture two batches in file exporter layout
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "traces"), 0o755); err != nil {
+		t.Fatalf("failed to create traces dir: %v", err)
+	}
+	var jsonl []byte
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		line, err := protojson.Marshal(&tracepb.TracesData{
+			ResourceSpans: []*tracepb.ResourceSpans{{
+				ScopeSpans: []*tracepb.ScopeSpans{{
+					Spans: []*tracepb.Span{{
+						TraceId:           []byte{9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, 9, byte(i)},
+						SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, byte(i)},
+						Name:              "replayed-span",
+						StartTimeUnixNano: uint64(start.Add(time.Duration(i) * time.Second).UnixNano()),
+						EndTimeUnixNano:   uint64(start.Add(time.Duration(i)*time.Second + time.Millisecond).UnixNano()),
+					}},
+				}},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal batch: %v", err)
+		}
+		jsonl = append(append(jsonl, line...), '\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, "traces", "traces.jsonl"), jsonl, 0o644); err != nil {
+		t.Fatalf("failed to write capture: %v", err)
+	}
+
+	items, err := replay.Load(ctx, []string{dir})
+	if err != nil {
+		t.Fatalf("failed to load capture: %v", err)
+	}
+
+	exporter, err := otlpclient.New(otlpclient.Config{Endpoint: otlpServer.Endpoint()})
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+	defer exporter.Close()
+
+	stats, err := replay.Run(ctx, exporter, items, replay.Options{
+		RewriteTimestamps: true,
+		RewriteTraceIDs:   true,
+	})
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if stats.Spans != 2 {
+		t.Errorf("expected 2 spans sent, got %d", stats.Spans)
+	}
+
+	if got := obsStorage.Traces().Stats().SpanCount; got != 2 {
+		t.Errorf("expected 2 spans stored, got %d", got)
+	}
+	if len(obsStorage.Traces().GetSpansByTraceID("09090909090909090909090909090900")) != 0 {
+		t.Error("trace IDs should have been rewritten")
+	}
+}